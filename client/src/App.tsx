@@ -9,14 +9,15 @@ import { ParameterInput } from './components/ParameterInput';
 import { ConfigInfo } from './components/ConfigInfo';
 import { ResponseCard } from './components/ResponseCard';
 import { useAPI } from './hooks/useAPI';
-import { Resource, Parameter } from './types/api';
+import { DiscoveryChange, Resource, Parameter, ServiceSummary, UIConfig, UIVersionInfo, VersionInfo } from './types/api';
+import { API_BASE_URL } from './constants/api';
 import { Play, RefreshCw, Trash2, Settings } from 'lucide-react';
 
 function App() {
-  const { loading, error, fetchServices, fetchResources, callGRPCMethod, clearCache } = useAPI();
+  const { loading, error, fetchServices, fetchUIConfig, fetchVersion, fetchUIVersion, fetchResources, callGRPCMethod, clearCache } = useAPI();
 
   // State management
-  const [services, setServices] = useState<string[]>([]);
+  const [services, setServices] = useState<ServiceSummary[]>([]);
   const [resources, setResources] = useState<Resource[]>([]);
   const [selectedService, setSelectedService] = useState<string>('');
   const [selectedResource, setSelectedResource] = useState<string>('');
@@ -37,6 +38,49 @@ function App() {
     timestamp: Date;
   }>>([]);
   const [showConfigInfo, setShowConfigInfo] = useState(false);
+  const [uiConfig, setUIConfig] = useState<UIConfig | null>(null);
+  const [versionInfo, setVersionInfo] = useState<VersionInfo | null>(null);
+  const [uiVersion, setUIVersion] = useState<UIVersionInfo | null>(null);
+  const [changedServices, setChangedServices] = useState<string[]>([]);
+
+  // Load server-driven UI settings on mount
+  useEffect(() => {
+    const loadUIConfig = async () => {
+      const config = await fetchUIConfig();
+      if (config) {
+        setUIConfig(config);
+        document.title = config.title;
+      }
+    };
+    loadUIConfig();
+  }, [fetchUIConfig]);
+
+  // Check for a newer release once on mount
+  useEffect(() => {
+    const loadVersion = async () => {
+      setVersionInfo(await fetchVersion());
+    };
+    loadVersion();
+  }, [fetchVersion]);
+
+  // Detect a web client that does not match the server's embedded build
+  useEffect(() => {
+    const loadUIVersion = async () => {
+      setUIVersion(await fetchUIVersion());
+    };
+    loadUIVersion();
+  }, [fetchUIVersion]);
+
+  // Offer to reload the navigation when the server rediscovers changed services
+  useEffect(() => {
+    if (typeof EventSource === 'undefined') return;
+    const events = new EventSource(`${API_BASE_URL}/api/discovery/events`);
+    events.addEventListener('discovery-changed', (event) => {
+      const change: DiscoveryChange = JSON.parse((event as MessageEvent).data);
+      setChangedServices(previous => previous.includes(change.service) ? previous : [...previous, change.service]);
+    });
+    return () => events.close();
+  }, []);
 
   // Load services on mount
   useEffect(() => {
@@ -119,6 +163,7 @@ function App() {
     } catch (err) {
       let errorMessage = 'Unknown error';
       let errorDetails = '';
+      let errorHint = '';
 
       if (err instanceof Error) {
         errorMessage = err.message;
@@ -129,13 +174,24 @@ function App() {
           if (details.details) {
             errorDetails = details.details;
           }
+          if (details.fields?.length) {
+            const fieldLines = details.fields.map((f: { field: string; message: string }) => `  ${f.field}: ${f.message}`);
+            errorDetails = [errorDetails, ...fieldLines].filter(Boolean).join('\n');
+          }
+          if (details.hint) {
+            errorHint = details.hint;
+          }
           if (details.code) {
-            errorMessage = `[${details.code}] ${errorMessage}`;
+            const codes = [details.code, details.grpcCode || details.errorCode].filter(Boolean).join(' ');
+            errorMessage = `[${codes}] ${errorMessage}`;
           }
         }
       }
 
-      const fullErrorMessage = errorDetails ? `${errorMessage}\n\nDetails: ${errorDetails}` : errorMessage;
+      let fullErrorMessage = errorDetails ? `${errorMessage}\n\nDetails: ${errorDetails}` : errorMessage;
+      if (errorHint) {
+        fullErrorMessage += `\n\nHint: ${errorHint}`;
+      }
 
       setResponses(prev => prev.map(resp =>
         resp.id === requestId
@@ -165,6 +221,15 @@ function App() {
     loadServices();
   };
 
+  // Reload services and the selected service's resources after a discovery change
+  const handleReloadNavigation = async () => {
+    setChangedServices([]);
+    handleClearCache();
+    if (selectedService) {
+      setResources(await fetchResources(selectedService));
+    }
+  };
+
 
   const canExecute = selectedService && selectedResource && selectedVerb;
 
@@ -175,10 +240,46 @@ function App() {
         <div className="mb-8">
           <div className="flex items-center justify-between">
             <div>
-              <h1 className="text-3xl font-bold tracking-tight">spacectl-WEB</h1>
+              <h1
+                className="text-3xl font-bold tracking-tight flex items-center"
+                style={uiConfig?.color ? { color: uiConfig.color } : undefined}
+              >
+                {uiConfig?.logo_url && (
+                  <img src={uiConfig.logo_url} alt="" className="h-8 w-8 mr-3" />
+                )}
+                {uiConfig?.title || 'spacectl-WEB'}
+                {uiConfig?.read_only && (
+                  <Badge variant="outline" className="ml-3">Read-only</Badge>
+                )}
+              </h1>
               <p className="text-muted-foreground mt-2">
                 Interactive gRPC API explorer for SpaceONE services
               </p>
+              {versionInfo?.update_available && (
+                <p className="text-sm text-muted-foreground mt-1">
+                  Update available: {versionInfo.version} → {versionInfo.latest_version}.{' '}
+                  {versionInfo.release_url && (
+                    <a href={versionInfo.release_url} target="_blank" rel="noreferrer" className="underline">
+                      Release notes
+                    </a>
+                  )}{' '}
+                  Run <code>spacectl-web update</code> to install it.
+                </p>
+              )}
+              {changedServices.length > 0 && (
+                <p className="text-sm text-muted-foreground mt-1">
+                  Resources or verbs of {changedServices.join(', ')} changed on the server.{' '}
+                  <button type="button" className="underline" onClick={handleReloadNavigation}>
+                    Reload navigation
+                  </button>
+                </p>
+              )}
+              {uiVersion?.mismatch && (
+                <p className="text-sm text-destructive mt-1">
+                  This web client (build {uiVersion.served.hash.slice(0, 12)}) differs from the build embedded in
+                  server {uiVersion.server_version} (build {uiVersion.embedded.hash.slice(0, 12)}); some features may not work.
+                </p>
+              )}
             </div>
             <div className="flex gap-2">
               <Button
@@ -4,6 +4,7 @@ import { Card, CardContent, CardHeader, CardTitle } from './ui/card';
 import { Badge } from './ui/badge';
 import { Settings, User, FileText, Server, X } from 'lucide-react';
 import { API_BASE_URL } from '../constants/api';
+import { UptimeSparkline } from './UptimeSparkline';
 
 interface JWTInfo {
     header: Record<string, any>;
@@ -131,6 +132,7 @@ export const ConfigInfo: React.FC<ConfigInfoProps> = ({ onClose }) => {
                                                 <span className="font-mono text-sm font-medium">{service}</span>
                                                 <code className="text-sm text-muted-foreground">{endpoint}</code>
                                             </div>
+                                            <UptimeSparkline service={service} />
                                         </div>
                                     ))}
                                 </div>
@@ -40,10 +40,15 @@ export const ResourceSelector: React.FC<ResourceSelectorProps> = ({
                     .sort((a, b) => a.Name.localeCompare(b.Name))
                     .map(resource => ({
                         value: resource.Name,
-                        label: resource.Name,
+                        label: resource.Alias ? `${resource.Alias} (${resource.Name})` : resource.Name,
+                        keywords: resource.Tags,
                         display: (
-                            <div className="flex items-center gap-2">
+                            <div className="flex items-center gap-2" title={resource.Description}>
+                                {resource.Alias && <span className="text-sm">{resource.Alias}</span>}
                                 <span className="font-mono text-sm">{resource.Name}</span>
+                                {resource.Tags?.map(tag => (
+                                    <Badge key={tag} variant="outline" className="text-xs">{tag}</Badge>
+                                ))}
                                 {resource.ShortNames && resource.ShortNames.length > 0 && (
                                     <Badge variant="outline" className="text-xs">
                                         {resource.ShortNames.join(', ')}
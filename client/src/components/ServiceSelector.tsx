@@ -1,9 +1,11 @@
 import React from 'react';
 import { SearchableSelect } from './ui/searchable-select';
 import { Label } from './ui/label';
+import { Badge } from './ui/badge';
+import { ServiceSummary } from '../types/api';
 
 interface ServiceSelectorProps {
-    services: string[];
+    services: ServiceSummary[];
     selectedService: string;
     onServiceChange: (service: string) => void;
     loading?: boolean;
@@ -27,11 +29,20 @@ export const ServiceSelector: React.FC<ServiceSelectorProps> = ({
                 disabled={loading || services.length === 0}
                 searchPlaceholder="Search services..."
                 options={services
-                    .sort((a, b) => a.localeCompare(b))
+                    .sort((a, b) => (a.alias || a.name).localeCompare(b.alias || b.name))
                     .map(service => ({
-                        value: service,
-                        label: service,
-                        display: <span className="font-mono text-sm">{service}</span>
+                        value: service.name,
+                        label: service.alias ? `${service.alias} (${service.name})` : service.name,
+                        keywords: service.tags,
+                        display: (
+                            <div className="flex items-center gap-2" title={service.description}>
+                                {service.alias && <span className="text-sm">{service.alias}</span>}
+                                <span className="font-mono text-sm">{service.name}</span>
+                                {service.tags?.map(tag => (
+                                    <Badge key={tag} variant="outline" className="text-xs">{tag}</Badge>
+                                ))}
+                            </div>
+                        )
                     }))}
             />
         </div>
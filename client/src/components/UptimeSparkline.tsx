@@ -0,0 +1,70 @@
+import React, { useEffect, useState } from 'react';
+import { API_BASE_URL } from '../constants/api';
+
+interface StatusSample {
+    time: string;
+    up: boolean;
+    latency_ms: number;
+    error?: string;
+}
+
+interface StatusHistory {
+    service: string;
+    interval_seconds: number;
+    uptime: number;
+    samples: StatusSample[];
+}
+
+interface UptimeSparklineProps {
+    service: string;
+}
+
+// Shows the background health checks of an endpoint; renders nothing when probing is disabled
+export const UptimeSparkline: React.FC<UptimeSparklineProps> = ({ service }) => {
+    const [history, setHistory] = useState<StatusHistory | null>(null);
+
+    useEffect(() => {
+        let cancelled = false;
+        let timer: ReturnType<typeof setTimeout> | undefined;
+
+        const load = async () => {
+            try {
+                const response = await fetch(`${API_BASE_URL}/api/endpoints/${encodeURIComponent(service)}/status/history`);
+                if (!response.ok) return;
+                const data = await response.json();
+                if (cancelled || !data.success) return;
+                setHistory(data.data);
+                timer = setTimeout(load, data.data.interval_seconds * 1000);
+            } catch {
+                // Probing is optional, so failures leave the sparkline hidden
+            }
+        };
+        load();
+
+        return () => {
+            cancelled = true;
+            if (timer) clearTimeout(timer);
+        };
+    }, [service]);
+
+    if (!history || history.samples.length === 0) {
+        return null;
+    }
+
+    const maxLatency = Math.max(...history.samples.map(sample => sample.latency_ms), 1);
+    return (
+        <div className="flex items-center gap-2 mt-2">
+            <div className="flex items-end gap-px h-4">
+                {history.samples.map(sample => (
+                    <div
+                        key={sample.time}
+                        className={sample.up ? 'w-1 bg-green-500' : 'w-1 h-full bg-red-500'}
+                        style={sample.up ? { height: `${Math.max(20, (sample.latency_ms / maxLatency) * 100)}%` } : undefined}
+                        title={`${new Date(sample.time).toLocaleTimeString()}: ${sample.up ? `${sample.latency_ms.toFixed(1)} ms` : sample.error || 'down'}`}
+                    />
+                ))}
+            </div>
+            <span className="text-xs text-muted-foreground">{(history.uptime * 100).toFixed(1)}% up</span>
+        </div>
+    );
+};
@@ -6,6 +6,7 @@ interface Option {
     value: string;
     label: string;
     display?: React.ReactNode;
+    keywords?: string[]; // Also matched when searching
 }
 
 interface SearchableSelectProps {
@@ -47,7 +48,9 @@ export const SearchableSelect: React.FC<SearchableSelectProps> = ({
         }
 
         const filtered = options.filter((option) => {
-            return option.label.toLowerCase().includes(searchTerm.toLowerCase());
+            const term = searchTerm.toLowerCase();
+            return option.label.toLowerCase().includes(term) ||
+                (option.keywords || []).some(keyword => keyword.toLowerCase().includes(term));
         });
 
         setFilteredOptions(filtered);
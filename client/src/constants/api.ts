@@ -14,6 +14,12 @@ export const API_CONFIG = {
     }
 } as const;
 
+// Base path injected by the server as a meta tag when hosted behind a reverse proxy (--base-path)
+const getInjectedBasePath = (): string | null => {
+    const meta = document.querySelector<HTMLMetaElement>('meta[name="spacectl-base-path"]');
+    return meta ? meta.content : null;
+};
+
 // Get API base URL based on environment
 export const getApiBaseUrl = (): string => {
     // Check if REACT_APP_API_URL is set (from environment variables)
@@ -21,6 +27,12 @@ export const getApiBaseUrl = (): string => {
         return process.env.REACT_APP_API_URL;
     }
 
+    // Use the server-injected base path relative to the current origin
+    const basePath = getInjectedBasePath();
+    if (basePath !== null) {
+        return basePath;
+    }
+
     // Fallback to default configuration
     const isDev = process.env.NODE_ENV === 'development';
     const config = isDev ? API_CONFIG.DEV : API_CONFIG.PROD;
@@ -1,5 +1,5 @@
 import { useState, useCallback } from 'react';
-import { APIResponse, Resource, Parameter } from '../types/api';
+import { APIResponse, Resource, Parameter, ServiceSummary, UIConfig, UIVersionInfo, VersionInfo } from '../types/api';
 import { API_BASE_URL } from '../constants/api';
 
 // Simple cache implementation
@@ -30,6 +30,12 @@ class APICache {
 
 const cache = new APICache();
 
+// Read the CSRF token issued by the server (only present when CSRF protection is enabled)
+const getCSRFToken = (): string | null => {
+    const match = document.cookie.match(/(?:^|;\s*)_csrf=([^;]*)/);
+    return match ? decodeURIComponent(match[1]) : null;
+};
+
 export const useAPI = () => {
     const [loading, setLoading] = useState(false);
     const [error, setError] = useState<string | null>(null);
@@ -74,11 +80,26 @@ export const useAPI = () => {
         }
     }, []);
 
-    const fetchServices = useCallback(async (): Promise<string[]> => {
-        const response = await fetchAPI<string[]>('/api/services');
+    const fetchServices = useCallback(async (): Promise<ServiceSummary[]> => {
+        const response = await fetchAPI<ServiceSummary[]>('/api/services?details=true');
         return response?.data || [];
     }, [fetchAPI]);
 
+    const fetchUIConfig = useCallback(async (): Promise<UIConfig | null> => {
+        const response = await fetchAPI<UIConfig>('/api/ui-config');
+        return response?.data || null;
+    }, [fetchAPI]);
+
+    const fetchVersion = useCallback(async (): Promise<VersionInfo | null> => {
+        const response = await fetchAPI<VersionInfo>('/api/version');
+        return response?.data || null;
+    }, [fetchAPI]);
+
+    const fetchUIVersion = useCallback(async (): Promise<UIVersionInfo | null> => {
+        const response = await fetchAPI<UIVersionInfo>('/api/ui-version');
+        return response?.data || null;
+    }, [fetchAPI]);
+
     const fetchResources = useCallback(async (service: string): Promise<Resource[]> => {
         const response = await fetchAPI<Resource[]>(`/api/services/${service}/resources`);
         return response?.data || [];
@@ -104,11 +125,17 @@ export const useAPI = () => {
                 });
             }
 
+            const headers: Record<string, string> = {
+                'Content-Type': 'application/json',
+            };
+            const csrfToken = getCSRFToken();
+            if (csrfToken) {
+                headers['X-CSRF-Token'] = csrfToken;
+            }
+
             const response = await fetch(`${API_BASE_URL}${endpoint}`, {
                 method: 'POST',
-                headers: {
-                    'Content-Type': 'application/json',
-                },
+                headers,
                 body: JSON.stringify(paramsObject),
             });
 
@@ -118,8 +145,12 @@ export const useAPI = () => {
                 // Create a detailed error object with backend error information
                 const errorDetails = data.error ? {
                     code: data.error.code,
+                    errorCode: data.error.error_code,
                     message: data.error.message,
-                    details: data.error.details
+                    details: data.error.details,
+                    grpcCode: data.error.grpc_code,
+                    hint: data.error.hint,
+                    fields: data.error.fields
                 } : {
                     code: response.status,
                     message: `HTTP ${response.status}`,
@@ -149,6 +180,9 @@ export const useAPI = () => {
         loading,
         error,
         fetchServices,
+        fetchUIConfig,
+        fetchVersion,
+        fetchUIVersion,
         fetchResources,
         callGRPCMethod,
         clearCache,
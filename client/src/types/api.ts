@@ -3,8 +3,13 @@ export interface APIResponse<T = any> {
     data?: T;
     error?: {
         code: number;
+        error_code?: string;
         message: string;
         details?: string;
+        grpc_code?: string;
+        hint?: string;
+        fields?: { field: string; message: string }[];
+        request_id?: string;
     };
     duration: string;
 }
@@ -18,6 +23,8 @@ export interface MethodInfo {
     required_params: string[];
     optional_params: string[];
     input_type: string;
+    client_streaming?: boolean;
+    server_streaming?: boolean;
 }
 
 export interface Resource {
@@ -25,6 +32,17 @@ export interface Resource {
     ShortNames?: string[];
     Verbs: string[];
     Methods?: Record<string, MethodInfo>;
+    Alias?: string;
+    Description?: string;
+    Tags?: string[];
+}
+
+// Service with its presentation from server.catalog
+export interface ServiceSummary {
+    name: string;
+    alias?: string;
+    description?: string;
+    tags?: string[];
 }
 
 export interface Parameter {
@@ -32,6 +50,51 @@ export interface Parameter {
     value: string;
 }
 
+export interface UIConfig {
+    title: string;
+    logo_url?: string;
+    color?: string;
+    read_only: boolean;
+    default_environment: string;
+    environments: string[];
+    features: {
+        history: boolean;
+        exports: boolean;
+        usage: boolean;
+        admin: boolean;
+    };
+}
+
+export interface VersionInfo {
+    version: string;
+    latest_version?: string;
+    update_available: boolean;
+    release_url?: string;
+}
+
+export interface UIBuild {
+    hash: string;
+    files: number;
+}
+
+export interface UIVersionInfo {
+    server_version: string;
+    source: 'embedded' | 'directory';
+    served: UIBuild;
+    embedded: UIBuild;
+    mismatch: boolean;
+}
+
 export interface APIConfig {
     baseURL: string;
 }
+
+// Sent on /api/discovery/events when rediscovering a service finds other resources or verbs
+export interface DiscoveryChange {
+    service: string;
+    added_resources?: string[];
+    removed_resources?: string[];
+    added_verbs?: Record<string, string[]>;
+    removed_verbs?: Record<string, string[]>;
+    timestamp: string;
+}
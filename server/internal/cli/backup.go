@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"spacectl-web/server/internal/config"
+	"spacectl-web/server/internal/storage"
+
+	"github.com/spf13/cobra"
+)
+
+// newBackupCommand creates the backup command
+func newBackupCommand(global *globalOptions) *cobra.Command {
+	var output, user string
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Write history, favorites, collections and schedules to a tar.gz archive",
+		Long: "Write the data in server.storage to a tar.gz archive that 'restore' or POST /api/restore accepts.\n" +
+			"Every user's data is included unless --user is set. Stop the server first when using the bolt backend.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, _, err := openStore(cmd, global)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			if output == "" {
+				output = fmt.Sprintf("spacectl-web-backup-%s.tar.gz", time.Now().Format("20060102-150405"))
+			}
+			var w io.Writer = cmd.OutOrStdout()
+			if output != "-" {
+				file, err := os.OpenFile(output, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+				w = file
+			}
+
+			manifest, err := storage.Backup(context.Background(), store, w, user)
+			if err != nil {
+				return err
+			}
+			if output != "-" {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Wrote %s (%s)\n", output, formatCounts(manifest.Counts))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Archive to write, or - for stdout (default spacectl-web-backup-<time>.tar.gz)")
+	cmd.Flags().StringVar(&user, "user", "", "Only back up this user's data")
+	return cmd
+}
+
+// newRestoreCommand creates the restore command
+func newRestoreCommand(global *globalOptions) *cobra.Command {
+	var user string
+	cmd := &cobra.Command{
+		Use:   "restore <archive>",
+		Short: "Restore history, favorites, collections and schedules from a backup archive",
+		Long: "Restore a backup into server.storage, replacing saved items with the same ID.\n" +
+			"Records keep their owners unless --user is set. Stop the server first when using the bolt backend.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, cfg, err := openStore(cmd, global)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			var r io.Reader = cmd.InOrStdin()
+			if args[0] != "-" {
+				file, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+				r = file
+			}
+
+			counts, err := storage.Restore(context.Background(), store, r, user, cfg.Server.Limits.WithDefaults().MaxRestoreBytes)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Restored %s\n", formatCounts(counts))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&user, "user", "", "Restore every record as owned by this user")
+	return cmd
+}
+
+// openStore opens the store configured in the config file and returns it with the config
+func openStore(cmd *cobra.Command, global *globalOptions) (storage.Store, *config.Config, error) {
+	cfg, err := loadConfig(cmd, global)
+	if err != nil {
+		return nil, nil, err
+	}
+	storageConfig := cfg.Server.Storage.WithDefaults()
+	if storageConfig.Backend == config.StorageMemory {
+		return nil, nil, fmt.Errorf("server.storage.backend is memory, which only lives inside the running server; use GET /api/backup and POST /api/restore instead")
+	}
+	store, err := storage.New(storageConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open %s storage: %w", storageConfig.Backend, err)
+	}
+	return store, cfg, nil
+}
+
+// formatCounts describes record counts by kind, e.g. "3 history, 1 favorite"
+func formatCounts(counts map[storage.Kind]int) string {
+	kinds := make([]string, 0, len(counts))
+	for kind := range counts {
+		kinds = append(kinds, string(kind))
+	}
+	sort.Strings(kinds)
+	description := ""
+	for i, kind := range kinds {
+		if i > 0 {
+			description += ", "
+		}
+		description += fmt.Sprintf("%d %s", counts[storage.Kind(kind)], kind)
+	}
+	if description == "" {
+		return "nothing"
+	}
+	return description
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"spacectl-web/server/internal/grpc"
+
+	"github.com/spf13/cobra"
+)
+
+// callOptions holds the flags of the call command
+type callOptions struct {
+	clientOptions
+	params     string
+	parameters []string
+}
+
+// newCallCommand creates the call command
+func newCallCommand(global *globalOptions) *cobra.Command {
+	o := &callOptions{}
+	cmd := &cobra.Command{
+		Use:   "call <service> <resource> <verb>",
+		Short: "Call a gRPC method and print the JSON response",
+		Example: "  spacectl-web call identity user list\n" +
+			"  spacectl-web call identity user get --param user_id=user-123\n" +
+			"  spacectl-web call inventory server list --params '{\"query\": {\"page\": {\"limit\": 10}}}'",
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCall(cmd, global, o, args[0], args[1], args[2])
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return completeMethod(cmd, global, &o.clientOptions, args)
+		},
+	}
+	addClientFlags(cmd, &o.clientOptions)
+	cmd.Flags().StringVar(&o.params, "params", "", "Request parameters as a JSON object")
+	cmd.Flags().StringArrayVarP(&o.parameters, "param", "p", nil, "Request parameter as key=value, repeatable; values are parsed as JSON when possible")
+	return cmd
+}
+
+// runCall calls the method and prints the indented JSON response
+func runCall(cmd *cobra.Command, global *globalOptions, o *callOptions, service, resource, verb string) error {
+	parameters, err := o.parseParameters()
+	if err != nil {
+		return err
+	}
+
+	c, err := newClients(cmd, global, &o.clientOptions)
+	if err != nil {
+		return err
+	}
+	defer c.close()
+
+	serviceCaller, err := c.manager.GetServiceCaller(service)
+	if err != nil {
+		return err
+	}
+	jsonBytes, err := serviceCaller.CallMethod(cmd.Context(), service, resource, verb, parameters)
+	if err != nil {
+		return err
+	}
+
+	var output bytes.Buffer
+	if err := json.Indent(&output, jsonBytes, "", "  "); err != nil {
+		output.Reset()
+		output.Write(jsonBytes)
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), output.String())
+	return nil
+}
+
+// parseParameters merges --params and --param into the request parameters; --param wins
+func (o *callOptions) parseParameters() (map[string]interface{}, error) {
+	parameters := make(map[string]interface{})
+	if o.params != "" {
+		if err := json.Unmarshal([]byte(o.params), &parameters); err != nil {
+			return nil, fmt.Errorf("invalid --params: %w", err)
+		}
+	}
+
+	for _, parameter := range o.parameters {
+		key, raw, found := strings.Cut(parameter, "=")
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid --param '%s': expected key=value", parameter)
+		}
+		var value interface{}
+		if err := json.Unmarshal([]byte(raw), &value); err != nil {
+			value = raw // Not JSON, so take it as a plain string
+		}
+		parameters[key] = value
+	}
+	return parameters, nil
+}
+
+// completeMethod completes the service, resource and verb arguments from the config and discovery
+func completeMethod(cmd *cobra.Command, global *globalOptions, o *clientOptions, args []string) ([]string, cobra.ShellCompDirective) {
+	if len(args) >= 3 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	c, err := newClients(cmd, global, o)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	defer c.close()
+
+	if len(args) == 0 {
+		return c.discovery.GetAvailableServices(), cobra.ShellCompDirectiveNoFileComp
+	}
+	serviceInfo, err := c.discovery.GetServiceInfo(args[0])
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	if len(args) == 1 {
+		return resourceNames(serviceInfo), cobra.ShellCompDirectiveNoFileComp
+	}
+	if resourceInfo, exists := serviceInfo.Resources[args[1]]; exists {
+		return resourceInfo.Verbs, cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// resourceNames returns the resource names of a service in sorted order
+func resourceNames(serviceInfo *grpc.ServiceInfo) []string {
+	names := make([]string, 0, len(serviceInfo.Resources))
+	for name := range serviceInfo.Resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"io"
+	"log"
+
+	"spacectl-web/server/internal/constants"
+	"spacectl-web/server/internal/grant"
+	"spacectl-web/server/internal/grpc"
+	"spacectl-web/server/internal/recording"
+
+	"github.com/spf13/cobra"
+)
+
+// clientOptions holds the flags of commands that talk to the services directly
+type clientOptions struct {
+	offline       bool
+	recordingsDir string
+	verbose       bool
+}
+
+// addClientFlags registers the client flags on a command
+func addClientFlags(cmd *cobra.Command, o *clientOptions) {
+	cmd.Flags().BoolVar(&o.offline, "offline", false, "Use recorded responses without any gRPC connectivity")
+	cmd.Flags().StringVar(&o.recordingsDir, "recordings", constants.DefaultRecordingsDir, "Directory for recorded responses")
+	cmd.Flags().BoolVarP(&o.verbose, "verbose", "v", false, "Print connection and discovery logs to stderr")
+}
+
+// clients holds the gRPC clients used by a command
+type clients struct {
+	pool      *grpc.ConnectionPool
+	discovery *grpc.ServiceDiscovery
+	manager   *grpc.ClientManager
+}
+
+// newClients loads the config and creates the gRPC clients; the caller must call close
+func newClients(cmd *cobra.Command, global *globalOptions, o *clientOptions) (*clients, error) {
+	// Keep the command output clean unless logs were asked for
+	log.SetOutput(io.Discard)
+	if o.verbose {
+		log.SetOutput(cmd.ErrOrStderr())
+	}
+
+	cfg, err := loadConfig(cmd, global)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := grpc.NewConnectionPool(cfg)
+	discovery := grpc.NewServiceDiscovery(cfg, pool)
+	manager := grpc.NewClientManager(cfg, discovery, pool)
+	if o.offline {
+		store := recording.NewStore(o.recordingsDir)
+		discovery.SetRecording(store, true)
+		manager.SetRecording(store, true)
+	} else if err := grant.New(cfg, manager).Grant(cmd.Context()); err != nil {
+		pool.Close()
+		return nil, err
+	}
+	return &clients{pool: pool, discovery: discovery, manager: manager}, nil
+}
+
+// close releases the pooled connections
+func (c *clients) close() {
+	c.pool.Close()
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"spacectl-web/server/internal/config"
+	"spacectl-web/server/internal/validate"
+
+	"github.com/spf13/cobra"
+)
+
+// newConfigCommand creates the config command and its subcommands
+func newConfigCommand(global *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect and validate the config file",
+	}
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "validate",
+			Short: "Validate the config file and print a JSON report",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runValidateConfig(cmd, global)
+			},
+		},
+		&cobra.Command{
+			Use:   "show",
+			Short: "Print the effective configuration with secrets redacted",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				cfg, err := loadConfig(cmd, global)
+				if err != nil {
+					return err
+				}
+				output, err := cfg.EffectiveYAML()
+				if err != nil {
+					return fmt.Errorf("failed to encode effective config: %w", err)
+				}
+				_, err = cmd.OutOrStdout().Write(output)
+				return err
+			},
+		},
+		&cobra.Command{
+			Use:   "profiles",
+			Short: "List the profiles in the config file",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				cfg, err := loadConfig(cmd, global)
+				if err != nil {
+					return err
+				}
+				active := cfg.ActiveProfile()
+				for _, name := range cfg.ProfileNames() {
+					marker := " "
+					if name == active {
+						marker = "*"
+					}
+					fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", marker, name)
+				}
+				return nil
+			},
+		},
+	)
+	return cmd
+}
+
+// newValidateConfigCommand keeps the validate-config command from before subcommands existed
+func newValidateConfigCommand(global *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:    "validate-config",
+		Short:  "Validate the config file (alias of 'config validate')",
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runValidateConfig(cmd, global)
+		},
+	}
+}
+
+// runValidateConfig prints the validation report and fails with exit code 1 when the config is invalid
+func runValidateConfig(cmd *cobra.Command, global *globalOptions) error {
+	global.applyEnv(cmd)
+
+	report := validate.Files(global.configFiles, global.profile)
+	output, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode validation report: %w", err)
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), string(output))
+
+	if !report.Valid {
+		return &exitError{code: 1}
+	}
+	return nil
+}
+
+// loadConfig loads the config files selected by the global flags with environment overrides
+func loadConfig(cmd *cobra.Command, global *globalOptions) (*config.Config, error) {
+	global.applyEnv(cmd)
+
+	cfg, err := config.LoadConfigWithEnv(global.configFiles, global.profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config file '%s': %w", global.configFileNames(), err)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"spacectl-web/server/internal/constants"
+	"spacectl-web/server/internal/daemon"
+
+	"github.com/spf13/cobra"
+)
+
+// statusNotRunning is the exit code of the status command when the server is not running (LSB convention)
+const statusNotRunning = 3
+
+// newStopCommand creates the stop command
+func newStopCommand() *cobra.Command {
+	var pidFile string
+	var timeout time.Duration
+	cmd := &cobra.Command{
+		Use:   "stop",
+		Short: "Stop a server started with --daemon or --pid-file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pid, err := daemon.Stop(pidFile, timeout)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Stopped spacectl-web (PID %d)\n", pid)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&pidFile, "pid-file", constants.DefaultPIDFile, "PID file of the server")
+	cmd.Flags().DurationVar(&timeout, "timeout", constants.DefaultStopTimeout*time.Second, "How long to wait for the server to exit")
+	return cmd
+}
+
+// newStatusCommand creates the status command
+func newStatusCommand() *cobra.Command {
+	var pidFile string
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Report whether a server started with --daemon or --pid-file is running",
+		Long:  "Report whether a server started with --daemon or --pid-file is running.\nExits with status 3 when it is not running.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pid, running, err := daemon.Status(pidFile)
+			if err != nil {
+				return err
+			}
+			if !running {
+				fmt.Fprintln(cmd.OutOrStdout(), "spacectl-web is not running")
+				return &exitError{code: statusNotRunning}
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "spacectl-web is running (PID %d)\n", pid)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&pidFile, "pid-file", constants.DefaultPIDFile, "PID file of the server")
+	return cmd
+}
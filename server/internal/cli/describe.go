@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// describeOptions holds the flags of the describe command
+type describeOptions struct {
+	clientOptions
+	output string
+}
+
+// newDescribeCommand creates the describe command
+func newDescribeCommand(global *globalOptions) *cobra.Command {
+	o := &describeOptions{}
+	cmd := &cobra.Command{
+		Use:   "describe <service> [resource]",
+		Short: "List the resources, verbs and parameters of a service",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDescribe(cmd, global, o, args)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) >= 2 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return completeMethod(cmd, global, &o.clientOptions, args)
+		},
+	}
+	addClientFlags(cmd, &o.clientOptions)
+	cmd.Flags().StringVarP(&o.output, "output", "o", "text", "Output format: text or json")
+	_ = cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions([]string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+// runDescribe prints the discovered methods of a service or one of its resources
+func runDescribe(cmd *cobra.Command, global *globalOptions, o *describeOptions, args []string) error {
+	if o.output != "text" && o.output != "json" {
+		return fmt.Errorf("invalid --output '%s': must be text or json", o.output)
+	}
+
+	c, err := newClients(cmd, global, &o.clientOptions)
+	if err != nil {
+		return err
+	}
+	defer c.close()
+
+	serviceInfo, err := c.discovery.GetServiceInfo(args[0])
+	if err != nil {
+		return err
+	}
+	names := resourceNames(serviceInfo)
+	if len(args) == 2 {
+		if _, exists := serviceInfo.Resources[args[1]]; !exists {
+			return fmt.Errorf("resource '%s' not found in service '%s'", args[1], args[0])
+		}
+		names = []string{args[1]}
+	}
+
+	out := cmd.OutOrStdout()
+	if o.output == "json" {
+		resources := make(map[string]interface{}, len(names))
+		for _, name := range names {
+			resources[name] = serviceInfo.Resources[name]
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(resources)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RESOURCE\tVERB\tREQUIRED\tSTREAMING")
+	for _, name := range names {
+		resourceInfo := serviceInfo.Resources[name]
+		for _, verb := range resourceInfo.Verbs {
+			required, streaming := "", ""
+			if method := resourceInfo.Methods[verb]; method != nil {
+				required = strings.Join(method.RequiredParams, ",")
+				streaming = streamingMode(method.ClientStreaming, method.ServerStreaming)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, verb, required, streaming)
+		}
+	}
+	return w.Flush()
+}
+
+// streamingMode describes the streaming direction of a method
+func streamingMode(client, server bool) string {
+	switch {
+	case client && server:
+		return "bidi"
+	case client:
+		return "client"
+	case server:
+		return "server"
+	}
+	return ""
+}
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"spacectl-web/server/internal/config"
+	"spacectl-web/server/internal/constants"
+
+	"github.com/spf13/cobra"
+)
+
+// globalOptions holds the flags shared by every command
+type globalOptions struct {
+	configFiles []string // Layered in order, later files overriding earlier ones
+	profile     string
+}
+
+// applyEnv applies environment overrides to global flags that were not explicitly set (flag > env > default)
+func (g *globalOptions) applyEnv(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	g.configFiles = config.ListOverride(g.configFiles, flags.Changed("config"), config.EnvConfigFile, []string{constants.DefaultConfigFile})
+	g.profile = config.StringOverride(g.profile, flags.Changed("profile"), config.EnvProfile, "")
+}
+
+// exitError ends a command with a specific exit code after its output has been written
+type exitError struct {
+	code int
+}
+
+func (e *exitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+// configFileNames returns the config files for messages, e.g. team.yaml, token.yaml
+func (g *globalOptions) configFileNames() string {
+	return strings.Join(g.configFiles, ", ")
+}
+
+// environmentHelp lists the environment variables understood by the commands
+const environmentHelp = `Environment variables:
+  SPACECTL_WEB_TOKEN                 Overrides the token in the config file
+  SPACECTL_WEB_ENDPOINT_<SERVICE>    Overrides or adds the endpoint URL for <service>
+  SPACECTL_WEB_PORT                  Port to listen on
+  SPACECTL_WEB_HOST                  Address to bind to
+  SPACECTL_WEB_ALLOW_REMOTE          Allow binding to a non-loopback address (true/false)
+  SPACECTL_WEB_CONFIG                Path to config.yaml file, or several separated like PATH
+  SPACECTL_WEB_BASE_PATH             URL path prefix when served behind a reverse proxy
+  SPACECTL_WEB_WEB_DIR               Serve the web client from this directory
+  SPACECTL_WEB_PROFILE               Profile to activate from the config file
+  SPACECTL_WEB_CONFIG_YAML           Whole config file content, used instead of the config file
+  SPACECTL_WEB_WAIT_FOR_ENDPOINTS    Report /api/readyz as not ready until every endpoint is reachable
+
+SPACECTL_WEB_TOKEN, SPACECTL_WEB_CONFIG_YAML and SPACECTL_WEB_ENDPOINT_<SERVICE> can instead be
+read from a file, such as a mounted secret, by appending _FILE: SPACECTL_WEB_TOKEN_FILE=/run/secrets/token`
+
+// NewRootCommand creates the spacectl-web command tree.
+// Running the root command without a subcommand starts the server, as before subcommands existed.
+func NewRootCommand(webFiles fs.FS) *cobra.Command {
+	global := &globalOptions{}
+	serve := &serveOptions{}
+
+	root := &cobra.Command{
+		Use:   "spacectl-web",
+		Short: "SpaceONE gRPC API Server",
+		Long:  "SpaceONE gRPC API Server\n\n" + environmentHelp,
+		Example: "  spacectl-web serve --config ~/.spaceone/environments/<YOUR_ENV>.yml --port 8080\n" +
+			"  spacectl-web call identity user list --param domain_id=domain-123\n" +
+			"  spacectl-web completion bash > /etc/bash_completion.d/spacectl-web",
+		Version:       constants.Version,
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runServe(cmd, global, serve, webFiles)
+		},
+	}
+	root.PersistentFlags().StringArrayVar(&global.configFiles, "config", []string{constants.DefaultConfigFile},
+		"Path to config.yaml file; repeat to layer files, later ones overriding earlier ones (env: SPACECTL_WEB_CONFIG)")
+	root.PersistentFlags().StringVar(&global.profile, "profile", "", "Profile to activate from the config file (env: SPACECTL_WEB_PROFILE)")
+	addServeFlags(root.Flags(), serve)
+
+	root.AddCommand(
+		newServeCommand(global, webFiles),
+		newCallCommand(global),
+		newDescribeCommand(global),
+		newConfigCommand(global),
+		newValidateConfigCommand(global),
+		newBackupCommand(global),
+		newRestoreCommand(global),
+		newStopCommand(),
+		newServiceCommand(global),
+		newStatusCommand(),
+		newUpdateCommand(),
+		newVersionCommand(),
+	)
+	return root
+}
+
+// Execute runs the command line and returns the process exit code
+func Execute(webFiles fs.FS) int {
+	err := NewRootCommand(webFiles).Execute()
+	if err == nil {
+		return 0
+	}
+
+	var exitErr *exitError
+	if stderrors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	fmt.Fprintln(os.Stderr, "Error:", err)
+	return 1
+}
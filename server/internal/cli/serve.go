@@ -0,0 +1,584 @@
+package cli
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"spacectl-web/server/internal/config"
+	"spacectl-web/server/internal/constants"
+	"spacectl-web/server/internal/daemon"
+	"spacectl-web/server/internal/diagnostics"
+	"spacectl-web/server/internal/disconnects"
+	"spacectl-web/server/internal/errorreport"
+	"spacectl-web/server/internal/grant"
+	"spacectl-web/server/internal/grpc"
+	"spacectl-web/server/internal/handlers"
+	"spacectl-web/server/internal/icons"
+	"spacectl-web/server/internal/jobs"
+	"spacectl-web/server/internal/logging"
+	"spacectl-web/server/internal/metrics"
+	customMiddleware "spacectl-web/server/internal/middleware"
+	"spacectl-web/server/internal/prober"
+	"spacectl-web/server/internal/readiness"
+	"spacectl-web/server/internal/recording"
+	"spacectl-web/server/internal/routes"
+	"spacectl-web/server/internal/scheduler"
+	"spacectl-web/server/internal/service"
+	"spacectl-web/server/internal/session"
+	"spacectl-web/server/internal/sharedcache"
+	"spacectl-web/server/internal/siem"
+	"spacectl-web/server/internal/startup"
+	"spacectl-web/server/internal/storage"
+	"spacectl-web/server/internal/update"
+	"spacectl-web/server/internal/usage"
+	"spacectl-web/server/internal/web"
+	"spacectl-web/server/internal/workflow"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// serveOptions holds the flags of the serve command
+type serveOptions struct {
+	port                 string
+	host                 string
+	allowRemote          bool
+	basePath             string
+	webDir               string
+	accessLog            string
+	appLog               string
+	logMaxSize           int
+	logMaxAge            int
+	logMaxBackups        int
+	logCompress          bool
+	enablePprof          bool
+	enableFaultInjection bool
+	recordingsDir        string
+	record               bool
+	offline              bool
+	daemon               bool
+	pidFile              string
+	daemonLog            string
+	autoRestart          bool
+	waitForEndpoints     bool
+	waitInterval         time.Duration
+}
+
+// newServeCommand creates the serve command, which is also run when no subcommand is given
+func newServeCommand(global *globalOptions, webFiles fs.FS) *cobra.Command {
+	o := &serveOptions{}
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Start the web server",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runServe(cmd, global, o, webFiles)
+		},
+	}
+	addServeFlags(cmd.Flags(), o)
+	return cmd
+}
+
+// addServeFlags registers the serve flags on a flag set
+func addServeFlags(flags *pflag.FlagSet, o *serveOptions) {
+	flags.StringVar(&o.port, "port", constants.DefaultPort, "Port to listen on (env: SPACECTL_WEB_PORT)")
+	flags.StringVar(&o.host, "host", constants.DefaultHost, "Address to bind to (env: SPACECTL_WEB_HOST)")
+	flags.BoolVar(&o.allowRemote, "allow-remote", false, "Allow binding to a non-loopback address (env: SPACECTL_WEB_ALLOW_REMOTE)")
+	flags.StringVar(&o.basePath, "base-path", "", "URL path prefix when served behind a reverse proxy, e.g. /spacectl (env: SPACECTL_WEB_BASE_PATH)")
+	flags.StringVar(&o.webDir, "web-dir", "", "Serve the web client from this directory instead of the embedded files (env: SPACECTL_WEB_WEB_DIR)")
+	flags.StringVar(&o.accessLog, "access-log", "", "Write the HTTP access log to this file instead of stdout")
+	flags.StringVar(&o.appLog, "app-log", "", "Write the application log to this file instead of stdout")
+	flags.IntVar(&o.logMaxSize, "log-max-size", constants.DefaultLogMaxSizeMB, "Maximum log file size in megabytes before rotation")
+	flags.IntVar(&o.logMaxAge, "log-max-age", constants.DefaultLogMaxAgeDays, "Maximum number of days to retain rotated log files")
+	flags.IntVar(&o.logMaxBackups, "log-max-backups", constants.DefaultLogMaxBackups, "Maximum number of rotated log files to retain")
+	flags.BoolVar(&o.logCompress, "log-compress", false, "Gzip rotated log files")
+	flags.BoolVar(&o.enablePprof, "enable-pprof", false, "Expose /debug/pprof/* and /debug/vars runtime diagnostics")
+	flags.BoolVar(&o.enableFaultInjection, "enable-fault-injection", false, "Apply server.fault_injection rules to gRPC calls (development only)")
+	flags.StringVar(&o.recordingsDir, "recordings", constants.DefaultRecordingsDir, "Directory for recorded responses")
+	flags.BoolVar(&o.record, "record", false, "Record real responses to the recordings directory")
+	flags.BoolVar(&o.offline, "offline", false, "Serve recorded responses without any gRPC connectivity")
+	flags.BoolVar(&o.daemon, "daemon", false, "Run in the background; stop with 'spacectl-web stop'")
+	flags.StringVar(&o.pidFile, "pid-file", "", "Write the process ID to this file (default \""+constants.DefaultPIDFile+"\" with --daemon)")
+	flags.StringVar(&o.daemonLog, "daemon-log", constants.DefaultDaemonLogFile, "File receiving the output of the background process with --daemon")
+	flags.BoolVar(&o.autoRestart, "auto-restart", false, "Restart the server when it crashes, e.g. on an unrecovered panic")
+	flags.BoolVar(&o.waitForEndpoints, "wait-for-endpoints", false, "Report /api/readyz as not ready until every endpoint is reachable (env: SPACECTL_WEB_WAIT_FOR_ENDPOINTS)")
+	flags.DurationVar(&o.waitInterval, "wait-interval", constants.DefaultWaitInterval*time.Second, "Interval between endpoint checks with --wait-for-endpoints")
+}
+
+// runServe starts the web server and blocks until it stops
+func runServe(cmd *cobra.Command, global *globalOptions, o *serveOptions, webFiles fs.FS) error {
+	// Apply environment overrides to flags that were not explicitly set (flag > env > default)
+	flags := cmd.Flags()
+	global.applyEnv(cmd)
+	o.port = config.StringOverride(o.port, flags.Changed("port"), config.EnvPort, constants.DefaultPort)
+	o.host = config.StringOverride(o.host, flags.Changed("host"), config.EnvHost, constants.DefaultHost)
+	o.allowRemote = config.BoolOverride(o.allowRemote, flags.Changed("allow-remote"), config.EnvAllowRemote)
+	o.webDir = config.StringOverride(o.webDir, flags.Changed("web-dir"), config.EnvWebDir, "")
+	o.waitForEndpoints = config.BoolOverride(o.waitForEndpoints, flags.Changed("wait-for-endpoints"), config.EnvWaitEndpoints)
+	o.basePath = web.NormalizeBasePath(config.StringOverride(o.basePath, flags.Changed("base-path"), config.EnvBasePath, ""))
+	if o.daemon && o.pidFile == "" {
+		o.pidFile = constants.DefaultPIDFile
+	}
+
+	// Detach from the terminal; the background process runs this command again
+	if o.daemon && !daemon.IsChild() {
+		pid, err := daemon.Start(o.daemonLog)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Started spacectl-web in the background (PID %d, output in %s)\n", pid, o.daemonLog)
+		return nil
+	}
+
+	// Run the server under a supervisor that restarts it when it crashes
+	if o.autoRestart && !daemon.IsSupervised() {
+		if o.pidFile != "" {
+			if err := daemon.WritePIDFile(o.pidFile); err != nil {
+				return err
+			}
+			defer daemon.RemovePIDFile(o.pidFile)
+		}
+		return daemon.Supervise()
+	}
+
+	// Setup log outputs with rotation
+	rotation := logging.RotationOptions{
+		MaxSizeMB:  o.logMaxSize,
+		MaxAgeDays: o.logMaxAge,
+		MaxBackups: o.logMaxBackups,
+		Compress:   o.logCompress,
+	}
+	appLogWriter := logging.NewWriter(o.appLog, rotation)
+	log.SetOutput(appLogWriter)
+	accessLogWriter := logging.NewWriter(o.accessLog, rotation)
+
+	// Refuse to expose the token-bearing API to the network unless explicitly allowed
+	if !isLoopbackHost(o.host) && !o.allowRemote {
+		log.Fatalf("Refusing to bind to non-loopback address '%s': use --allow-remote to expose the server to the network", o.host)
+	}
+
+	// Print ASCII art logo
+	printLogo()
+
+	// Load configuration files with environment overrides
+	cfg, err := config.LoadConfigWithEnv(global.configFiles, global.profile)
+	if err != nil {
+		log.Fatalf("Failed to load config file '%s': %v", global.configFileNames(), err)
+	}
+
+	// Require either a config file or endpoints supplied via the environment
+	if len(cfg.Endpoints) == 0 && !o.offline {
+		if configFileMissing(global.configFiles) {
+			log.Fatalf("Config file not found: %s", global.configFileNames())
+		}
+		log.Fatalf("No endpoints configured in %s: add endpoints or set %s<SERVICE>", global.configFileNames(), config.EnvEndpointPrefix)
+	}
+
+	// Create the connection pool shared by discovery and calls
+	pool := grpc.NewConnectionPool(cfg)
+	defer pool.Close()
+
+	// Create service discovery
+	serviceDiscovery := grpc.NewServiceDiscovery(cfg, pool)
+
+	// Share the discovery cache and quota counters with other replicas
+	var shared *sharedcache.Cache
+	if sharedConfig := cfg.Server.SharedCache; sharedConfig.Enabled && !o.offline {
+		shared, err = sharedcache.New(sharedConfig)
+		if err != nil {
+			log.Fatalf("Failed to setup shared cache: %v", err)
+		}
+		defer shared.Close()
+		serviceDiscovery.SetSharedCache(shared)
+		log.Printf("Sharing discovery cache and quota counters with other replicas through Redis")
+	}
+
+	// Open the store for history, favorites, collections and schedules
+	storageConfig := cfg.Server.Storage.WithDefaults()
+	savedData, err := storage.New(storageConfig)
+	if err != nil {
+		log.Fatalf("Failed to open %s storage: %v", storageConfig.Backend, err)
+	}
+	defer savedData.Close()
+	if storageConfig.Backend == config.StorageMemory {
+		log.Printf("Keeping history, favorites, collections and schedules in memory; set server.storage.backend to persist them")
+	}
+
+	// Ship the audit log and history to the configured SIEM destinations
+	var exporter *siem.Exporter
+	if cfg.Server.SIEM.Enabled() {
+		exporter, err = siem.New(cfg.Server.SIEM)
+		if err != nil {
+			log.Fatalf("Failed to create SIEM exporters: %v", err)
+		}
+		defer exporter.Close()
+		log.Printf("Exporting the audit log to %d SIEM destination(s)", len(cfg.Server.SIEM.Exporters))
+	}
+
+	// Create gRPC client manager
+	grpcManager := grpc.NewClientManager(cfg, serviceDiscovery, pool)
+
+	// Enable response recording or offline replay
+	store := recording.NewStore(o.recordingsDir)
+	if o.record || o.offline {
+		serviceDiscovery.SetRecording(store, o.offline)
+		grpcManager.SetRecording(store, o.offline)
+		if o.offline {
+			log.Printf("Offline mode: serving recorded responses from %s", o.recordingsDir)
+		} else {
+			log.Printf("Recording responses to %s", o.recordingsDir)
+		}
+	}
+
+	// Enable fault injection for UI development
+	if o.enableFaultInjection {
+		grpcManager.SetFaultInjector(grpc.NewFaultInjector(cfg.Server.FaultInjection.Rules))
+		log.Printf("WARNING: fault injection enabled with %d rule(s)", len(cfg.Server.FaultInjection.Rules))
+	}
+
+	// Log the payloads of sampled calls
+	if payloadLogging := cfg.Server.PayloadLogging; payloadLogging.Enabled {
+		payloadWriter := appLogWriter
+		if payloadLogging.Path != "" {
+			payloadWriter = logging.NewWriter(payloadLogging.Path, rotation)
+		}
+		grpcManager.SetPayloadLogger(grpc.NewPayloadLogger(payloadLogging, cfg.Server.Redaction, payloadWriter))
+		log.Printf("Logging payloads of %.0f%% of calls and calls matching %d rule(s)", payloadLogging.SampleRate*100, len(payloadLogging.Rules))
+	}
+
+	// Cache the responses of the methods server.cache sets a TTL for
+	if responseCache := grpc.NewResponseCache(cfg); responseCache != nil {
+		grpcManager.SetResponseCache(responseCache)
+		log.Printf("Caching responses of %d method pattern(s)", len(cfg.Server.Cache))
+	}
+
+	// Log calls slower than the threshold
+	var slowCalls *grpc.SlowCallLog
+	if cfg.Server.SlowCalls.Enabled {
+		slowCalls = grpc.NewSlowCallLog(cfg.Server.SlowCalls)
+		grpcManager.SetSlowCallLog(slowCalls)
+		log.Printf("Logging calls slower than %dms", cfg.Server.SlowCalls.WithDefaults().ThresholdMS)
+	}
+
+	// Obtain the token with the app token and refresh it before it expires. Grants get their
+	// own client manager so the tokens they carry are never recorded.
+	grantManager := grpc.NewClientManager(cfg, serviceDiscovery, pool)
+	var granter *grant.Granter
+	var grantErr error
+	if cfg.UsesGrant() && !o.offline {
+		granter = grant.New(cfg, grantManager)
+		if grantErr = granter.Grant(context.Background()); grantErr != nil {
+			log.Printf("ERROR: %v", grantErr)
+		}
+		go granter.Run(context.Background())
+	}
+
+	// Create Echo instance
+	e := echo.New()
+	e.HideBanner = true
+	e.Logger.SetOutput(appLogWriter)
+
+	// Setup middleware
+	var myLoggerConfig = middleware.LoggerConfig{
+		Format:           `[${time_rfc3339}] ${id} ${method} [${status}] : ${uri} ${error} [${latency_human}]` + "\n",
+		CustomTimeFormat: "2006-01-02 15:04:05",
+		Output:           accessLogWriter,
+	}
+	e.Use(middleware.RequestID())
+	e.Use(middleware.LoggerWithConfig(myLoggerConfig))
+	e.Use(middleware.Recover())
+	if reporting := cfg.Server.ErrorReporting; reporting.Enabled {
+		reporter, err := errorreport.New(reporting)
+		if err != nil {
+			log.Fatalf("Failed to create error reporter: %v", err)
+		}
+		defer reporter.Close()
+		e.Use(customMiddleware.ReportErrors(reporter, cfg.Server.Quotas.WithDefaults().UserHeader))
+		log.Printf("Reporting panics and 5xx responses")
+	}
+	e.Use(middleware.CORS())
+	e.Use(customMiddleware.Compress(cfg.Server.Compression))
+	e.Use(customMiddleware.SecurityHeaders(cfg.Server.SecurityHeaders))
+	e.Use(customMiddleware.CSRF(cfg.Server.CSRF))
+	allowedNetworks, err := cfg.Server.ParseAllowedCIDRs()
+	if err != nil {
+		log.Fatalf("Invalid server configuration: %v", err)
+	}
+	e.Use(customMiddleware.IPAllowlist(allowedNetworks, o.basePath+constants.APIPrefix))
+	e.Use(customMiddleware.BodyLimit(cfg.Server.Limits.WithDefaults().MaxRequestBytes))
+	e.Use(customMiddleware.GRPCMiddleware(grpcManager))
+
+	// Let sessions narrow their calls to a workspace with a scoped token
+	var scopes *session.Store
+	if !o.offline {
+		scopes = session.NewStore()
+		e.Use(customMiddleware.ScopedToken(scopes))
+	}
+
+	// Create handlers
+	handler := handlers.NewHandler(grpcManager, serviceDiscovery, cfg, global.configFileNames())
+	handler.SetRecordings(store)
+	handler.SetStore(savedData)
+	handler.SetWorkflows(workflow.New(savedData, grpcManager, handler.CheckRequest))
+	if exporter != nil {
+		handler.SetSIEM(exporter)
+	}
+	if slowCalls != nil {
+		handler.SetSlowCalls(slowCalls)
+	}
+	if cfg.Server.Icons.Enabled {
+		handler.SetIcons(icons.New(cfg.Server.Icons))
+	}
+	if granter != nil {
+		handler.SetGranter(granter)
+	}
+	if scopes != nil {
+		handler.SetScopes(scopes, grantManager)
+	}
+
+	// Let admin API clients make a session's calls on behalf of a user or workspace
+	if impersonation := cfg.Server.Impersonation; impersonation.Enabled && scopes != nil {
+		auditWriter := appLogWriter
+		if impersonation.AuditLog != "" {
+			auditWriter = logging.NewWriter(impersonation.AuditLog, rotation)
+		}
+		if exporter != nil {
+			auditWriter = exporter.AuditWriter(auditWriter)
+		}
+		audit := log.New(auditWriter, "AUDIT ", log.LstdFlags)
+		handler.SetImpersonation(audit)
+		e.Use(customMiddleware.Impersonate(scopes, impersonation, audit, o.basePath+constants.APIPrefix))
+		log.Printf("Impersonation enabled at %s%s%s%s", o.basePath, constants.APIPrefix, constants.AdminPath, constants.AdminImpersonatePath)
+	}
+
+	// Count the calls abandoned by their clients
+	disconnectTracker := disconnects.NewTracker()
+	handler.SetDisconnects(disconnectTracker)
+	callMiddleware := []echo.MiddlewareFunc{customMiddleware.TrackDisconnects(disconnectTracker)}
+
+	// Track per-user usage and enforce quotas on gRPC calls
+	if quotas := cfg.Server.Quotas.WithDefaults(); quotas.Enabled {
+		tracker := usage.NewTracker(quotas)
+		if shared != nil {
+			tracker.SetSharedCache(shared)
+		}
+		handler.SetUsage(tracker)
+		callMiddleware = append(callMiddleware, customMiddleware.Quota(tracker, quotas.UserHeader))
+		log.Printf("Usage tracking enabled for users identified by the %s header", quotas.UserHeader)
+	}
+
+	// Track running calls so the admin API can list and cancel them
+	if admin := cfg.Server.Admin; admin.Enabled {
+		registry := jobs.NewRegistry()
+		handler.SetJobs(registry)
+		callMiddleware = append(callMiddleware, customMiddleware.TrackJobs(registry))
+		routes.SetupAdminRoutes(e, o.basePath, handler, customMiddleware.AdminAuth(admin.Token))
+		log.Printf("Admin API enabled at %s%s%s", o.basePath, constants.APIPrefix, constants.AdminPath)
+	}
+
+	// Keep the readiness probe failing until the endpoints accept connections
+	if o.waitForEndpoints && !o.offline {
+		gate := readiness.NewGate()
+		handler.SetReadiness(gate)
+		go gate.WaitForEndpoints(context.Background(), cfg, o.waitInterval)
+	}
+
+	// Check every endpoint's health in the background for the status history
+	if proberConfig := cfg.Server.Prober.WithDefaults(); proberConfig.Enabled && !o.offline {
+		endpointProber := prober.New(cfg, pool, proberConfig)
+		handler.SetProber(endpointProber)
+		go endpointProber.Run(context.Background())
+		log.Printf("Checking endpoint health every %s", proberConfig.Interval())
+	}
+
+	// Run the enabled schedules and report how their list results change between runs
+	if schedulerConfig := cfg.Server.Scheduler; schedulerConfig.Enabled && !o.offline {
+		go scheduler.New(savedData, grpcManager, handler.CheckRequest, schedulerConfig).Run(context.Background())
+		log.Printf("Running schedules in the background")
+	}
+
+	// Look up newer releases for the version endpoint
+	if !cfg.Server.UI.DisableUpdateCheck {
+		handler.SetUpdateChecker(update.NewChecker(constants.Version))
+	}
+
+	// Setup routes
+	routes.SetupRoutes(e, o.basePath, handler, callMiddleware...)
+
+	// Let connect-es clients call the upstream services through the Connect protocol
+	if cfg.Server.Connect.Enabled {
+		routes.SetupConnectRoutes(e, o.basePath, handler, callMiddleware...)
+		log.Printf("Connect protocol enabled at %s%s/connect/<service>", o.basePath, constants.APIPrefix)
+	}
+
+	// Setup Prometheus metrics endpoint
+	metrics.Setup(e, o.basePath)
+
+	// Setup runtime diagnostics endpoints
+	if o.enablePprof {
+		diagnostics.Setup(e, o.basePath, grpcManager, serviceDiscovery)
+		log.Printf("Diagnostics enabled at %s%s", o.basePath, diagnostics.DebugPath)
+	}
+
+	// Setup web file serving from the embedded files or an external directory
+	embeddedFS, err := fs.Sub(webFiles, "web")
+	if err != nil {
+		log.Fatalf("Failed to create static filesystem: %v", err)
+	}
+	webFS := embeddedFS
+	if o.webDir != "" {
+		if info, err := os.Stat(o.webDir); err != nil || !info.IsDir() {
+			log.Fatalf("Web directory not found: %s", o.webDir)
+		}
+		log.Printf("Serving web client from %s", o.webDir)
+		webFS = os.DirFS(o.webDir)
+	}
+
+	// Identify the served build so a client built for another server version can be spotted
+	assets, err := webAssets(webFS, embeddedFS, o.webDir != "")
+	if err != nil {
+		log.Fatalf("Failed to hash web files: %v", err)
+	}
+	handler.SetUIAssets(assets)
+	if assets.Mismatch() {
+		log.Printf("WARNING: web client in %s (build %.12s) differs from the build embedded in this server (build %.12s)",
+			o.webDir, assets.Served.Hash, assets.Embedded.Hash)
+	}
+	ui := cfg.Server.UI
+	branding := web.Branding{Title: ui.Title, LogoURL: ui.LogoURL, Color: ui.Color}
+	if err := web.Setup(e, webFS, o.basePath, branding); err != nil {
+		log.Fatalf("Failed to setup web files: %v", err)
+	}
+
+	// Record the process ID unless the supervisor already did
+	if o.pidFile != "" && !daemon.IsSupervised() {
+		if err := daemon.WritePIDFile(o.pidFile); err != nil {
+			log.Fatalf("Failed to write PID file: %v", err)
+		}
+		defer daemon.RemovePIDFile(o.pidFile)
+	}
+
+	// Summarize the configuration so misconfigurations surface before the first call
+	serverAddr := net.JoinHostPort(o.host, o.port)
+	report := startup.NewReport(cfg, global.configFileNames(), serverAddr)
+	report.Features = map[string]bool{
+		"offline":            o.offline,
+		"recording":          o.record && !o.offline,
+		"fault-injection":    o.enableFaultInjection,
+		"shared-cache":       shared != nil,
+		"quotas":             cfg.Server.Quotas.Enabled,
+		"admin-api":          cfg.Server.Admin.Enabled,
+		"prober":             cfg.Server.Prober.Enabled && !o.offline,
+		"scheduler":          cfg.Server.Scheduler.Enabled && !o.offline,
+		"connect":            cfg.Server.Connect.Enabled,
+		"app-token-grant":    granter != nil,
+		"impersonation":      cfg.Server.Impersonation.Enabled && !o.offline,
+		"wait-for-endpoints": o.waitForEndpoints && !o.offline,
+		"pprof":              o.enablePprof,
+		"update-check":       !cfg.Server.UI.DisableUpdateCheck,
+		"persistent-storage": storageConfig.Backend != config.StorageMemory,
+	}
+	if grantErr != nil {
+		report.Warn("%v", grantErr)
+	}
+	if !isLoopbackHost(o.host) {
+		report.Warn("listening on non-loopback address %s", o.host)
+	}
+	handler.SetStartupReport(report)
+	report.Write(log.Writer())
+
+	// Start server on specified host and port
+	log.Printf(constants.LogServerStarting, serverAddr)
+	return serveUntilSignal(e, serverAddr)
+}
+
+// serveUntilSignal runs the server until it fails or the process is asked to stop by a signal
+// or the service manager, then shuts it down gracefully so deferred cleanup such as PID file
+// removal runs
+func serveUntilSignal(e *echo.Echo, serverAddr string) error {
+	ctx, stop := service.NotifyContext(context.Background())
+	defer stop()
+
+	failed := make(chan error, 1)
+	go func() {
+		if err := e.Start(serverAddr); err != nil && !stderrors.Is(err, http.ErrServerClosed) {
+			failed <- err
+		}
+	}()
+
+	select {
+	case err := <-failed:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), constants.DefaultStopTimeout*time.Second)
+	defer cancel()
+	return e.Shutdown(shutdownCtx)
+}
+
+// webAssets hashes the served web client and the embedded build
+func webAssets(webFS, embeddedFS fs.FS, fromDirectory bool) (*web.Assets, error) {
+	embedded, err := web.HashBuild(embeddedFS)
+	if err != nil {
+		return nil, err
+	}
+	assets := &web.Assets{Source: web.SourceEmbedded, Served: embedded, Embedded: embedded}
+	if fromDirectory {
+		assets.Source = web.SourceDirectory
+		if assets.Served, err = web.HashBuild(webFS); err != nil {
+			return nil, err
+		}
+	}
+	return assets, nil
+}
+
+// printLogo prints the ASCII art logo
+func printLogo() {
+	lines := []string{
+		"                                    __  __                   __  ",
+		"   _________  ____ _________  _____/ /_/ /    _      _____  / /_ ",
+		"  / ___/ __ \\/ __ `/ ___/ _ \\/ ___/ __/ /____| | /| / / _ \\/ __ \\",
+		" (__  ) /_/ / /_/ / /__/  __/ /__/ /_/ /_____/ |/ |/ /  __/ /_/ /",
+		"/____/ .___/\\__,_/\\___/\\___/\\___/\\__/_/      |__/|__/\\___/_.___/ ",
+		"    /_/                                                          ",
+	}
+
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+	fmt.Println("____________________________________O/____________________________")
+	fmt.Println("                                    O\\")
+	fmt.Println("High performance, minimalist Go web framework Echo")
+	fmt.Println("")
+
+}
+
+// isLoopbackHost reports whether the bind host only accepts local connections
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// configFileMissing reports whether no config was read because the config file does not exist.
+// Several files must all exist to load, and an inline config replaces the file.
+func configFileMissing(files []string) bool {
+	if _, inline, _ := config.LookupEnv(config.EnvConfigYAML); inline {
+		return false
+	}
+	_, err := os.Stat(files[0])
+	return len(files) == 1 && os.IsNotExist(err)
+}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"spacectl-web/server/internal/constants"
+	"spacectl-web/server/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+// serviceOptions holds the flags of the service install command
+type serviceOptions struct {
+	name   string
+	port   string
+	host   string
+	logDir string
+}
+
+// newServiceCommand creates the service command and its subcommands
+func newServiceCommand(global *globalOptions) *cobra.Command {
+	o := &serviceOptions{}
+	cmd := &cobra.Command{
+		Use:   "service",
+		Short: "Register the server as a Windows service or macOS launchd agent",
+	}
+
+	install := &cobra.Command{
+		Use:     "install",
+		Short:   "Register and start the server with the chosen config and port",
+		Example: "  spacectl-web service install --config ~/.spaceone/environments/dev.yml --port 8080",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runServiceInstall(cmd, global, o)
+		},
+	}
+	install.Flags().StringVar(&o.port, "port", constants.DefaultPort, "Port the service listens on")
+	install.Flags().StringVar(&o.host, "host", constants.DefaultHost, "Address the service binds to")
+	install.Flags().StringVar(&o.logDir, "log-dir", "", "Directory for the service's log files (default: the config file's directory)")
+
+	uninstall := &cobra.Command{
+		Use:   "uninstall",
+		Short: "Stop and unregister the server",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := service.Uninstall(o.name); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Uninstalled service '%s'\n", o.name)
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&o.name, "name", service.DefaultName, "Service name, or launchd label on macOS")
+	cmd.AddCommand(install, uninstall)
+	return cmd
+}
+
+// runServiceInstall registers the serve command with absolute paths, since services do not
+// start in the current directory
+func runServiceInstall(cmd *cobra.Command, global *globalOptions, o *serviceOptions) error {
+	global.applyEnv(cmd)
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate executable: %w", err)
+	}
+	if executable, err = filepath.EvalSymlinks(executable); err != nil {
+		return fmt.Errorf("failed to locate executable: %w", err)
+	}
+	configFiles := make([]string, 0, len(global.configFiles))
+	for _, configFile := range global.configFiles {
+		configFile, err := filepath.Abs(configFile)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(configFile); err != nil {
+			return fmt.Errorf("config file not found: %s", configFile)
+		}
+		configFiles = append(configFiles, configFile)
+	}
+	logDir := o.logDir
+	if logDir == "" {
+		logDir = filepath.Dir(configFiles[0])
+	}
+	if logDir, err = filepath.Abs(logDir); err != nil {
+		return err
+	}
+
+	args := []string{"serve"}
+	for _, configFile := range configFiles {
+		args = append(args, "--config", configFile)
+	}
+	args = append(args,
+		"--port", o.port,
+		"--host", o.host,
+		"--app-log", filepath.Join(logDir, o.name+".log"),
+		"--access-log", filepath.Join(logDir, o.name+"-access.log"),
+		"--recordings", filepath.Join(logDir, constants.DefaultRecordingsDir),
+	)
+	if global.profile != "" {
+		args = append(args, "--profile", global.profile)
+	}
+
+	location, err := service.Install(service.Options{Name: o.name, Executable: executable, Args: args, LogDir: logDir})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Installed service '%s' (%s): http://%s:%s\n", o.name, location, o.host, o.port)
+	return nil
+}
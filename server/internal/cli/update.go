@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"spacectl-web/server/internal/constants"
+	"spacectl-web/server/internal/update"
+
+	"github.com/spf13/cobra"
+)
+
+// downloadTimeout bounds the release lookup and binary download of the update command
+const downloadTimeout = 5 * time.Minute
+
+// newUpdateCommand creates the update command
+func newUpdateCommand() *cobra.Command {
+	var checkOnly, force bool
+	cmd := &cobra.Command{
+		Use:   "update",
+		Short: "Update spacectl-web to the latest release",
+		Long: "Update spacectl-web to the latest GitHub release of " + update.Repository + ".\n" +
+			"The binary for this platform is verified against the release checksums before it replaces the\n" +
+			"current executable. Restart a running server afterwards to use the new version.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			client := &http.Client{Timeout: downloadTimeout}
+			release, err := update.Latest(cmd.Context(), client)
+			if err != nil {
+				return err
+			}
+
+			newer := update.IsNewer(release.Version(), constants.Version)
+			if !newer && !force {
+				fmt.Fprintf(out, "spacectl-web %s is up to date (latest release: %s)\n", constants.Version, release.Version())
+				return nil
+			}
+			if checkOnly {
+				fmt.Fprintf(out, "Update available: %s -> %s (%s)\n", constants.Version, release.Version(), release.HTMLURL)
+				return nil
+			}
+
+			executable, err := os.Executable()
+			if err != nil {
+				return fmt.Errorf("failed to locate executable: %w", err)
+			}
+			if executable, err = filepath.EvalSymlinks(executable); err != nil {
+				return fmt.Errorf("failed to locate executable: %w", err)
+			}
+			fmt.Fprintf(out, "Downloading %s %s...\n", update.BinaryAsset(), release.TagName)
+			if err := update.Apply(cmd.Context(), client, release, executable); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Updated %s from %s to %s\n", executable, constants.Version, release.Version())
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&checkOnly, "check", false, "Only report whether an update is available")
+	cmd.Flags().BoolVar(&force, "force", false, "Install the latest release even if it is not newer, e.g. on dev builds")
+	return cmd
+}
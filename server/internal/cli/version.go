@@ -0,0 +1,22 @@
+package cli
+
+import (
+	"fmt"
+	"runtime"
+
+	"spacectl-web/server/internal/constants"
+
+	"github.com/spf13/cobra"
+)
+
+// newVersionCommand creates the version command
+func newVersionCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Print the version",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Fprintf(cmd.OutOrStdout(), "spacectl-web %s (%s, %s/%s)\n", constants.Version, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+		},
+	}
+}
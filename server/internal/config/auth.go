@@ -0,0 +1,116 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// Endpoint credential types
+const (
+	AuthToken  = "token"   // The token in the token metadata key, as SpaceONE expects
+	AuthBearer = "bearer"  // The token as authorization: Bearer <token>
+	AuthAPIKey = "api_key" // A static key in a header, x-api-key by default
+	AuthBasic  = "basic"   // authorization: Basic with a username and password
+	AuthHeader = "header"  // The token, or a static value, in a custom header
+	AuthNone   = "none"    // No credentials
+)
+
+// DefaultAPIKeyHeader carries the key of api_key credentials unless another header is set
+const DefaultAPIKeyHeader = "x-api-key"
+
+// AuthConfig represents how an endpoint's calls are authenticated.
+// Value and Password may be secret references such as env://GATEWAY_API_KEY.
+type AuthConfig struct {
+	Type     string `yaml:"type" json:"type,omitempty"`
+	Header   string `yaml:"header" json:"header,omitempty"` // Header of api_key and header credentials
+	Value    string `yaml:"value" json:"value,omitempty"`   // Key of api_key credentials; replaces the token of header credentials
+	Username string `yaml:"username" json:"username,omitempty"`
+	Password string `yaml:"password" json:"password,omitempty"`
+}
+
+// AuthType returns the endpoint's credential type: token for gRPC endpoints and bearer for
+// console-api endpoints unless configured
+func (e *EndpointConfig) AuthType() string {
+	switch {
+	case e.Auth.Type != "":
+		return e.Auth.Type
+	case e.IsREST():
+		return AuthBearer
+	}
+	return AuthToken
+}
+
+// ResolveAuth returns the endpoint's credentials with the type filled in and secret references resolved
+func (e *EndpointConfig) ResolveAuth() (AuthConfig, error) {
+	auth := e.Auth
+	auth.Type = e.AuthType()
+	for name, field := range map[string]*string{"value": &auth.Value, "password": &auth.Password} {
+		if !IsSecretRef(*field) {
+			continue
+		}
+		secret, err := ResolveSecret(*field)
+		if err != nil {
+			return AuthConfig{}, fmt.Errorf("auth %s: %w", name, err)
+		}
+		*field = secret
+	}
+	return auth, nil
+}
+
+// Credentials returns the metadata, or HTTP headers, authenticating a call with the token
+func (a AuthConfig) Credentials(token string) map[string]string {
+	token = strings.TrimPrefix(token, "Bearer ")
+	switch a.Type {
+	case AuthBearer:
+		if token != "" {
+			return map[string]string{"authorization": "Bearer " + token}
+		}
+	case AuthAPIKey:
+		header := a.Header
+		if header == "" {
+			header = DefaultAPIKeyHeader
+		}
+		return map[string]string{strings.ToLower(header): a.Value}
+	case AuthBasic:
+		basic := base64.StdEncoding.EncodeToString([]byte(a.Username + ":" + a.Password))
+		return map[string]string{"authorization": "Basic " + basic}
+	case AuthHeader:
+		if a.Value != "" {
+			return map[string]string{strings.ToLower(a.Header): a.Value}
+		}
+		if token != "" {
+			return map[string]string{strings.ToLower(a.Header): token}
+		}
+	case AuthNone:
+	default:
+		return map[string]string{"token": token}
+	}
+	return nil
+}
+
+// validate checks the credential settings, prefixing errors with the given key path
+func (a AuthConfig) validate(key string) error {
+	switch a.Type {
+	case "", AuthToken, AuthBearer, AuthNone:
+	case AuthAPIKey:
+		if a.Value == "" {
+			return fmt.Errorf("%s.value: must be set for api_key credentials", key)
+		}
+	case AuthBasic:
+		if a.Username == "" {
+			return fmt.Errorf("%s.username: must be set for basic credentials", key)
+		}
+	case AuthHeader:
+		if a.Header == "" {
+			return fmt.Errorf("%s.header: must be set for header credentials", key)
+		}
+	default:
+		return fmt.Errorf("%s.type: unsupported credential type '%s', expected %s, %s, %s, %s, %s or %s",
+			key, a.Type, AuthToken, AuthBearer, AuthAPIKey, AuthBasic, AuthHeader, AuthNone)
+	}
+	if a.Header != "" && !metadataKeyPattern.MatchString(strings.ToLower(a.Header)) {
+		return fmt.Errorf("%s.header: may only contain letters, digits, '-', '_' and '.'", key)
+	}
+	return nil
+}
@@ -3,27 +3,319 @@ package config
 import (
 	"fmt"
 	"os"
+	"sort"
+	"sync"
 
 	"gopkg.in/yaml.v2"
 )
 
 // Config represents the configuration structure for config.yaml
 type Config struct {
-	Token     string            `yaml:"token"`
-	Endpoints map[string]string `yaml:"endpoints"`
+	Token     string                     `yaml:"token"`
+	Grant     GrantConfig                `yaml:"grant"` // Obtains the token with an app token instead
+	Endpoints map[string]*EndpointConfig `yaml:"endpoints"`
+
+	// Server settings shared by all profiles
+	Server ServerConfig `yaml:"server"`
+
+	// Named profiles, each with its own token and endpoints
+	DefaultProfile string              `yaml:"default_profile"`
+	Profiles       map[string]*Profile `yaml:"profiles"`
+
+	activeProfile string
+	tokenRef      string // Secret reference the token was resolved from
+	tokenGranted  bool   // The token was obtained with the app token
+	mutex         sync.RWMutex
+}
+
+// Profile represents a named set of credentials and endpoints
+type Profile struct {
+	Token     string                     `yaml:"token"`
+	Grant     GrantConfig                `yaml:"grant"`
+	Endpoints map[string]*EndpointConfig `yaml:"endpoints"`
 }
 
-// LoadConfig loads and parses the config.yaml file
+// EndpointConfig represents the connection settings for a single service endpoint.
+// It can be written either as a plain URL string or as a structured object.
+type EndpointConfig struct {
+	URL            string            `yaml:"url" json:"url"`
+	TLS            TLSConfig         `yaml:"tls" json:"tls"`
+	Timeout        int               `yaml:"timeout" json:"timeout,omitempty"`                   // RPC timeout in seconds
+	MaxMessageSize int               `yaml:"max_message_size" json:"max_message_size,omitempty"` // Max receive message size in bytes
+	Metadata       map[string]string `yaml:"metadata" json:"metadata,omitempty"`                 // Extra gRPC metadata or HTTP headers sent with every call; values may be secret references
+	Aliases        []string          `yaml:"aliases" json:"aliases,omitempty"`
+	Auth           AuthConfig        `yaml:"auth" json:"auth,omitempty"` // How calls are authenticated; the token metadata key by default
+}
+
+// TLSConfig represents TLS options for an endpoint
+type TLSConfig struct {
+	Insecure           bool   `yaml:"insecure" json:"insecure,omitempty"`                         // Use plaintext instead of TLS
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify" json:"insecure_skip_verify,omitempty"` // Skip server certificate verification
+	CAFile             string `yaml:"ca_file" json:"ca_file,omitempty"`
+	ServerName         string `yaml:"server_name" json:"server_name,omitempty"`
+}
+
+// UnmarshalYAML accepts both the legacy string format and the structured object format
+func (e *EndpointConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var url string
+	if err := unmarshal(&url); err == nil {
+		*e = EndpointConfig{URL: url}
+		return nil
+	}
+
+	type rawEndpointConfig EndpointConfig
+	var raw rawEndpointConfig
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	*e = EndpointConfig(raw)
+	return nil
+}
+
+// LoadConfig loads and parses the config.yaml file using the default profile
 func LoadConfig(filename string) (*Config, error) {
+	return LoadProfile(filename, "")
+}
+
+// LoadProfile loads and parses the config.yaml file and activates the given profile.
+// An empty profile name selects the file's default profile.
+func LoadProfile(filename, profile string) (*Config, error) {
 	data, err := os.ReadFile(filename)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
+	return ParseProfile(data, profile)
+}
 
+// ParseProfile parses config.yaml content and activates the given profile
+func ParseProfile(data []byte, profile string) (*Config, error) {
 	var config Config
 	if err := yaml.Unmarshal(data, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse config.yaml: %w", err)
 	}
 
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	if len(config.Profiles) > 0 {
+		if profile == "" {
+			profile = config.DefaultProfile
+		}
+		// Environment overrides are applied by LoadConfigWithEnv
+		if err := config.switchProfile(profile); err != nil {
+			return nil, err
+		}
+	} else {
+		if profile != "" {
+			return nil, fmt.Errorf("profile '%s' not found: config file has no profiles", profile)
+		}
+		if err := config.ResolveToken(); err != nil {
+			return nil, err
+		}
+	}
+
 	return &config, nil
 }
+
+// Validate checks the endpoint settings and reports the offending key on failure
+func (c *Config) Validate() error {
+	for _, name := range c.ProfileNames() {
+		profile := c.Profiles[name]
+		if profile == nil {
+			return fmt.Errorf("profiles.%s: must not be empty", name)
+		}
+		if err := validateEndpoints("profiles."+name+".endpoints", profile.Endpoints); err != nil {
+			return err
+		}
+		if err := profile.Grant.Validate("profiles." + name + ".grant"); err != nil {
+			return err
+		}
+	}
+	if err := c.Grant.Validate("grant"); err != nil {
+		return err
+	}
+	if _, err := c.Server.ParseAllowedCIDRs(); err != nil {
+		return err
+	}
+	if err := c.Server.FaultInjection.Validate(); err != nil {
+		return err
+	}
+	if err := c.Server.Limits.Validate(); err != nil {
+		return err
+	}
+	if err := c.Server.Compression.Validate(); err != nil {
+		return err
+	}
+	if err := c.Server.Quotas.Validate(); err != nil {
+		return err
+	}
+	if err := c.Server.Admin.Validate(); err != nil {
+		return err
+	}
+	if err := c.Server.UI.Validate(); err != nil {
+		return err
+	}
+	if err := c.Server.SharedCache.Validate(); err != nil {
+		return err
+	}
+	if err := c.Server.Storage.Validate(); err != nil {
+		return err
+	}
+	if err := c.Server.Catalog.Validate(); err != nil {
+		return err
+	}
+	if err := c.Server.Prober.Validate(); err != nil {
+		return err
+	}
+	if err := c.Server.Scheduler.Validate(); err != nil {
+		return err
+	}
+	if err := c.Server.Impersonation.Validate(c.Server.Admin); err != nil {
+		return err
+	}
+	if err := c.Server.ClaimDefaults.Validate(); err != nil {
+		return err
+	}
+	if err := c.Server.Presets.Validate(); err != nil {
+		return err
+	}
+	if err := c.Server.Redaction.Validate(); err != nil {
+		return err
+	}
+	if err := c.Server.SIEM.Validate(); err != nil {
+		return err
+	}
+	if err := c.Server.ErrorReporting.Validate(); err != nil {
+		return err
+	}
+	if err := c.Server.PayloadLogging.Validate(); err != nil {
+		return err
+	}
+	if err := c.Server.Cache.Validate(); err != nil {
+		return err
+	}
+	if c.DefaultProfile != "" {
+		if _, exists := c.Profiles[c.DefaultProfile]; !exists {
+			return fmt.Errorf("default_profile: profile '%s' not found", c.DefaultProfile)
+		}
+	}
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return validateEndpoints("endpoints", c.Endpoints)
+}
+
+// validateEndpoints checks a set of endpoint settings, prefixing errors with the given key path
+func validateEndpoints(prefix string, endpoints map[string]*EndpointConfig) error {
+	names := make([]string, 0, len(endpoints))
+	for name := range endpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	aliases := make(map[string]string)
+	for _, name := range names {
+		endpoint := endpoints[name]
+		if endpoint == nil || endpoint.URL == "" {
+			return fmt.Errorf("%s.%s.url: must not be empty", prefix, name)
+		}
+		if _, err := ParseEndpointURL(endpoint.URL); err != nil {
+			return fmt.Errorf("%s.%s.url: %w", prefix, name, err)
+		}
+		if endpoint.Timeout < 0 {
+			return fmt.Errorf("%s.%s.timeout: must not be negative", prefix, name)
+		}
+		if endpoint.MaxMessageSize < 0 {
+			return fmt.Errorf("%s.%s.max_message_size: must not be negative", prefix, name)
+		}
+		if endpoint.TLS.CAFile != "" {
+			if _, err := os.Stat(endpoint.TLS.CAFile); err != nil {
+				return fmt.Errorf("%s.%s.tls.ca_file: %w", prefix, name, err)
+			}
+		}
+		if err := validateMetadata(fmt.Sprintf("%s.%s.metadata", prefix, name), endpoint.Metadata); err != nil {
+			return err
+		}
+		if err := endpoint.Auth.validate(fmt.Sprintf("%s.%s.auth", prefix, name)); err != nil {
+			return err
+		}
+		for _, alias := range endpoint.Aliases {
+			if _, exists := endpoints[alias]; exists {
+				return fmt.Errorf("%s.%s.aliases: '%s' conflicts with an endpoint name", prefix, name, alias)
+			}
+			if owner, exists := aliases[alias]; exists {
+				return fmt.Errorf("%s.%s.aliases: '%s' is already an alias of '%s'", prefix, name, alias, owner)
+			}
+			aliases[alias] = name
+		}
+	}
+	return nil
+}
+
+// ServiceNames returns the configured endpoint names in sorted order
+func (c *Config) ServiceNames() []string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	names := make([]string, 0, len(c.Endpoints))
+	for name := range c.Endpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetEndpoint returns the endpoint settings for a service name or one of its aliases
+func (c *Config) GetEndpoint(serviceName string) (*EndpointConfig, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if endpoint, exists := c.Endpoints[serviceName]; exists {
+		return endpoint, true
+	}
+	for _, endpoint := range c.Endpoints {
+		for _, alias := range endpoint.Aliases {
+			if alias == serviceName {
+				return endpoint, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// EndpointURLs returns a map of service name to endpoint URL
+func (c *Config) EndpointURLs() map[string]string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	urls := make(map[string]string, len(c.Endpoints))
+	for name, endpoint := range c.Endpoints {
+		urls[name] = endpoint.URL
+	}
+	return urls
+}
+
+// Address returns the host:port to dial, or the URL itself when it cannot be parsed
+func (e *EndpointConfig) Address() string {
+	parsed, err := ParseEndpointURL(e.URL)
+	if err != nil {
+		return e.URL
+	}
+	return parsed.Address()
+}
+
+// IsPlaintext reports whether the endpoint should be dialed without TLS
+func (e *EndpointConfig) IsPlaintext() bool {
+	if e.TLS.Insecure {
+		return true
+	}
+	parsed, err := ParseEndpointURL(e.URL)
+	return err == nil && !parsed.IsTLS()
+}
+
+// IsREST reports whether the endpoint is a console-api called over HTTP instead of gRPC
+func (e *EndpointConfig) IsREST() bool {
+	parsed, err := ParseEndpointURL(e.URL)
+	return err == nil && parsed.IsREST()
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"net/url"
+
+	"gopkg.in/yaml.v2"
+)
+
+// redacted replaces secret values in the effective configuration
+const redacted = "<redacted>"
+
+// effectiveConfig is the configuration in effect, in the config file format
+type effectiveConfig struct {
+	Profile   string                     `yaml:"profile"`
+	Token     string                     `yaml:"token"`
+	Grant     *GrantConfig               `yaml:"grant,omitempty"`
+	Endpoints map[string]*EndpointConfig `yaml:"endpoints"`
+	Server    ServerConfig               `yaml:"server"`
+}
+
+// EffectiveYAML returns the configuration in effect as YAML: the active profile's endpoints
+// with environment overrides applied and server settings with defaults filled in.
+// The token, app token, admin token, endpoint metadata values and credentials, shared cache and storage
+// passwords, SIEM exporter and error webhook headers, and the Sentry DSN are redacted.
+func (c *Config) EffectiveYAML() ([]byte, error) {
+	effective := effectiveConfig{
+		Profile: c.ActiveProfile(),
+		Server:  c.Server.WithDefaults(),
+	}
+
+	c.mutex.RLock()
+	switch {
+	case c.tokenRef != "":
+		effective.Token = c.tokenRef // Secret references are not secret themselves
+	case c.Token != "":
+		effective.Token = redacted
+	}
+	if c.Grant.Enabled() {
+		grant := c.Grant.WithDefaults()
+		if !IsSecretRef(grant.AppToken) {
+			grant.AppToken = redacted
+		}
+		effective.Grant = &grant
+	}
+	effective.Endpoints = make(map[string]*EndpointConfig, len(c.Endpoints))
+	for name, endpoint := range c.Endpoints {
+		endpointCopy := *endpoint
+		if len(endpoint.Metadata) > 0 {
+			endpointCopy.Metadata = make(map[string]string, len(endpoint.Metadata))
+			for key := range endpoint.Metadata {
+				endpointCopy.Metadata[key] = redacted
+			}
+		}
+		for _, secret := range []*string{&endpointCopy.Auth.Value, &endpointCopy.Auth.Password} {
+			if *secret != "" && !IsSecretRef(*secret) {
+				*secret = redacted
+			}
+		}
+		effective.Endpoints[name] = &endpointCopy
+	}
+	c.mutex.RUnlock()
+
+	if effective.Server.Admin.Token != "" {
+		effective.Server.Admin.Token = redacted
+	}
+	if cacheURL, err := url.Parse(effective.Server.SharedCache.URL); err == nil && cacheURL.User != nil {
+		effective.Server.SharedCache.URL = cacheURL.Redacted()
+	}
+	if dsn, err := url.Parse(effective.Server.Storage.DSN); err == nil && dsn.User != nil {
+		effective.Server.Storage.DSN = dsn.Redacted()
+	}
+	exporters := make([]SIEMExporterConfig, 0, len(effective.Server.SIEM.Exporters))
+	for _, exporter := range effective.Server.SIEM.Exporters {
+		headers := make(map[string]string, len(exporter.Headers))
+		for key, value := range exporter.Headers {
+			if !IsSecretRef(value) {
+				value = redacted
+			}
+			headers[key] = value
+		}
+		exporter.Headers = headers
+		exporters = append(exporters, exporter.WithDefaults())
+	}
+	effective.Server.SIEM.Exporters = exporters
+
+	reporting := &effective.Server.ErrorReporting
+	if reporting.SentryDSN != "" && !IsSecretRef(reporting.SentryDSN) {
+		reporting.SentryDSN = redacted // The key is the DSN's user
+	}
+	if len(reporting.Headers) > 0 {
+		headers := make(map[string]string, len(reporting.Headers))
+		for key, value := range reporting.Headers {
+			if !IsSecretRef(value) {
+				value = redacted
+			}
+			headers[key] = value
+		}
+		reporting.Headers = headers
+	}
+	return yaml.Marshal(&effective)
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Endpoint URL schemes
+const (
+	SchemeGRPC    = "grpc"     // Plaintext
+	SchemeGRPCSSL = "grpc+ssl" // TLS
+	SchemeGRPCS   = "grpcs"    // TLS, short form of grpc+ssl
+	SchemeHTTP    = "http"     // Plaintext console-api (REST)
+	SchemeHTTPS   = "https"    // TLS console-api (REST)
+)
+
+// Default ports used when an endpoint URL has none
+const (
+	DefaultPlaintextPort = "80"
+	DefaultTLSPort       = "443"
+)
+
+// EndpointURL is a parsed endpoint URL such as grpc+ssl://identity.example.com:443/v1,
+// or https://console-api.example.com for a console-api endpoint
+type EndpointURL struct {
+	Scheme string
+	Host   string
+	Port   string
+	Path   string // Ignored when dialing gRPC, e.g. /v1; the route prefix for REST
+}
+
+// ParseEndpointURL parses an endpoint URL, defaulting the port from the scheme
+func ParseEndpointURL(rawURL string) (*EndpointURL, error) {
+	if !strings.Contains(rawURL, "://") {
+		return nil, fmt.Errorf("'%s' has no scheme, expected %s://host:port or %s://host:port", rawURL, SchemeGRPCSSL, SchemeGRPC)
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("'%s' is not a valid URL: %w", rawURL, err)
+	}
+
+	endpoint := &EndpointURL{Scheme: strings.ToLower(parsed.Scheme), Host: parsed.Hostname(), Port: parsed.Port(), Path: parsed.Path}
+	switch endpoint.Scheme {
+	case SchemeGRPC, SchemeHTTP:
+		if endpoint.Port == "" {
+			endpoint.Port = DefaultPlaintextPort
+		}
+	case SchemeGRPCSSL, SchemeGRPCS, SchemeHTTPS:
+		if endpoint.Port == "" {
+			endpoint.Port = DefaultTLSPort
+		}
+	default:
+		return nil, fmt.Errorf("unsupported scheme '%s', expected %s, %s, %s, %s or %s",
+			parsed.Scheme, SchemeGRPCSSL, SchemeGRPCS, SchemeGRPC, SchemeHTTPS, SchemeHTTP)
+	}
+
+	switch {
+	case endpoint.Host == "":
+		return nil, fmt.Errorf("'%s' has no host", rawURL)
+	case parsed.User != nil:
+		return nil, fmt.Errorf("'%s' must not contain credentials, use the token setting instead", rawURL)
+	case parsed.RawQuery != "" || parsed.Fragment != "":
+		return nil, fmt.Errorf("'%s' must not contain a query or fragment", rawURL)
+	case strings.HasSuffix(parsed.Host, ":"):
+		return nil, fmt.Errorf("'%s' has an empty port", rawURL)
+	}
+	return endpoint, nil
+}
+
+// Address returns the host:port to dial
+func (u *EndpointURL) Address() string {
+	return net.JoinHostPort(u.Host, u.Port)
+}
+
+// IsTLS reports whether the scheme requires TLS
+func (u *EndpointURL) IsTLS() bool {
+	return u.Scheme != SchemeGRPC && u.Scheme != SchemeHTTP
+}
+
+// IsREST reports whether the endpoint is a console-api served over HTTP instead of gRPC
+func (u *EndpointURL) IsREST() bool {
+	return u.Scheme == SchemeHTTP || u.Scheme == SchemeHTTPS
+}
+
+// metadataKeyPattern matches the metadata keys gRPC allows in text form
+var metadataKeyPattern = regexp.MustCompile(`^[0-9a-z_.-]+$`)
+
+// reservedMetadataKeys are set by the server itself and cannot be configured per endpoint
+var reservedMetadataKeys = map[string]string{
+	"token":         "configure auth instead",
+	"authorization": "configure auth instead",
+	"x-request-id":  "it carries the ID of each request",
+}
+
+// validateMetadata checks the metadata keys of an endpoint
+func validateMetadata(key string, metadata map[string]string) error {
+	names := make([]string, 0, len(metadata))
+	for name := range metadata {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		lower := strings.ToLower(name)
+		switch {
+		case !metadataKeyPattern.MatchString(lower):
+			return fmt.Errorf("%s.%s: key may only contain letters, digits, '-', '_' and '.'", key, name)
+		case strings.HasPrefix(lower, "grpc-"):
+			return fmt.Errorf("%s.%s: keys starting with 'grpc-' are reserved by gRPC", key, name)
+		case strings.HasSuffix(lower, "-bin"):
+			return fmt.Errorf("%s.%s: binary metadata is not supported", key, name)
+		case reservedMetadataKeys[lower] != "":
+			return fmt.Errorf("%s.%s: reserved key, %s", key, name, reservedMetadataKeys[lower])
+		}
+	}
+	return nil
+}
+
+// ResolveMetadata returns the endpoint's metadata with lower-case keys and secret references
+// such as env://GATEWAY_API_KEY resolved to their values
+func (e *EndpointConfig) ResolveMetadata() (map[string]string, error) {
+	resolved := make(map[string]string, len(e.Metadata))
+	for name, value := range e.Metadata {
+		if IsSecretRef(value) {
+			secret, err := ResolveSecret(value)
+			if err != nil {
+				return nil, fmt.Errorf("metadata '%s': %w", name, err)
+			}
+			value = secret
+		}
+		resolved[strings.ToLower(name)] = value
+	}
+	return resolved, nil
+}
@@ -0,0 +1,177 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Environment variable names used to override configuration values
+const (
+	EnvPrefix         = "SPACECTL_WEB_"
+	EnvToken          = EnvPrefix + "TOKEN"
+	EnvPort           = EnvPrefix + "PORT"
+	EnvHost           = EnvPrefix + "HOST"
+	EnvAllowRemote    = EnvPrefix + "ALLOW_REMOTE"
+	EnvConfigFile     = EnvPrefix + "CONFIG"
+	EnvProfile        = EnvPrefix + "PROFILE"
+	EnvBasePath       = EnvPrefix + "BASE_PATH"
+	EnvWebDir         = EnvPrefix + "WEB_DIR"
+	EnvWaitEndpoints  = EnvPrefix + "WAIT_FOR_ENDPOINTS"
+	EnvEndpointPrefix = EnvPrefix + "ENDPOINT_"
+	EnvConfigYAML     = EnvPrefix + "CONFIG_YAML" // Whole config file content, used instead of the config file
+)
+
+// EnvFileSuffix marks a variable whose value is read from the named file, e.g. a mounted
+// secret: SPACECTL_WEB_TOKEN_FILE=/run/secrets/token
+const EnvFileSuffix = "_FILE"
+
+// ApplyEnvOverrides layers SPACECTL_WEB_* environment variables over the loaded file values
+func (c *Config) ApplyEnvOverrides() error {
+	token, ok, err := LookupEnv(EnvToken)
+	if err != nil {
+		return err
+	}
+	if ok {
+		c.mutex.Lock()
+		c.Token = token
+		c.tokenRef = ""
+		c.mutex.Unlock()
+
+		if err := c.ResolveToken(); err != nil {
+			return err
+		}
+	}
+
+	for _, env := range os.Environ() {
+		key, value, found := strings.Cut(env, "=")
+		if !found || !strings.HasPrefix(key, EnvEndpointPrefix) || value == "" {
+			continue
+		}
+		if name, isFile := strings.CutSuffix(key, EnvFileSuffix); isFile {
+			if _, set := os.LookupEnv(name); set {
+				continue // The plain variable takes precedence
+			}
+			if value, err = readEnvFile(key, value); err != nil {
+				return err
+			}
+			key = name
+		}
+
+		// SPACECTL_WEB_ENDPOINT_INVENTORY_V2 -> inventory_v2
+		serviceName := strings.ToLower(strings.TrimPrefix(key, EnvEndpointPrefix))
+		if serviceName == "" {
+			continue
+		}
+
+		c.mutex.Lock()
+		if c.Endpoints == nil {
+			c.Endpoints = make(map[string]*EndpointConfig)
+		}
+		if endpoint, exists := c.Endpoints[serviceName]; exists {
+			endpoint.URL = value
+		} else {
+			c.Endpoints[serviceName] = &EndpointConfig{URL: value}
+		}
+		c.mutex.Unlock()
+	}
+
+	return nil
+}
+
+// LoadConfigWithEnv loads the config files layered in order, activates the profile and applies
+// environment overrides. A missing config file is allowed so the server can be configured entirely
+// from the environment, but when several files are layered each of them must exist.
+func LoadConfigWithEnv(filenames []string, profile string) (*Config, error) {
+	cfg := &Config{}
+	inline, hasInline, err := LookupEnv(EnvConfigYAML)
+	if err != nil {
+		return nil, err
+	}
+	if hasInline {
+		loaded, err := ParseProfile([]byte(inline), profile)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", EnvConfigYAML, err)
+		}
+		cfg = loaded
+	} else if _, err := os.Stat(filenames[0]); err == nil || len(filenames) > 1 {
+		loaded, err := LoadFiles(filenames, profile)
+		if err != nil {
+			return nil, err
+		}
+		cfg = loaded
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if err := cfg.ApplyEnvOverrides(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// LookupEnv returns the value of an environment variable, or the content of the file named by
+// the variable with the _FILE suffix when the variable itself is not set
+func LookupEnv(name string) (string, bool, error) {
+	if value, ok := os.LookupEnv(name); ok {
+		return value, true, nil
+	}
+	path, ok := os.LookupEnv(name + EnvFileSuffix)
+	if !ok || path == "" {
+		return "", false, nil
+	}
+	value, err := readEnvFile(name+EnvFileSuffix, path)
+	return value, err == nil, err
+}
+
+// readEnvFile reads the file named by a _FILE variable, without surrounding whitespace
+func readEnvFile(name, path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// StringOverride resolves a setting with flag > env > default precedence
+func StringOverride(flagValue string, flagSet bool, envName, defaultValue string) string {
+	if flagSet {
+		return flagValue
+	}
+	if value, ok := os.LookupEnv(envName); ok && value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// ListOverride resolves a list setting with flag > env > default precedence. The environment
+// variable separates values like PATH, e.g. team.yaml:token.yaml.
+func ListOverride(flagValue []string, flagSet bool, envName string, defaultValue []string) []string {
+	if flagSet {
+		return flagValue
+	}
+	if value, ok := os.LookupEnv(envName); ok && value != "" {
+		return filepath.SplitList(value)
+	}
+	return defaultValue
+}
+
+// BoolOverride resolves a boolean setting with flag > env > flag default precedence
+func BoolOverride(flagValue, flagSet bool, envName string) bool {
+	if flagSet {
+		return flagValue
+	}
+	if value, ok := os.LookupEnv(envName); ok {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+	}
+	return flagValue
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Scopes an app token can be granted for
+const (
+	GrantScopeSystem    = "SYSTEM"
+	GrantScopeDomain    = "DOMAIN"
+	GrantScopeWorkspace = "WORKSPACE"
+	GrantScopeUser      = "USER"
+)
+
+// Grant defaults
+const (
+	DefaultGrantService              = "identity"
+	DefaultGrantRefreshBeforeSeconds = 300
+)
+
+// GrantConfig exchanges a SpaceONE app token (API key) for a scoped access token through
+// the identity Token.grant API, the way service accounts authenticate. AppToken may be a
+// secret reference such as env://SPACEONE_APP_TOKEN.
+type GrantConfig struct {
+	AppToken             string `yaml:"app_token"`
+	Scope                string `yaml:"scope"` // SYSTEM, DOMAIN, WORKSPACE or USER; WORKSPACE when workspace_id is set, DOMAIN otherwise
+	DomainID             string `yaml:"domain_id"`
+	WorkspaceID          string `yaml:"workspace_id"`
+	Service              string `yaml:"service"`                // Endpoint of the identity service
+	RefreshBeforeSeconds int    `yaml:"refresh_before_seconds"` // How long before expiry the access token is refreshed
+}
+
+// Enabled reports whether an app token is configured
+func (g GrantConfig) Enabled() bool {
+	return g.AppToken != ""
+}
+
+// WithDefaults returns the grant settings with unset values replaced by their defaults
+func (g GrantConfig) WithDefaults() GrantConfig {
+	g.Scope = strings.ToUpper(g.Scope)
+	if g.Scope == "" {
+		g.Scope = GrantScopeDomain
+		if g.WorkspaceID != "" {
+			g.Scope = GrantScopeWorkspace
+		}
+	}
+	if g.Service == "" {
+		g.Service = DefaultGrantService
+	}
+	if g.RefreshBeforeSeconds == 0 {
+		g.RefreshBeforeSeconds = DefaultGrantRefreshBeforeSeconds
+	}
+	return g
+}
+
+// RefreshBefore returns how long before expiry the access token is refreshed
+func (g GrantConfig) RefreshBefore() time.Duration {
+	return time.Duration(g.WithDefaults().RefreshBeforeSeconds) * time.Second
+}
+
+// ResolveAppToken returns the app token, resolving it if it is a secret reference
+func (g GrantConfig) ResolveAppToken() (string, error) {
+	appToken, err := ResolveSecret(g.AppToken)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve app token: %w", err)
+	}
+	return appToken, nil
+}
+
+// Validate checks the grant settings, prefixing errors with the given key path
+func (g GrantConfig) Validate(key string) error {
+	if !g.Enabled() {
+		if g.Scope != "" || g.DomainID != "" || g.WorkspaceID != "" {
+			return fmt.Errorf("%s.app_token: must be set", key)
+		}
+		return nil
+	}
+
+	g = g.WithDefaults()
+	switch g.Scope {
+	case GrantScopeSystem, GrantScopeDomain, GrantScopeWorkspace, GrantScopeUser:
+	default:
+		return fmt.Errorf("%s.scope: must be one of %s, %s, %s or %s",
+			key, GrantScopeSystem, GrantScopeDomain, GrantScopeWorkspace, GrantScopeUser)
+	}
+	if g.DomainID == "" {
+		return fmt.Errorf("%s.domain_id: must be set", key)
+	}
+	if g.Scope == GrantScopeWorkspace && g.WorkspaceID == "" {
+		return fmt.Errorf("%s.workspace_id: must be set for the %s scope", key, GrantScopeWorkspace)
+	}
+	if g.RefreshBeforeSeconds < 0 {
+		return fmt.Errorf("%s.refresh_before_seconds: must not be negative", key)
+	}
+	return nil
+}
+
+// GetGrant returns the active grant settings
+func (c *Config) GetGrant() GrantConfig {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.Grant
+}
+
+// UsesGrant reports whether the top-level config or any profile exchanges an app token
+func (c *Config) UsesGrant() bool {
+	if c.GetGrant().Enabled() {
+		return true
+	}
+	for _, profile := range c.Profiles {
+		if profile != nil && profile.Grant.Enabled() {
+			return true
+		}
+	}
+	return false
+}
+
+// SetGrantedToken makes an access token obtained with the app token the current token
+func (c *Config) SetGrantedToken(token string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.Token = token
+	c.tokenRef = ""
+	c.tokenGranted = true
+}
+
+// TokenGranted reports whether the current token was obtained with the app token
+func (c *Config) TokenGranted() bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.tokenGranted
+}
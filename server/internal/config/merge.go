@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// LoadFiles loads config files layered in order and activates the given profile, so a shared
+// team endpoints file can be combined with a personal token file. See MergeFiles.
+func LoadFiles(filenames []string, profile string) (*Config, error) {
+	data, err := MergeFiles(filenames)
+	if err != nil {
+		return nil, err
+	}
+	return ParseProfile(data, profile)
+}
+
+// MergeFiles merges config files into one, later files overriding earlier ones. Settings are
+// merged key by key and endpoint maps service by service, with each endpoint taken whole from
+// the last file defining it. Empty values do not override, so the token comes from the last
+// file providing one.
+func MergeFiles(filenames []string) ([]byte, error) {
+	if len(filenames) == 1 {
+		data, err := os.ReadFile(filenames[0])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		return data, nil
+	}
+
+	merged := make(map[interface{}]interface{})
+	for _, filename := range filenames {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		var layer map[interface{}]interface{}
+		if err := yaml.Unmarshal(data, &layer); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+		}
+		mergeLayer(merged, layer, "")
+	}
+	return yaml.Marshal(merged)
+}
+
+// mergeLayer merges the settings of a file into dst; path is the dotted key of the maps
+func mergeLayer(dst, src map[interface{}]interface{}, path string) {
+	for key, value := range src {
+		if value == nil || value == "" {
+			continue
+		}
+		child := fmt.Sprint(key)
+		if path != "" {
+			child = path + "." + child
+		}
+		existing, dstIsMap := dst[key].(map[interface{}]interface{})
+		layer, srcIsMap := value.(map[interface{}]interface{})
+		if dstIsMap && srcIsMap && !isEndpointsKey(path) {
+			mergeLayer(existing, layer, child)
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+// isEndpointsKey reports whether a dotted key is an endpoint map: endpoints or profiles.<name>.endpoints
+func isEndpointsKey(path string) bool {
+	if path == "endpoints" {
+		return true
+	}
+	parts := strings.Split(path, ".")
+	return len(parts) == 3 && parts[0] == "profiles" && parts[2] == "endpoints"
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DefaultProfileName is reported as the active profile when the config file has no profiles
+const DefaultProfileName = "default"
+
+// ProfileNames returns the configured profile names in sorted order
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ActiveProfile returns the name of the currently active profile
+func (c *Config) ActiveProfile() string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if c.activeProfile == "" {
+		return DefaultProfileName
+	}
+	return c.activeProfile
+}
+
+// SwitchProfile makes the named profile's token, grant and endpoints the active configuration
+// and reapplies the environment overrides over them. An empty name selects the first profile
+// when no default profile is configured.
+func (c *Config) SwitchProfile(name string) error {
+	if err := c.switchProfile(name); err != nil {
+		return err
+	}
+	return c.ApplyEnvOverrides()
+}
+
+// switchProfile activates a profile without applying environment overrides. The profile's
+// token is resolved first, so a failure leaves the active profile unchanged.
+func (c *Config) switchProfile(name string) error {
+	if name == "" {
+		names := c.ProfileNames()
+		if len(names) == 0 {
+			return fmt.Errorf("config file has no profiles")
+		}
+		name = names[0]
+	}
+
+	profile, exists := c.Profiles[name]
+	if !exists {
+		return fmt.Errorf("profile '%s' not found", name)
+	}
+
+	token, tokenRef := profile.Token, ""
+	if IsSecretRef(token) {
+		resolved, err := ResolveSecret(token)
+		if err != nil {
+			return fmt.Errorf("failed to resolve token: %w", err)
+		}
+		token, tokenRef = resolved, profile.Token
+	}
+
+	// Copy endpoints so runtime overrides don't modify the stored profile
+	endpoints := make(map[string]*EndpointConfig, len(profile.Endpoints))
+	for serviceName, endpoint := range profile.Endpoints {
+		endpointCopy := *endpoint
+		endpoints[serviceName] = &endpointCopy
+	}
+
+	c.mutex.Lock()
+	c.Token = token
+	c.Grant = profile.Grant
+	c.Endpoints = endpoints
+	c.tokenRef = tokenRef
+	c.tokenGranted = false
+	c.activeProfile = name
+	c.mutex.Unlock()
+	return nil
+}
+
+// ForProfile returns a config with the named profile's token, grant and endpoints and this
+// config's server settings, leaving the active profile of this config unchanged. Environment
+// overrides are not applied, since they configure the active profile.
+func (c *Config) ForProfile(name string) (*Config, error) {
+	if _, exists := c.Profiles[name]; !exists {
+		return nil, fmt.Errorf("profile '%s' not found", name)
+	}
+	c.mutex.RLock()
+	profileConfig := &Config{Server: c.Server, DefaultProfile: c.DefaultProfile, Profiles: c.Profiles}
+	c.mutex.RUnlock()
+	if err := profileConfig.switchProfile(name); err != nil {
+		return nil, err
+	}
+	return profileConfig, nil
+}
@@ -0,0 +1,180 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Supported secret reference schemes for the token value
+const (
+	SecretSchemeEnv   = "env://"
+	SecretSchemeVault = "vault://"
+	SecretSchemeAWSSM = "aws-sm://"
+)
+
+// secretResolveTimeout bounds how long a single secret lookup may take
+const secretResolveTimeout = 10 * time.Second
+
+// IsSecretRef reports whether the value is an external secret reference
+func IsSecretRef(value string) bool {
+	return strings.HasPrefix(value, SecretSchemeEnv) ||
+		strings.HasPrefix(value, SecretSchemeVault) ||
+		strings.HasPrefix(value, SecretSchemeAWSSM)
+}
+
+// ResolveSecret resolves a secret reference URI to its value.
+//
+// Supported formats:
+//   - env://VAR_NAME
+//   - vault://<path>#<field>  (uses VAULT_ADDR and VAULT_TOKEN, field defaults to "token")
+//   - aws-sm://<secret-id>#<field>  (uses the aws CLI, field is optional for plain string secrets)
+func ResolveSecret(ref string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), secretResolveTimeout)
+	defer cancel()
+
+	switch {
+	case strings.HasPrefix(ref, SecretSchemeEnv):
+		name := strings.TrimPrefix(ref, SecretSchemeEnv)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable '%s' is not set", name)
+		}
+		return value, nil
+	case strings.HasPrefix(ref, SecretSchemeVault):
+		path, field := splitSecretField(strings.TrimPrefix(ref, SecretSchemeVault), "token")
+		return resolveVaultSecret(ctx, path, field)
+	case strings.HasPrefix(ref, SecretSchemeAWSSM):
+		secretID, field := splitSecretField(strings.TrimPrefix(ref, SecretSchemeAWSSM), "")
+		return resolveAWSSecret(ctx, secretID, field)
+	default:
+		return ref, nil
+	}
+}
+
+// splitSecretField splits "path#field" into its path and field parts
+func splitSecretField(ref, defaultField string) (path, field string) {
+	path, field, found := strings.Cut(ref, "#")
+	if !found || field == "" {
+		field = defaultField
+	}
+	return path, field
+}
+
+// resolveVaultSecret reads a field from a Vault KV secret via the HTTP API
+func resolveVaultSecret(ctx context.Context, path, field string) (string, error) {
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		return "", fmt.Errorf("VAULT_ADDR is not set")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(addr, "/")+"/v1/"+strings.TrimPrefix(path, "/"), http.NoBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to create vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", os.Getenv("VAULT_TOKEN"))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to read vault secret: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned status %d for '%s'", resp.StatusCode, path)
+	}
+
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to parse vault response: %w", err)
+	}
+
+	// KV v2 nests the secret under data.data
+	data := body.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		data = nested
+	}
+
+	value, ok := data[field].(string)
+	if !ok {
+		return "", fmt.Errorf("field '%s' not found in vault secret '%s'", field, path)
+	}
+	return value, nil
+}
+
+// resolveAWSSecret reads a secret from AWS Secrets Manager using the aws CLI
+func resolveAWSSecret(ctx context.Context, secretID, field string) (string, error) {
+	out, err := exec.CommandContext(ctx, "aws", "secretsmanager", "get-secret-value",
+		"--secret-id", secretID, "--query", "SecretString", "--output", "text").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read aws secret '%s': %w", secretID, err)
+	}
+
+	secret := strings.TrimSpace(string(out))
+	if field == "" {
+		return secret, nil
+	}
+
+	var values map[string]interface{}
+	if err := json.Unmarshal([]byte(secret), &values); err != nil {
+		return "", fmt.Errorf("aws secret '%s' is not a JSON object: %w", secretID, err)
+	}
+	value, ok := values[field].(string)
+	if !ok {
+		return "", fmt.Errorf("field '%s' not found in aws secret '%s'", field, secretID)
+	}
+	return value, nil
+}
+
+// ResolveToken resolves the token if it references an external secret. The secret is read
+// without holding the lock, since Vault and AWS lookups can take seconds and every call reads
+// the token.
+func (c *Config) ResolveToken() error {
+	c.mutex.Lock()
+	if IsSecretRef(c.Token) {
+		c.tokenRef = c.Token
+	}
+	tokenRef := c.tokenRef
+	c.mutex.Unlock()
+	if tokenRef == "" {
+		return nil
+	}
+
+	token, err := ResolveSecret(tokenRef)
+	if err != nil {
+		return fmt.Errorf("failed to resolve token: %w", err)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.tokenRef == tokenRef { // Not replaced meanwhile, e.g. by a profile switch
+		c.Token = token
+	}
+	return nil
+}
+
+// RefreshToken re-resolves the token from its secret reference, if any
+func (c *Config) RefreshToken() error {
+	return c.ResolveToken()
+}
+
+// GetToken returns the current token value
+func (c *Config) GetToken() string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.Token
+}
+
+// TokenRef returns the secret reference the token was resolved from, if any
+func (c *Config) TokenRef() string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.tokenRef
+}
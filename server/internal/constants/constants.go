@@ -1,22 +1,113 @@
 package constants
 
+// Version is the release version, set at build time with
+// -ldflags "-X spacectl-web/server/internal/constants.Version=<version>"
+var Version = "dev"
+
 // Default values
 const (
 	DefaultPort       = "8080"
+	DefaultHost       = "127.0.0.1"
 	DefaultConfigFile = "config.yaml"
 	DefaultTimeout    = 30
+
+	DefaultRecordingsDir = "recordings"
+
+	DefaultPIDFile       = "spacectl-web.pid"
+	DefaultDaemonLogFile = "spacectl-web.out"
+	DefaultStopTimeout   = 10 // Seconds to wait for the server to shut down
+
+	DefaultWaitInterval = 5 // Seconds between endpoint checks with --wait-for-endpoints
+)
+
+// Log rotation defaults
+const (
+	DefaultLogMaxSizeMB  = 100
+	DefaultLogMaxAgeDays = 28
+	DefaultLogMaxBackups = 5
 )
 
 // API paths
 const (
-	APIPrefix      = "/api"
-	ServicesPath   = "/services"
-	ResourcesPath  = "/services/:service/resources"
-	GRPCMethodPath = "/services/:service/resources/:resource/verbs/:verb"
-	ConfigInfoPath = "/configinfo"
+	APIPrefix            = "/api"
+	ServicesPath         = "/services"
+	ResourcesPath        = "/services/:service/resources"
+	GRPCMethodPath       = "/services/:service/resources/:resource/verbs/:verb"
+	GRPCCountPath        = "/services/:service/resources/:resource/verbs/:verb/count"
+	GRPCSnippetsPath     = "/services/:service/resources/:resource/verbs/:verb/snippets"
+	GRPCSchemaPath       = "/services/:service/resources/:resource/verbs/:verb/schema"
+	FieldStatsPath       = "/stats/:service/:resource"
+	CostSeriesPath       = "/cost-analysis/series"
+	MetricDataPath       = "/monitoring/metrics"
+	LogsPath             = "/monitoring/logs"
+	AlertsBulkPath       = "/monitoring/alerts/bulk"
+	AccessOverviewPath   = "/identity/access-overview"
+	ProvidersPath        = "/identity/provider-connections"
+	CollectorJobPath     = "/inventory/jobs/:job_id"
+	CollectWorkflowPath  = "/workflows/collect"
+	BatchPath            = "/batch"
+	TypeScriptClientPath = "/client.ts"
+	HealthWatchPath      = "/services/:service/health/watch"
+	HTTPRoutesPath       = "/services/:service/http-routes"
+	HTTPTranscodePath    = "/http/:service/*"
+	ConnectPath          = "/connect/:service/:procedure/:method"
+	ConfigInfoPath       = "/configinfo"
+	TokenRefreshPath     = "/configinfo/token/refresh"
+	ScopePath            = "/auth/scope"
+	ValidatePath         = "/config/validate"
+	BenchPath            = "/bench"
+	WebSocketPath        = "/ws"
+	RecordingsPath       = "/recordings"
+	RecordingsExportPath = "/recordings/export"
+	ProfilesPath         = "/profiles"
+	ProfileSwitchPath    = "/profiles/:profile/activate"
+	ErrorCodesPath       = "/errors"
+	UsagePath            = "/usage"
+	SlowCallsPath        = "/slow-calls"
+	DisconnectsPath      = "/disconnects"
+	IconPath             = "/icons"
+	ReportsPath          = "/reports"
+	UIConfigPath         = "/ui-config"
+	VersionPath          = "/version"
+	UIVersionPath        = "/ui-version"
+	HealthzPath          = "/healthz"
+	ReadyzPath           = "/readyz"
+	HistoryPath          = "/history"
+	FavoritesPath        = "/favorites"
+	FavoritePath         = "/favorites/:id"
+	FavoriteComparePath  = "/favorites/:id/compare"
+	CollectionsPath      = "/collections"
+	CollectionPath       = "/collections/:id"
+	SchedulesPath        = "/schedules"
+	SchedulePath         = "/schedules/:id"
+	ViewsPath            = "/views"
+	ViewPath             = "/views/:id"
+	ViewRunPath          = "/views/:id/run"
+	ChangeReportsPath    = "/change-reports"
+	ChangeReportPath     = "/change-reports/:id"
+	WorkflowsPath        = "/workflows"
+	WorkflowPath         = "/workflows/:id"
+	WorkflowRunPath      = "/workflows/:id/run"
+	WorkflowRunsPath     = "/workflow-runs"
+	WorkflowRunIDPath    = "/workflow-runs/:id"
+	WorkflowCancelPath   = "/workflow-runs/:id/cancel"
+	BackupPath           = "/backup"
+	RestorePath          = "/restore"
+	LibraryPath          = "/library"
+	StatusHistoryPath    = "/endpoints/:service/status/history"
+	DiscoveryEventsPath  = "/discovery/events"
+	StartupPath          = "/startup"
+	AdminPath            = "/admin"
+	AdminConnectionsPath = "/connections"
+	AdminConnectionPath  = "/connections/:service"
+	AdminCachePath       = "/cache"
+	AdminJobsPath        = "/jobs"
+	AdminJobPath         = "/jobs/:id"
+	AdminConfigPath      = "/config"
+	AdminImpersonatePath = "/impersonation"
 )
 
 // Log messages
 const (
-	LogServerStarting = "Starting SpaceONE gRPC API Server on port %s"
+	LogServerStarting = "Starting SpaceONE gRPC API Server on %s"
 )
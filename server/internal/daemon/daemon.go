@@ -0,0 +1,104 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// Environment markers passed to re-executed server processes
+const (
+	envChild      = "SPACECTL_WEB_DAEMON_CHILD" // Set in the background process started by --daemon
+	envSupervised = "SPACECTL_WEB_SUPERVISED"   // Set in the server process run by the auto-restart supervisor
+)
+
+// startCheckDelay is how long Start waits before checking that the background process survived startup
+const startCheckDelay = 500 * time.Millisecond
+
+// IsChild reports whether this process is the background process started by Start
+func IsChild() bool {
+	return os.Getenv(envChild) != ""
+}
+
+// IsSupervised reports whether this process is run by Supervise
+func IsSupervised() bool {
+	return os.Getenv(envSupervised) != ""
+}
+
+// Start re-executes the current command as a background process detached from the terminal,
+// with its output appended to logFile, and returns the process ID
+func Start(logFile string) (int, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return 0, fmt.Errorf("failed to locate executable: %w", err)
+	}
+	output, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open daemon log '%s': %w", logFile, err)
+	}
+	defer output.Close()
+
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Env = append(os.Environ(), envChild+"=1")
+	cmd.Stdout = output
+	cmd.Stderr = output
+	cmd.SysProcAttr = detachedProcAttr()
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start background process: %w", err)
+	}
+	pid := cmd.Process.Pid
+
+	// Catch configuration errors that stop the server right away
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+	select {
+	case err := <-exited:
+		return 0, fmt.Errorf("background process exited during startup (%v): see %s", err, logFile)
+	case <-time.After(startCheckDelay):
+	}
+	_ = cmd.Process.Release()
+	return pid, nil
+}
+
+// Stop terminates the process recorded in the PID file and waits up to timeout for it to exit
+func Stop(pidFile string, timeout time.Duration) (int, error) {
+	pid, running, err := Status(pidFile)
+	if err != nil {
+		return 0, err
+	}
+	if !running {
+		return pid, fmt.Errorf("no running server found for PID file '%s'", pidFile)
+	}
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return pid, err
+	}
+	if err := terminate(process); err != nil {
+		return pid, fmt.Errorf("failed to stop process %d: %w", pid, err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if !processAlive(pid) {
+			removeIfOwnedBy(pidFile, pid)
+			return pid, nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return pid, fmt.Errorf("process %d did not exit within %s", pid, timeout)
+}
+
+// Status returns the process ID recorded in the PID file and whether that process is running.
+// A missing PID file reports a stopped server without an error.
+func Status(pidFile string) (int, bool, error) {
+	pid, err := ReadPIDFile(pidFile)
+	if os.IsNotExist(err) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return pid, processAlive(pid), nil
+}
@@ -0,0 +1,42 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// WritePIDFile records the current process ID, refusing to replace the PID file of a running server.
+// PID files left behind by a crashed server are replaced.
+func WritePIDFile(pidFile string) error {
+	if pid, running, err := Status(pidFile); err == nil && running && pid != os.Getpid() {
+		return fmt.Errorf("server already running with PID %d (PID file '%s')", pid, pidFile)
+	}
+	return os.WriteFile(pidFile, []byte(strconv.Itoa(os.Getpid())+"\n"), 0o644)
+}
+
+// ReadPIDFile returns the process ID recorded in a PID file
+func ReadPIDFile(pidFile string) (int, error) {
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("invalid PID file '%s'", pidFile)
+	}
+	return pid, nil
+}
+
+// RemovePIDFile removes the PID file if it still records the current process
+func RemovePIDFile(pidFile string) {
+	removeIfOwnedBy(pidFile, os.Getpid())
+}
+
+// removeIfOwnedBy removes the PID file if it records the given process
+func removeIfOwnedBy(pidFile string, pid int) {
+	if recorded, err := ReadPIDFile(pidFile); err == nil && recorded == pid {
+		_ = os.Remove(pidFile)
+	}
+}
@@ -0,0 +1,24 @@
+//go:build unix
+
+package daemon
+
+import (
+	"os"
+	"syscall"
+)
+
+// detachedProcAttr starts the process in a new session so it outlives the terminal
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
+
+// processAlive reports whether a process with the ID exists
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
+
+// terminate asks the process to shut down gracefully
+func terminate(process *os.Process) error {
+	return process.Signal(syscall.SIGTERM)
+}
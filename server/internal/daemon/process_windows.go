@@ -0,0 +1,28 @@
+//go:build windows
+
+package daemon
+
+import (
+	"os"
+	"syscall"
+)
+
+// detachedProcAttr starts the process without a console so it outlives the terminal
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | 0x00000008} // DETACHED_PROCESS
+}
+
+// processAlive reports whether a process with the ID exists
+func processAlive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	_ = process.Release()
+	return true
+}
+
+// terminate stops the process; Windows has no graceful termination signal
+func terminate(process *os.Process) error {
+	return process.Kill()
+}
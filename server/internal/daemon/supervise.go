@@ -0,0 +1,70 @@
+package daemon
+
+import (
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Restart backoff of the supervisor
+const (
+	minRestartDelay = time.Second
+	maxRestartDelay = 30 * time.Second
+	stableRunTime   = time.Minute // Runs at least this long reset the backoff
+)
+
+// Supervise runs the current command as a child process and restarts it whenever it crashes,
+// for example on an unrecovered panic. It returns when the child exits cleanly or the
+// supervisor is asked to stop, in which case the signal is forwarded to the child.
+func Supervise() error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	delay := minRestartDelay
+	for {
+		cmd := exec.Command(executable, os.Args[1:]...)
+		cmd.Env = append(os.Environ(), envSupervised+"=1")
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		started := time.Now()
+		if err := cmd.Start(); err != nil {
+			return err
+		}
+
+		exited := make(chan error, 1)
+		go func() { exited <- cmd.Wait() }()
+
+		var exitErr error
+		select {
+		case <-signals:
+			_ = terminate(cmd.Process)
+			<-exited
+			return nil
+		case exitErr = <-exited:
+		}
+		if exitErr == nil {
+			return nil
+		}
+
+		if time.Since(started) >= stableRunTime {
+			delay = minRestartDelay
+		}
+		log.Printf("Server exited unexpectedly (%v): restarting in %s", exitErr, delay)
+		select {
+		case <-signals:
+			return nil
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxRestartDelay)
+	}
+}
@@ -0,0 +1,34 @@
+package diagnostics
+
+import (
+	"expvar"
+	"net/http"
+	_ "net/http/pprof" //nolint:gosec // only registered when --enable-pprof is set
+	"runtime"
+
+	"spacectl-web/server/internal/grpc"
+
+	"github.com/labstack/echo/v4"
+)
+
+// DebugPath is the path prefix for pprof and expvar endpoints
+const DebugPath = "/debug"
+
+// Setup exposes /debug/pprof/* and /debug/vars with gRPC pool and runtime statistics
+func Setup(e *echo.Echo, basePath string, grpcManager *grpc.ClientManager, serviceDiscovery *grpc.ServiceDiscovery) {
+	expvar.Publish("grpc_clients", expvar.Func(func() interface{} {
+		return grpcManager.Stats()
+	}))
+	expvar.Publish("grpc_discovery", expvar.Func(func() interface{} {
+		return serviceDiscovery.Stats()
+	}))
+	expvar.Publish("goroutines", expvar.Func(func() interface{} {
+		return runtime.NumGoroutine()
+	}))
+
+	// net/http/pprof and expvar register their handlers on the default mux
+	handler := echo.WrapHandler(http.StripPrefix(basePath, http.DefaultServeMux))
+	e.GET(basePath+DebugPath+"/pprof/*", handler)
+	e.POST(basePath+DebugPath+"/pprof/symbol", handler)
+	e.GET(basePath+DebugPath+"/vars", handler)
+}
@@ -0,0 +1,107 @@
+package disconnects
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Tracker counts, per route and verb, the requests whose client went away before the response
+// was complete. Verbs abandoned often are the ones whose screens need paging or background jobs.
+type Tracker struct {
+	started time.Time
+	calls   map[callKey]*callCounts
+	mutex   sync.Mutex
+}
+
+// callKey identifies what a request called
+type callKey struct {
+	route, service, resource, verb string
+}
+
+// callCounts are the outcomes of requests with the same key
+type callCounts struct {
+	requests       int64
+	abandoned      int64
+	completedTime  time.Duration // Total time of completed requests
+	abandonedAfter time.Duration // Total time clients waited before leaving
+}
+
+// CallStats reports how often clients abandoned requests to a route and verb
+type CallStats struct {
+	Route              string  `json:"route"`
+	Service            string  `json:"service,omitempty"`
+	Resource           string  `json:"resource,omitempty"`
+	Verb               string  `json:"verb,omitempty"`
+	Requests           int64   `json:"requests"`
+	Abandoned          int64   `json:"abandoned"`
+	AbandonRate        float64 `json:"abandon_rate"`                    // Abandoned share of requests, 0 to 1
+	MeanDurationMS     int64   `json:"mean_duration_ms"`                // Mean time of completed requests
+	MeanAbandonAfterMS int64   `json:"mean_abandon_after_ms,omitempty"` // Mean time clients waited before leaving
+}
+
+// Report is the abandonment of every route and verb since the server started, most abandoned first
+type Report struct {
+	Since time.Time   `json:"since"`
+	Calls []CallStats `json:"calls"`
+}
+
+// NewTracker creates an empty tracker
+func NewTracker() *Tracker {
+	return &Tracker{started: time.Now(), calls: make(map[callKey]*callCounts)}
+}
+
+// Observe records a request that took elapsed, and whether its client left before the response was complete
+func (t *Tracker) Observe(route, serviceName, resourceName, verb string, abandoned bool, elapsed time.Duration) {
+	key := callKey{route: route, service: serviceName, resource: resourceName, verb: verb}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	counts, exists := t.calls[key]
+	if !exists {
+		counts = &callCounts{}
+		t.calls[key] = counts
+	}
+	counts.requests++
+	if abandoned {
+		counts.abandoned++
+		counts.abandonedAfter += elapsed
+	} else {
+		counts.completedTime += elapsed
+	}
+}
+
+// Report returns the abandonment of every route and verb, most abandoned first
+func (t *Tracker) Report() *Report {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	report := &Report{Since: t.started, Calls: make([]CallStats, 0, len(t.calls))}
+	for key, counts := range t.calls {
+		stats := CallStats{
+			Route:       key.route,
+			Service:     key.service,
+			Resource:    key.resource,
+			Verb:        key.verb,
+			Requests:    counts.requests,
+			Abandoned:   counts.abandoned,
+			AbandonRate: float64(counts.abandoned) / float64(counts.requests),
+		}
+		if completed := counts.requests - counts.abandoned; completed > 0 {
+			stats.MeanDurationMS = (counts.completedTime / time.Duration(completed)).Milliseconds()
+		}
+		if counts.abandoned > 0 {
+			stats.MeanAbandonAfterMS = (counts.abandonedAfter / time.Duration(counts.abandoned)).Milliseconds()
+		}
+		report.Calls = append(report.Calls, stats)
+	}
+	sort.Slice(report.Calls, func(i, j int) bool {
+		a, b := report.Calls[i], report.Calls[j]
+		if a.Abandoned != b.Abandoned {
+			return a.Abandoned > b.Abandoned
+		}
+		if a.Route != b.Route {
+			return a.Route < b.Route
+		}
+		return a.Service+"."+a.Resource+"."+a.Verb < b.Service+"."+b.Resource+"."+b.Verb
+	})
+	return report
+}
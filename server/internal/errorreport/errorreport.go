@@ -0,0 +1,272 @@
+package errorreport
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"spacectl-web/server/internal/config"
+	"spacectl-web/server/internal/constants"
+)
+
+const (
+	queueSize   = 100 // Reports waiting to be sent; further reports are dropped
+	sendTimeout = 10 * time.Second
+)
+
+// Kinds of reports
+const (
+	KindPanic = "panic"
+	KindError = "error" // A 5xx response
+)
+
+// Report is a failure of a request. Request headers, query strings and bodies are left out,
+// since they may hold tokens and secrets.
+type Report struct {
+	EventID     string         `json:"event_id"`
+	Time        time.Time      `json:"time"`
+	Kind        string         `json:"kind"`
+	Status      int            `json:"status"`
+	Message     string         `json:"message"`
+	ErrorCode   string         `json:"error_code,omitempty"`
+	Details     string         `json:"details,omitempty"`
+	Stack       string         `json:"stack,omitempty"` // Goroutine stack of panics
+	Request     RequestContext `json:"request"`
+	Environment string         `json:"environment,omitempty"`
+	Release     string         `json:"release"`
+	ServerName  string         `json:"server_name,omitempty"`
+}
+
+// RequestContext identifies the failed request
+type RequestContext struct {
+	Method    string `json:"method"`
+	Route     string `json:"route"` // Registered path, e.g. /api/grpc/:service/:resource/:verb
+	Path      string `json:"path"`
+	RequestID string `json:"request_id,omitempty"`
+	User      string `json:"user,omitempty"`
+	RemoteIP  string `json:"remote_ip,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+}
+
+// Reporter sends reports to Sentry and a webhook in the background. Reports are dropped when
+// the queue is full, so a failing destination never delays requests.
+type Reporter struct {
+	sentry      *sentryDSN
+	webhookURL  string
+	headers     map[string]string
+	environment string
+	serverName  string
+	client      *http.Client
+	reports     chan *Report
+	dropped     atomic.Int64
+	done        chan struct{}
+	once        sync.Once
+}
+
+// sentryDSN is where and how Sentry events are sent
+type sentryDSN struct {
+	storeURL string
+	auth     string // X-Sentry-Auth header
+}
+
+// New creates a reporter for the configured destinations and starts sending. Secret references
+// in the DSN and webhook headers are resolved once here.
+func New(cfg config.ErrorReportingConfig) (*Reporter, error) {
+	r := &Reporter{
+		webhookURL:  cfg.WebhookURL,
+		headers:     make(map[string]string, len(cfg.Headers)),
+		environment: cfg.Environment,
+		client:      &http.Client{Timeout: sendTimeout},
+		reports:     make(chan *Report, queueSize),
+		done:        make(chan struct{}),
+	}
+	r.serverName, _ = os.Hostname()
+	if cfg.SentryDSN != "" {
+		dsn, err := resolve(cfg.SentryDSN)
+		if err != nil {
+			return nil, fmt.Errorf("server.error_reporting.sentry_dsn: %w", err)
+		}
+		if r.sentry, err = parseDSN(dsn); err != nil {
+			return nil, fmt.Errorf("server.error_reporting.sentry_dsn: %w", err)
+		}
+	}
+	for key, value := range cfg.Headers {
+		resolved, err := resolve(value)
+		if err != nil {
+			return nil, fmt.Errorf("server.error_reporting.headers.%s: %w", key, err)
+		}
+		r.headers[key] = resolved
+	}
+	go r.run()
+	return r, nil
+}
+
+// Report fills in the report's ID, time and deployment and queues it, dropping it when the queue is full
+func (r *Reporter) Report(report *Report) {
+	report.EventID = newEventID()
+	if report.Time.IsZero() {
+		report.Time = time.Now()
+	}
+	report.Environment = r.environment
+	report.Release = constants.Version
+	report.ServerName = r.serverName
+	select {
+	case r.reports <- report:
+	default:
+		r.dropped.Add(1)
+	}
+}
+
+// Close sends the queued reports
+func (r *Reporter) Close() {
+	r.once.Do(func() {
+		close(r.reports)
+		<-r.done
+		r.client.CloseIdleConnections()
+	})
+}
+
+// run sends queued reports until the reporter is closed
+func (r *Reporter) run() {
+	defer close(r.done)
+	for report := range r.reports {
+		if dropped := r.dropped.Swap(0); dropped > 0 {
+			log.Printf("WARNING: dropped %d error reports because the queue was full", dropped)
+		}
+		if r.sentry != nil {
+			if err := r.post(r.sentry.storeURL, sentryEvent(report), map[string]string{"X-Sentry-Auth": r.sentry.auth}); err != nil {
+				log.Printf("WARNING: failed to report error %s to Sentry: %v", report.EventID, err)
+			}
+		}
+		if r.webhookURL != "" {
+			if err := r.post(r.webhookURL, report, r.headers); err != nil {
+				log.Printf("WARNING: failed to report error %s to the webhook: %v", report.EventID, err)
+			}
+		}
+	}
+}
+
+// post sends a JSON body, failing on non-2xx responses
+func (r *Reporter) post(target string, body interface{}, headers map[string]string) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %s", req.URL.Redacted(), resp.Status)
+	}
+	return nil
+}
+
+// parseDSN derives the store endpoint and authentication of a Sentry DSN such as
+// https://<key>@sentry.example.com/<project>
+func parseDSN(dsn string) (*sentryDSN, error) {
+	parsed, err := url.Parse(dsn)
+	if err != nil {
+		return nil, err
+	}
+	path := strings.Trim(parsed.Path, "/")
+	if parsed.User == nil || parsed.User.Username() == "" || parsed.Host == "" || path == "" {
+		return nil, fmt.Errorf("must be a DSN such as https://<key>@sentry.example.com/<project>")
+	}
+	prefix, project := "", path
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		prefix, project = "/"+path[:i], path[i+1:]
+	}
+	auth := fmt.Sprintf("Sentry sentry_version=7, sentry_client=spacectl-web/%s, sentry_key=%s", constants.Version, parsed.User.Username())
+	if secret, hasSecret := parsed.User.Password(); hasSecret {
+		auth += ", sentry_secret=" + secret
+	}
+	return &sentryDSN{storeURL: fmt.Sprintf("%s://%s%s/api/%s/store/", parsed.Scheme, parsed.Host, prefix, project), auth: auth}, nil
+}
+
+// sentryEvent converts a report to a Sentry event
+func sentryEvent(report *Report) map[string]interface{} {
+	level := "error"
+	exceptionType := report.ErrorCode
+	if report.Kind == KindPanic {
+		level = "fatal"
+		exceptionType = "panic"
+	}
+	if exceptionType == "" {
+		exceptionType = fmt.Sprintf("HTTP %d", report.Status)
+	}
+	tags := map[string]string{"route": report.Request.Route, "status": fmt.Sprint(report.Status)}
+	if report.ErrorCode != "" {
+		tags["error_code"] = report.ErrorCode
+	}
+	if report.Request.RequestID != "" {
+		tags["request_id"] = report.Request.RequestID
+	}
+	extra := map[string]string{}
+	if report.Details != "" {
+		extra["details"] = report.Details
+	}
+	if report.Stack != "" {
+		extra["stack"] = report.Stack
+	}
+	return map[string]interface{}{
+		"event_id":    report.EventID,
+		"timestamp":   report.Time.UTC().Format(time.RFC3339Nano),
+		"level":       level,
+		"platform":    "go",
+		"logger":      "spacectl-web",
+		"server_name": report.ServerName,
+		"release":     "spacectl-web@" + report.Release,
+		"environment": report.Environment,
+		"transaction": report.Request.Method + " " + report.Request.Route,
+		"message":     map[string]string{"formatted": report.Message},
+		"exception": map[string]interface{}{
+			"values": []map[string]string{{"type": exceptionType, "value": report.Message}},
+		},
+		"request": map[string]interface{}{
+			"method":  report.Request.Method,
+			"url":     report.Request.Path,
+			"headers": map[string]string{"User-Agent": report.Request.UserAgent},
+		},
+		"user":  map[string]string{"id": report.Request.User, "ip_address": report.Request.RemoteIP},
+		"tags":  tags,
+		"extra": extra,
+	}
+}
+
+// resolve returns the value of a secret reference, or the value itself
+func resolve(value string) (string, error) {
+	if config.IsSecretRef(value) {
+		return config.ResolveSecret(value)
+	}
+	return value, nil
+}
+
+// newEventID returns a random 32-digit hex ID, the form Sentry expects
+func newEventID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -3,13 +3,49 @@ package errors
 import (
 	"fmt"
 	"net/http"
+	"sort"
+)
+
+// ErrorCode is a stable, machine-readable error identifier
+type ErrorCode string
+
+// Error codes returned in the error_code field
+const (
+	CodeServiceNotFound          ErrorCode = "SERVICE_NOT_FOUND"
+	CodeResourceNotFound         ErrorCode = "RESOURCE_NOT_FOUND"
+	CodeVerbNotSupported         ErrorCode = "VERB_NOT_SUPPORTED"
+	CodeGRPCClientFailed         ErrorCode = "GRPC_CLIENT_FAILED"
+	CodeEndpointUnreachable      ErrorCode = "ENDPOINT_UNREACHABLE"
+	CodeServiceDescriptorFailed  ErrorCode = "SERVICE_DESCRIPTOR_FAILED"
+	CodeMethodNotFound           ErrorCode = "METHOD_NOT_FOUND"
+	CodeRPCCallFailed            ErrorCode = "RPC_CALL_FAILED"
+	CodeResponseConversionFailed ErrorCode = "RESPONSE_CONVERSION_FAILED"
+	CodeJSONConversionFailed     ErrorCode = "JSON_CONVERSION_FAILED"
+	CodeRequestTooLarge          ErrorCode = "REQUEST_TOO_LARGE"
+	CodeResponseTooLarge         ErrorCode = "RESPONSE_TOO_LARGE"
+	CodeRecordingNotFound        ErrorCode = "RECORDING_NOT_FOUND"
+	CodeStreamFailed             ErrorCode = "STREAM_FAILED"
+	CodeInvalidParameters        ErrorCode = "INVALID_PARAMETERS"
+	CodeQuotaExceeded            ErrorCode = "QUOTA_EXCEEDED"
+	CodeReadOnly                 ErrorCode = "READ_ONLY"
+	CodeNotReady                 ErrorCode = "NOT_READY"
 )
 
 // APIError represents a structured API error
 type APIError struct {
-	Code    int    `json:"code"`
+	Code      int          `json:"code"`
+	ErrorCode ErrorCode    `json:"error_code"`
+	Message   string       `json:"message"`
+	Details   string       `json:"details,omitempty"`
+	GRPCCode  string       `json:"grpc_code,omitempty"` // Upstream gRPC status code name, e.g. UNAUTHENTICATED
+	Hint      string       `json:"hint,omitempty"`      // Suggested remediation
+	Fields    []FieldError `json:"fields,omitempty"`    // Per-field validation failures
+}
+
+// FieldError represents a request parameter that could not be applied
+type FieldError struct {
+	Field   string `json:"field"`
 	Message string `json:"message"`
-	Details string `json:"details,omitempty"`
 }
 
 // Error implements the error interface
@@ -23,56 +59,188 @@ func (e *APIError) Error() string {
 // Predefined errors
 var (
 	ErrServiceNotFound = &APIError{
-		Code:    http.StatusNotFound,
-		Message: "Service not found",
+		Code:      http.StatusNotFound,
+		ErrorCode: CodeServiceNotFound,
+		Message:   "Service not found",
+		Hint:      "Check that the service has an endpoint in the config file",
 	}
 
 	ErrResourceNotFound = &APIError{
-		Code:    http.StatusNotFound,
-		Message: "Resource not found",
+		Code:      http.StatusNotFound,
+		ErrorCode: CodeResourceNotFound,
+		Message:   "Resource not found",
 	}
 
 	ErrVerbNotSupported = &APIError{
-		Code:    http.StatusBadRequest,
-		Message: "Verb not supported for resource",
+		Code:      http.StatusBadRequest,
+		ErrorCode: CodeVerbNotSupported,
+		Message:   "Verb not supported for resource",
 	}
 
 	ErrGRPCClientFailed = &APIError{
-		Code:    http.StatusInternalServerError,
-		Message: "Failed to create gRPC client",
+		Code:      http.StatusInternalServerError,
+		ErrorCode: CodeGRPCClientFailed,
+		Message:   "Failed to create gRPC client",
+	}
+
+	ErrEndpointUnreachable = &APIError{
+		Code:      http.StatusBadGateway,
+		ErrorCode: CodeEndpointUnreachable,
+		Message:   "Cannot reach service endpoint",
+		Hint:      "Check the endpoint URL in the config file and your network or VPN connection",
 	}
 
 	ErrServiceDescriptorFailed = &APIError{
-		Code:    http.StatusInternalServerError,
-		Message: "Failed to get service descriptor",
+		Code:      http.StatusInternalServerError,
+		ErrorCode: CodeServiceDescriptorFailed,
+		Message:   "Failed to get service descriptor",
+		Hint:      "Make sure gRPC reflection is enabled on the server",
 	}
 
 	ErrMethodNotFound = &APIError{
-		Code:    http.StatusNotFound,
-		Message: "Method not found",
+		Code:      http.StatusNotFound,
+		ErrorCode: CodeMethodNotFound,
+		Message:   "Method not found",
 	}
 
 	ErrRPCCallFailed = &APIError{
-		Code:    http.StatusInternalServerError,
-		Message: "RPC call failed",
+		Code:      http.StatusInternalServerError,
+		ErrorCode: CodeRPCCallFailed,
+		Message:   "RPC call failed",
 	}
 
 	ErrResponseConversionFailed = &APIError{
-		Code:    http.StatusInternalServerError,
-		Message: "Failed to convert response",
+		Code:      http.StatusInternalServerError,
+		ErrorCode: CodeResponseConversionFailed,
+		Message:   "Failed to convert response",
 	}
 
 	ErrJSONConversionFailed = &APIError{
-		Code:    http.StatusInternalServerError,
-		Message: "Failed to convert response to JSON",
+		Code:      http.StatusInternalServerError,
+		ErrorCode: CodeJSONConversionFailed,
+		Message:   "Failed to convert response to JSON",
+	}
+
+	ErrRequestTooLarge = &APIError{
+		Code:      http.StatusRequestEntityTooLarge,
+		ErrorCode: CodeRequestTooLarge,
+		Message:   "Request body too large",
+		Hint:      "Send a smaller request or raise server.limits.max_request_bytes",
+	}
+
+	ErrResponseTooLarge = &APIError{
+		Code:      http.StatusBadGateway,
+		ErrorCode: CodeResponseTooLarge,
+		Message:   "Upstream response too large",
+		Hint:      "Narrow the query with filters or pagination, or raise server.limits.max_response_bytes",
+	}
+
+	ErrInvalidParameters = &APIError{
+		Code:      http.StatusBadRequest,
+		ErrorCode: CodeInvalidParameters,
+		Message:   "Invalid request parameters",
+		Hint:      "Fix the listed fields to match the method's input type",
+	}
+
+	ErrQuotaExceeded = &APIError{
+		Code:      http.StatusTooManyRequests,
+		ErrorCode: CodeQuotaExceeded,
+		Message:   "Usage quota exceeded",
+		Hint:      "Wait for the quota window to reset or ask an administrator to raise server.quotas limits",
+	}
+
+	ErrReadOnly = &APIError{
+		Code:      http.StatusForbidden,
+		ErrorCode: CodeReadOnly,
+		Message:   "Server is in read-only mode",
+		Hint:      "Only verbs that read data, such as list, get and stat, are allowed; unset server.ui.read_only to allow changes",
+	}
+
+	ErrNotReady = &APIError{
+		Code:      http.StatusServiceUnavailable,
+		ErrorCode: CodeNotReady,
+		Message:   "Server is not ready",
+		Hint:      "Waiting for the service endpoints to become reachable; check the endpoint URLs and network policies",
+	}
+
+	ErrStreamFailed = &APIError{
+		Code:      http.StatusBadGateway,
+		ErrorCode: CodeStreamFailed,
+		Message:   "Stream failed",
+	}
+
+	ErrRecordingNotFound = &APIError{
+		Code:      http.StatusNotFound,
+		ErrorCode: CodeRecordingNotFound,
+		Message:   "No recorded response available in offline mode",
+		Hint:      "Record the call first by running the server with --record",
 	}
 )
 
+// predefined lists every predefined error
+var predefined = []*APIError{
+	ErrServiceNotFound,
+	ErrResourceNotFound,
+	ErrVerbNotSupported,
+	ErrGRPCClientFailed,
+	ErrEndpointUnreachable,
+	ErrServiceDescriptorFailed,
+	ErrMethodNotFound,
+	ErrRPCCallFailed,
+	ErrResponseConversionFailed,
+	ErrJSONConversionFailed,
+	ErrRequestTooLarge,
+	ErrResponseTooLarge,
+	ErrInvalidParameters,
+	ErrQuotaExceeded,
+	ErrReadOnly,
+	ErrNotReady,
+	ErrStreamFailed,
+	ErrRecordingNotFound,
+}
+
 // NewAPIError creates a new API error with details
 func NewAPIError(baseErr *APIError, details string) *APIError {
 	return &APIError{
-		Code:    baseErr.Code,
-		Message: baseErr.Message,
-		Details: details,
+		Code:      baseErr.Code,
+		ErrorCode: baseErr.ErrorCode,
+		Message:   baseErr.Message,
+		Details:   details,
+		GRPCCode:  baseErr.GRPCCode,
+		Hint:      baseErr.Hint,
+		Fields:    baseErr.Fields,
+	}
+}
+
+// NewValidationError creates an invalid parameters error listing every failed field, sorted by name
+func NewValidationError(messageName string, fields []FieldError) *APIError {
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+
+	apiErr := NewAPIError(ErrInvalidParameters, fmt.Sprintf("%d invalid field(s) in %s", len(fields), messageName))
+	apiErr.Fields = fields
+	return apiErr
+}
+
+// ErrorCodeInfo describes a predefined error for clients that handle failures programmatically
+type ErrorCodeInfo struct {
+	ErrorCode ErrorCode `json:"error_code"`
+	Status    int       `json:"status"` // HTTP status returned with the error
+	Message   string    `json:"message"`
+	Hint      string    `json:"hint,omitempty"`
+}
+
+// Catalog returns every predefined error in the given language, sorted by error code
+func Catalog(language string) []ErrorCodeInfo {
+	infos := make([]ErrorCodeInfo, 0, len(predefined))
+	for _, apiErr := range predefined {
+		localized := apiErr.Localize(language)
+		infos = append(infos, ErrorCodeInfo{
+			ErrorCode: localized.ErrorCode,
+			Status:    localized.Code,
+			Message:   localized.Message,
+			Hint:      localized.Hint,
+		})
 	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ErrorCode < infos[j].ErrorCode })
+	return infos
 }
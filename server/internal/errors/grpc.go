@@ -0,0 +1,60 @@
+package errors
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// grpcHints are remediation hints for upstream gRPC status codes
+var grpcHints = map[codes.Code]string{
+	codes.Unauthenticated:   "Token expired or invalid: run 'spacectl config set token' or refresh the token in the config file",
+	codes.PermissionDenied:  "The token's role does not allow this call: check the role bindings of the user or app",
+	codes.InvalidArgument:   "Check the request parameters against the method's input type",
+	codes.NotFound:          "The requested item does not exist or is outside the token's domain",
+	codes.Unavailable:       "The service is unavailable: check the endpoint URL and your network or VPN connection",
+	codes.DeadlineExceeded:  "The call timed out: narrow the query or raise the endpoint's timeout in the config file",
+	codes.ResourceExhausted: "A rate limit or quota was exceeded: retry later",
+	codes.Unimplemented:     "The server does not implement this method: check the service version",
+}
+
+// WithGRPCStatus returns a copy of the error annotated with the gRPC status code of err
+// and a remediation hint for that code
+func (e *APIError) WithGRPCStatus(err error) *APIError {
+	code := status.Code(err)
+	annotated := NewAPIError(e, e.Details)
+	annotated.GRPCCode = GRPCCodeName(code)
+	if hint, exists := grpcHints[code]; exists {
+		annotated.Hint = hint
+	}
+	return annotated
+}
+
+// GRPCCodeName returns the canonical upper-case name of a gRPC code, e.g. UNAUTHENTICATED
+func GRPCCodeName(code codes.Code) string {
+	name := code.String()
+	if upper, exists := grpcCodeNames[name]; exists {
+		return upper
+	}
+	return name
+}
+
+// grpcCodeNames maps codes.Code.String() values to their canonical names
+var grpcCodeNames = map[string]string{
+	"OK":                 "OK",
+	"Canceled":           "CANCELLED",
+	"Unknown":            "UNKNOWN",
+	"InvalidArgument":    "INVALID_ARGUMENT",
+	"DeadlineExceeded":   "DEADLINE_EXCEEDED",
+	"NotFound":           "NOT_FOUND",
+	"AlreadyExists":      "ALREADY_EXISTS",
+	"PermissionDenied":   "PERMISSION_DENIED",
+	"ResourceExhausted":  "RESOURCE_EXHAUSTED",
+	"FailedPrecondition": "FAILED_PRECONDITION",
+	"Aborted":            "ABORTED",
+	"OutOfRange":         "OUT_OF_RANGE",
+	"Unimplemented":      "UNIMPLEMENTED",
+	"Internal":           "INTERNAL",
+	"Unavailable":        "UNAVAILABLE",
+	"DataLoss":           "DATA_LOSS",
+	"Unauthenticated":    "UNAUTHENTICATED",
+}
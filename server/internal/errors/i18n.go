@@ -0,0 +1,146 @@
+package errors
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Supported error message languages
+const (
+	LanguageEnglish = "en"
+	LanguageKorean  = "ko"
+)
+
+// DefaultLanguage is the language of the predefined error messages
+const DefaultLanguage = LanguageEnglish
+
+// translation is a localized error message and hint
+type translation struct {
+	Message string
+	Hint    string
+}
+
+// catalog holds translations of the predefined errors by language and error code.
+// English is the source language and is not listed.
+var catalog = map[string]map[ErrorCode]translation{
+	LanguageKorean: {
+		CodeServiceNotFound:          {"서비스를 찾을 수 없습니다", "설정 파일에 해당 서비스의 엔드포인트가 있는지 확인하세요"},
+		CodeResourceNotFound:         {"리소스를 찾을 수 없습니다", ""},
+		CodeVerbNotSupported:         {"리소스에서 지원하지 않는 verb입니다", ""},
+		CodeGRPCClientFailed:         {"gRPC 클라이언트를 생성하지 못했습니다", ""},
+		CodeEndpointUnreachable:      {"서비스 엔드포인트에 연결할 수 없습니다", "설정 파일의 엔드포인트 URL과 네트워크 또는 VPN 연결을 확인하세요"},
+		CodeServiceDescriptorFailed:  {"서비스 디스크립터를 가져오지 못했습니다", "서버에서 gRPC 리플렉션이 활성화되어 있는지 확인하세요"},
+		CodeMethodNotFound:           {"메서드를 찾을 수 없습니다", ""},
+		CodeRPCCallFailed:            {"RPC 호출에 실패했습니다", ""},
+		CodeResponseConversionFailed: {"응답을 변환하지 못했습니다", ""},
+		CodeJSONConversionFailed:     {"응답을 JSON으로 변환하지 못했습니다", ""},
+		CodeRequestTooLarge:          {"요청 본문이 너무 큽니다", "더 작은 요청을 보내거나 server.limits.max_request_bytes 값을 늘리세요"},
+		CodeResponseTooLarge:         {"업스트림 응답이 너무 큽니다", "필터나 페이지네이션으로 조회 범위를 줄이거나 server.limits.max_response_bytes 값을 늘리세요"},
+		CodeInvalidParameters:        {"요청 파라미터가 올바르지 않습니다", "표시된 필드를 메서드의 입력 타입에 맞게 수정하세요"},
+		CodeQuotaExceeded:            {"사용량 한도를 초과했습니다", "한도 기간이 초기화될 때까지 기다리거나 관리자에게 server.quotas 한도를 늘려 달라고 요청하세요"},
+		CodeReadOnly:                 {"서버가 읽기 전용 모드입니다", "list, get, stat처럼 데이터를 읽는 verb만 허용됩니다. 변경하려면 server.ui.read_only 설정을 해제하세요"},
+		CodeNotReady:                 {"서버가 아직 준비되지 않았습니다", "서비스 엔드포인트에 연결되기를 기다리는 중입니다. 엔드포인트 URL과 네트워크 정책을 확인하세요"},
+		CodeStreamFailed:             {"스트림이 실패했습니다", ""},
+		CodeRecordingNotFound:        {"오프라인 모드에서 사용할 수 있는 녹화된 응답이 없습니다", "--record 옵션으로 서버를 실행해 먼저 호출을 녹화하세요"},
+	},
+}
+
+// grpcHintCatalog holds translations of the gRPC status hints by language
+var grpcHintCatalog = map[string]map[codes.Code]string{
+	LanguageKorean: {
+		codes.Unauthenticated:   "토큰이 만료되었거나 유효하지 않습니다: 'spacectl config set token'을 실행하거나 설정 파일의 토큰을 갱신하세요",
+		codes.PermissionDenied:  "토큰의 역할로는 이 호출을 할 수 없습니다: 사용자 또는 앱의 역할 바인딩을 확인하세요",
+		codes.InvalidArgument:   "요청 파라미터가 메서드의 입력 타입과 맞는지 확인하세요",
+		codes.NotFound:          "요청한 항목이 없거나 토큰의 도메인 밖에 있습니다",
+		codes.Unavailable:       "서비스를 사용할 수 없습니다: 엔드포인트 URL과 네트워크 또는 VPN 연결을 확인하세요",
+		codes.DeadlineExceeded:  "호출 시간이 초과되었습니다: 조회 범위를 줄이거나 설정 파일에서 엔드포인트의 timeout 값을 늘리세요",
+		codes.ResourceExhausted: "요청 한도 또는 할당량을 초과했습니다: 잠시 후 다시 시도하세요",
+		codes.Unimplemented:     "서버가 이 메서드를 구현하지 않았습니다: 서비스 버전을 확인하세요",
+	},
+}
+
+// Localize returns a copy of the error with its message and hint in the given language.
+// Only predefined messages and hints are translated; details from upstream services and
+// the error codes are left unchanged.
+func (e *APIError) Localize(language string) *APIError {
+	translations, exists := catalog[language]
+	if !exists {
+		return e
+	}
+
+	localized := NewAPIError(e, e.Details)
+	base, isPredefined := predefinedByCode[e.ErrorCode]
+	t := translations[e.ErrorCode]
+	if isPredefined && e.Message == base.Message && t.Message != "" {
+		localized.Message = t.Message
+	}
+
+	if code, exists := grpcCodeByName[e.GRPCCode]; exists && e.Hint == grpcHints[code] {
+		if hint := grpcHintCatalog[language][code]; hint != "" {
+			localized.Hint = hint
+		}
+	} else if isPredefined && e.Hint == base.Hint && t.Hint != "" {
+		localized.Hint = t.Hint
+	}
+	return localized
+}
+
+// PreferredLanguage returns the supported language that best matches an Accept-Language
+// header, or the default language when none matches
+func PreferredLanguage(acceptLanguage string) string {
+	type candidate struct {
+		language string
+		quality  float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if value, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		// Match on the primary subtag, e.g. ko-KR selects ko
+		primary, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if quality > 0 && IsSupportedLanguage(primary) {
+			candidates = append(candidates, candidate{primary, quality})
+		}
+	}
+	if len(candidates) == 0 {
+		return DefaultLanguage
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].quality > candidates[j].quality })
+	return candidates[0].language
+}
+
+// IsSupportedLanguage reports whether error messages are available in the language
+func IsSupportedLanguage(language string) bool {
+	_, exists := catalog[language]
+	return language == DefaultLanguage || exists
+}
+
+// predefinedByCode maps error codes to their predefined errors
+var predefinedByCode = func() map[ErrorCode]*APIError {
+	byCode := make(map[ErrorCode]*APIError, len(predefined))
+	for _, apiErr := range predefined {
+		byCode[apiErr.ErrorCode] = apiErr
+	}
+	return byCode
+}()
+
+// grpcCodeByName maps canonical gRPC code names to codes
+var grpcCodeByName = func() map[string]codes.Code {
+	byName := make(map[string]codes.Code, len(grpcHints))
+	for code := range grpcHints {
+		byName[GRPCCodeName(code)] = code
+	}
+	return byName
+}()
@@ -0,0 +1,182 @@
+package grant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"spacectl-web/server/internal/config"
+	"spacectl-web/server/internal/grpc"
+	"spacectl-web/server/internal/jwt"
+)
+
+// grantType exchanges an app token, which SpaceONE issues as a refresh token, for an access token
+const grantType = "REFRESH_TOKEN"
+
+const (
+	grantTimeout     = 30 * time.Second
+	retryInterval    = 30 * time.Second // Wait after a failed grant before trying again
+	refreshInterval  = 30 * time.Minute // Refresh interval of access tokens without an expiry
+	minRefreshDelay  = 10 * time.Second
+	tokenResource    = "Token"
+	tokenGrantVerb   = "grant"
+	accessTokenField = "access_token"
+	accessTokenJSON  = "accessToken" // Protobuf JSON name of access_token
+)
+
+// Granter exchanges the configured app token for a scoped access token and refreshes it
+// before it expires, so calls are made with a valid token without a user token in the config
+type Granter struct {
+	config    *config.Config
+	manager   *grpc.ClientManager
+	expiresAt time.Time // Zero when the access token has no expiry
+	failed    bool      // The last grant failed
+	granted   chan struct{}
+	mutex     sync.Mutex
+}
+
+// New creates a granter that calls identity through the manager. The manager should not
+// record responses, since the request carries the app token.
+func New(cfg *config.Config, manager *grpc.ClientManager) *Granter {
+	return &Granter{
+		config:  cfg,
+		manager: manager,
+		granted: make(chan struct{}, 1),
+	}
+}
+
+// Grant exchanges the app token of the active config for an access token and makes it the
+// config's token. It does nothing when the active profile has no app token.
+func (g *Granter) Grant(ctx context.Context) error {
+	settings := g.config.GetGrant().WithDefaults()
+	if !settings.Enabled() {
+		g.update(time.Time{}, false)
+		return nil
+	}
+
+	token, expiresAt, err := g.grant(ctx, settings)
+	if err != nil {
+		g.update(time.Time{}, true)
+		return err
+	}
+	g.config.SetGrantedToken(token)
+	g.update(expiresAt, false)
+	log.Printf("Granted %s scope access token for %s", settings.Scope, settings.DomainID)
+	return nil
+}
+
+// grant resolves the app token and exchanges it for an access token
+func (g *Granter) grant(ctx context.Context, settings config.GrantConfig) (string, time.Time, error) {
+	appToken, err := settings.ResolveAppToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return Exchange(ctx, g.manager, settings.Service, Request{
+		Token:       appToken,
+		Scope:       settings.Scope,
+		DomainID:    settings.DomainID,
+		WorkspaceID: settings.WorkspaceID,
+	})
+}
+
+// Request is a Token.grant request
+type Request struct {
+	Token       string // Refresh token or app token to exchange
+	Scope       string
+	DomainID    string
+	WorkspaceID string
+}
+
+// Exchange calls Token.grant of the identity service and returns the access token and its
+// expiry, which is zero when the token has none
+func Exchange(ctx context.Context, manager *grpc.ClientManager, service string, request Request) (string, time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, grantTimeout)
+	defer cancel()
+
+	caller, err := manager.GetServiceCaller(service)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to connect to %s: %w", service, err)
+	}
+	parameters := map[string]interface{}{
+		"grant_type": grantType,
+		"token":      request.Token,
+		"scope":      request.Scope,
+		"domain_id":  request.DomainID,
+		// Always sent so claim defaults never fill in the workspace of the current token
+		"workspace_id": request.WorkspaceID,
+	}
+	jsonBytes, err := caller.CallMethod(ctx, service, tokenResource, tokenGrantVerb, parameters)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to grant access token: %w", err)
+	}
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(jsonBytes, &response); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to parse grant response: %w", err)
+	}
+	token, _ := response[accessTokenField].(string)
+	if token == "" {
+		token, _ = response[accessTokenJSON].(string)
+	}
+	if token == "" {
+		return "", time.Time{}, fmt.Errorf("grant response has no access token")
+	}
+
+	var expiresAt time.Time
+	if info, err := jwt.Parse(token); err == nil {
+		expiresAt, _ = info.ExpiresAt()
+	}
+	return token, expiresAt, nil
+}
+
+// update records the outcome of a grant and wakes Run to reschedule the refresh
+func (g *Granter) update(expiresAt time.Time, failed bool) {
+	g.mutex.Lock()
+	g.expiresAt = expiresAt
+	g.failed = failed
+	g.mutex.Unlock()
+
+	select {
+	case g.granted <- struct{}{}:
+	default:
+	}
+}
+
+// nextRefresh returns how long to wait before the access token is refreshed
+func (g *Granter) nextRefresh() time.Duration {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	switch {
+	case g.failed:
+		return retryInterval
+	case g.expiresAt.IsZero():
+		return refreshInterval
+	}
+	refreshBefore := g.config.GetGrant().RefreshBefore()
+	return max(time.Until(g.expiresAt.Add(-refreshBefore)), minRefreshDelay)
+}
+
+// Run refreshes the access token before it expires, and retries failed grants, until ctx is canceled
+func (g *Granter) Run(ctx context.Context) {
+	timer := time.NewTimer(g.nextRefresh())
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-g.granted:
+			// Granted elsewhere, e.g. after a profile switch: reschedule only
+		case <-timer.C:
+			if err := g.Grant(ctx); err != nil {
+				log.Printf("ERROR: %v", err)
+			}
+			<-g.granted
+		}
+		timer.Stop()
+		timer.Reset(g.nextRefresh())
+	}
+}
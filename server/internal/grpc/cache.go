@@ -0,0 +1,143 @@
+package grpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"spacectl-web/server/internal/config"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// maxCachedResponses bounds the response cache; expired entries are dropped first when it is full
+const maxCachedResponses = 1000
+
+// ResponseCache keeps successful responses of the methods server.cache sets a TTL for.
+// Responses are cached per profile, token and outgoing metadata, so sessions with scoped
+// tokens or impersonation never see each other's responses.
+type ResponseCache struct {
+	policy  config.CachePolicyConfig
+	token   func() string // Configured token, used when the call has no token of its own
+	profile func() string
+	entries map[string]*cachedResponse
+	mutex   sync.Mutex
+}
+
+// cachedResponse is a cached JSON response
+type cachedResponse struct {
+	body    []byte
+	expires time.Time
+}
+
+// NewResponseCache creates a response cache from the configured policy, or returns nil when
+// no method is cached
+func NewResponseCache(cfg *config.Config) *ResponseCache {
+	if len(cfg.Server.Cache) == 0 {
+		return nil
+	}
+	return &ResponseCache{
+		policy:  cfg.Server.Cache,
+		token:   cfg.GetToken,
+		profile: cfg.ActiveProfile,
+		entries: make(map[string]*cachedResponse),
+	}
+}
+
+// TTL returns how long responses of the method are cached, zero when they are not
+func (r *ResponseCache) TTL(serviceName, resourceName, verb string) time.Duration {
+	if r == nil {
+		return 0
+	}
+	return r.policy.TTL(serviceName, resourceName, verb)
+}
+
+// key identifies a call by everything that may change its response
+func (r *ResponseCache) key(ctx context.Context, serviceName, resourceName, verb string, parameters map[string]interface{}) (string, error) {
+	encoded, err := json.Marshal(parameters) // Map keys are sorted
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	for _, part := range []string{r.profile(), callToken(ctx, r.token), outgoingMetadata(ctx), serviceName, resourceName, verb} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	hash.Write(encoded)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// get returns a cached response that has not expired
+func (r *ResponseCache) get(key string) ([]byte, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	entry, exists := r.entries[key]
+	if !exists || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.body, true
+}
+
+// put caches a response for ttl
+func (r *ResponseCache) put(key string, body []byte, ttl time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	now := time.Now()
+	if len(r.entries) >= maxCachedResponses {
+		for entryKey, entry := range r.entries {
+			if now.After(entry.expires) {
+				delete(r.entries, entryKey)
+			}
+		}
+	}
+	if len(r.entries) >= maxCachedResponses {
+		for entryKey := range r.entries { // Evict an arbitrary entry
+			delete(r.entries, entryKey)
+			break
+		}
+	}
+	r.entries[key] = &cachedResponse{body: body, expires: now.Add(ttl)}
+}
+
+// cached returns the cached response of the call when its method is cached, calling fetch and
+// caching its response otherwise. Failed calls are not cached.
+func (r *ResponseCache) cached(ctx context.Context, serviceName, resourceName, verb string, parameters map[string]interface{},
+	fetch func() ([]byte, error)) ([]byte, error) {
+	ttl := r.TTL(serviceName, resourceName, verb)
+	if ttl <= 0 {
+		return fetch()
+	}
+	key, err := r.key(ctx, serviceName, resourceName, verb, parameters)
+	if err != nil {
+		return fetch()
+	}
+	if body, hit := r.get(key); hit {
+		return body, nil
+	}
+	body, err := fetch()
+	if err == nil {
+		r.put(key, body, ttl)
+	}
+	return body, err
+}
+
+// outgoingMetadata returns the metadata sent with the call other than its request ID, in a stable form
+func outgoingMetadata(ctx context.Context) string {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		return ""
+	}
+	pairs := make([]string, 0, md.Len())
+	for key, values := range md {
+		if key != RequestIDMetadataKey {
+			pairs = append(pairs, key+"="+strings.Join(values, ","))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "\n")
+}
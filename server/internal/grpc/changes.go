@@ -0,0 +1,103 @@
+package grpc
+
+import (
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// discoveryChangeBuffer is how many changes a slow subscriber may fall behind before
+// further changes are dropped for it
+const discoveryChangeBuffer = 16
+
+// DiscoveryChange describes how a service's resources and verbs differ from its previous discovery
+type DiscoveryChange struct {
+	Service          string              `json:"service"`
+	AddedResources   []string            `json:"added_resources,omitempty"`
+	RemovedResources []string            `json:"removed_resources,omitempty"`
+	AddedVerbs       map[string][]string `json:"added_verbs,omitempty"`   // By resource
+	RemovedVerbs     map[string][]string `json:"removed_verbs,omitempty"` // By resource
+	Timestamp        time.Time           `json:"timestamp"`
+}
+
+// changeBroker fans discovery changes out to subscribers
+type changeBroker struct {
+	subscribers map[chan DiscoveryChange]struct{}
+	mutex       sync.Mutex
+}
+
+// subscribe registers a subscriber and returns its channel and a function that unregisters it
+func (b *changeBroker) subscribe() (<-chan DiscoveryChange, func()) {
+	ch := make(chan DiscoveryChange, discoveryChangeBuffer)
+	b.mutex.Lock()
+	if b.subscribers == nil {
+		b.subscribers = make(map[chan DiscoveryChange]struct{})
+	}
+	b.subscribers[ch] = struct{}{}
+	b.mutex.Unlock()
+
+	return ch, func() {
+		b.mutex.Lock()
+		delete(b.subscribers, ch)
+		b.mutex.Unlock()
+	}
+}
+
+// publish sends a change to every subscriber without blocking on slow ones
+func (b *changeBroker) publish(change DiscoveryChange) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- change:
+		default:
+		}
+	}
+}
+
+// diffServiceInfo compares two discoveries of a service and reports whether they differ
+func diffServiceInfo(previous, current *ServiceInfo) (DiscoveryChange, bool) {
+	change := DiscoveryChange{
+		Service:      current.Name,
+		AddedVerbs:   make(map[string][]string),
+		RemovedVerbs: make(map[string][]string),
+		Timestamp:    time.Now(),
+	}
+	for name, resource := range current.Resources {
+		old, existed := previous.Resources[name]
+		if !existed {
+			change.AddedResources = append(change.AddedResources, name)
+			continue
+		}
+		if added := missingFrom(resource.Verbs, old.Verbs); len(added) > 0 {
+			change.AddedVerbs[name] = added
+		}
+		if removed := missingFrom(old.Verbs, resource.Verbs); len(removed) > 0 {
+			change.RemovedVerbs[name] = removed
+		}
+	}
+	for name := range previous.Resources {
+		if _, exists := current.Resources[name]; !exists {
+			change.RemovedResources = append(change.RemovedResources, name)
+		}
+	}
+	sort.Strings(change.AddedResources)
+	sort.Strings(change.RemovedResources)
+
+	changed := len(change.AddedResources) > 0 || len(change.RemovedResources) > 0 ||
+		len(change.AddedVerbs) > 0 || len(change.RemovedVerbs) > 0
+	return change, changed
+}
+
+// missingFrom returns the values of a that are not in b, sorted
+func missingFrom(a, b []string) []string {
+	var missing []string
+	for _, value := range a {
+		if !slices.Contains(b, value) {
+			missing = append(missing, value)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
@@ -0,0 +1,59 @@
+package grpc
+
+import (
+	"context"
+	"slices"
+
+	"spacectl-web/server/internal/jwt"
+)
+
+// withClaimDefaults returns the parameters with the configured fields the caller left out
+// filled from the claims of the call's token. Only fields the input declares, as reported by
+// hasField, are filled; the caller's map is not modified.
+func (sc *ServiceCaller) withClaimDefaults(ctx context.Context, parameters map[string]interface{}, hasField func(string) bool) map[string]interface{} {
+	if len(sc.claimFields) == 0 {
+		return parameters
+	}
+	info, err := jwt.Parse(callToken(ctx, sc.token))
+	if err != nil {
+		return parameters
+	}
+
+	var filled map[string]interface{}
+	for field, claim := range sc.claimFields {
+		if _, supplied := parameters[field]; supplied || !hasField(field) {
+			continue
+		}
+		value, ok := info.Payload[claim].(string)
+		if !ok || value == "" {
+			continue
+		}
+		if filled == nil {
+			filled = make(map[string]interface{}, len(parameters)+len(sc.claimFields))
+			for key, existing := range parameters {
+				filled[key] = existing
+			}
+		}
+		filled[field] = value
+	}
+	if filled == nil {
+		return parameters
+	}
+	return filled
+}
+
+// restHasField reports whether the console-api method takes the field, according to discovery
+func (sc *ServiceCaller) restHasField(serviceName, resourceName, verb string) func(string) bool {
+	return func(field string) bool {
+		serviceInfo, err := sc.serviceDiscovery.GetServiceInfo(serviceName)
+		if err != nil {
+			return false
+		}
+		resource, exists := serviceInfo.Resources[resourceName]
+		if !exists || resource.Methods[verb] == nil {
+			return false
+		}
+		method := resource.Methods[verb]
+		return slices.Contains(method.RequiredParams, field) || slices.Contains(method.OptionalParams, field)
+	}
+}
@@ -2,105 +2,159 @@ package grpc
 
 import (
 	"context"
-	"fmt"
-	"strings"
+	"time"
 
 	"spacectl-web/server/internal/config"
-
-	"github.com/jhump/protoreflect/grpcreflect"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
+	"spacectl-web/server/internal/recording"
 )
 
-// PerRPCCredentials implements credentials.PerRPCCredentials for token-based authentication
+// PerRPCCredentials implements credentials.PerRPCCredentials for the endpoint's credential type
 type PerRPCCredentials struct {
-	Token string
+	Token     func() string     // Returns the current token so refreshed secrets take effect; a token set with WithToken wins
+	Auth      config.AuthConfig // Credential type with secrets resolved
+	Metadata  map[string]string // Extra metadata configured for the endpoint, with lower-case keys
+	Plaintext bool              // Allow sending credentials over plaintext connections
 }
 
 // GetRequestMetadata adds authentication metadata to the context
 func (c PerRPCCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
-	// Remove "Bearer " prefix if present
-	token := strings.TrimPrefix(c.Token, "Bearer ")
-	return map[string]string{
-		"token": token,
-	}, nil
+	credentials := c.Auth.Credentials(callToken(ctx, c.Token))
+	md := make(map[string]string, len(c.Metadata)+len(credentials))
+	for key, value := range c.Metadata {
+		md[key] = value
+	}
+	for key, value := range credentials {
+		md[key] = value
+	}
+	return md, nil
 }
 
 // RequireTransportSecurity indicates whether TLS security is required
 func (c PerRPCCredentials) RequireTransportSecurity() bool {
-	return true
+	return !c.Plaintext
 }
 
-// ClientManager manages gRPC connections for different services
+// ClientManager creates service callers on top of the shared connection pool
 type ClientManager struct {
 	config           *config.Config
-	clients          map[string]*grpc.ClientConn
-	refClients       map[string]*grpcreflect.Client
+	pool             *ConnectionPool
 	serviceDiscovery *ServiceDiscovery
+	faultInjector    *FaultInjector
+	recorder         *recording.Store // Records responses when set
+	offline          bool             // Replay responses from the recorder without gRPC connectivity
+	redactor         *Redactor        // Replaces sensitive response fields; nil without rules
+	payloadLogger    *PayloadLogger   // Logs payloads of sampled calls; nil when payload logging is disabled
+	slowCalls        *SlowCallLog     // Logs calls slower than a threshold; nil when the slow call log is disabled
+	cache            *ResponseCache   // Responses of cached methods; nil when no method is cached
 }
 
 // NewClientManager creates a new GRPCClientManager instance
-func NewClientManager(cfg *config.Config, serviceDiscovery *ServiceDiscovery) *ClientManager {
+func NewClientManager(cfg *config.Config, serviceDiscovery *ServiceDiscovery, pool *ConnectionPool) *ClientManager {
 	return &ClientManager{
 		config:           cfg,
-		clients:          make(map[string]*grpc.ClientConn),
-		refClients:       make(map[string]*grpcreflect.Client),
+		pool:             pool,
 		serviceDiscovery: serviceDiscovery,
+		redactor:         NewRedactor(cfg.Server.Redaction),
 	}
 }
 
-// GetClient returns a gRPC client and reflection client for the specified service
-func (m *ClientManager) GetClient(serviceName string) (*grpc.ClientConn, *grpcreflect.Client, error) {
-	// Return existing client if already connected
-	if conn, exists := m.clients[serviceName]; exists {
-		return conn, m.refClients[serviceName], nil
-	}
+// SetFaultInjector enables fault injection for all service callers created by the manager
+func (m *ClientManager) SetFaultInjector(faultInjector *FaultInjector) {
+	m.faultInjector = faultInjector
+}
 
-	// Get endpoint URL for the service
-	endpoint, exists := m.config.Endpoints[serviceName]
-	if !exists {
-		return nil, nil, fmt.Errorf("endpoint not found for service '%s'", serviceName)
-	}
+// SetPayloadLogger logs the payloads of sampled calls made by the manager's service callers
+func (m *ClientManager) SetPayloadLogger(payloadLogger *PayloadLogger) {
+	m.payloadLogger = payloadLogger
+}
 
-	// Extract host:port from grpc+ssl://host:port/v1 format
-	address := strings.TrimPrefix(endpoint, "grpc+ssl://")
-	address = strings.TrimSuffix(address, "/v1")
-
-	// Create SSL/TLS credentials
-	creds := credentials.NewClientTLSFromCert(nil, "")
-
-	// Create gRPC connection
-	conn, err := grpc.NewClient(
-		address,
-		grpc.WithTransportCredentials(creds),
-		grpc.WithPerRPCCredentials(PerRPCCredentials{Token: m.config.Token}),
-	)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to connect to service '%s': %w", serviceName, err)
-	}
+// SetSlowCallLog logs the slow calls made by the manager's service callers
+func (m *ClientManager) SetSlowCallLog(slowCalls *SlowCallLog) {
+	m.slowCalls = slowCalls
+}
 
-	// Create reflection client without timeout first
-	refClient := grpcreflect.NewClientAuto(context.Background(), conn)
+// SetResponseCache caches the responses of the methods the cache's policy sets a TTL for
+func (m *ClientManager) SetResponseCache(cache *ResponseCache) {
+	m.cache = cache
+}
 
-	// Store clients
-	m.clients[serviceName] = conn
-	m.refClients[serviceName] = refClient
+// Cached reports whether responses of the method are cached
+func (m *ClientManager) Cached(serviceName, resourceName, verb string) bool {
+	return m.cache.TTL(serviceName, resourceName, verb) > 0
+}
 
-	return conn, refClient, nil
+// SetRecording records responses to the store, or replays them from it in offline mode
+func (m *ClientManager) SetRecording(store *recording.Store, offline bool) {
+	m.recorder = store
+	m.offline = offline
 }
 
 // GetServiceCaller returns a ServiceCaller for the specified service
 func (m *ClientManager) GetServiceCaller(serviceName string) (*ServiceCaller, error) {
-	conn, refClient, err := m.GetClient(serviceName)
-	if err != nil {
-		return nil, err
+	// Offline callers replay recordings and never connect
+	if m.offline {
+		caller := NewServiceCaller(nil, nil, m.serviceDiscovery)
+		caller.recorder = m.recorder
+		caller.offline = true
+		caller.presets = m.config.Server.Presets
+		caller.redactor = m.redactor
+		return caller, nil
 	}
-	return NewServiceCaller(conn, refClient, m.serviceDiscovery), nil
-}
 
-// Close closes all gRPC connections
-func (m *ClientManager) Close() {
-	for _, conn := range m.clients {
-		conn.Close()
+	var caller *ServiceCaller
+	if m.pool.IsREST(serviceName) {
+		restClient, err := m.pool.REST(serviceName)
+		if err != nil {
+			return nil, err
+		}
+		caller = NewServiceCaller(nil, nil, m.serviceDiscovery)
+		caller.rest = restClient
+	} else {
+		conn, refClient, err := m.pool.Get(serviceName)
+		if err != nil {
+			return nil, err
+		}
+		caller = NewServiceCaller(conn, refClient, m.serviceDiscovery)
+		caller.pool = m.pool
+		caller.poolKey = serviceName
+	}
+	caller.faultInjector = m.faultInjector
+	caller.payloadLogger = m.payloadLogger
+	caller.slowCalls = m.slowCalls
+	caller.cache = m.cache
+	caller.presets = m.config.Server.Presets
+	caller.redactor = m.redactor
+	caller.recorder = m.recorder
+	caller.maxResponseBytes = m.config.Server.Limits.WithDefaults().MaxResponseBytes
+	responses := m.config.Server.Responses.WithDefaults()
+	caller.indent = responses.Indent
+	caller.streamThreshold = max(responses.StreamThresholdBytes, 0)
+	if claimDefaults := m.config.Server.ClaimDefaults; claimDefaults.Enabled {
+		caller.claimFields = claimDefaults.WithDefaults().Fields
+		caller.token = m.config.GetToken
+	}
+	if endpoint, exists := m.config.GetEndpoint(serviceName); exists && endpoint.Timeout > 0 {
+		caller.timeout = time.Duration(endpoint.Timeout) * time.Second
 	}
+	return caller, nil
+}
+
+// Stats returns connection statistics for diagnostics
+func (m *ClientManager) Stats() PoolStats {
+	return m.pool.Stats()
+}
+
+// Connections returns the open gRPC connections for runtime inspection
+func (m *ClientManager) Connections() []ConnectionInfo {
+	return m.pool.Connections()
+}
+
+// DropConnection closes a service's gRPC connection and reports whether one was open
+func (m *ClientManager) DropConnection(serviceName string) bool {
+	return m.pool.Drop(serviceName)
+}
+
+// Reset closes all gRPC connections so the next call reconnects with the current config
+func (m *ClientManager) Reset() {
+	m.pool.Reset()
 }
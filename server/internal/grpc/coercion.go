@@ -0,0 +1,90 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/golang/protobuf/jsonpb" //nolint:staticcheck // dynamic messages use the v1 JSON API
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+)
+
+// Parameter coercion statuses
+const (
+	CoercionSet       = "set"       // Value used as supplied
+	CoercionConverted = "converted" // Value converted to the field's type
+	CoercionDropped   = "dropped"   // Value not sent upstream
+)
+
+// ParameterCoercion describes how a supplied parameter was applied to the request message
+type ParameterCoercion struct {
+	Parameter string          `json:"parameter"`
+	Field     string          `json:"field,omitempty"` // Fully qualified target field
+	Type      string          `json:"type,omitempty"`  // Proto type of the field, e.g. "repeated string"
+	Value     json.RawMessage `json:"value,omitempty"` // Value as sent upstream, in protobuf JSON form
+	Status    string          `json:"status"`
+	Reason    string          `json:"reason,omitempty"`
+}
+
+// ParameterReport collects how each supplied parameter of a call was interpreted
+type ParameterReport struct {
+	Parameters []ParameterCoercion
+}
+
+// parameterReportKey is the context key for the parameter report of a call
+type parameterReportKey struct{}
+
+// WithParameterReport returns a context whose unary call records parameter coercion in report
+func WithParameterReport(ctx context.Context, report *ParameterReport) context.Context {
+	return context.WithValue(ctx, parameterReportKey{}, report)
+}
+
+// parameterReportFromContext returns the parameter report attached to the context, if any
+func parameterReportFromContext(ctx context.Context) *ParameterReport {
+	report, _ := ctx.Value(parameterReportKey{}).(*ParameterReport)
+	return report
+}
+
+// describeParameter reports how a parameter ended up in the built request message
+func describeParameter(msg *dynamic.Message, parameter string, converted bool) ParameterCoercion {
+	fieldDesc := msg.GetMessageDescriptor().FindFieldByName(parameter)
+	coercion := ParameterCoercion{
+		Parameter: parameter,
+		Field:     fieldDesc.GetFullyQualifiedName(),
+		Type:      fieldTypeName(fieldDesc),
+		Value:     fieldJSON(msg, fieldDesc),
+		Status:    CoercionSet,
+	}
+
+	switch {
+	case !msg.HasField(fieldDesc):
+		coercion.Status = CoercionDropped
+		coercion.Reason = "equals the default value, so it is not sent"
+		if oneOf := fieldDesc.GetOneOf(); oneOf != nil {
+			if other, _ := msg.GetOneOfField(oneOf); other != nil {
+				coercion.Reason = fmt.Sprintf("overridden by '%s' in oneof '%s'", other.GetName(), oneOf.GetName())
+			}
+		}
+	case converted:
+		coercion.Status = CoercionConverted
+	}
+	return coercion
+}
+
+// fieldJSON returns the protobuf JSON form of a single field's value
+func fieldJSON(msg *dynamic.Message, fieldDesc *desc.FieldDescriptor) json.RawMessage {
+	single := dynamic.NewMessage(msg.GetMessageDescriptor())
+	if err := single.TrySetField(fieldDesc, msg.GetField(fieldDesc)); err != nil {
+		return nil
+	}
+	data, err := single.MarshalJSONPB(&jsonpb.Marshaler{OrigName: true, EmitDefaults: true})
+	if err != nil {
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+	return fields[fieldDesc.GetName()]
+}
@@ -0,0 +1,73 @@
+package grpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"spacectl-web/server/internal/errors"
+
+	"github.com/golang/protobuf/jsonpb" //nolint:staticcheck // dynamic messages use the v1 JSON API
+	"github.com/jhump/protoreflect/dynamic"
+)
+
+// CallDescription is a call as it is sent to the service, for rendering it as code
+type CallDescription struct {
+	REST        bool                   `json:"rest"`                   // Sent to a console-api over HTTP instead of gRPC
+	URL         string                 `json:"url,omitempty"`          // Route the console-api serves the verb at
+	Service     string                 `json:"service,omitempty"`      // Fully qualified gRPC service, e.g. spaceone.api.identity.v2.Project
+	Method      string                 `json:"method,omitempty"`       // gRPC method name
+	ServiceFile string                 `json:"service_file,omitempty"` // Proto file declaring the service
+	InputType   string                 `json:"input_type,omitempty"`   // Request message name within its package, e.g. GetProjectRequest
+	InputFile   string                 `json:"input_file,omitempty"`   // Proto file declaring the request message
+	Parameters  map[string]interface{} `json:"parameters"`             // Request JSON with presets applied and proto field names
+}
+
+// DescribeCall returns a call with its presets applied. gRPC parameters are converted to the
+// method's request message and back, so they have the field names and value types the
+// service expects; console-api parameters are sent as they are.
+func (sc *ServiceCaller) DescribeCall(serviceName, resourceName, verb string, parameters map[string]interface{}) (*CallDescription, error) {
+	parameters = sc.withPresets(serviceName, resourceName, verb, parameters)
+	if parameters == nil {
+		parameters = make(map[string]interface{})
+	}
+	if sc.offline {
+		return nil, errors.NewAPIError(errors.ErrServiceDescriptorFailed, "offline mode has no service descriptors")
+	}
+	if sc.rest != nil {
+		return &CallDescription{REST: true, URL: sc.rest.baseURL + restPath(serviceName, resourceName, verb), Parameters: parameters}, nil
+	}
+
+	methodDesc, err := sc.resolveMethod(serviceName, resourceName, verb)
+	if err != nil {
+		return nil, err
+	}
+	requestMsg, err := sc.buildRequest(methodDesc, parameters, nil)
+	if err != nil {
+		return nil, err
+	}
+	dynamicMsg, ok := requestMsg.(*dynamic.Message)
+	if !ok {
+		return nil, errors.NewAPIError(errors.ErrJSONConversionFailed, "request is not a dynamic message")
+	}
+	data, err := dynamicMsg.MarshalJSONPB(&jsonpb.Marshaler{OrigName: true})
+	if err != nil {
+		return nil, errors.NewAPIError(errors.ErrJSONConversionFailed, err.Error())
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber() // Keep 64-bit values exact
+	var converted map[string]interface{}
+	if err := decoder.Decode(&converted); err != nil {
+		return nil, errors.NewAPIError(errors.ErrJSONConversionFailed, err.Error())
+	}
+
+	inputType := methodDesc.GetInputType()
+	return &CallDescription{
+		Service:     methodDesc.GetService().GetFullyQualifiedName(),
+		Method:      methodDesc.GetName(),
+		ServiceFile: methodDesc.GetFile().GetName(),
+		InputType:   strings.TrimPrefix(inputType.GetFullyQualifiedName(), inputType.GetFile().GetPackage()+"."),
+		InputFile:   inputType.GetFile().GetName(),
+		Parameters:  converted,
+	}, nil
+}
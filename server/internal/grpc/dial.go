@@ -0,0 +1,99 @@
+package grpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"spacectl-web/server/internal/config"
+	"spacectl-web/server/internal/errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// dialEndpoint creates a gRPC connection using the endpoint's connection settings.
+// maxResponseBytes caps received messages unless the endpoint sets its own limit.
+func dialEndpoint(endpoint *config.EndpointConfig, token func() string, maxResponseBytes int64) (*grpc.ClientConn, error) {
+	transportCreds, err := transportCredentials(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := endpoint.ResolveMetadata()
+	if err != nil {
+		return nil, err
+	}
+	auth, err := endpoint.ResolveAuth()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(transportCreds),
+		grpc.WithPerRPCCredentials(PerRPCCredentials{
+			Token:     token,
+			Auth:      auth,
+			Metadata:  metadata,
+			Plaintext: endpoint.IsPlaintext(),
+		}),
+	}
+	maxMessageSize := int(maxResponseBytes)
+	if endpoint.MaxMessageSize > 0 {
+		maxMessageSize = endpoint.MaxMessageSize
+	}
+	if maxMessageSize > 0 {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxMessageSize)))
+	}
+
+	return grpc.NewClient(endpoint.Address(), opts...)
+}
+
+// ProbeEndpoint checks that the endpoint accepts TCP connections within the timeout,
+// so an unreachable host fails immediately instead of at the RPC deadline
+func ProbeEndpoint(serviceName string, endpoint *config.EndpointConfig, timeout time.Duration) error {
+	address := endpoint.Address()
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return errors.NewAPIError(errors.ErrEndpointUnreachable,
+			fmt.Sprintf("cannot reach %s for service '%s': %v", address, serviceName, err))
+	}
+	return conn.Close()
+}
+
+// transportCredentials builds transport credentials from the endpoint's TLS options
+func transportCredentials(endpoint *config.EndpointConfig) (credentials.TransportCredentials, error) {
+	if endpoint.IsPlaintext() {
+		return insecure.NewCredentials(), nil
+	}
+	tlsConfig, err := endpointTLSConfig(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// endpointTLSConfig builds the TLS configuration from the endpoint's TLS options
+func endpointTLSConfig(endpoint *config.EndpointConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         endpoint.TLS.ServerName,
+		InsecureSkipVerify: endpoint.TLS.InsecureSkipVerify, //nolint:gosec // explicitly opted in via config
+	}
+
+	if endpoint.TLS.CAFile != "" {
+		caData, err := os.ReadFile(endpoint.TLS.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caData) {
+			return nil, fmt.Errorf("failed to parse CA file '%s'", endpoint.TLS.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
@@ -2,30 +2,69 @@ package grpc
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"log"
 	"maps"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"spacectl-web/server/internal/config"
+	"spacectl-web/server/internal/errors"
+	"spacectl-web/server/internal/recording"
+	"spacectl-web/server/internal/sharedcache"
 
 	"github.com/jhump/protoreflect/desc"
 	"github.com/jhump/protoreflect/grpcreflect"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
 )
 
 // ServiceDiscovery manages service discovery and caching
 type ServiceDiscovery struct {
 	config     *config.Config
-	clients    map[string]*grpc.ClientConn
-	refClients map[string]*grpcreflect.Client
+	pool       *ConnectionPool
 	cache      map[string]*ServiceInfo
 	cacheMutex sync.RWMutex
 	cacheTTL   time.Duration
+	recorder   *recording.Store         // Records discovered services when set
+	offline    bool                     // Serve discovery from the recorder instead of gRPC reflection
+	shared     *sharedcache.Cache       // Discovery results shared with other replicas; nil when disabled
+	wellKnown  map[string]*ResourceInfo // Well-known resources resolved without full discovery, by service/resource
+	snapshots  map[string]*ServiceInfo  // Last discovery of each service, kept across cache clears to detect changes
+	changes    changeBroker
 }
 
+// wellKnownServices maps the resources every service exposes to their fully-qualified gRPC services
+var wellKnownServices = map[string]string{
+	"Health":     "grpc.health.v1.Health",
+	"ServerInfo": "spaceone.api.core.v1.ServerInfo",
+}
+
+// IsWellKnownResource reports whether a resource is served by every service, like Health and
+// ServerInfo, and can be resolved without discovering the service
+func IsWellKnownResource(resourceName string) bool {
+	_, exists := wellKnownServices[resourceName]
+	return exists
+}
+
+// restDiscoveryTimeout bounds fetching the OpenAPI document of a console-api endpoint
+const restDiscoveryTimeout = 30 * time.Second
+
+// WellKnownResourceName returns the resource a well-known gRPC service such as
+// grpc.health.v1.Health is exposed as
+func WellKnownResourceName(fullServiceName string) (string, bool) {
+	for resourceName, wellKnownName := range wellKnownServices {
+		if wellKnownName == fullServiceName {
+			return resourceName, true
+		}
+	}
+	return "", false
+}
+
+// sharedCacheTimeout bounds a shared cache lookup so a slow Redis falls back to discovery
+const sharedCacheTimeout = 2 * time.Second
+
 // ServiceInfo contains discovered service information
 type ServiceInfo struct {
 	Name       string                   `json:"name"`
@@ -43,20 +82,24 @@ type ResourceInfo struct {
 
 // MethodInfo contains method information including required parameters
 type MethodInfo struct {
-	Name           string   `json:"name"`
-	RequiredParams []string `json:"required_params"`
-	OptionalParams []string `json:"optional_params"`
-	InputType      string   `json:"input_type"`
+	Name            string        `json:"name"`
+	RequiredParams  []string      `json:"required_params"`
+	OptionalParams  []string      `json:"optional_params"`
+	InputType       string        `json:"input_type"`
+	ClientStreaming bool          `json:"client_streaming"`
+	ServerStreaming bool          `json:"server_streaming"`
+	HTTP            []HTTPBinding `json:"http,omitempty"` // Bindings from the google.api.http option
 }
 
 // NewServiceDiscovery creates a new ServiceDiscovery instance
-func NewServiceDiscovery(cfg *config.Config) *ServiceDiscovery {
+func NewServiceDiscovery(cfg *config.Config, pool *ConnectionPool) *ServiceDiscovery {
 	return &ServiceDiscovery{
-		config:     cfg,
-		clients:    make(map[string]*grpc.ClientConn),
-		refClients: make(map[string]*grpcreflect.Client),
-		cache:      make(map[string]*ServiceInfo),
-		cacheTTL:   5 * time.Minute, // Cache for 5 minutes
+		config:    cfg,
+		pool:      pool,
+		cache:     make(map[string]*ServiceInfo),
+		cacheTTL:  5 * time.Minute, // Cache for 5 minutes
+		wellKnown: make(map[string]*ResourceInfo),
+		snapshots: make(map[string]*ServiceInfo),
 	}
 }
 
@@ -71,27 +114,184 @@ func (sd *ServiceDiscovery) GetServiceInfo(serviceName string) (*ServiceInfo, er
 		return cached, nil
 	}
 
-	// Discover service information
-	serviceInfo, err := sd.discoverService(serviceName)
-	if err != nil {
-		return nil, err
+	// Use a result discovered by another replica, otherwise discover and share it
+	serviceInfo, shared := sd.loadShared(serviceName)
+	if !shared {
+		var err error
+		serviceInfo, err = sd.loadOrDiscoverService(serviceName)
+		if err != nil {
+			return nil, err
+		}
+		sd.storeShared(serviceName, serviceInfo)
 	}
 
 	// Update cache
 	sd.cacheMutex.Lock()
 	sd.cache[serviceName] = serviceInfo
+	previous := sd.snapshots[serviceName]
+	sd.snapshots[serviceName] = serviceInfo
 	sd.cacheMutex.Unlock()
 
+	// Tell open sessions when resources or verbs changed since the last discovery
+	if previous != nil {
+		if change, changed := diffServiceInfo(previous, serviceInfo); changed {
+			log.Printf("Discovery of %s changed: %d resource(s) added, %d removed", serviceName, len(change.AddedResources), len(change.RemovedResources))
+			sd.changes.publish(change)
+		}
+	}
+
 	return serviceInfo, nil
 }
 
-// discoverService discovers service information via gRPC reflection
+// SubscribeChanges returns a channel receiving a DiscoveryChange whenever rediscovering a
+// service finds different resources or verbs, and a function that unsubscribes
+func (sd *ServiceDiscovery) SubscribeChanges() (<-chan DiscoveryChange, func()) {
+	return sd.changes.subscribe()
+}
+
+// GetWellKnownResource returns a well-known resource of a service, such as Health, by resolving
+// only its gRPC service instead of walking every service the server exposes. A cached full
+// discovery is used when available, and offline mode always uses the recorded discovery.
+func (sd *ServiceDiscovery) GetWellKnownResource(serviceName, resourceName string) (*ResourceInfo, error) {
+	fullName, exists := wellKnownServices[resourceName]
+	if !exists {
+		return nil, fmt.Errorf("resource '%s' is not well-known", resourceName)
+	}
+
+	key := serviceName + "/" + resourceName
+	sd.cacheMutex.RLock()
+	cached, discovered := sd.cache[serviceName]
+	resource := sd.wellKnown[key]
+	sd.cacheMutex.RUnlock()
+	if discovered && time.Since(cached.LastUpdate) < sd.cacheTTL {
+		if resource, found := cached.Resources[resourceName]; found {
+			return resource, nil
+		}
+	}
+	if resource != nil {
+		return resource, nil
+	}
+	if sd.offline || sd.pool.IsREST(serviceName) {
+		serviceInfo, err := sd.GetServiceInfo(serviceName)
+		if err != nil {
+			return nil, err
+		}
+		if resource, found := serviceInfo.Resources[resourceName]; found {
+			return resource, nil
+		}
+		return nil, errors.NewAPIError(errors.ErrResourceNotFound, fmt.Sprintf("resource '%s' not found in %s", resourceName, serviceName))
+	}
+
+	_, refClient, release, err := sd.pool.acquire(serviceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gRPC client for %s: %w", serviceName, err)
+	}
+	defer release()
+	serviceDesc, err := refClient.ResolveService(fullName)
+	if grpcreflect.IsElementNotFoundError(err) {
+		return nil, errors.NewAPIError(errors.ErrResourceNotFound, fmt.Sprintf("resource '%s' not found: %s does not serve %s", resourceName, serviceName, fullName))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", fullName, err)
+	}
+
+	// Kept until the cache is cleared: these services do not change while a server runs
+	resource = sd.resourceInfo(resourceName, fullName, serviceDesc)
+	sd.cacheMutex.Lock()
+	sd.wellKnown[key] = resource
+	sd.cacheMutex.Unlock()
+	return resource, nil
+}
+
+// SetRecording records discovered services to the store, or replays them from it in offline mode
+func (sd *ServiceDiscovery) SetRecording(store *recording.Store, offline bool) {
+	sd.recorder = store
+	sd.offline = offline
+}
+
+// SetSharedCache shares discovery results with other replicas through the cache
+func (sd *ServiceDiscovery) SetSharedCache(cache *sharedcache.Cache) {
+	sd.shared = cache
+}
+
+// sharedKey returns the shared cache key of a service in the active profile
+func (sd *ServiceDiscovery) sharedKey(serviceName string) string {
+	return "discovery:" + sd.config.ActiveProfile() + ":" + serviceName
+}
+
+// loadShared returns a still valid discovery result from the shared cache
+func (sd *ServiceDiscovery) loadShared(serviceName string) (*ServiceInfo, bool) {
+	if sd.shared == nil || sd.offline {
+		return nil, false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), sharedCacheTimeout)
+	defer cancel()
+
+	data, found, err := sd.shared.Get(ctx, sd.sharedKey(serviceName))
+	if err != nil {
+		log.Printf("WARNING: failed to read shared discovery cache for %s: %v", serviceName, err)
+		return nil, false
+	}
+	var serviceInfo ServiceInfo
+	if !found || json.Unmarshal(data, &serviceInfo) != nil || time.Since(serviceInfo.LastUpdate) >= sd.cacheTTL {
+		return nil, false
+	}
+	return &serviceInfo, true
+}
+
+// storeShared shares a discovery result with other replicas until it expires
+func (sd *ServiceDiscovery) storeShared(serviceName string, serviceInfo *ServiceInfo) {
+	if sd.shared == nil || sd.offline {
+		return
+	}
+	data, err := json.Marshal(serviceInfo)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), sharedCacheTimeout)
+	defer cancel()
+	if err := sd.shared.Set(ctx, sd.sharedKey(serviceName), data, sd.cacheTTL); err != nil {
+		log.Printf("WARNING: failed to write shared discovery cache for %s: %v", serviceName, err)
+	}
+}
+
+// loadOrDiscoverService loads recorded service information in offline mode, otherwise discovers and records it
+func (sd *ServiceDiscovery) loadOrDiscoverService(serviceName string) (*ServiceInfo, error) {
+	if sd.offline {
+		var serviceInfo ServiceInfo
+		if err := sd.recorder.LoadDiscovery(serviceName, &serviceInfo); err != nil {
+			return nil, err
+		}
+		serviceInfo.LastUpdate = time.Now()
+		return &serviceInfo, nil
+	}
+
+	serviceInfo, err := sd.discoverService(serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	if sd.recorder != nil {
+		if err := sd.recorder.SaveDiscovery(serviceName, serviceInfo); err != nil {
+			log.Printf("WARNING: failed to record discovery for %s: %v", serviceName, err)
+		}
+	}
+	return serviceInfo, nil
+}
+
+// discoverService discovers service information via gRPC reflection, or from the OpenAPI
+// document of a console-api endpoint
 func (sd *ServiceDiscovery) discoverService(serviceName string) (*ServiceInfo, error) {
-	// Get gRPC client
-	_, refClient, err := sd.getClient(serviceName)
+	if sd.pool.IsREST(serviceName) {
+		return sd.discoverRESTService(serviceName)
+	}
+
+	// Get gRPC client, holding the connection while discovering
+	_, refClient, release, err := sd.pool.acquire(serviceName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get gRPC client for %s: %w", serviceName, err)
 	}
+	defer release()
 
 	// List all available services
 	services, err := refClient.ListServices()
@@ -99,11 +299,11 @@ func (sd *ServiceDiscovery) discoverService(serviceName string) (*ServiceInfo, e
 		return nil, fmt.Errorf("failed to list services: %w", err)
 	}
 	// Debug: Log all discovered services
-	fmt.Printf("*** Discovered %d services for %s:\n", len(services), serviceName)
+	log.Printf("*** Discovered %d services for %s:", len(services), serviceName)
 	for _, service := range services {
-		fmt.Printf("  - %s\n", service)
+		log.Printf("  - %s", service)
 	}
-	fmt.Println("*** End of discovered services")
+	log.Println("*** End of discovered services")
 
 	// Filter services that belong to this service name
 	serviceInfo := &ServiceInfo{
@@ -128,27 +328,8 @@ func (sd *ServiceDiscovery) discoverService(serviceName string) (*ServiceInfo, e
 			continue // Skip services we can't resolve
 		}
 
-		// Get methods for this service
-		methods := serviceDesc.GetMethods()
-		var verbs []string
-		methodDetails := make(map[string]*MethodInfo)
-
-		for _, method := range methods {
-			methodName := method.GetName()
-			verbs = append(verbs, methodName)
-
-			// Extract method parameter information
-			methodInfo := sd.extractMethodInfo(method)
-			methodDetails[methodName] = methodInfo
-		}
-
 		// Store resource info with actual service name
-		resourceMap[resourceName] = &ResourceInfo{
-			Name:        resourceName,
-			Verbs:       verbs,
-			ServiceName: service, // Store the actual discovered service name
-			Methods:     methodDetails,
-		}
+		resourceMap[resourceName] = sd.resourceInfo(resourceName, service, serviceDesc)
 	}
 
 	// Copy resource info to service info
@@ -157,14 +338,54 @@ func (sd *ServiceDiscovery) discoverService(serviceName string) (*ServiceInfo, e
 	return serviceInfo, nil
 }
 
+// discoverRESTService discovers the resources of a console-api service from its routes
+func (sd *ServiceDiscovery) discoverRESTService(serviceName string) (*ServiceInfo, error) {
+	client, err := sd.pool.REST(serviceName)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), restDiscoveryTimeout)
+	defer cancel()
+
+	resources, err := client.Discover(ctx, serviceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover %s: %w", serviceName, err)
+	}
+	log.Printf("*** Discovered %d resources for %s from its console-api routes", len(resources), serviceName)
+	return &ServiceInfo{
+		Name:       serviceName,
+		Resources:  resources,
+		LastUpdate: time.Now(),
+	}, nil
+}
+
+// resourceInfo describes a resource from its gRPC service descriptor
+func (sd *ServiceDiscovery) resourceInfo(resourceName, fullServiceName string, serviceDesc *desc.ServiceDescriptor) *ResourceInfo {
+	var verbs []string
+	methodDetails := make(map[string]*MethodInfo)
+	for _, method := range serviceDesc.GetMethods() {
+		methodName := method.GetName()
+		verbs = append(verbs, methodName)
+
+		// Extract method parameter information
+		methodDetails[methodName] = sd.extractMethodInfo(method)
+	}
+
+	return &ResourceInfo{
+		Name:        resourceName,
+		Verbs:       verbs,
+		ServiceName: fullServiceName, // Store the actual discovered service name
+		Methods:     methodDetails,
+	}
+}
+
 // extractResourceName extracts resource name from full service name
 func (sd *ServiceDiscovery) extractResourceName(fullServiceName, serviceName string) string {
 	// Handle special cases first
-	if fullServiceName == "grpc.health.v1.Health" {
-		return "Health"
-	}
-	if fullServiceName == "spaceone.api.core.v1.ServerInfo" {
-		return "ServerInfo"
+	for resourceName, wellKnownName := range wellKnownServices {
+		if fullServiceName == wellKnownName {
+			return resourceName
+		}
 	}
 
 	parts := strings.Split(fullServiceName, ".")
@@ -217,10 +438,13 @@ func (sd *ServiceDiscovery) extractMethodInfo(method *desc.MethodDescriptor) *Me
 	}
 
 	methodInfo := &MethodInfo{
-		Name:           method.GetName(),
-		RequiredParams: requiredParams,
-		OptionalParams: optionalParams,
-		InputType:      inputType.GetFullyQualifiedName(),
+		Name:            method.GetName(),
+		RequiredParams:  requiredParams,
+		OptionalParams:  optionalParams,
+		InputType:       inputType.GetFullyQualifiedName(),
+		ClientStreaming: method.IsClientStreaming(),
+		ServerStreaming: method.IsServerStreaming(),
+		HTTP:            httpBindings(method),
 	}
 
 	return methodInfo
@@ -249,65 +473,100 @@ func (sd *ServiceDiscovery) isCompatibleService(discoveredServiceName, requested
 	return false
 }
 
-// getClient returns a gRPC client and reflection client for the specified service
-func (sd *ServiceDiscovery) getClient(serviceName string) (*grpc.ClientConn, *grpcreflect.Client, error) {
-	// Return existing client if already connected
-	if conn, exists := sd.clients[serviceName]; exists {
-		return conn, sd.refClients[serviceName], nil
+// GetAvailableServices returns list of available service names from config, or from recordings in offline mode
+func (sd *ServiceDiscovery) GetAvailableServices() []string {
+	if sd.offline {
+		return sd.recorder.Services()
 	}
+	return sd.config.ServiceNames()
+}
 
-	// Get endpoint URL for the service
-	endpoint, exists := sd.config.Endpoints[serviceName]
-	if !exists {
-		return nil, nil, fmt.Errorf("endpoint not found for service '%s'", serviceName)
+// ClearCache clears the service discovery cache
+func (sd *ServiceDiscovery) ClearCache() {
+	sd.cacheMutex.Lock()
+	sd.cache = make(map[string]*ServiceInfo)
+	sd.wellKnown = make(map[string]*ResourceInfo)
+	sd.cacheMutex.Unlock()
+
+	// Clear the shared entries too so other replicas rediscover as well
+	if sd.shared != nil {
+		var keys []string
+		for _, serviceName := range sd.config.ServiceNames() {
+			keys = append(keys, sd.sharedKey(serviceName))
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), sharedCacheTimeout)
+		defer cancel()
+		if err := sd.shared.Delete(ctx, keys...); err != nil {
+			log.Printf("WARNING: failed to clear shared discovery cache: %v", err)
+		}
 	}
+}
 
-	// Extract host:port from grpc+ssl://host:port/v1 format
-	address := strings.TrimPrefix(endpoint, "grpc+ssl://")
-	address = strings.TrimSuffix(address, "/v1")
+// DiscoveryStats represents cache statistics for diagnostics
+type DiscoveryStats struct {
+	CachedServices  int `json:"cached_services"`
+	CachedResources int `json:"cached_resources"`
+	CachedMethods   int `json:"cached_methods"`
+	CacheTTLSeconds int `json:"cache_ttl_seconds"`
+}
 
-	// Create SSL/TLS credentials
-	creds := credentials.NewClientTLSFromCert(nil, "")
+// Stats returns cache statistics for diagnostics
+func (sd *ServiceDiscovery) Stats() DiscoveryStats {
+	sd.cacheMutex.RLock()
+	defer sd.cacheMutex.RUnlock()
 
-	// Create gRPC connection
-	conn, err := grpc.NewClient(
-		address,
-		grpc.WithTransportCredentials(creds),
-		grpc.WithPerRPCCredentials(PerRPCCredentials{Token: sd.config.Token}),
-	)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to connect to service '%s': %w", serviceName, err)
+	stats := DiscoveryStats{
+		CachedServices:  len(sd.cache),
+		CacheTTLSeconds: int(sd.cacheTTL.Seconds()),
 	}
+	for _, serviceInfo := range sd.cache {
+		stats.CachedResources += len(serviceInfo.Resources)
+		for _, resource := range serviceInfo.Resources {
+			stats.CachedMethods += len(resource.Methods)
+		}
+	}
+	return stats
+}
 
-	// Create reflection client
-	refClient := grpcreflect.NewClientAuto(context.Background(), conn)
-
-	// Store clients
-	sd.clients[serviceName] = conn
-	sd.refClients[serviceName] = refClient
-
-	return conn, refClient, nil
+// CacheEntry represents a cached discovery result for runtime inspection
+type CacheEntry struct {
+	Service    string    `json:"service"`
+	Resources  int       `json:"resources"`
+	Methods    int       `json:"methods"`
+	LastUpdate time.Time `json:"last_update"`
+	AgeSeconds int       `json:"age_seconds"`
+	Expired    bool      `json:"expired"` // Rediscovered on next use
 }
 
-// GetAvailableServices returns list of available service names from config
-func (sd *ServiceDiscovery) GetAvailableServices() []string {
-	services := make([]string, 0, len(sd.config.Endpoints))
-	for serviceName := range sd.config.Endpoints {
-		services = append(services, serviceName)
+// CacheEntries returns the cached services, ordered by name
+func (sd *ServiceDiscovery) CacheEntries() []CacheEntry {
+	sd.cacheMutex.RLock()
+	defer sd.cacheMutex.RUnlock()
+
+	entries := make([]CacheEntry, 0, len(sd.cache))
+	for name, serviceInfo := range sd.cache {
+		age := time.Since(serviceInfo.LastUpdate)
+		entry := CacheEntry{
+			Service:    name,
+			Resources:  len(serviceInfo.Resources),
+			LastUpdate: serviceInfo.LastUpdate,
+			AgeSeconds: int(age.Seconds()),
+			Expired:    age >= sd.cacheTTL,
+		}
+		for _, resource := range serviceInfo.Resources {
+			entry.Methods += len(resource.Methods)
+		}
+		entries = append(entries, entry)
 	}
-	return services
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Service < entries[j].Service })
+	return entries
 }
 
-// ClearCache clears the service discovery cache
-func (sd *ServiceDiscovery) ClearCache() {
+// Reset clears the cache so discovery uses the current config. Snapshots are cleared too,
+// since services of another profile are not changes of the previous ones.
+func (sd *ServiceDiscovery) Reset() {
+	sd.ClearCache()
 	sd.cacheMutex.Lock()
-	defer sd.cacheMutex.Unlock()
-	sd.cache = make(map[string]*ServiceInfo)
-}
-
-// Close closes all gRPC connections
-func (sd *ServiceDiscovery) Close() {
-	for _, conn := range sd.clients {
-		conn.Close()
-	}
+	sd.snapshots = make(map[string]*ServiceInfo)
+	sd.cacheMutex.Unlock()
 }
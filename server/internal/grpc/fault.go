@@ -0,0 +1,72 @@
+package grpc
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"time"
+
+	"spacectl-web/server/internal/config"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// FaultInjector injects artificial latency and errors into gRPC calls for UI development
+type FaultInjector struct {
+	rules []config.FaultRule
+}
+
+// NewFaultInjector creates a FaultInjector from the configured rules
+func NewFaultInjector(rules []config.FaultRule) *FaultInjector {
+	return &FaultInjector{rules: rules}
+}
+
+// Apply sleeps and/or returns an injected error for the first rule matching the call
+func (f *FaultInjector) Apply(ctx context.Context, serviceName, resourceName, verb string) error {
+	if f == nil {
+		return nil
+	}
+
+	for _, rule := range f.rules {
+		if !matchFault(rule.Service, serviceName) || !matchFault(rule.Resource, resourceName) || !matchFault(rule.Verb, verb) {
+			continue
+		}
+
+		delay := time.Duration(rule.LatencyMs) * time.Millisecond
+		if rule.JitterMs > 0 {
+			delay += time.Duration(rand.Intn(rule.JitterMs)) * time.Millisecond //nolint:gosec // not security sensitive
+		}
+		if delay > 0 {
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if rule.ErrorRate > 0 && rand.Float64() < rule.ErrorRate { //nolint:gosec // not security sensitive
+			message := rule.ErrorMessage
+			if message == "" {
+				message = "injected fault"
+			}
+			return status.Error(faultCode(rule.ErrorCode), message)
+		}
+		return nil
+	}
+	return nil
+}
+
+// matchFault reports whether a rule field matches the call value
+func matchFault(pattern, value string) bool {
+	return pattern == "" || pattern == "*" || pattern == value
+}
+
+// faultCode converts a gRPC code name such as UNAVAILABLE to its code, defaulting to Unavailable
+func faultCode(name string) codes.Code {
+	var code codes.Code
+	if err := code.UnmarshalJSON([]byte(`"` + strings.ToUpper(name) + `"`)); err != nil {
+		return codes.Unavailable
+	}
+	return code
+}
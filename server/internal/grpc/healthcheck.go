@@ -0,0 +1,35 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// CheckHealth calls grpc.health.v1.Health/Check on the service's endpoint and fails unless
+// the server reports SERVING. Console-api endpoints only need to answer without a server error.
+func (p *ConnectionPool) CheckHealth(ctx context.Context, serviceName string) error {
+	if p.IsREST(serviceName) {
+		client, err := p.REST(serviceName)
+		if err != nil {
+			return err
+		}
+		return client.CheckHealth(ctx)
+	}
+
+	conn, _, release, err := p.acquire(serviceName)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		return err
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("health status is %s", resp.GetStatus())
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package grpc
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jhump/protoreflect/desc"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// HTTPBinding is an HTTP mapping of a method declared with the google.api.http option
+type HTTPBinding struct {
+	Method       string `json:"method"`                  // HTTP method, e.g. GET
+	Path         string `json:"path"`                    // Path template, e.g. /v1/projects/{project_id}
+	Body         string `json:"body,omitempty"`          // Field the request body maps to; "*" for the whole request
+	ResponseBody string `json:"response_body,omitempty"` // Field of the response returned as the body
+}
+
+// httpBindings returns the HTTP bindings of a method, including additional bindings
+func httpBindings(method *desc.MethodDescriptor) []HTTPBinding {
+	rule := httpRule(method.GetMethodOptions())
+	if rule == nil {
+		return nil
+	}
+
+	var bindings []HTTPBinding
+	for _, r := range append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...) {
+		binding := HTTPBinding{Body: r.GetBody(), ResponseBody: r.GetResponseBody()}
+		switch pattern := r.GetPattern().(type) {
+		case *annotations.HttpRule_Get:
+			binding.Method, binding.Path = http.MethodGet, pattern.Get
+		case *annotations.HttpRule_Put:
+			binding.Method, binding.Path = http.MethodPut, pattern.Put
+		case *annotations.HttpRule_Post:
+			binding.Method, binding.Path = http.MethodPost, pattern.Post
+		case *annotations.HttpRule_Delete:
+			binding.Method, binding.Path = http.MethodDelete, pattern.Delete
+		case *annotations.HttpRule_Patch:
+			binding.Method, binding.Path = http.MethodPatch, pattern.Patch
+		case *annotations.HttpRule_Custom:
+			binding.Method, binding.Path = strings.ToUpper(pattern.Custom.GetKind()), pattern.Custom.GetPath()
+		default:
+			continue
+		}
+		bindings = append(bindings, binding)
+	}
+	return bindings
+}
+
+// httpRule returns the google.api.http option of a method, or nil when it has none
+func httpRule(options *descriptorpb.MethodOptions) *annotations.HttpRule {
+	if options == nil {
+		return nil
+	}
+	// Options decoded before the extension was registered keep it as unknown fields
+	if !proto.HasExtension(options, annotations.E_Http) && len(options.ProtoReflect().GetUnknown()) > 0 {
+		data, err := proto.Marshal(options)
+		if err != nil {
+			return nil
+		}
+		options = &descriptorpb.MethodOptions{}
+		if err := proto.Unmarshal(data, options); err != nil {
+			return nil
+		}
+	}
+	rule, _ := proto.GetExtension(options, annotations.E_Http).(*annotations.HttpRule)
+	return rule
+}
+
+// Match matches a request against the binding, returning the path variables by field path
+// and the number of literal segments matched, which ranks overlapping templates
+func (b HTTPBinding) Match(method, path string) (map[string]string, int, bool) {
+	if !strings.EqualFold(b.Method, method) {
+		return nil, 0, false
+	}
+	template, templateVerb := splitVerb(b.Path)
+	path, pathVerb := splitVerb(path)
+	if templateVerb != pathVerb {
+		return nil, 0, false
+	}
+
+	segments, err := parseTemplate(template)
+	if err != nil {
+		return nil, 0, false
+	}
+	vars := make(map[string]string)
+	literals, ok := matchSegments(segments, strings.Split(strings.Trim(path, "/"), "/"), vars)
+	return vars, literals, ok
+}
+
+// Parameters builds the call parameters from the path variables, the request body and the
+// query string. Query parameters fill the fields not bound by the path or the body.
+func (b HTTPBinding) Parameters(vars map[string]string, body interface{}, query url.Values) map[string]interface{} {
+	parameters := make(map[string]interface{})
+	switch b.Body {
+	case "":
+	case "*":
+		if fields, ok := body.(map[string]interface{}); ok {
+			for key, value := range fields {
+				parameters[key] = value
+			}
+		}
+	default:
+		if body != nil {
+			setFieldPath(parameters, b.Body, body)
+		}
+	}
+
+	if b.Body != "*" {
+		for key, values := range query {
+			if _, bound := vars[key]; bound || key == b.Body || len(values) == 0 {
+				continue
+			}
+			if len(values) == 1 {
+				setFieldPath(parameters, key, values[0])
+			} else {
+				list := make([]interface{}, len(values))
+				for i, value := range values {
+					list[i] = value
+				}
+				setFieldPath(parameters, key, list)
+			}
+		}
+	}
+
+	for fieldPath, value := range vars {
+		setFieldPath(parameters, fieldPath, value)
+	}
+	return parameters
+}
+
+// setFieldPath sets a dotted field path such as project.project_id, creating nested objects
+func setFieldPath(parameters map[string]interface{}, fieldPath string, value interface{}) {
+	names := strings.Split(fieldPath, ".")
+	for _, name := range names[:len(names)-1] {
+		nested, ok := parameters[name].(map[string]interface{})
+		if !ok {
+			nested = make(map[string]interface{})
+			parameters[name] = nested
+		}
+		parameters = nested
+	}
+	parameters[names[len(names)-1]] = value
+}
+
+// templateSegment is a literal path segment, or a variable bound to a field path
+type templateSegment struct {
+	literal string
+	field   string   // Set for variables
+	pattern []string // Segments matched by a variable: literals, * or **
+}
+
+// parseTemplate parses a path template such as /v1/{name=projects/*}/items/{item_id}
+func parseTemplate(template string) ([]templateSegment, error) {
+	var segments []templateSegment
+	rest := strings.Trim(template, "/")
+	for rest != "" {
+		if !strings.HasPrefix(rest, "{") {
+			literal, remainder, _ := strings.Cut(rest, "/")
+			segments = append(segments, templateSegment{literal: literal})
+			rest = remainder
+			continue
+		}
+
+		end := strings.Index(rest, "}")
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed variable in path template '%s'", template)
+		}
+		field, pattern, hasPattern := strings.Cut(rest[1:end], "=")
+		segment := templateSegment{field: field, pattern: []string{"*"}}
+		if hasPattern {
+			segment.pattern = strings.Split(pattern, "/")
+		}
+		segments = append(segments, segment)
+		rest = strings.TrimPrefix(rest[end+1:], "/")
+	}
+	return segments, nil
+}
+
+// matchSegments matches path segments against a template, collecting variables and counting literals
+func matchSegments(segments []templateSegment, path []string, vars map[string]string) (int, bool) {
+	literals := 0
+	for i, segment := range segments {
+		if segment.field == "" {
+			if len(path) == 0 || path[0] != segment.literal {
+				return 0, false
+			}
+			path = path[1:]
+			literals++
+			continue
+		}
+
+		// A trailing ** in the last variable takes the rest of the path
+		count := len(segment.pattern)
+		if segment.pattern[count-1] == "**" {
+			if i != len(segments)-1 {
+				return 0, false
+			}
+			count = max(len(path), count-1)
+		}
+		if len(path) < count {
+			return 0, false
+		}
+		for j, part := range segment.pattern {
+			if part != "*" && part != "**" && part != path[j] {
+				return 0, false
+			}
+			if part != "*" && part != "**" {
+				literals++
+			}
+		}
+		value, err := url.PathUnescape(strings.Join(path[:count], "/"))
+		if err != nil || value == "" {
+			return 0, false
+		}
+		vars[segment.field] = value
+		path = path[count:]
+	}
+	return literals, len(path) == 0
+}
+
+// splitVerb splits a custom verb such as :cancel off the last segment of a path
+func splitVerb(path string) (string, string) {
+	lastSlash := strings.LastIndex(path, "/")
+	if colon := strings.LastIndex(path, ":"); colon > lastSlash {
+		return path[:colon], path[colon+1:]
+	}
+	return path, ""
+}
@@ -0,0 +1,113 @@
+package grpc
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck // dynamic messages use the v1 message API
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+)
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int
+}
+
+// Write implements io.Writer
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += n
+	return n, err
+}
+
+// writeMessageJSON writes a message as compact JSON without building the whole document in memory.
+// Repeated message fields, such as the results of a list call, are marshaled one element at a time;
+// all other fields are small and are marshaled together.
+func writeMessageJSON(w io.Writer, msg *dynamic.Message) error {
+	md := msg.GetMessageDescriptor()
+
+	var listFields []*desc.FieldDescriptor
+	rest := dynamic.NewMessage(md)
+	for _, fd := range md.GetFields() {
+		if !msg.HasField(fd) {
+			continue
+		}
+		if fd.IsRepeated() && !fd.IsMap() && fd.GetMessageType() != nil {
+			listFields = append(listFields, fd)
+			continue
+		}
+		if err := rest.TrySetField(fd, msg.GetField(fd)); err != nil {
+			return err
+		}
+	}
+
+	restJSON, err := rest.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	if len(listFields) == 0 {
+		_, err := w.Write(restJSON)
+		return err
+	}
+
+	// Write the small fields without the closing brace, then append the lists
+	if _, err := w.Write(restJSON[:len(restJSON)-1]); err != nil {
+		return err
+	}
+	first := len(restJSON) == 2
+	for _, fd := range listFields {
+		if err := writeListField(w, msg, fd, first); err != nil {
+			return err
+		}
+		first = false
+	}
+	_, err = w.Write([]byte("}"))
+	return err
+}
+
+// writeListField writes a repeated message field as a JSON key and array, one element at a time
+func writeListField(w io.Writer, msg *dynamic.Message, fd *desc.FieldDescriptor, first bool) error {
+	name, err := json.Marshal(jsonName(fd))
+	if err != nil {
+		return err
+	}
+	prefix := append(name, ':', '[')
+	if !first {
+		prefix = append([]byte(","), prefix...)
+	}
+	if _, err := w.Write(prefix); err != nil {
+		return err
+	}
+
+	for i := 0; i < msg.FieldLength(fd); i++ {
+		element, err := dynamic.AsDynamicMessage(msg.GetRepeatedField(fd, i).(proto.Message))
+		if err != nil {
+			return err
+		}
+		elementJSON, err := element.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			if _, err := w.Write([]byte(",")); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(elementJSON); err != nil {
+			return err
+		}
+	}
+
+	_, err = w.Write([]byte("]"))
+	return err
+}
+
+// jsonName returns the JSON key used for a field, matching dynamic.Message.MarshalJSON
+func jsonName(fd *desc.FieldDescriptor) string {
+	if name := fd.AsFieldDescriptorProto().GetJsonName(); name != "" {
+		return name
+	}
+	return fd.GetName()
+}
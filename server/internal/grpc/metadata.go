@@ -0,0 +1,65 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDMetadataKey is the gRPC metadata key used to forward the request ID upstream
+const RequestIDMetadataKey = "x-request-id"
+
+// WithRequestID returns a context that forwards the request ID as outgoing gRPC metadata
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	if requestID == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, RequestIDMetadataKey, requestID)
+}
+
+// WithMetadata returns a context that sends the pairs as outgoing gRPC metadata, or as HTTP
+// headers to console-api endpoints
+func WithMetadata(ctx context.Context, pairs map[string]string) context.Context {
+	kv := make([]string, 0, 2*len(pairs))
+	for key, value := range pairs {
+		if value != "" {
+			kv = append(kv, key, value)
+		}
+	}
+	if len(kv) == 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, kv...)
+}
+
+// requestIDFromContext returns the request ID attached to the outgoing context, if any
+func requestIDFromContext(ctx context.Context) string {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(RequestIDMetadataKey); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// tokenContextKey is the context key of a token that replaces the configured token
+type tokenContextKey struct{}
+
+// WithToken returns a context whose calls are authenticated with the token instead of the
+// configured one, e.g. a token scoped to a workspace for a single session
+func WithToken(ctx context.Context, token string) context.Context {
+	if token == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, tokenContextKey{}, token)
+}
+
+// callToken returns the token set on the context, or the configured token
+func callToken(ctx context.Context, configured func() string) string {
+	if token, ok := ctx.Value(tokenContextKey{}).(string); ok {
+		return token
+	}
+	return configured()
+}
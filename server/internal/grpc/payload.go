@@ -0,0 +1,127 @@
+package grpc
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"spacectl-web/server/internal/config"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck // grpcdynamic uses the v1 message API
+	"github.com/jhump/protoreflect/dynamic"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// PayloadLogger writes the request and response payloads of sampled calls as JSON lines, to
+// debug failures such as intermittent INVALID_ARGUMENT errors that access logs cannot explain
+type PayloadLogger struct {
+	sampleRate float64
+	rules      []config.PayloadLoggingRule
+	maxBytes   int
+	redactor   *Redactor // Also applied to requests, whose sensitive fields share names with responses
+	w          io.Writer
+	mutex      sync.Mutex
+}
+
+// payloadEntry is a logged call
+type payloadEntry struct {
+	Time       time.Time       `json:"time"`
+	RequestID  string          `json:"request_id,omitempty"`
+	Service    string          `json:"service"`
+	Resource   string          `json:"resource"`
+	Verb       string          `json:"verb"`
+	DurationMS int64           `json:"duration_ms"`
+	Code       string          `json:"code"` // gRPC code name, OK on success
+	Error      string          `json:"error,omitempty"`
+	Request    json.RawMessage `json:"request,omitempty"`
+	Response   json.RawMessage `json:"response,omitempty"`
+	Truncated  bool            `json:"truncated,omitempty"` // A payload exceeded max_bytes and is logged as a string prefix
+}
+
+// NewPayloadLogger creates a payload logger writing to w
+func NewPayloadLogger(cfg config.PayloadLoggingConfig, redaction config.RedactionConfig, w io.Writer) *PayloadLogger {
+	cfg = cfg.WithDefaults()
+	return &PayloadLogger{sampleRate: cfg.SampleRate, rules: cfg.Rules, maxBytes: cfg.MaxBytes, redactor: NewRedactor(redaction), w: w}
+}
+
+// Sample reports whether the call's payloads are logged: always for calls matching a rule,
+// otherwise with the sample rate
+func (p *PayloadLogger) Sample(serviceName, resourceName, verb string) bool {
+	if p == nil {
+		return false
+	}
+	for _, rule := range p.rules {
+		if matchFault(rule.Service, serviceName) && matchFault(rule.Resource, resourceName) && matchFault(rule.Verb, verb) {
+			return true
+		}
+	}
+	return p.sampleRate > 0 && rand.Float64() < p.sampleRate //nolint:gosec // not security sensitive
+}
+
+// Log writes a sampled call. request and response are JSON payloads, proto messages, or
+// parameter maps; response is ignored when the call failed.
+func (p *PayloadLogger) Log(requestID, serviceName, resourceName, verb string, request, response interface{},
+	duration time.Duration, code codes.Code, callErr error) {
+	entry := &payloadEntry{
+		Time:       time.Now(),
+		RequestID:  requestID,
+		Service:    serviceName,
+		Resource:   resourceName,
+		Verb:       verb,
+		DurationMS: duration.Milliseconds(),
+		Code:       code.String(),
+	}
+	entry.Request = p.payload(entry, serviceName, resourceName, verb, request)
+	if callErr != nil {
+		entry.Error = callErr.Error()
+	} else {
+		entry.Response = p.payload(entry, serviceName, resourceName, verb, response)
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		log.Printf("WARNING: failed to log payloads of %s.%s.%s: %v", serviceName, resourceName, verb, err)
+		return
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if _, err := p.w.Write(append(line, '\n')); err != nil {
+		log.Printf("WARNING: failed to log payloads of %s.%s.%s: %v", serviceName, resourceName, verb, err)
+	}
+}
+
+// payload returns a payload as redacted JSON, or as a JSON string of its first bytes when it
+// exceeds the size limit
+func (p *PayloadLogger) payload(entry *payloadEntry, serviceName, resourceName, verb string, payload interface{}) json.RawMessage {
+	var jsonBytes []byte
+	var err error
+	switch v := payload.(type) {
+	case nil:
+		return nil
+	case []byte:
+		jsonBytes = v
+	case *dynamic.Message:
+		jsonBytes, err = v.MarshalJSON()
+	case proto.Message:
+		jsonBytes, err = protojson.Marshal(proto.MessageV2(v))
+	default:
+		jsonBytes, err = json.Marshal(v)
+	}
+	if err == nil {
+		jsonBytes, err = p.redactor.Redact(serviceName, resourceName, verb, jsonBytes, false)
+	}
+	if err != nil {
+		message, _ := json.Marshal("unloggable payload: " + err.Error())
+		return message
+	}
+	if len(jsonBytes) > p.maxBytes {
+		entry.Truncated = true
+		prefix, _ := json.Marshal(string(jsonBytes[:p.maxBytes]))
+		return prefix
+	}
+	return jsonBytes
+}
@@ -0,0 +1,303 @@
+package grpc
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"spacectl-web/server/internal/config"
+
+	"google.golang.org/grpc"
+)
+
+// ConnectionPool holds one gRPC connection and reflection client per service,
+// or one REST client per console-api service, shared by service discovery and method calls
+type ConnectionPool struct {
+	config       *config.Config
+	conns        map[string]*pooledConn
+	restClients  map[string]*RESTClient
+	idleTimeout  time.Duration // Connections unused for this long are closed; zero disables eviction
+	dialTimeout  time.Duration // New connections must reach the host within this time; zero skips the check
+	stopEviction chan struct{}
+	mutex        sync.Mutex
+}
+
+// pooledConn is a pooled connection with its reference count
+type pooledConn struct {
+	conn      *grpc.ClientConn
+	refClient *ReflectionClient
+	refs      int // Calls and streams currently using the connection
+	lastUsed  time.Time
+}
+
+// PoolStats represents connection statistics for diagnostics
+type PoolStats struct {
+	OpenConnections   int `json:"open_connections"`
+	ActiveConnections int `json:"active_connections"`
+}
+
+// NewConnectionPool creates a connection pool and starts idle eviction when enabled
+func NewConnectionPool(cfg *config.Config) *ConnectionPool {
+	p := &ConnectionPool{
+		config:       cfg,
+		conns:        make(map[string]*pooledConn),
+		restClients:  make(map[string]*RESTClient),
+		idleTimeout:  cfg.Server.Connections.IdleTimeout(),
+		dialTimeout:  cfg.Server.Connections.DialTimeout(),
+		stopEviction: make(chan struct{}),
+	}
+	if p.idleTimeout > 0 {
+		go p.runEviction()
+	}
+	return p
+}
+
+// Get returns the gRPC connection and reflection client for a service, connecting on first use
+func (p *ConnectionPool) Get(serviceName string) (*grpc.ClientConn, *ReflectionClient, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	pc, err := p.get(serviceName)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pc.conn, pc.refClient, nil
+}
+
+// IsREST reports whether the service is served by a console-api endpoint instead of gRPC
+func (p *ConnectionPool) IsREST(serviceName string) bool {
+	endpoint, exists := p.config.GetEndpoint(serviceName)
+	return exists && endpoint.IsREST()
+}
+
+// REST returns the REST client for a console-api service, creating it on first use
+func (p *ConnectionPool) REST(serviceName string) (*RESTClient, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if client, exists := p.restClients[serviceName]; exists {
+		return client, nil
+	}
+	endpoint, exists := p.config.GetEndpoint(serviceName)
+	if !exists {
+		return nil, fmt.Errorf("endpoint not found for service '%s'", serviceName)
+	}
+	client, err := newRESTClient(endpoint, p.config.GetToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create REST client for service '%s': %w", serviceName, err)
+	}
+	p.restClients[serviceName] = client
+	return client, nil
+}
+
+// acquire returns the clients for a service and holds the connection until release is called
+func (p *ConnectionPool) acquire(serviceName string) (*grpc.ClientConn, *ReflectionClient, func(), error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	pc, err := p.get(serviceName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	pc.refs++
+	return pc.conn, pc.refClient, p.releaseFunc(pc), nil
+}
+
+// hold keeps an already open connection from being evicted until release is called
+func (p *ConnectionPool) hold(serviceName string) func() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	pc, exists := p.conns[serviceName]
+	if !exists {
+		return func() {}
+	}
+	pc.refs++
+	return p.releaseFunc(pc)
+}
+
+// releaseFunc returns a function that drops one reference to the connection
+func (p *ConnectionPool) releaseFunc(pc *pooledConn) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			p.mutex.Lock()
+			defer p.mutex.Unlock()
+			pc.refs--
+			pc.lastUsed = time.Now()
+		})
+	}
+}
+
+// get returns the pooled connection for a service, dialing it if needed.
+// The caller must hold the mutex; it is released while checking connectivity.
+func (p *ConnectionPool) get(serviceName string) (*pooledConn, error) {
+	// Return existing client if already connected
+	if pc, exists := p.conns[serviceName]; exists {
+		pc.lastUsed = time.Now()
+		return pc, nil
+	}
+
+	// Get endpoint settings for the service
+	endpoint, exists := p.config.GetEndpoint(serviceName)
+	if !exists {
+		return nil, fmt.Errorf("endpoint not found for service '%s'", serviceName)
+	}
+	if endpoint.IsREST() {
+		return nil, fmt.Errorf("service '%s' uses a REST endpoint and has no gRPC connection", serviceName)
+	}
+
+	// Fail fast when the host is unreachable, without blocking other services
+	if p.dialTimeout > 0 {
+		p.mutex.Unlock()
+		err := ProbeEndpoint(serviceName, endpoint, p.dialTimeout)
+		p.mutex.Lock()
+		if err != nil {
+			return nil, err
+		}
+		if pc, exists := p.conns[serviceName]; exists {
+			pc.lastUsed = time.Now()
+			return pc, nil
+		}
+	}
+
+	// Create gRPC connection
+	conn, err := dialEndpoint(endpoint, p.config.GetToken, p.config.Server.Limits.WithDefaults().MaxResponseBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to service '%s': %w", serviceName, err)
+	}
+
+	// The reflection stream is opened on first use
+	pc := &pooledConn{
+		conn:      conn,
+		refClient: NewReflectionClient(serviceName, conn),
+		lastUsed:  time.Now(),
+	}
+	p.conns[serviceName] = pc
+	return pc, nil
+}
+
+// runEviction periodically closes connections that have been idle longer than the idle timeout
+func (p *ConnectionPool) runEviction() {
+	ticker := time.NewTicker(min(p.idleTimeout/2, time.Minute))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.evictIdle(time.Now())
+		case <-p.stopEviction:
+			return
+		}
+	}
+}
+
+// evictIdle closes and removes unreferenced connections last used before the idle timeout.
+// Evicted services reconnect on their next call.
+func (p *ConnectionPool) evictIdle(now time.Time) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for serviceName, pc := range p.conns {
+		if pc.refs > 0 || now.Sub(pc.lastUsed) < p.idleTimeout {
+			continue
+		}
+		pc.close()
+		delete(p.conns, serviceName)
+		log.Printf("Closed idle connection to %s (unused for %s)", serviceName, now.Sub(pc.lastUsed).Round(time.Second))
+	}
+}
+
+// Stats returns connection statistics for diagnostics
+func (p *ConnectionPool) Stats() PoolStats {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	stats := PoolStats{OpenConnections: len(p.conns)}
+	for _, pc := range p.conns {
+		if pc.refs > 0 {
+			stats.ActiveConnections++
+		}
+	}
+	return stats
+}
+
+// ConnectionInfo represents a pooled connection for runtime inspection
+type ConnectionInfo struct {
+	Service  string    `json:"service"`
+	Target   string    `json:"target"`
+	State    string    `json:"state"` // gRPC connectivity state, e.g. READY
+	Refs     int       `json:"refs"`  // Calls and streams currently using the connection
+	LastUsed time.Time `json:"last_used"`
+}
+
+// Connections returns the open connections, ordered by service
+func (p *ConnectionPool) Connections() []ConnectionInfo {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	infos := make([]ConnectionInfo, 0, len(p.conns))
+	for serviceName, pc := range p.conns {
+		infos = append(infos, ConnectionInfo{
+			Service:  serviceName,
+			Target:   pc.conn.Target(),
+			State:    pc.conn.GetState().String(),
+			Refs:     pc.refs,
+			LastUsed: pc.lastUsed,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Service < infos[j].Service })
+	return infos
+}
+
+// Drop closes a service's connection, failing calls still using it.
+// The service reconnects on its next call. It reports whether a connection was open.
+func (p *ConnectionPool) Drop(serviceName string) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	pc, exists := p.conns[serviceName]
+	if !exists {
+		return false
+	}
+	pc.close()
+	delete(p.conns, serviceName)
+	log.Printf("Dropped connection to %s (%d active reference(s))", serviceName, pc.refs)
+	return true
+}
+
+// Reset closes all connections so the next use reconnects with the current config
+func (p *ConnectionPool) Reset() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, pc := range p.conns {
+		pc.close()
+	}
+	p.conns = make(map[string]*pooledConn)
+	for _, client := range p.restClients {
+		client.close()
+	}
+	p.restClients = make(map[string]*RESTClient)
+}
+
+// Close stops idle eviction and closes all connections
+func (p *ConnectionPool) Close() {
+	p.mutex.Lock()
+	select {
+	case <-p.stopEviction:
+	default:
+		close(p.stopEviction)
+	}
+	p.mutex.Unlock()
+
+	p.Reset()
+}
+
+// close closes the reflection stream and the connection
+func (pc *pooledConn) close() {
+	pc.refClient.Reset()
+	pc.conn.Close()
+}
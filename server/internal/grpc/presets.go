@@ -0,0 +1,42 @@
+package grpc
+
+// withPresets returns the parameters deep-merged over the presets matching the call.
+// The caller's map and the presets are not modified.
+func (sc *ServiceCaller) withPresets(serviceName, resourceName, verb string, parameters map[string]interface{}) map[string]interface{} {
+	var merged map[string]interface{}
+	for _, preset := range sc.presets {
+		if !matchFault(preset.Service, serviceName) || !matchFault(preset.Resource, resourceName) || !matchFault(preset.Verb, verb) {
+			continue
+		}
+		merged = deepMerge(merged, preset.Parameters)
+	}
+	if merged == nil {
+		return parameters
+	}
+	return deepMerge(merged, parameters)
+}
+
+// deepMerge returns a copy of base with overlay merged over it. Nested objects are merged
+// field by field; any other overlay value replaces the base value.
+func deepMerge(base, overlay map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(overlay))
+	for key, value := range base {
+		if object, ok := value.(map[string]interface{}); ok {
+			value = deepMerge(nil, object)
+		}
+		merged[key] = value
+	}
+	for key, value := range overlay {
+		overlayObject, overlayIsObject := value.(map[string]interface{})
+		baseObject, baseIsObject := merged[key].(map[string]interface{})
+		switch {
+		case overlayIsObject && baseIsObject:
+			merged[key] = deepMerge(baseObject, overlayObject)
+		case overlayIsObject:
+			merged[key] = deepMerge(nil, overlayObject)
+		default:
+			merged[key] = value
+		}
+	}
+	return merged
+}
@@ -0,0 +1,180 @@
+package grpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"spacectl-web/server/internal/config"
+	"spacectl-web/server/internal/errors"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck // grpcdynamic uses the v1 message API
+)
+
+// Redactor replaces sensitive response fields according to the configured rules
+type Redactor struct {
+	rules       []config.RedactionRule
+	replacement string
+}
+
+// credentialPatterns match request fields carrying credentials, such as the app token
+// Token.grant exchanges. They are replaced in logged requests whatever the rules.
+var credentialPatterns = [][]string{{"*", "*token"}, {"*", "*password*"}, {"*", "*secret*"}}
+
+// NewRedactor creates a redactor from the configuration, or returns nil when there are no rules
+func NewRedactor(cfg config.RedactionConfig) *Redactor {
+	if len(cfg.Rules) == 0 {
+		return nil
+	}
+	cfg = cfg.WithDefaults()
+	return &Redactor{rules: cfg.Rules, replacement: cfg.Replacement}
+}
+
+// patterns returns the field patterns of the rules matching the call, split into segments
+func (r *Redactor) patterns(serviceName, resourceName, verb string) [][]string {
+	if r == nil {
+		return nil
+	}
+	var patterns [][]string
+	for _, rule := range r.rules {
+		if !matchFault(rule.Service, serviceName) || !matchFault(rule.Resource, resourceName) || !matchFault(rule.Verb, verb) {
+			continue
+		}
+		for _, field := range rule.Fields {
+			patterns = append(patterns, strings.Split(normalizeFieldName(field), "."))
+		}
+	}
+	return patterns
+}
+
+// Applies reports whether any rule matches the call
+func (r *Redactor) Applies(serviceName, resourceName, verb string) bool {
+	return len(r.patterns(serviceName, resourceName, verb)) > 0
+}
+
+// Redact returns the JSON response with the fields matched by the call's rules replaced
+func (r *Redactor) Redact(serviceName, resourceName, verb string, jsonBytes []byte, indent bool) ([]byte, error) {
+	patterns := r.patterns(serviceName, resourceName, verb)
+	if len(patterns) == 0 {
+		return jsonBytes, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(jsonBytes))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, errors.NewAPIError(errors.ErrJSONConversionFailed, err.Error())
+	}
+	if !r.redactValue(value, nil, patterns) {
+		return jsonBytes, nil
+	}
+
+	// Keep the replacement readable instead of escaping its angle brackets
+	var redacted bytes.Buffer
+	encoder := json.NewEncoder(&redacted)
+	encoder.SetEscapeHTML(false)
+	if indent {
+		encoder.SetIndent("", "  ")
+	}
+	if err := encoder.Encode(value); err != nil {
+		return nil, errors.NewAPIError(errors.ErrJSONConversionFailed, err.Error())
+	}
+	return bytes.TrimSuffix(redacted.Bytes(), []byte("\n")), nil
+}
+
+// RedactRequest returns a request message as JSON for the log, with credential fields and the
+// fields matched by the call's rules replaced. It works on a nil redactor.
+func (r *Redactor) RedactRequest(serviceName, resourceName, verb string, request proto.Message) string {
+	jsonBytes, err := marshalMessage(request, false)
+	if err != nil {
+		return fmt.Sprintf("(not logged: %v)", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(jsonBytes))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Sprintf("(not logged: %v)", err)
+	}
+
+	redactor := &Redactor{replacement: config.DefaultRedactionReplacement}
+	if r != nil {
+		redactor.replacement = r.replacement
+	}
+	patterns := append(slices.Clone(credentialPatterns), r.patterns(serviceName, resourceName, verb)...)
+	redactor.redactValue(value, nil, patterns)
+
+	var redacted bytes.Buffer
+	encoder := json.NewEncoder(&redacted)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Sprintf("(not logged: %v)", err)
+	}
+	return strings.TrimSuffix(redacted.String(), "\n")
+}
+
+// redactValue replaces the fields under value whose path matches a pattern and reports
+// whether any was replaced. Array elements share the path of their array.
+func (r *Redactor) redactValue(value interface{}, fieldPath []string, patterns [][]string) bool {
+	changed := false
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			itemPath := append(fieldPath[:len(fieldPath):len(fieldPath)], normalizeFieldName(key))
+			if matchesAny(itemPath, patterns) {
+				v[key] = r.replacement
+				changed = true
+				continue
+			}
+			if r.redactValue(item, itemPath, patterns) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if r.redactValue(item, fieldPath, patterns) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// matchesAny reports whether the field path matches one of the patterns
+func matchesAny(fieldPath []string, patterns [][]string) bool {
+	for _, pattern := range patterns {
+		if matchFieldPath(pattern, fieldPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchFieldPath matches a field path against a pattern whose * segments match any number of fields
+func matchFieldPath(pattern, fieldPath []string) bool {
+	if len(pattern) == 0 {
+		return len(fieldPath) == 0
+	}
+	if pattern[0] == "*" {
+		for i := 0; i <= len(fieldPath); i++ {
+			if matchFieldPath(pattern[1:], fieldPath[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(fieldPath) == 0 {
+		return false
+	}
+	if matched, _ := path.Match(pattern[0], fieldPath[0]); !matched {
+		return false
+	}
+	return matchFieldPath(pattern[1:], fieldPath[1:])
+}
+
+// normalizeFieldName lets proto names such as secret_data match JSON names such as secretData
+func normalizeFieldName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
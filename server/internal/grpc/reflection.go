@@ -0,0 +1,84 @@
+package grpc
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/grpcreflect"
+	"google.golang.org/grpc"
+)
+
+// ReflectionClient is a reflection client for a service that is created on first use
+// and recovers from broken reflection streams by resetting and retrying once
+type ReflectionClient struct {
+	serviceName string
+	conn        *grpc.ClientConn
+	client      *grpcreflect.Client
+	mutex       sync.Mutex
+}
+
+// NewReflectionClient creates a reflection client for the connection
+func NewReflectionClient(serviceName string, conn *grpc.ClientConn) *ReflectionClient {
+	return &ReflectionClient{serviceName: serviceName, conn: conn}
+}
+
+// ListServices lists the services exposed by the server
+func (r *ReflectionClient) ListServices() ([]string, error) {
+	var services []string
+	err := r.do("ListServices", func(client *grpcreflect.Client) error {
+		var err error
+		services, err = client.ListServices()
+		return err
+	})
+	return services, err
+}
+
+// ResolveService returns the descriptor for a fully-qualified service name
+func (r *ReflectionClient) ResolveService(serviceName string) (*desc.ServiceDescriptor, error) {
+	var serviceDesc *desc.ServiceDescriptor
+	err := r.do("ResolveService", func(client *grpcreflect.Client) error {
+		var err error
+		serviceDesc, err = client.ResolveService(serviceName)
+		return err
+	})
+	return serviceDesc, err
+}
+
+// Reset closes the reflection stream; it is reopened on the next request
+func (r *ReflectionClient) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.client != nil {
+		r.client.Reset()
+	}
+}
+
+// do runs a reflection request, resetting the stream and retrying once if it failed
+// for any reason other than a missing element
+func (r *ReflectionClient) do(operation string, request func(*grpcreflect.Client) error) error {
+	client := r.get()
+	err := request(client)
+	if err == nil || grpcreflect.IsElementNotFoundError(err) {
+		return err
+	}
+
+	log.Printf("WARNING: reflection %s for %s failed, resetting the reflection stream: %v", operation, r.serviceName, err)
+	client.Reset()
+	if err := request(client); err != nil {
+		return err
+	}
+	log.Printf("Reflection recovered for %s", r.serviceName)
+	return nil
+}
+
+// get returns the underlying client, creating it on first use
+func (r *ReflectionClient) get() *grpcreflect.Client {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.client == nil {
+		r.client = grpcreflect.NewClientAuto(context.Background(), r.conn)
+	}
+	return r.client
+}
@@ -0,0 +1,375 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode"
+
+	"spacectl-web/server/internal/config"
+	"spacectl-web/server/internal/errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// restOpenAPIPath is where console-api serves the OpenAPI document describing its routes
+const restOpenAPIPath = "/openapi.json"
+
+// restErrorBodyLimit caps how much of an error response is reported
+const restErrorBodyLimit = 1024
+
+// httpStatusCodes maps console-api HTTP statuses to the gRPC codes they stand for,
+// so REST failures get the same error codes and hints as gRPC ones
+var httpStatusCodes = map[int]codes.Code{
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusUnauthorized:        codes.Unauthenticated,
+	http.StatusForbidden:           codes.PermissionDenied,
+	http.StatusNotFound:            codes.NotFound,
+	http.StatusConflict:            codes.AlreadyExists,
+	http.StatusUnprocessableEntity: codes.InvalidArgument,
+	http.StatusTooManyRequests:     codes.ResourceExhausted,
+	http.StatusNotImplemented:      codes.Unimplemented,
+	http.StatusBadGateway:          codes.Unavailable,
+	http.StatusServiceUnavailable:  codes.Unavailable,
+	http.StatusGatewayTimeout:      codes.DeadlineExceeded,
+}
+
+// RESTClient calls a console-api endpoint, which serves each verb as
+// POST <base>/<service>/<resource>/<verb> with the parameters as the JSON body
+type RESTClient struct {
+	baseURL  string
+	metadata map[string]string // Sent as headers with every request
+	auth     config.AuthConfig
+	token    func() string
+	client   *http.Client
+}
+
+// newRESTClient creates a client for a console-api endpoint using its TLS options
+func newRESTClient(endpoint *config.EndpointConfig, token func() string) (*RESTClient, error) {
+	parsed, err := config.ParseEndpointURL(endpoint.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := endpoint.ResolveMetadata()
+	if err != nil {
+		return nil, err
+	}
+	auth, err := endpoint.ResolveAuth()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !endpoint.IsPlaintext() {
+		tlsConfig, err := endpointTLSConfig(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return &RESTClient{
+		baseURL:  parsed.Scheme + "://" + parsed.Address() + strings.TrimSuffix(parsed.Path, "/"),
+		metadata: metadata,
+		auth:     auth,
+		token:    token,
+		client:   &http.Client{Transport: transport},
+	}, nil
+}
+
+// Call posts the parameters to the verb's route and returns the JSON response body with
+// the gRPC code the HTTP status stands for. Responses larger than maxResponseBytes are
+// rejected; zero disables the check.
+func (c *RESTClient) Call(ctx context.Context, serviceName, resourceName, verb string, parameters map[string]interface{}, maxResponseBytes int64) ([]byte, codes.Code, error) {
+	if parameters == nil {
+		parameters = map[string]interface{}{}
+	}
+	body, err := json.Marshal(parameters)
+	if err != nil {
+		return nil, codes.InvalidArgument, errors.NewAPIError(errors.ErrInvalidParameters, err.Error())
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, restPath(serviceName, resourceName, verb), body)
+	if err != nil {
+		code := codes.Unavailable
+		if stderrors.Is(err, context.DeadlineExceeded) {
+			code = codes.DeadlineExceeded
+		}
+		return nil, code, errors.NewAPIError(errors.ErrRPCCallFailed, fmt.Sprintf("console-api call failed: %v", err)).
+			WithGRPCStatus(status.Error(code, err.Error()))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, httpStatusCode(resp.StatusCode), restError(resp)
+	}
+
+	reader := io.Reader(resp.Body)
+	if maxResponseBytes > 0 {
+		reader = io.LimitReader(resp.Body, maxResponseBytes+1)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, codes.Unavailable, errors.NewAPIError(errors.ErrRPCCallFailed, fmt.Sprintf("failed to read console-api response: %v", err))
+	}
+	if maxResponseBytes > 0 && int64(len(data)) > maxResponseBytes {
+		return nil, codes.ResourceExhausted, errors.NewAPIError(errors.ErrResponseTooLarge,
+			fmt.Sprintf("response exceeds the limit of %d bytes", maxResponseBytes))
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		data = []byte("{}")
+	}
+	if !json.Valid(data) {
+		return nil, codes.Internal, errors.NewAPIError(errors.ErrResponseConversionFailed, "console-api response is not JSON")
+	}
+	return data, codes.OK, nil
+}
+
+// CheckHealth fails unless the endpoint answers HTTP requests without a server error
+func (c *RESTClient) CheckHealth(ctx context.Context) error {
+	resp, err := c.do(ctx, http.MethodGet, "/", nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("console-api returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Discover lists the service's resources and verbs from the console-api OpenAPI document
+func (c *RESTClient) Discover(ctx context.Context, serviceName string) (map[string]*ResourceInfo, error) {
+	resp, err := c.do(ctx, http.MethodGet, restOpenAPIPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the OpenAPI document: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch the OpenAPI document: HTTP %d", resp.StatusCode)
+	}
+
+	var document openAPIDocument
+	if err := json.NewDecoder(resp.Body).Decode(&document); err != nil {
+		return nil, fmt.Errorf("failed to parse the OpenAPI document: %w", err)
+	}
+	return document.resources(serviceName), nil
+}
+
+// do sends a request with the endpoint's credentials, its metadata and the metadata of the context as headers
+func (c *RESTClient) do(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range c.metadata {
+		req.Header.Set(key, value)
+	}
+	if md, ok := metadata.FromOutgoingContext(ctx); ok {
+		for key, values := range md {
+			if len(values) > 0 {
+				req.Header.Set(key, values[0])
+			}
+		}
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	for key, value := range c.auth.Credentials(callToken(ctx, c.token)) {
+		req.Header.Set(key, value)
+	}
+	return c.client.Do(req)
+}
+
+// close releases idle keep-alive connections
+func (c *RESTClient) close() {
+	c.client.CloseIdleConnections()
+}
+
+// restError converts a failed console-api response into an APIError with the matching gRPC code
+func restError(resp *http.Response) *errors.APIError {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, restErrorBodyLimit))
+	message := restErrorMessage(data)
+	if message == "" {
+		message = http.StatusText(resp.StatusCode)
+	}
+	code := httpStatusCode(resp.StatusCode)
+	return errors.NewAPIError(errors.ErrRPCCallFailed, fmt.Sprintf("console-api call failed with HTTP %d: %s", resp.StatusCode, message)).
+		WithGRPCStatus(status.Error(code, message))
+}
+
+// restErrorMessage extracts the message of a console-api error body, which is either
+// {"detail": {"message": ...}}, {"detail": ...}, {"message": ...} or plain text
+func restErrorMessage(data []byte) string {
+	var body struct {
+		Detail  json.RawMessage `json:"detail"`
+		Message string          `json:"message"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return strings.TrimSpace(string(data))
+	}
+	var detail struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body.Detail, &detail) == nil && detail.Message != "" {
+		return detail.Message
+	}
+	var text string
+	if json.Unmarshal(body.Detail, &text) == nil && text != "" {
+		return text
+	}
+	if body.Message != "" {
+		return body.Message
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// httpStatusCode returns the gRPC code an HTTP status stands for
+func httpStatusCode(statusCode int) codes.Code {
+	if code, exists := httpStatusCodes[statusCode]; exists {
+		return code
+	}
+	if statusCode >= http.StatusInternalServerError {
+		return codes.Internal
+	}
+	return codes.Unknown
+}
+
+// restPath returns the console-api route of a verb, e.g. /inventory/cloud-service-type/list
+func restPath(serviceName, resourceName, verb string) string {
+	return "/" + kebabCase(serviceName) + "/" + kebabCase(resourceName) + "/" + kebabCase(verb)
+}
+
+// kebabCase converts CamelCase and snake_case names to kebab-case
+func kebabCase(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case r == '_':
+			b.WriteRune('-')
+		case unicode.IsUpper(r):
+			if i > 0 && runes[i-1] != '_' && (unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				b.WriteRune('-')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// camelCase converts a kebab-case route segment to a CamelCase resource name
+func camelCase(segment string) string {
+	var b strings.Builder
+	for _, word := range strings.Split(segment, "-") {
+		if word == "" {
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	return b.String()
+}
+
+// openAPIDocument is the part of an OpenAPI document needed to list routes and their parameters
+type openAPIDocument struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]openAPISchema `json:"schemas"`
+	} `json:"components"`
+}
+
+// openAPIOperation is an operation of a path
+type openAPIOperation struct {
+	RequestBody struct {
+		Content map[string]struct {
+			Schema openAPISchema `json:"schema"`
+		} `json:"content"`
+	} `json:"requestBody"`
+}
+
+// openAPISchema is an object schema, or a reference to one
+type openAPISchema struct {
+	Ref        string                     `json:"$ref"`
+	Required   []string                   `json:"required"`
+	Properties map[string]json.RawMessage `json:"properties"`
+}
+
+// resources returns the resources of the service from the POST routes /<service>/<resource>/<verb>
+func (d *openAPIDocument) resources(serviceName string) map[string]*ResourceInfo {
+	prefix := "/" + kebabCase(serviceName) + "/"
+	resources := make(map[string]*ResourceInfo)
+	for path, operations := range d.Paths {
+		rawOperation, exists := operations["post"]
+		if !exists || !strings.HasPrefix(path, prefix) {
+			continue
+		}
+		segments := strings.Split(strings.TrimPrefix(path, prefix), "/")
+		if len(segments) != 2 || segments[0] == "" || segments[1] == "" {
+			continue
+		}
+
+		resourceName := camelCase(segments[0])
+		verb := strings.ReplaceAll(segments[1], "-", "_")
+		resource, exists := resources[resourceName]
+		if !exists {
+			resource = &ResourceInfo{
+				Name:        resourceName,
+				ServiceName: prefix + segments[0],
+				Methods:     make(map[string]*MethodInfo),
+			}
+			resources[resourceName] = resource
+		}
+
+		var operation openAPIOperation
+		_ = json.Unmarshal(rawOperation, &operation)
+		resource.Verbs = append(resource.Verbs, verb)
+		resource.Methods[verb] = d.methodInfo(verb, operation)
+	}
+
+	for _, resource := range resources {
+		sort.Strings(resource.Verbs)
+	}
+	return resources
+}
+
+// methodInfo describes a verb from the JSON schema of its request body
+func (d *openAPIDocument) methodInfo(verb string, operation openAPIOperation) *MethodInfo {
+	info := &MethodInfo{Name: verb, RequiredParams: []string{}, OptionalParams: []string{}}
+	content, exists := operation.RequestBody.Content["application/json"]
+	if !exists {
+		return info
+	}
+
+	schema := content.Schema
+	if schema.Ref != "" {
+		info.InputType = schema.Ref[strings.LastIndex(schema.Ref, "/")+1:]
+		schema = d.Components.Schemas[info.InputType]
+	}
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+		info.RequiredParams = append(info.RequiredParams, name)
+	}
+	for name := range schema.Properties {
+		if !required[name] {
+			info.OptionalParams = append(info.OptionalParams, name)
+		}
+	}
+	sort.Strings(info.RequiredParams)
+	sort.Strings(info.OptionalParams)
+	return info
+}
@@ -0,0 +1,190 @@
+package grpc
+
+import (
+	"strings"
+
+	"spacectl-web/server/internal/errors"
+
+	"github.com/jhump/protoreflect/desc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// resultsField is the repeated field list responses return their items in
+const resultsField = "results"
+
+// minSuggestedColumns is the number of columns suggested even when few fields match the heuristics
+const minSuggestedColumns = 3
+
+// columnHeuristics are the fields suggested as columns after the resource's ID, in column
+// order. The first field of each group found is suggested.
+var columnHeuristics = [][]string{
+	{"name", "display_name", "title"},
+	{"state", "status"},
+	{"provider", "resource_type", "type"},
+	{"created_at"},
+}
+
+// scalarMessages are the well-known messages whose JSON form is a single value
+var scalarMessages = map[string]bool{
+	"google.protobuf.Timestamp":   true,
+	"google.protobuf.Duration":    true,
+	"google.protobuf.DoubleValue": true,
+	"google.protobuf.FloatValue":  true,
+	"google.protobuf.Int32Value":  true,
+	"google.protobuf.UInt32Value": true,
+	"google.protobuf.Int64Value":  true,
+	"google.protobuf.UInt64Value": true,
+	"google.protobuf.BoolValue":   true,
+	"google.protobuf.StringValue": true,
+}
+
+// ResponseSchema describes the response message of a verb
+type ResponseSchema struct {
+	Message          string        `json:"message,omitempty"`       // Fully qualified response message; empty for console-api verbs
+	Fields           []SchemaField `json:"fields"`                  // Fields of the response message
+	ResultsField     string        `json:"results_field,omitempty"` // Repeated field holding the items of a list response
+	ItemFields       []SchemaField `json:"item_fields,omitempty"`   // Fields of each item of a list response
+	SuggestedColumns []string      `json:"suggested_columns"`       // JSON names of item fields for a default table
+}
+
+// SchemaField is a field of a response message
+type SchemaField struct {
+	Name     string `json:"name"`      // Proto field name
+	JSONName string `json:"json_name"` // Name in the response JSON
+	Type     string `json:"type"`      // Proto type or message name, e.g. repeated spaceone.api.core.v2.Tag
+}
+
+// DescribeResponse returns the response schema of a verb with suggested table columns for list
+// verbs: the resource ID, then name, state, type and creation time style fields. console-api
+// verbs and offline mode have no descriptors, so their schema is empty.
+func (sc *ServiceCaller) DescribeResponse(serviceName, resourceName, verb string) (*ResponseSchema, error) {
+	if sc.offline || sc.rest != nil {
+		return &ResponseSchema{Fields: []SchemaField{}, SuggestedColumns: []string{}}, nil
+	}
+	methodDesc, err := sc.resolveMethod(serviceName, resourceName, verb)
+	if err != nil {
+		return nil, err
+	}
+	if methodDesc.IsClientStreaming() || methodDesc.IsServerStreaming() {
+		return nil, errors.NewAPIError(errors.ErrVerbNotSupported, "streaming verbs have no table schema")
+	}
+
+	outputType := methodDesc.GetOutputType()
+	schema := &ResponseSchema{
+		Message:          outputType.GetFullyQualifiedName(),
+		Fields:           schemaFields(outputType),
+		SuggestedColumns: []string{},
+	}
+	items := resultsMessage(outputType)
+	if items == nil {
+		return schema, nil
+	}
+	schema.ResultsField = resultsField
+	schema.ItemFields = schemaFields(items)
+	schema.SuggestedColumns = suggestColumns(items, resourceName)
+	return schema, nil
+}
+
+// resultsMessage returns the item message of a list response's results field, or nil
+func resultsMessage(msgDesc *desc.MessageDescriptor) *desc.MessageDescriptor {
+	field := msgDesc.FindFieldByName(resultsField)
+	if field == nil || !field.IsRepeated() || field.IsMap() || field.GetMessageType() == nil {
+		return nil
+	}
+	return field.GetMessageType()
+}
+
+// schemaFields describes the fields of a message
+func schemaFields(msgDesc *desc.MessageDescriptor) []SchemaField {
+	fields := make([]SchemaField, 0, len(msgDesc.GetFields()))
+	for _, field := range msgDesc.GetFields() {
+		fields = append(fields, SchemaField{
+			Name:     field.GetName(),
+			JSONName: field.GetJSONName(),
+			Type:     fieldTypeName(field),
+		})
+	}
+	return fields
+}
+
+// suggestColumns picks the item fields a table shows by default. Only fields with a single
+// displayable value are suggested; when few match the heuristics, the first such fields fill up
+// to minSuggestedColumns.
+func suggestColumns(items *desc.MessageDescriptor, resourceName string) []string {
+	var candidates []*desc.FieldDescriptor
+	for _, field := range items.GetFields() {
+		if columnField(field) {
+			candidates = append(candidates, field)
+		}
+	}
+
+	var columns []*desc.FieldDescriptor
+	suggested := make(map[string]bool)
+	suggest := func(field *desc.FieldDescriptor) {
+		if field != nil && !suggested[field.GetName()] {
+			suggested[field.GetName()] = true
+			columns = append(columns, field)
+		}
+	}
+
+	suggest(idField(candidates, resourceName))
+	for _, names := range columnHeuristics {
+		for _, name := range names {
+			if field := findField(candidates, name); field != nil {
+				suggest(field)
+				break
+			}
+		}
+	}
+	for _, field := range candidates {
+		if len(columns) >= minSuggestedColumns {
+			break
+		}
+		suggest(field)
+	}
+
+	names := make([]string, 0, len(columns))
+	for _, field := range columns {
+		names = append(names, field.GetJSONName())
+	}
+	return names
+}
+
+// columnField reports whether a field holds a single value a table cell can show
+func columnField(field *desc.FieldDescriptor) bool {
+	if field.IsRepeated() || field.IsMap() {
+		return false
+	}
+	if msgType := field.GetMessageType(); msgType != nil {
+		return scalarMessages[msgType.GetFullyQualifiedName()]
+	}
+	return field.GetType() != descriptorpb.FieldDescriptorProto_TYPE_BYTES
+}
+
+// idField returns the resource's ID field, e.g. project_id for Project, or the first ID field
+func idField(fields []*desc.FieldDescriptor, resourceName string) *desc.FieldDescriptor {
+	if field := findField(fields, snakeCase(resourceName)+"_id"); field != nil {
+		return field
+	}
+	for _, field := range fields {
+		if strings.HasSuffix(field.GetName(), "_id") || field.GetName() == "id" {
+			return field
+		}
+	}
+	return nil
+}
+
+// findField returns the field with the given proto name, or nil
+func findField(fields []*desc.FieldDescriptor, name string) *desc.FieldDescriptor {
+	for _, field := range fields {
+		if field.GetName() == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// snakeCase converts CamelCase names to snake_case, e.g. CloudServiceType to cloud_service_type
+func snakeCase(name string) string {
+	return strings.ReplaceAll(kebabCase(name), "-", "_")
+}
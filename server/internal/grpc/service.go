@@ -1,53 +1,286 @@
 package grpc
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
 	"time"
 
+	"spacectl-web/server/internal/config"
 	"spacectl-web/server/internal/constants"
 	"spacectl-web/server/internal/errors"
+	"spacectl-web/server/internal/metrics"
+	"spacectl-web/server/internal/recording"
 
+	"github.com/golang/protobuf/proto" //nolint:staticcheck // grpcdynamic uses the v1 message API
 	"github.com/jhump/protoreflect/desc"
 	"github.com/jhump/protoreflect/dynamic"
 	"github.com/jhump/protoreflect/dynamic/grpcdynamic"
-	"github.com/jhump/protoreflect/grpcreflect"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/descriptorpb"
 )
 
 // ServiceCaller handles gRPC service method calls
 type ServiceCaller struct {
 	conn             *grpc.ClientConn
-	refClient        *grpcreflect.Client
+	refClient        *ReflectionClient
 	serviceDiscovery *ServiceDiscovery
+	timeout          time.Duration
+	faultInjector    *FaultInjector
+	recorder         *recording.Store
+	offline          bool
+	maxResponseBytes int64 // Responses larger than this are rejected; zero disables the check
+	streamThreshold  int64 // Responses larger than this are streamed by CallMethodTo; zero disables streaming
+	indent           bool  // Marshal buffered responses as indented JSON
+	pool             *ConnectionPool
+	poolKey          string               // Service name the connection is pooled under
+	rest             *RESTClient          // Calls the console-api instead of gRPC when set
+	claimFields      map[string]string    // Claim by request field filled from the token when left out
+	token            func() string        // Configured token, for claim defaults
+	presets          config.PresetsConfig // Default parameters merged under the caller's
+	redactor         *Redactor            // Replaces sensitive response fields; nil without rules
+	payloadLogger    *PayloadLogger       // Logs payloads of sampled calls; nil when payload logging is disabled
+	slowCalls        *SlowCallLog         // Logs calls slower than a threshold; nil when the slow call log is disabled
+	cache            *ResponseCache       // Responses of cached methods; nil when no method is cached
 }
 
 // NewServiceCaller creates a new ServiceCaller
-func NewServiceCaller(conn *grpc.ClientConn, refClient *grpcreflect.Client, serviceDiscovery *ServiceDiscovery) *ServiceCaller {
+func NewServiceCaller(conn *grpc.ClientConn, refClient *ReflectionClient, serviceDiscovery *ServiceDiscovery) *ServiceCaller {
 	return &ServiceCaller{
 		conn:             conn,
 		refClient:        refClient,
 		serviceDiscovery: serviceDiscovery,
+		timeout:          time.Duration(constants.DefaultTimeout) * time.Second,
 	}
 }
 
 // CallMethod calls a gRPC method with the given parameters
-func (sc *ServiceCaller) CallMethod(serviceName, resourceName, verb string, parameters map[string]interface{}) ([]byte, error) {
+func (sc *ServiceCaller) CallMethod(ctx context.Context, serviceName, resourceName, verb string, parameters map[string]interface{}) ([]byte, error) {
+	parameters = sc.withPresets(serviceName, resourceName, verb, parameters)
+
+	// Replay recorded responses in offline mode
+	if sc.offline {
+		return sc.replay(serviceName, resourceName, verb, parameters)
+	}
+	return sc.callBuffered(ctx, serviceName, resourceName, verb, parameters)
+}
+
+// callBuffered calls the console-api or gRPC method and returns its JSON response, from the
+// response cache when the method is cached
+func (sc *ServiceCaller) callBuffered(ctx context.Context, serviceName, resourceName, verb string, parameters map[string]interface{}) ([]byte, error) {
+	return sc.cache.cached(ctx, serviceName, resourceName, verb, parameters, func() ([]byte, error) {
+		if sc.rest != nil {
+			return sc.callREST(ctx, serviceName, resourceName, verb, parameters)
+		}
+		resp, duration, err := sc.call(ctx, serviceName, resourceName, verb, parameters)
+		if err != nil {
+			return nil, err
+		}
+		return sc.complete(serviceName, resourceName, verb, parameters, resp, duration)
+	})
+}
+
+// CallMethodTo calls a gRPC method and writes the JSON response to w.
+// Responses larger than the stream threshold are written one top-level field at a time
+// instead of being marshaled into a single buffer.
+func (sc *ServiceCaller) CallMethodTo(ctx context.Context, serviceName, resourceName, verb string, parameters map[string]interface{}, w io.Writer) error {
+	parameters = sc.withPresets(serviceName, resourceName, verb, parameters)
+
+	if sc.offline {
+		jsonBytes, err := sc.replay(serviceName, resourceName, verb, parameters)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(jsonBytes)
+		return err
+	}
+	// Cached responses are always buffered
+	if sc.rest != nil || sc.cache.TTL(serviceName, resourceName, verb) > 0 {
+		jsonBytes, err := sc.callBuffered(ctx, serviceName, resourceName, verb, parameters)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(jsonBytes)
+		return err
+	}
+
+	resp, duration, err := sc.call(ctx, serviceName, resourceName, verb, parameters)
+	if err != nil {
+		return err
+	}
+
+	// Recorded and redacted responses are always marshaled in full
+	if respDynamic, ok := resp.(*dynamic.Message); ok && sc.recorder == nil && !sc.redactor.Applies(serviceName, resourceName, verb) &&
+		sc.streamThreshold > 0 && int64(proto.Size(resp)) > sc.streamThreshold {
+		counter := &countingWriter{w: w}
+		err := writeMessageJSON(counter, respDynamic)
+		metrics.ObserveCall(serviceName, resourceName, verb, codes.OK, duration, counter.n)
+		return err
+	}
+
+	jsonBytes, err := sc.complete(serviceName, resourceName, verb, parameters, resp, duration)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(jsonBytes)
+	return err
+}
+
+// hold keeps the pooled connection open until the returned function is called
+func (sc *ServiceCaller) hold() func() {
+	if sc.pool == nil {
+		return func() {}
+	}
+	return sc.pool.hold(sc.poolKey)
+}
+
+// call resolves the method, builds the request and invokes the RPC
+func (sc *ServiceCaller) call(ctx context.Context, serviceName, resourceName, verb string, parameters map[string]interface{}) (proto.Message, time.Duration, error) {
+	release := sc.hold()
+	defer release()
+
+	// Get method descriptor
+	methodDesc, err := sc.resolveMethod(serviceName, resourceName, verb)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Create request message
+	parameters = sc.withClaimDefaults(ctx, parameters, func(field string) bool {
+		return methodDesc.GetInputType().FindFieldByName(field) != nil
+	})
+	requestMsg, err := sc.buildRequest(methodDesc, parameters, parameterReportFromContext(ctx))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Create dynamic gRPC stub
+	stub := grpcdynamic.NewStub(sc.conn)
+
+	// Invoke RPC call with timeout
+	ctx, cancel := context.WithTimeout(ctx, sc.timeout)
+	defer cancel()
+
+	start := time.Now()
+	resp, err := sc.invoke(ctx, stub, methodDesc, requestMsg, serviceName, resourceName, verb)
+	duration := time.Since(start)
+	if sc.payloadLogger.Sample(serviceName, resourceName, verb) {
+		sc.payloadLogger.Log(requestIDFromContext(ctx), serviceName, resourceName, verb, requestMsg, resp, duration, status.Code(err), err)
+	}
+	sc.slowCalls.Observe(ctx, serviceName, resourceName, verb, parameters, duration, status.Code(err))
+	if err != nil {
+		metrics.ObserveCall(serviceName, resourceName, verb, status.Code(err), duration, 0)
+
+		// Log detailed error information for debugging
+		log.Printf("ERROR: gRPC call failed for %s.%s.%s (request_id=%s)", serviceName, resourceName, verb, requestIDFromContext(ctx))
+		log.Printf("ERROR: Error details: %v", err)
+		log.Printf("ERROR: Request message: %s", sc.redactor.RedactRequest(serviceName, resourceName, verb, requestMsg))
+
+		// Report oversized responses with the observed size
+		if isMessageTooLarge(err) {
+			return nil, duration, errors.NewAPIError(errors.ErrResponseTooLarge, status.Convert(err).Message()).WithGRPCStatus(err)
+		}
+
+		// Create more detailed error message
+		errorMsg := fmt.Sprintf("gRPC call failed: %v", err)
+		return nil, duration, errors.NewAPIError(errors.ErrRPCCallFailed, errorMsg).WithGRPCStatus(err)
+	}
+
+	return resp, duration, nil
+}
+
+// complete converts the response to JSON, enforces the response size limit and records it
+func (sc *ServiceCaller) complete(serviceName, resourceName, verb string, parameters map[string]interface{},
+	resp proto.Message, duration time.Duration) ([]byte, error) {
+	// Convert response to JSON
+	jsonBytes, err := marshalMessage(resp, sc.indent)
+	if err != nil {
+		return nil, err
+	}
+	return sc.finish(serviceName, resourceName, verb, parameters, jsonBytes, duration)
+}
+
+// callREST posts the call to the console-api and completes its JSON response
+func (sc *ServiceCaller) callREST(ctx context.Context, serviceName, resourceName, verb string, parameters map[string]interface{}) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, sc.timeout)
+	defer cancel()
+
+	start := time.Now()
+	sent := sc.withClaimDefaults(ctx, parameters, sc.restHasField(serviceName, resourceName, verb))
+	jsonBytes, code, err := sc.rest.Call(ctx, serviceName, resourceName, verb, sent, sc.maxResponseBytes)
+	duration := time.Since(start)
+	if sc.payloadLogger.Sample(serviceName, resourceName, verb) {
+		sc.payloadLogger.Log(requestIDFromContext(ctx), serviceName, resourceName, verb, sent, jsonBytes, duration, code, err)
+	}
+	sc.slowCalls.Observe(ctx, serviceName, resourceName, verb, sent, duration, code)
+	if err != nil {
+		metrics.ObserveCall(serviceName, resourceName, verb, code, duration, 0)
+		log.Printf("ERROR: console-api call failed for %s.%s.%s (request_id=%s): %v", serviceName, resourceName, verb, requestIDFromContext(ctx), err)
+		return nil, err
+	}
+
+	if sc.indent {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, jsonBytes, "", "  "); err == nil {
+			jsonBytes = indented.Bytes()
+		}
+	}
+	return sc.finish(serviceName, resourceName, verb, parameters, jsonBytes, duration)
+}
+
+// finish enforces the response size limit on a JSON response and records it
+func (sc *ServiceCaller) finish(serviceName, resourceName, verb string, parameters map[string]interface{},
+	jsonBytes []byte, duration time.Duration) ([]byte, error) {
+	metrics.ObserveCall(serviceName, resourceName, verb, codes.OK, duration, len(jsonBytes))
+
+	if sc.maxResponseBytes > 0 && int64(len(jsonBytes)) > sc.maxResponseBytes {
+		return nil, errors.NewAPIError(errors.ErrResponseTooLarge,
+			fmt.Sprintf("response is %d bytes, limit is %d bytes", len(jsonBytes), sc.maxResponseBytes))
+	}
+
+	jsonBytes, err := sc.redactor.Redact(serviceName, resourceName, verb, jsonBytes, sc.indent)
+	if err != nil {
+		return nil, err
+	}
+
+	// Record the response for offline replay
+	if sc.recorder != nil {
+		if err := sc.recorder.SaveResponse(serviceName, resourceName, verb, parameters, jsonBytes); err != nil {
+			log.Printf("WARNING: failed to record response for %s.%s.%s: %v", serviceName, resourceName, verb, err)
+		}
+	}
+
+	return jsonBytes, nil
+}
+
+// replay returns a recorded response for the call
+func (sc *ServiceCaller) replay(serviceName, resourceName, verb string, parameters map[string]interface{}) ([]byte, error) {
+	recorded, err := sc.recorder.LoadResponse(serviceName, resourceName, verb, parameters)
+	if err != nil {
+		return nil, errors.NewAPIError(errors.ErrRecordingNotFound, err.Error())
+	}
+	return sc.redactor.Redact(serviceName, resourceName, verb, recorded.Response, sc.indent)
+}
+
+// resolveMethod returns the method descriptor for the service, resource and verb
+func (sc *ServiceCaller) resolveMethod(serviceName, resourceName, verb string) (*desc.MethodDescriptor, error) {
 	// Get service descriptor - use proper service name format
 	// For Health and ServerInfo resources, use the main service name
 	var serviceFullName string
 	var serviceDesc *desc.ServiceDescriptor
 	var err error
 
-	if resourceName == "Health" || resourceName == "ServerInfo" {
+	if IsWellKnownResource(resourceName) {
 		// Handle special services directly without service discovery
-		if resourceName == "Health" {
-			serviceFullName = "grpc.health.v1.Health"
-		} else if resourceName == "ServerInfo" {
-			serviceFullName = "spaceone.api.core.v1.ServerInfo"
-		}
-
+		serviceFullName = wellKnownServices[resourceName]
 		serviceDesc, err = sc.refClient.ResolveService(serviceFullName)
 		if err != nil {
 			return nil, errors.NewAPIError(errors.ErrServiceDescriptorFailed,
@@ -82,86 +315,136 @@ func (sc *ServiceCaller) CallMethod(serviceName, resourceName, verb string, para
 		return nil, errors.NewAPIError(errors.ErrMethodNotFound, fmt.Sprintf("method '%s' not found", verb))
 	}
 
-	// Create request message
+	return methodDesc, nil
+}
+
+// buildRequest creates a request message for the method with the given parameters set.
+// Every field that cannot be set is reported in a single invalid parameters error.
+// When report is not nil, how each parameter was interpreted is added to it.
+func (sc *ServiceCaller) buildRequest(methodDesc *desc.MethodDescriptor, parameters map[string]interface{}, report *ParameterReport) (proto.Message, error) {
 	reqFactory := dynamic.NewMessageFactoryWithDefaults()
 	requestMsg := reqFactory.NewMessage(methodDesc.GetInputType())
 
 	// Set parameters in the request message
-	if len(parameters) > 0 {
-		// Convert to dynamic message
-		if dynamicMsg, ok := requestMsg.(*dynamic.Message); ok {
-			for key, value := range parameters {
-				if err := sc.setMessageField(dynamicMsg, key, value); err != nil {
-					// Log field setting errors for debugging
-					// Continue with other fields even if one fails
-					// Note: Some fields like service, resource, verb are not part of the request message
-				}
-			}
+	dynamicMsg, ok := requestMsg.(*dynamic.Message)
+	if !ok || len(parameters) == 0 {
+		return requestMsg, nil
+	}
+
+	var fieldErrors []errors.FieldError
+	converted := make(map[string]bool, len(parameters))
+	for key, value := range parameters {
+		wasConverted, err := sc.setMessageField(dynamicMsg, key, value)
+		if err != nil {
+			fieldErrors = append(fieldErrors, errors.FieldError{Field: key, Message: err.Error()})
 		}
+		converted[key] = wasConverted
+	}
+	if len(fieldErrors) > 0 {
+		return nil, errors.NewValidationError(methodDesc.GetInputType().GetFullyQualifiedName(), fieldErrors)
 	}
 
-	// Create dynamic gRPC stub
-	stub := grpcdynamic.NewStub(sc.conn)
+	if report != nil {
+		keys := make([]string, 0, len(parameters))
+		for key := range parameters {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			report.Parameters = append(report.Parameters, describeParameter(dynamicMsg, key, converted[key]))
+		}
+	}
 
-	// Invoke RPC call with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(constants.DefaultTimeout)*time.Second)
-	defer cancel()
+	return requestMsg, nil
+}
 
-	resp, err := stub.InvokeRpc(ctx, methodDesc, requestMsg)
-	if err != nil {
-		// Log detailed error information for debugging
-		fmt.Printf("ERROR: gRPC call failed for %s.%s.%s\n", serviceName, resourceName, verb)
-		fmt.Printf("ERROR: Error details: %v\n", err)
-		fmt.Printf("ERROR: Request message: %s\n", requestMsg.String())
+// invoke calls the RPC, applying injected faults first when fault injection is enabled
+func (sc *ServiceCaller) invoke(ctx context.Context, stub grpcdynamic.Stub, methodDesc *desc.MethodDescriptor,
+	requestMsg proto.Message, serviceName, resourceName, verb string) (proto.Message, error) {
+	if err := sc.faultInjector.Apply(ctx, serviceName, resourceName, verb); err != nil {
+		return nil, err
+	}
+	return stub.InvokeRpc(ctx, methodDesc, requestMsg)
+}
 
-		// Create more detailed error message
-		errorMsg := fmt.Sprintf("gRPC call failed: %v", err)
-		return nil, errors.NewAPIError(errors.ErrRPCCallFailed, errorMsg)
+// setMessageField sets a field in the dynamic message, reporting whether the value had to be converted
+func (sc *ServiceCaller) setMessageField(msg *dynamic.Message, fieldName string, value interface{}) (bool, error) {
+	// Try to set the field directly
+	if err := msg.TrySetFieldByName(fieldName, value); err == nil {
+		return false, nil
 	}
 
-	// Convert response to JSON
-	respDynamic, ok := resp.(*dynamic.Message)
-	if !ok {
-		return nil, errors.NewAPIError(errors.ErrResponseConversionFailed, "failed to convert response to dynamic.Message")
+	// If direct setting fails, try to convert the value to the appropriate type
+	fieldDesc := msg.GetMessageDescriptor().FindFieldByName(fieldName)
+	if fieldDesc == nil {
+		return false, fmt.Errorf("field '%s' not found in %s", fieldName, msg.GetMessageDescriptor().GetName())
 	}
 
-	jsonBytes, err := respDynamic.MarshalJSONIndent()
-	if err != nil {
-		return nil, errors.NewAPIError(errors.ErrJSONConversionFailed, err.Error())
+	// Convert value based on field type
+	convertedValue, err := sc.convertValue(value, fieldDesc)
+	if err == nil && msg.TrySetField(fieldDesc, convertedValue) == nil {
+		return true, nil
 	}
 
-	return jsonBytes, nil
+	// Fall back to protobuf JSON rules for nested messages, repeated fields and enums
+	if err := setFieldFromJSON(msg, fieldDesc, value); err != nil {
+		return false, fmt.Errorf("invalid value for %s field: %w", fieldTypeName(fieldDesc), err)
+	}
+	return true, nil
 }
 
-// setMessageField sets a field in the dynamic message
-func (sc *ServiceCaller) setMessageField(msg *dynamic.Message, fieldName string, value interface{}) error {
-	// Try to set the field directly
-	if err := msg.TrySetFieldByName(fieldName, value); err != nil {
-		// If direct setting fails, try to convert the value to the appropriate type
-		fieldDesc := msg.GetMessageDescriptor().FindFieldByName(fieldName)
-		if fieldDesc == nil {
-			return fmt.Errorf("field '%s' not found in message", fieldName)
-		}
-
-		// Convert value based on field type
-		convertedValue, err := sc.convertValue(value, fieldDesc)
-		if err != nil {
-			return fmt.Errorf("failed to convert value for field '%s': %w", fieldName, err)
-		}
+// setFieldFromJSON sets a field by decoding its value with protobuf JSON rules
+func setFieldFromJSON(msg *dynamic.Message, fieldDesc *desc.FieldDescriptor, value interface{}) error {
+	data, err := json.Marshal(map[string]interface{}{fieldDesc.GetName(): value})
+	if err != nil {
+		return err
+	}
+	decoded := dynamic.NewMessage(msg.GetMessageDescriptor())
+	if err := decoded.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	return msg.TrySetField(fieldDesc, decoded.GetField(fieldDesc))
+}
 
-		return msg.TrySetFieldByName(fieldName, convertedValue)
+// fieldTypeName describes a field's type for error messages, e.g. "repeated int32"
+func fieldTypeName(fieldDesc *desc.FieldDescriptor) string {
+	typeName := strings.ToLower(strings.TrimPrefix(fieldDesc.GetType().String(), "TYPE_"))
+	if messageType := fieldDesc.GetMessageType(); messageType != nil {
+		typeName = messageType.GetFullyQualifiedName()
+	} else if enumType := fieldDesc.GetEnumType(); enumType != nil {
+		typeName = enumType.GetFullyQualifiedName()
+	}
+	if fieldDesc.IsMap() {
+		return "map"
 	}
-	return nil
+	if fieldDesc.IsRepeated() {
+		return "repeated " + typeName
+	}
+	return typeName
 }
 
 // convertValue converts interface{} value to the appropriate protobuf type
 func (sc *ServiceCaller) convertValue(value interface{}, fieldDesc *desc.FieldDescriptor) (interface{}, error) {
 	switch fieldDesc.GetType() {
 	case descriptorpb.FieldDescriptorProto_TYPE_STRING:
-		if str, ok := value.(string); ok {
-			return str, nil
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case []byte:
+			return string(v), nil
 		}
 		return fmt.Sprintf("%v", value), nil
+	case descriptorpb.FieldDescriptorProto_TYPE_BYTES:
+		switch v := value.(type) {
+		case []byte:
+			return v, nil
+		case string:
+			// JSON clients send bytes fields base64-encoded
+			if decoded, err := base64.StdEncoding.DecodeString(v); err == nil {
+				return decoded, nil
+			}
+			return []byte(v), nil
+		}
 	case descriptorpb.FieldDescriptorProto_TYPE_INT32, descriptorpb.FieldDescriptorProto_TYPE_INT64:
 		switch v := value.(type) {
 		case int:
@@ -212,3 +495,9 @@ func (sc *ServiceCaller) convertValue(value interface{}, fieldDesc *desc.FieldDe
 	// For complex types (maps, arrays, messages), try to set as-is
 	return value, nil
 }
+
+// isMessageTooLarge reports whether the call failed because the response exceeded the receive limit
+func isMessageTooLarge(err error) bool {
+	st := status.Convert(err)
+	return st.Code() == codes.ResourceExhausted && strings.Contains(st.Message(), "larger than max")
+}
@@ -0,0 +1,123 @@
+package grpc
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"spacectl-web/server/internal/config"
+
+	"google.golang.org/grpc/codes"
+)
+
+// SlowCall is a call that took at least the slow call threshold. Only the names of its
+// parameters are kept, so the log shows which queries are slow without their values.
+type SlowCall struct {
+	Time       time.Time `json:"time"`
+	RequestID  string    `json:"request_id,omitempty"`
+	Service    string    `json:"service"`
+	Resource   string    `json:"resource"`
+	Verb       string    `json:"verb"`
+	DurationMS int64     `json:"duration_ms"`
+	Code       string    `json:"code"`       // gRPC code name, OK on success
+	Parameters []string  `json:"parameters"` // Dotted parameter paths, e.g. query.filter[].k
+}
+
+// SlowCallLog logs calls slower than a threshold and keeps the latest ones
+type SlowCallLog struct {
+	threshold time.Duration
+	capacity  int
+	calls     []SlowCall // Oldest first
+	mutex     sync.Mutex
+}
+
+// SlowCallReport is the slow call threshold and the latest slow calls, newest first
+type SlowCallReport struct {
+	ThresholdMS int        `json:"threshold_ms"`
+	Calls       []SlowCall `json:"calls"`
+}
+
+// NewSlowCallLog creates a slow call log from the configuration
+func NewSlowCallLog(cfg config.SlowCallsConfig) *SlowCallLog {
+	cfg = cfg.WithDefaults()
+	return &SlowCallLog{threshold: time.Duration(cfg.ThresholdMS) * time.Millisecond, capacity: cfg.Capacity}
+}
+
+// Observe logs and keeps the call when it took at least the threshold
+func (l *SlowCallLog) Observe(ctx context.Context, serviceName, resourceName, verb string, parameters map[string]interface{},
+	duration time.Duration, code codes.Code) {
+	if l == nil || duration < l.threshold {
+		return
+	}
+	call := SlowCall{
+		Time:       time.Now(),
+		RequestID:  requestIDFromContext(ctx),
+		Service:    serviceName,
+		Resource:   resourceName,
+		Verb:       verb,
+		DurationMS: duration.Milliseconds(),
+		Code:       code.String(),
+		Parameters: parameterShape(parameters),
+	}
+	log.Printf("WARNING: slow call %s.%s.%s took %s (request_id=%s, code=%s, parameters: %s)",
+		serviceName, resourceName, verb, duration.Round(time.Millisecond), call.RequestID, call.Code, strings.Join(call.Parameters, ", "))
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if len(l.calls) >= l.capacity {
+		l.calls = append(l.calls[:0], l.calls[len(l.calls)-l.capacity+1:]...)
+	}
+	l.calls = append(l.calls, call)
+}
+
+// Report returns the threshold and the kept slow calls, newest first
+func (l *SlowCallLog) Report() *SlowCallReport {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	report := &SlowCallReport{ThresholdMS: int(l.threshold.Milliseconds()), Calls: make([]SlowCall, 0, len(l.calls))}
+	for i := len(l.calls) - 1; i >= 0; i-- {
+		report.Calls = append(report.Calls, l.calls[i])
+	}
+	return report
+}
+
+// parameterShape returns the sorted dotted paths of the fields set in the parameters. Array
+// elements share their array's path with a [] suffix.
+func parameterShape(parameters map[string]interface{}) []string {
+	paths := make(map[string]bool)
+	var walk func(path string, value interface{})
+	walk = func(path string, value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if len(v) == 0 && path != "" {
+				paths[path] = true
+			}
+			for key, child := range v {
+				childPath := key
+				if path != "" {
+					childPath = path + "." + key
+				}
+				walk(childPath, child)
+			}
+		case []interface{}:
+			if len(v) == 0 {
+				paths[path+"[]"] = true
+			}
+			for _, element := range v {
+				walk(path+"[]", element)
+			}
+		default:
+			paths[path] = true
+		}
+	}
+	walk("", parameters)
+	shape := make([]string, 0, len(paths))
+	for path := range paths {
+		shape = append(shape, path)
+	}
+	sort.Strings(shape)
+	return shape
+}
@@ -0,0 +1,176 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"spacectl-web/server/internal/errors"
+
+	"github.com/golang/protobuf/proto" //nolint:staticcheck // grpcdynamic uses the v1 message API
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/jhump/protoreflect/dynamic/grpcdynamic"
+)
+
+// Stream represents an open client-streaming, server-streaming or bidirectional RPC
+type Stream struct {
+	sc           *ServiceCaller
+	method       *desc.MethodDescriptor
+	service      string
+	resource     string
+	verb         string
+	clientStream *grpcdynamic.ClientStream
+	serverStream *grpcdynamic.ServerStream
+	bidiStream   *grpcdynamic.BidiStream
+	cancel       context.CancelFunc
+	release      func() // Releases the pooled connection
+
+	closeSendOnce sync.Once
+	sendClosed    chan struct{}
+	received      bool // The single client-streaming response has been returned
+}
+
+// OpenStream opens a streaming RPC. For server-streaming methods the initial parameters
+// form the single request; for client-streaming and bidirectional methods messages are sent with Send.
+func (sc *ServiceCaller) OpenStream(ctx context.Context, serviceName, resourceName, verb string, initial map[string]interface{}) (*Stream, error) {
+	if sc.offline {
+		return nil, errors.NewAPIError(errors.ErrRPCCallFailed, "streaming is not available in offline mode")
+	}
+	if sc.rest != nil {
+		return nil, errors.NewAPIError(errors.ErrVerbNotSupported, "streaming is not available for REST endpoints")
+	}
+
+	methodDesc, err := sc.resolveMethod(serviceName, resourceName, verb)
+	if err != nil {
+		return nil, err
+	}
+	if !methodDesc.IsClientStreaming() && !methodDesc.IsServerStreaming() {
+		return nil, errors.NewAPIError(errors.ErrVerbNotSupported, fmt.Sprintf("method '%s' is unary", verb))
+	}
+
+	var initialMsg proto.Message
+	if methodDesc.IsServerStreaming() && !methodDesc.IsClientStreaming() {
+		if initialMsg, err = sc.buildRequest(methodDesc, initial, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	stream := &Stream{
+		sc:         sc,
+		method:     methodDesc,
+		service:    serviceName,
+		resource:   resourceName,
+		verb:       verb,
+		cancel:     cancel,
+		release:    sc.hold(),
+		sendClosed: make(chan struct{}),
+	}
+
+	stub := grpcdynamic.NewStub(sc.conn)
+	switch {
+	case methodDesc.IsClientStreaming() && methodDesc.IsServerStreaming():
+		stream.bidiStream, err = stub.InvokeRpcBidiStream(ctx, methodDesc)
+	case methodDesc.IsClientStreaming():
+		stream.clientStream, err = stub.InvokeRpcClientStream(ctx, methodDesc)
+	default:
+		stream.serverStream, err = stub.InvokeRpcServerStream(ctx, methodDesc, initialMsg)
+	}
+	if err != nil {
+		stream.Close()
+		return nil, errors.NewAPIError(errors.ErrRPCCallFailed, fmt.Sprintf("failed to open stream: %v", err))
+	}
+
+	return stream, nil
+}
+
+// ClientStreaming reports whether the stream accepts messages from the client
+func (s *Stream) ClientStreaming() bool {
+	return s.method.IsClientStreaming()
+}
+
+// Send sends a request message built from the parameters
+func (s *Stream) Send(parameters map[string]interface{}) error {
+	msg, err := s.sc.buildRequest(s.method, parameters, nil)
+	if err != nil {
+		return err
+	}
+	switch {
+	case s.bidiStream != nil:
+		return s.bidiStream.SendMsg(msg)
+	case s.clientStream != nil:
+		return s.clientStream.SendMsg(msg)
+	default:
+		return fmt.Errorf("method '%s' does not accept client messages", s.method.GetName())
+	}
+}
+
+// CloseSend signals that the client has finished sending messages
+func (s *Stream) CloseSend() error {
+	var err error
+	s.closeSendOnce.Do(func() {
+		if s.bidiStream != nil {
+			err = s.bidiStream.CloseSend()
+		}
+		close(s.sendClosed)
+	})
+	return err
+}
+
+// Recv returns the next response as JSON, or io.EOF when the stream is finished.
+// For client-streaming methods the single response is returned after CloseSend.
+func (s *Stream) Recv() ([]byte, error) {
+	var msg proto.Message
+	var err error
+
+	switch {
+	case s.bidiStream != nil:
+		msg, err = s.bidiStream.RecvMsg()
+	case s.serverStream != nil:
+		msg, err = s.serverStream.RecvMsg()
+	default:
+		if s.received {
+			return nil, io.EOF
+		}
+		<-s.sendClosed
+		s.received = true
+		msg, err = s.clientStream.CloseAndReceive()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	jsonBytes, err := marshalMessage(msg, s.sc.indent)
+	if err != nil {
+		return nil, err
+	}
+	return s.sc.redactor.Redact(s.service, s.resource, s.verb, jsonBytes, s.sc.indent)
+}
+
+// Close cancels the stream and releases its connection
+func (s *Stream) Close() {
+	s.cancel()
+	s.release()
+}
+
+// marshalMessage converts a dynamic response message to compact or indented JSON
+func marshalMessage(msg proto.Message, indent bool) ([]byte, error) {
+	respDynamic, ok := msg.(*dynamic.Message)
+	if !ok {
+		return nil, errors.NewAPIError(errors.ErrResponseConversionFailed, "failed to convert response to dynamic.Message")
+	}
+
+	var jsonBytes []byte
+	var err error
+	if indent {
+		jsonBytes, err = respDynamic.MarshalJSONIndent()
+	} else {
+		jsonBytes, err = respDynamic.MarshalJSON()
+	}
+	if err != nil {
+		return nil, errors.NewAPIError(errors.ErrJSONConversionFailed, err.Error())
+	}
+	return jsonBytes, nil
+}
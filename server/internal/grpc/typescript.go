@@ -0,0 +1,236 @@
+package grpc
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jhump/protoreflect/desc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// TypeScript namespaces of message declarations. Requests are set by proto field name and
+// accept numbers for 64-bit and enum fields; responses are marshaled with JSON field names.
+const (
+	TypeScriptRequests  = "requests"
+	TypeScriptResponses = "responses"
+)
+
+// typeScriptUntyped is the type of messages without descriptors, such as console-api calls
+const typeScriptUntyped = "Record<string, unknown>"
+
+// typeScriptWellKnown maps well-known message types to the TypeScript type of their JSON form
+var typeScriptWellKnown = map[string]string{
+	"google.protobuf.Struct":      "Record<string, unknown>",
+	"google.protobuf.Value":       "unknown",
+	"google.protobuf.ListValue":   "unknown[]",
+	"google.protobuf.Empty":       "Record<string, never>",
+	"google.protobuf.Any":         `{ "@type": string; [key: string]: unknown }`,
+	"google.protobuf.Timestamp":   "string",
+	"google.protobuf.Duration":    "string",
+	"google.protobuf.FieldMask":   "string",
+	"google.protobuf.DoubleValue": "number",
+	"google.protobuf.FloatValue":  "number",
+	"google.protobuf.Int32Value":  "number",
+	"google.protobuf.UInt32Value": "number",
+	"google.protobuf.Int64Value":  "string",
+	"google.protobuf.UInt64Value": "string",
+	"google.protobuf.BoolValue":   "boolean",
+	"google.protobuf.StringValue": "string",
+	"google.protobuf.BytesValue":  "string",
+}
+
+// TypeScriptTypes collects TypeScript declarations of the messages calls send and receive
+type TypeScriptTypes struct {
+	declarations map[string]map[string]map[string]string // Declaration by namespace, proto package and name
+}
+
+// NewTypeScriptTypes creates an empty set of declarations
+func NewTypeScriptTypes() *TypeScriptTypes {
+	return &TypeScriptTypes{declarations: make(map[string]map[string]map[string]string)}
+}
+
+// TypeScriptMethodTypes adds the request and response messages of a method to types and
+// returns their type names. Messages of console-api calls and offline mode have no
+// descriptors, so they are untyped records.
+func (sc *ServiceCaller) TypeScriptMethodTypes(types *TypeScriptTypes, serviceName, resourceName, verb string) (string, string, error) {
+	if sc.offline || sc.rest != nil {
+		return typeScriptUntyped, typeScriptUntyped, nil
+	}
+	methodDesc, err := sc.resolveMethod(serviceName, resourceName, verb)
+	if err != nil {
+		return "", "", err
+	}
+	return types.message(TypeScriptRequests, methodDesc.GetInputType()), types.message(TypeScriptResponses, methodDesc.GetOutputType()), nil
+}
+
+// WriteTo writes the declarations as a namespace per proto package
+func (t *TypeScriptTypes) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	for _, namespace := range sortedKeys(t.declarations) {
+		packages := t.declarations[namespace]
+		for _, pkg := range sortedKeys(packages) {
+			fmt.Fprintf(&b, "export namespace %s {\n", typeScriptNamespace(namespace, pkg))
+			declarations := packages[pkg]
+			for i, name := range sortedKeys(declarations) {
+				if i > 0 {
+					b.WriteString("\n")
+				}
+				b.WriteString(declarations[name])
+			}
+			b.WriteString("}\n\n")
+		}
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// message declares a message and the messages and enums of its fields, returning its type name
+func (t *TypeScriptTypes) message(namespace string, msgDesc *desc.MessageDescriptor) string {
+	if wellKnown, ok := typeScriptWellKnown[msgDesc.GetFullyQualifiedName()]; ok {
+		return wellKnown
+	}
+	pkg := msgDesc.GetFile().GetPackage()
+	name := typeScriptName(msgDesc.GetFullyQualifiedName(), pkg)
+	ref := typeScriptNamespace(namespace, pkg) + "." + name
+	if t.declared(namespace, pkg, name) {
+		return ref
+	}
+	t.declare(namespace, pkg, name, "") // Placeholder, so recursive messages refer to themselves
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "  export interface %s {\n", name)
+	for _, field := range msgDesc.GetFields() {
+		fieldName := field.GetName()
+		if namespace == TypeScriptResponses {
+			fieldName = field.GetJSONName()
+		}
+		fmt.Fprintf(&b, "    %s?: %s;\n", TypeScriptProperty(fieldName), t.fieldType(namespace, field))
+	}
+	b.WriteString("  }\n")
+	t.declare(namespace, pkg, name, b.String())
+	return ref
+}
+
+// enum declares an enum as a union of its value names, returning its type name
+func (t *TypeScriptTypes) enum(namespace string, enumDesc *desc.EnumDescriptor) string {
+	pkg := enumDesc.GetFile().GetPackage()
+	name := typeScriptName(enumDesc.GetFullyQualifiedName(), pkg)
+	ref := typeScriptNamespace(namespace, pkg) + "." + name
+	if t.declared(namespace, pkg, name) {
+		return ref
+	}
+	values := make([]string, 0, len(enumDesc.GetValues()))
+	for _, value := range enumDesc.GetValues() {
+		values = append(values, strconv.Quote(value.GetName()))
+	}
+	if len(values) == 0 {
+		values = append(values, "never")
+	}
+	t.declare(namespace, pkg, name, fmt.Sprintf("  export type %s = %s;\n", name, strings.Join(values, " | ")))
+	return ref
+}
+
+// fieldType returns the TypeScript type of a field's JSON value
+func (t *TypeScriptTypes) fieldType(namespace string, field *desc.FieldDescriptor) string {
+	if field.IsMap() {
+		return "Record<string, " + t.scalarType(namespace, field.GetMapValueType()) + ">"
+	}
+	fieldType := t.scalarType(namespace, field)
+	if !field.IsRepeated() {
+		return fieldType
+	}
+	if strings.Contains(fieldType, " ") {
+		return "(" + fieldType + ")[]"
+	}
+	return fieldType + "[]"
+}
+
+// scalarType returns the TypeScript type of a single value of a field
+func (t *TypeScriptTypes) scalarType(namespace string, field *desc.FieldDescriptor) string {
+	switch field.GetType() {
+	case descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, descriptorpb.FieldDescriptorProto_TYPE_GROUP:
+		return t.message(namespace, field.GetMessageType())
+	case descriptorpb.FieldDescriptorProto_TYPE_ENUM:
+		enum := t.enum(namespace, field.GetEnumType())
+		if namespace == TypeScriptRequests {
+			return enum + " | number"
+		}
+		return enum
+	case descriptorpb.FieldDescriptorProto_TYPE_BOOL:
+		return "boolean"
+	case descriptorpb.FieldDescriptorProto_TYPE_STRING, descriptorpb.FieldDescriptorProto_TYPE_BYTES:
+		return "string"
+	case descriptorpb.FieldDescriptorProto_TYPE_INT64, descriptorpb.FieldDescriptorProto_TYPE_UINT64,
+		descriptorpb.FieldDescriptorProto_TYPE_SINT64, descriptorpb.FieldDescriptorProto_TYPE_FIXED64,
+		descriptorpb.FieldDescriptorProto_TYPE_SFIXED64:
+		// Marshaled as strings to keep 64-bit values exact
+		if namespace == TypeScriptRequests {
+			return "string | number"
+		}
+		return "string"
+	}
+	return "number"
+}
+
+// declared reports whether a name is declared in the namespace and package
+func (t *TypeScriptTypes) declared(namespace, pkg, name string) bool {
+	_, exists := t.declarations[namespace][pkg][name]
+	return exists
+}
+
+// declare sets the declaration of a name in the namespace and package
+func (t *TypeScriptTypes) declare(namespace, pkg, name, declaration string) {
+	packages, exists := t.declarations[namespace]
+	if !exists {
+		packages = make(map[string]map[string]string)
+		t.declarations[namespace] = packages
+	}
+	if packages[pkg] == nil {
+		packages[pkg] = make(map[string]string)
+	}
+	packages[pkg][name] = declaration
+}
+
+// typeScriptNamespace returns the namespace of a proto package, e.g. requests.spaceone.api.identity.v2
+func typeScriptNamespace(namespace, pkg string) string {
+	if pkg == "" {
+		return namespace
+	}
+	return namespace + "." + pkg
+}
+
+// typeScriptName returns the name of a type within its package, joining nested names with
+// underscores, e.g. Outer_Inner
+func typeScriptName(fullName, pkg string) string {
+	if pkg != "" {
+		fullName = strings.TrimPrefix(fullName, pkg+".")
+	}
+	return strings.ReplaceAll(fullName, ".", "_")
+}
+
+// TypeScriptProperty quotes a property name unless it is a valid identifier
+func TypeScriptProperty(name string) string {
+	for i, r := range name {
+		if r == '_' || r == '$' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (i > 0 && r >= '0' && r <= '9') {
+			continue
+		}
+		return strconv.Quote(name)
+	}
+	if name == "" {
+		return `""`
+	}
+	return name
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"spacectl-web/server/internal/errors"
+	"spacectl-web/server/internal/grpc"
+	"spacectl-web/server/internal/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// identityService is the service whose users, roles, and workspaces the access overview joins
+const identityService = "identity"
+
+// AccessBinding is a role a user has in the domain or a workspace
+type AccessBinding struct {
+	RoleBindingID string `json:"role_binding_id"`
+	ResourceGroup string `json:"resource_group"` // DOMAIN or WORKSPACE
+	RoleID        string `json:"role_id"`
+	RoleName      string `json:"role_name,omitempty"`
+	RoleType      string `json:"role_type,omitempty"`
+	WorkspaceID   string `json:"workspace_id,omitempty"`
+	WorkspaceName string `json:"workspace_name,omitempty"`
+}
+
+// AccessUser is a user with their role bindings
+type AccessUser struct {
+	UserID       string          `json:"user_id"`
+	Name         string          `json:"name,omitempty"`
+	Email        string          `json:"email,omitempty"`
+	State        string          `json:"state,omitempty"`
+	AuthType     string          `json:"auth_type,omitempty"`
+	RoleBindings []AccessBinding `json:"role_bindings"`
+}
+
+// AccessWorkspace is a workspace with the number of users bound to it
+type AccessWorkspace struct {
+	WorkspaceID string `json:"workspace_id"`
+	Name        string `json:"name,omitempty"`
+	State       string `json:"state,omitempty"`
+	UserCount   int    `json:"user_count"`
+}
+
+// AccessOverview is the users of a domain with their roles in each workspace
+type AccessOverview struct {
+	Users      []AccessUser      `json:"users"`      // Sorted by user ID
+	Workspaces []AccessWorkspace `json:"workspaces"` // Sorted by name
+}
+
+// resourceList selects the results of a list verb
+type resourceList struct {
+	Service  string
+	Resource string
+	Filters  []interface{} // SpaceONE query filters
+	Optional bool          // Failures leave the list empty instead of failing the whole request
+}
+
+// listResult is the response of a list verb
+type listResult struct {
+	Results []map[string]interface{} `json:"results"`
+}
+
+// GetAccessOverview lists users, role bindings, roles, and workspaces of the identity service
+// concurrently and joins them into one payload. The optional user_id and workspace_id query
+// parameters narrow the overview to one user or workspace.
+func (h *Handler) GetAccessOverview(c echo.Context) error {
+	userID := c.QueryParam("user_id")
+	workspaceID := c.QueryParam("workspace_id")
+
+	var bindingFilters []interface{}
+	if userID != "" {
+		bindingFilters = append(bindingFilters, map[string]interface{}{"k": "user_id", "v": userID, "o": "eq"})
+	}
+	if workspaceID != "" {
+		bindingFilters = append(bindingFilters, map[string]interface{}{"k": "workspace_id", "v": workspaceID, "o": "eq"})
+	}
+	queries := map[string]*resourceList{
+		"User":        {Service: identityService, Resource: "User"},
+		"RoleBinding": {Service: identityService, Resource: "RoleBinding", Filters: bindingFilters},
+		"Role":        {Service: identityService, Resource: "Role"},
+		"Workspace":   {Service: identityService, Resource: "Workspace"},
+	}
+	if userID != "" {
+		queries["User"].Filters = []interface{}{map[string]interface{}{"k": "user_id", "v": userID, "o": "eq"}}
+	}
+	if workspaceID != "" {
+		queries["Workspace"].Filters = []interface{}{map[string]interface{}{"k": "workspace_id", "v": workspaceID, "o": "eq"}}
+	}
+
+	ctx := grpc.WithRequestID(c.Request().Context(), c.Response().Header().Get(echo.HeaderXRequestID))
+	lists, _, apiErr := h.listConcurrently(ctx, queries)
+	if apiErr != nil {
+		return response.APIError(c, apiErr)
+	}
+	return response.Success(c, joinAccess(lists, workspaceID != ""))
+}
+
+// listConcurrently calls the list verbs concurrently and returns their results by key. Failed
+// optional lists are empty and reported as warnings; the first other failure is returned.
+func (h *Handler) listConcurrently(ctx context.Context, queries map[string]*resourceList) (map[string]*listResult, []string, *errors.APIError) {
+	lists := make(map[string]*listResult, len(queries))
+	var warnings []string
+	var firstErr *errors.APIError
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for key, query := range queries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			parameters := map[string]interface{}{}
+			if len(query.Filters) > 0 {
+				parameters["query"] = map[string]interface{}{"filter": query.Filters}
+			}
+			list := &listResult{}
+			jsonBytes, apiErr := h.invoke(ctx, query.Service, query.Resource, "list", parameters)
+			if apiErr == nil {
+				if err := json.Unmarshal(jsonBytes, list); err != nil {
+					apiErr = errors.NewAPIError(errors.ErrResponseConversionFailed, err.Error())
+				}
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			lists[key] = list
+			switch {
+			case apiErr == nil:
+			case query.Optional:
+				list.Results = nil
+				warnings = append(warnings, fmt.Sprintf("%s.%s.list failed: %s", query.Service, query.Resource, apiErr.Error()))
+			case firstErr == nil:
+				firstErr = apiErr
+			}
+		}()
+	}
+	wg.Wait()
+	sort.Strings(warnings)
+	return lists, warnings, firstErr
+}
+
+// joinAccess attaches role bindings to their users with role and workspace names. When the
+// overview is narrowed to a workspace, users without a binding in it are left out.
+func joinAccess(lists map[string]*listResult, byWorkspace bool) *AccessOverview {
+	roleNames := make(map[string]string)
+	for _, role := range lists["Role"].Results {
+		roleNames[stringField(role, "role_id")] = stringField(role, "name")
+	}
+	workspaces := make(map[string]*AccessWorkspace)
+	for _, workspace := range lists["Workspace"].Results {
+		id := stringField(workspace, "workspace_id")
+		workspaces[id] = &AccessWorkspace{WorkspaceID: id, Name: stringField(workspace, "name"), State: stringField(workspace, "state")}
+	}
+
+	bindings := make(map[string][]AccessBinding)
+	workspaceUsers := make(map[string]map[string]bool)
+	for _, binding := range lists["RoleBinding"].Results {
+		userID := stringField(binding, "user_id")
+		access := AccessBinding{
+			RoleBindingID: stringField(binding, "role_binding_id"),
+			ResourceGroup: stringField(binding, "resource_group"),
+			RoleID:        stringField(binding, "role_id"),
+			RoleType:      stringField(binding, "role_type"),
+			WorkspaceID:   stringField(binding, "workspace_id"),
+		}
+		access.RoleName = roleNames[access.RoleID]
+		if workspace := workspaces[access.WorkspaceID]; workspace != nil {
+			access.WorkspaceName = workspace.Name
+			if workspaceUsers[access.WorkspaceID] == nil {
+				workspaceUsers[access.WorkspaceID] = make(map[string]bool)
+			}
+			workspaceUsers[access.WorkspaceID][userID] = true
+		}
+		bindings[userID] = append(bindings[userID], access)
+	}
+
+	overview := &AccessOverview{Users: []AccessUser{}, Workspaces: []AccessWorkspace{}}
+	for _, user := range lists["User"].Results {
+		userID := stringField(user, "user_id")
+		if byWorkspace && len(bindings[userID]) == 0 {
+			continue
+		}
+		userBindings := bindings[userID]
+		if userBindings == nil {
+			userBindings = []AccessBinding{}
+		}
+		overview.Users = append(overview.Users, AccessUser{
+			UserID:       userID,
+			Name:         stringField(user, "name"),
+			Email:        stringField(user, "email"),
+			State:        stringField(user, "state"),
+			AuthType:     stringField(user, "auth_type"),
+			RoleBindings: userBindings,
+		})
+	}
+	for id, workspace := range workspaces {
+		workspace.UserCount = len(workspaceUsers[id])
+		overview.Workspaces = append(overview.Workspaces, *workspace)
+	}
+
+	sort.Slice(overview.Users, func(i, j int) bool { return overview.Users[i].UserID < overview.Users[j].UserID })
+	sort.Slice(overview.Workspaces, func(i, j int) bool {
+		a, b := overview.Workspaces[i], overview.Workspaces[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.WorkspaceID < b.WorkspaceID
+	})
+	return overview
+}
+
+// stringField returns a string field of a result, or an empty string
+func stringField(result map[string]interface{}, name string) string {
+	value, _ := result[name].(string)
+	return value
+}
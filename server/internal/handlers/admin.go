@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"spacectl-web/server/internal/jobs"
+	"spacectl-web/server/internal/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SetJobs sets the registry of running calls exposed by the admin API
+func (h *Handler) SetJobs(registry *jobs.Registry) {
+	h.jobs = registry
+}
+
+// ListConnections returns the open gRPC connections
+func (h *Handler) ListConnections(c echo.Context) error {
+	return response.Success(c, h.grpcManager.Connections())
+}
+
+// DropConnection closes a service's gRPC connection; the service reconnects on its next call
+func (h *Handler) DropConnection(c echo.Context) error {
+	serviceName := c.Param("service")
+	if !h.grpcManager.DropConnection(serviceName) {
+		return response.NotFound(c, "Connection not found", fmt.Sprintf("no open connection to '%s'", serviceName))
+	}
+	return response.Success(c, map[string]string{"service": serviceName})
+}
+
+// ListCache returns the cached discovery results and their ages
+func (h *Handler) ListCache(c echo.Context) error {
+	return response.Success(c, h.serviceDiscovery.CacheEntries())
+}
+
+// ListJobs returns the running calls, bench runs and websocket sessions
+func (h *Handler) ListJobs(c echo.Context) error {
+	return response.Success(c, h.jobs.List())
+}
+
+// CancelJob cancels a running call, bench run or websocket session
+func (h *Handler) CancelJob(c echo.Context) error {
+	id := c.Param("id")
+	if !h.jobs.Cancel(id) {
+		return response.NotFound(c, "Job not found", fmt.Sprintf("no running job with ID '%s'", id))
+	}
+	return response.Success(c, map[string]string{"id": id})
+}
+
+// GetEffectiveConfig returns the configuration in effect as YAML, with secrets redacted
+func (h *Handler) GetEffectiveConfig(c echo.Context) error {
+	data, err := h.config.EffectiveYAML()
+	if err != nil {
+		return response.InternalServerError(c, "Failed to render configuration", err.Error())
+	}
+	return c.Blob(http.StatusOK, "application/yaml", data)
+}
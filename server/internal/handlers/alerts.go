@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"spacectl-web/server/internal/errors"
+	"spacectl-web/server/internal/grpc"
+	"spacectl-web/server/internal/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Bulk alert limits to keep a single request from overwhelming the monitoring service
+const (
+	maxBulkAlerts         = 500
+	bulkAlertsConcurrency = 8
+)
+
+// alertActionStates maps bulk alert actions to the alert states they set
+var alertActionStates = map[string]string{
+	"acknowledge": "ACKNOWLEDGED",
+	"resolve":     "RESOLVED",
+}
+
+// BulkAlertRequest changes the state of several alerts
+type BulkAlertRequest struct {
+	AlertIDs []string `json:"alert_ids"`
+	Action   string   `json:"action"` // acknowledge or resolve
+	Note     string   `json:"note"`   // Sent as the status message of every alert
+}
+
+// BulkAlertFailure is an alert whose update failed
+type BulkAlertFailure struct {
+	AlertID string              `json:"alert_id"`
+	Error   *response.ErrorInfo `json:"error"`
+}
+
+// BulkAlertResult reports which alerts were updated
+type BulkAlertResult struct {
+	Action    string             `json:"action"`
+	State     string             `json:"state"`
+	Succeeded []string           `json:"succeeded"`
+	Failed    []BulkAlertFailure `json:"failed"`
+}
+
+// UpdateAlerts acknowledges or resolves a set of alerts with concurrent Alert.update calls.
+// Alerts that fail do not stop the others; they are reported with their errors.
+func (h *Handler) UpdateAlerts(c echo.Context) error {
+	var req BulkAlertRequest
+	if err := c.Bind(&req); err != nil {
+		if apiErr := bodyTooLarge(err); apiErr != nil {
+			return response.APIError(c, apiErr)
+		}
+		return response.BadRequest(c, "Invalid request body", err.Error())
+	}
+	state, ok := alertActionStates[req.Action]
+	if !ok {
+		return response.BadRequest(c, "Invalid action", "action must be 'acknowledge' or 'resolve'")
+	}
+
+	// Update each alert once, whatever the order of the IDs
+	seen := make(map[string]bool, len(req.AlertIDs))
+	alertIDs := make([]string, 0, len(req.AlertIDs))
+	for _, alertID := range req.AlertIDs {
+		if alertID != "" && !seen[alertID] {
+			seen[alertID] = true
+			alertIDs = append(alertIDs, alertID)
+		}
+	}
+	if len(alertIDs) == 0 || len(alertIDs) > maxBulkAlerts {
+		return response.BadRequest(c, "Invalid alert IDs", fmt.Sprintf("alert_ids must have between 1 and %d IDs", maxBulkAlerts))
+	}
+	if apiErr := h.validateRequest(monitoringService, "Alert", "update"); apiErr != nil {
+		return response.APIError(c, apiErr)
+	}
+
+	ctx := grpc.WithRequestID(c.Request().Context(), c.Response().Header().Get(echo.HeaderXRequestID))
+	errs := h.updateAlerts(ctx, alertIDs, state, req.Note)
+
+	result := &BulkAlertResult{Action: req.Action, State: state, Succeeded: []string{}, Failed: []BulkAlertFailure{}}
+	language := response.Language(c)
+	for i, alertID := range alertIDs {
+		if errs[i] != nil {
+			result.Failed = append(result.Failed, BulkAlertFailure{AlertID: alertID, Error: response.NewErrorInfo(errs[i].Localize(language))})
+		} else {
+			result.Succeeded = append(result.Succeeded, alertID)
+		}
+	}
+	return response.Success(c, result)
+}
+
+// updateAlerts sets the state of each alert and returns the error of each update, by index
+func (h *Handler) updateAlerts(ctx context.Context, alertIDs []string, state, note string) []*errors.APIError {
+	errs := make([]*errors.APIError, len(alertIDs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(bulkAlertsConcurrency, len(alertIDs)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				parameters := map[string]interface{}{"alert_id": alertIDs[i], "state": state}
+				if note != "" {
+					parameters["status_message"] = note
+				}
+				_, errs[i] = h.invoke(ctx, monitoringService, "Alert", "update", parameters)
+			}
+		}()
+	}
+
+	for i := range alertIDs {
+		if ctx.Err() != nil {
+			errs[i] = toAPIError(ctx.Err())
+			continue
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return errs
+}
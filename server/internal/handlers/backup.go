@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"spacectl-web/server/internal/response"
+	"spacectl-web/server/internal/storage"
+
+	"github.com/labstack/echo/v4"
+)
+
+// backupFileField is the multipart form field carrying an uploaded backup
+const backupFileField = "file"
+
+// Backup downloads the user's history, favorites, collections and schedules as a tar.gz archive
+func (h *Handler) Backup(c echo.Context) error {
+	// Build the archive first so a storage failure can still be reported as JSON
+	var archive bytes.Buffer
+	if _, err := storage.Backup(c.Request().Context(), h.store, &archive, h.user(c)); err != nil {
+		return response.InternalServerError(c, "Backup failed", err.Error())
+	}
+
+	filename := fmt.Sprintf("spacectl-web-backup-%s.tar.gz", time.Now().Format("20060102-150405"))
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+	return c.Blob(http.StatusOK, "application/gzip", archive.Bytes())
+}
+
+// Restore saves the records of an uploaded backup as the user's, either as the raw request
+// body or as the "file" field of a multipart form
+func (h *Handler) Restore(c echo.Context) error {
+	body := c.Request().Body
+	if isMultipart(c) {
+		upload, err := c.FormFile(backupFileField)
+		if err != nil {
+			if apiErr := bodyTooLarge(err); apiErr != nil {
+				return response.APIError(c, apiErr)
+			}
+			return response.BadRequest(c, "Invalid restore request", fmt.Sprintf("upload the backup as the '%s' field: %v", backupFileField, err))
+		}
+		file, err := upload.Open()
+		if err != nil {
+			return response.BadRequest(c, "Invalid restore request", err.Error())
+		}
+		defer file.Close()
+		body = file
+	}
+
+	owner := h.user(c)
+	counts, err := storage.Restore(c.Request().Context(), h.store, body, owner, h.config.Server.Limits.WithDefaults().MaxRestoreBytes)
+	if err != nil {
+		if apiErr := bodyTooLarge(err); apiErr != nil {
+			return response.APIError(c, apiErr)
+		}
+		return response.BadRequest(c, "Restore failed", err.Error())
+	}
+	if err := h.store.Trim(c.Request().Context(), storage.KindHistory, owner, h.config.Server.Storage.WithDefaults().HistoryLimit); err != nil {
+		return response.InternalServerError(c, "Restore failed", err.Error())
+	}
+	return response.Success(c, map[string]interface{}{"restored": counts})
+}
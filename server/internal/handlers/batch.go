@@ -0,0 +1,323 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"spacectl-web/server/internal/errors"
+	"spacectl-web/server/internal/grpc"
+	"spacectl-web/server/internal/response"
+	"spacectl-web/server/internal/storage"
+	"spacectl-web/server/internal/workflow"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxBatchItems bounds the calls of a single batch request
+const maxBatchItems = 50
+
+// compensateTimeout bounds the compensation calls of a rollback, which go on after the client disconnects
+const compensateTimeout = 2 * time.Minute
+
+// itemReferencePrefix starts a parameter that takes its value from an earlier item's result
+const itemReferencePrefix = "$items["
+
+// What a batch does when a call fails
+const (
+	batchContinue = "continue" // Make the remaining calls
+	batchStop     = "stop"     // Skip the remaining calls
+	batchRollback = "rollback" // Skip the remaining calls and compensate the calls that succeeded, newest first
+)
+
+// onErrorMessage describes the valid on_error modes
+var onErrorMessage = fmt.Sprintf("must be '%s', '%s', or '%s'", batchContinue, batchStop, batchRollback)
+
+// Outcomes of batch items
+const (
+	batchSucceeded   = "succeeded"
+	batchFailed      = "failed"
+	batchSkipped     = "skipped"
+	batchCompensated = "compensated"
+)
+
+// BatchRequest is a list of calls made in order. A string parameter such as
+// "$items[0].data.project_id" is replaced by that part of an earlier item's result.
+type BatchRequest struct {
+	Items   []BatchItem `json:"items"`
+	OnError string      `json:"on_error"` // continue (default), stop, or rollback
+}
+
+// BatchItem is a call of a batch. Its compensation undoes the call during a rollback, e.g.
+// a delete for a create, and may reference the item's own result.
+type BatchItem struct {
+	storage.Request
+	OnError    string           `json:"on_error,omitempty"` // Overrides the batch's on_error when this call fails
+	Compensate *storage.Request `json:"compensate,omitempty"`
+}
+
+// BatchCompensation is the outcome of an item's compensation call
+type BatchCompensation struct {
+	Success bool                `json:"success"`
+	Data    json.RawMessage     `json:"data,omitempty"`
+	Error   *response.ErrorInfo `json:"error,omitempty"`
+}
+
+// BatchItemResult is the outcome of one call of a batch
+type BatchItemResult struct {
+	Index        int                 `json:"index"`
+	Status       string              `json:"status"` // succeeded, failed, skipped, or compensated
+	Success      bool                `json:"success"`
+	Data         json.RawMessage     `json:"data,omitempty"`
+	Error        *response.ErrorInfo `json:"error,omitempty"`
+	Compensation *BatchCompensation  `json:"compensation,omitempty"` // Set when a rollback ran the item's compensation
+}
+
+// BatchResult is the outcome of every call of a batch, in order
+type BatchResult struct {
+	Results     []BatchItemResult `json:"results"`
+	Succeeded   int               `json:"succeeded"`
+	Failed      int               `json:"failed"`
+	Skipped     int               `json:"skipped"`
+	Compensated int               `json:"compensated"`
+	StoppedAt   *int              `json:"stopped_at,omitempty"` // Index of the failed call that stopped the batch
+	RolledBack  bool              `json:"rolled_back"`
+}
+
+// CallBatch makes the calls of a batch one after another. By default a failed call does not
+// stop the others, but items whose parameters reference it fail without being called. With
+// on_error stop or rollback, a failed call skips the rest of the batch, and rollback also runs
+// the compensation of every call that succeeded, newest first.
+func (h *Handler) CallBatch(c echo.Context) error {
+	var req BatchRequest
+	if err := c.Bind(&req); err != nil {
+		if apiErr := bodyTooLarge(err); apiErr != nil {
+			return response.APIError(c, apiErr)
+		}
+		return response.BadRequest(c, "Invalid request body", err.Error())
+	}
+	if len(req.Items) == 0 || len(req.Items) > maxBatchItems {
+		return response.BadRequest(c, "Invalid batch", fmt.Sprintf("items must have between 1 and %d calls", maxBatchItems))
+	}
+	if req.OnError == "" {
+		req.OnError = batchContinue
+	}
+	if fields := validateBatch(&req); len(fields) > 0 {
+		return response.APIError(c, errors.NewValidationError("batch", fields))
+	}
+
+	ctx := grpc.WithRequestID(c.Request().Context(), c.Response().Header().Get(echo.HeaderXRequestID))
+	language := response.Language(c)
+	result := &BatchResult{Results: make([]BatchItemResult, 0, len(req.Items))}
+	done := make([]interface{}, 0, len(req.Items)) // Decoded results that references resolve against
+	onError := ""
+	for i := range req.Items {
+		item := &req.Items[i]
+		if result.StoppedAt != nil {
+			result.Results = append(result.Results, BatchItemResult{Index: i, Status: batchSkipped})
+			result.Skipped++
+			continue
+		}
+
+		jsonBytes, apiErr := h.callBatchItem(ctx, &item.Request, done)
+		itemResult := BatchItemResult{Index: i, Status: batchSucceeded, Success: apiErr == nil, Data: jsonBytes}
+		decoded := map[string]interface{}{"success": apiErr == nil}
+		if apiErr != nil {
+			itemResult.Status = batchFailed
+			itemResult.Error = response.NewErrorInfo(apiErr.Localize(language))
+			result.Failed++
+			onError = req.OnError
+			if item.OnError != "" {
+				onError = item.OnError
+			}
+			if onError != batchContinue {
+				result.StoppedAt = &i
+			}
+		} else {
+			decoded["data"] = decodeNumbers(jsonBytes)
+			result.Succeeded++
+		}
+		result.Results = append(result.Results, itemResult)
+		done = append(done, decoded)
+	}
+
+	if result.StoppedAt != nil && onError == batchRollback {
+		result.RolledBack = true
+		h.compensateBatch(ctx, req.Items, done, result, language)
+	}
+	return response.Success(c, result)
+}
+
+// compensateBatch runs the compensation of each call that succeeded, newest first. Rollbacks
+// finish even when the client disconnects, so that they do not leave half of the changes.
+func (h *Handler) compensateBatch(ctx context.Context, items []BatchItem, done []interface{}, result *BatchResult, language string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), compensateTimeout)
+	defer cancel()
+	for i := len(done) - 1; i >= 0; i-- {
+		itemResult := &result.Results[i]
+		if itemResult.Status != batchSucceeded || items[i].Compensate == nil {
+			continue
+		}
+		jsonBytes, apiErr := h.callBatchItem(ctx, items[i].Compensate, done)
+		compensation := &BatchCompensation{Success: apiErr == nil, Data: jsonBytes}
+		if apiErr != nil {
+			compensation.Error = response.NewErrorInfo(apiErr.Localize(language))
+		} else {
+			itemResult.Status = batchCompensated
+			result.Compensated++
+		}
+		itemResult.Compensation = compensation
+	}
+}
+
+// callBatchItem resolves a call's references to earlier results and makes it
+func (h *Handler) callBatchItem(ctx context.Context, item *storage.Request, done []interface{}) ([]byte, *errors.APIError) {
+	resolved, err := resolveItemReferences(item.Parameters, done)
+	if err != nil {
+		return nil, errors.NewAPIError(errors.ErrInvalidParameters, err.Error())
+	}
+	parameters, _ := resolved.(map[string]interface{})
+	if parameters == nil {
+		parameters = make(map[string]interface{})
+	}
+	return h.invoke(ctx, item.Service, item.Resource, item.Verb, parameters)
+}
+
+// validateBatch reports items with missing fields, unknown on_error modes, and references to
+// items that do not come before them. Compensations may also reference their own item.
+func validateBatch(req *BatchRequest) []errors.FieldError {
+	var fields []errors.FieldError
+	if !validOnError(req.OnError) {
+		fields = append(fields, errors.FieldError{Field: "on_error", Message: onErrorMessage})
+	}
+	for i := range req.Items {
+		item := &req.Items[i]
+		prefix := fmt.Sprintf("items[%d].", i)
+		fields = append(fields, item.Validate(prefix)...)
+		fields = append(fields, validateItemReferences(item.Parameters, i, prefix+"parameters")...)
+		if item.OnError != "" && !validOnError(item.OnError) {
+			fields = append(fields, errors.FieldError{Field: prefix + "on_error", Message: onErrorMessage})
+		}
+		if item.Compensate != nil {
+			fields = append(fields, item.Compensate.Validate(prefix+"compensate.")...)
+			fields = append(fields, validateItemReferences(item.Compensate.Parameters, i+1, prefix+"compensate.parameters")...)
+		}
+	}
+	return fields
+}
+
+// validOnError reports whether a mode is a valid on_error value
+func validOnError(mode string) bool {
+	return mode == batchContinue || mode == batchStop || mode == batchRollback
+}
+
+// validateItemReferences reports references in parameters that are invalid or do not point
+// before limit
+func validateItemReferences(parameters map[string]interface{}, limit int, field string) []errors.FieldError {
+	var fields []errors.FieldError
+	for _, reference := range itemReferences(parameters) {
+		index, _, err := parseItemReference(reference)
+		switch {
+		case err != nil:
+			fields = append(fields, errors.FieldError{Field: field, Message: err.Error()})
+		case index >= limit:
+			fields = append(fields, errors.FieldError{Field: field, Message: fmt.Sprintf("%s must reference an earlier item", reference)})
+		}
+	}
+	return fields
+}
+
+// itemReferences returns the strings of a parameter value that reference earlier items
+func itemReferences(value interface{}) []string {
+	var found []string
+	switch v := value.(type) {
+	case string:
+		if strings.HasPrefix(v, itemReferencePrefix) {
+			found = append(found, v)
+		}
+	case map[string]interface{}:
+		for _, item := range v {
+			found = append(found, itemReferences(item)...)
+		}
+	case []interface{}:
+		for _, item := range v {
+			found = append(found, itemReferences(item)...)
+		}
+	}
+	return found
+}
+
+// parseItemReference returns the item index of a reference such as $items[0].data.project_id,
+// and the JSONPath expression selecting the value from the list of results
+func parseItemReference(reference string) (int, string, error) {
+	rest := strings.TrimPrefix(reference, itemReferencePrefix)
+	end := strings.Index(rest, "]")
+	if end < 0 {
+		return 0, "", fmt.Errorf("%s has an unclosed [", reference)
+	}
+	index, err := strconv.Atoi(rest[:end])
+	if err != nil || index < 0 {
+		return 0, "", fmt.Errorf("%s must start with an item index, e.g. $items[0]", reference)
+	}
+	return index, "$" + strings.TrimPrefix(reference, "$items"), nil
+}
+
+// resolveItemReferences replaces the references in a parameter value with the values they
+// select from the results of earlier items
+func resolveItemReferences(value interface{}, done []interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		if !strings.HasPrefix(v, itemReferencePrefix) {
+			return v, nil
+		}
+		index, path, err := parseItemReference(v)
+		if err != nil {
+			return nil, err
+		}
+		if index >= len(done) {
+			return nil, fmt.Errorf("%s must reference an earlier item", v)
+		}
+		if succeeded, _ := done[index].(map[string]interface{})["success"].(bool); !succeeded {
+			return nil, fmt.Errorf("%s references item %d, which failed", v, index)
+		}
+		resolved, err := workflow.Extract(done, path)
+		if err != nil {
+			return nil, fmt.Errorf("%s matched nothing", v)
+		}
+		return resolved, nil
+	case map[string]interface{}:
+		resolved := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			r, err := resolveItemReferences(item, done)
+			if err != nil {
+				return nil, err
+			}
+			resolved[key] = r
+		}
+		return resolved, nil
+	case []interface{}:
+		resolved := make([]interface{}, len(v))
+		for i, item := range v {
+			r, err := resolveItemReferences(item, done)
+			if err != nil {
+				return nil, err
+			}
+			resolved[i] = r
+		}
+		return resolved, nil
+	}
+	return value, nil
+}
+
+// decodeNumbers decodes a JSON response, keeping numbers such as large IDs exact
+func decodeNumbers(jsonBytes []byte) interface{} {
+	decoder := json.NewDecoder(bytes.NewReader(jsonBytes))
+	decoder.UseNumber()
+	var value interface{}
+	_ = decoder.Decode(&value)
+	return value
+}
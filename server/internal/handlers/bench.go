@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"spacectl-web/server/internal/grpc"
+	"spacectl-web/server/internal/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Benchmark limits to keep a single request from overwhelming the upstream service
+const (
+	maxBenchCount       = 1000
+	maxBenchConcurrency = 50
+)
+
+// BenchRequest represents a request to invoke a verb repeatedly
+type BenchRequest struct {
+	Service     string                 `json:"service"`
+	Resource    string                 `json:"resource"`
+	Verb        string                 `json:"verb"`
+	Parameters  map[string]interface{} `json:"parameters"`
+	Count       int                    `json:"count"`
+	Concurrency int                    `json:"concurrency"`
+}
+
+// BenchResult represents latency percentiles and error counts of a benchmark run
+type BenchResult struct {
+	Count             int            `json:"count"`
+	Concurrency       int            `json:"concurrency"`
+	Successes         int            `json:"successes"`
+	Errors            int            `json:"errors"`
+	ErrorMessages     map[string]int `json:"error_messages,omitempty"`
+	TotalDurationMs   float64        `json:"total_duration_ms"`
+	RequestsPerSecond float64        `json:"requests_per_second"`
+	MinMs             float64        `json:"min_ms"`
+	P50Ms             float64        `json:"p50_ms"`
+	P90Ms             float64        `json:"p90_ms"`
+	P95Ms             float64        `json:"p95_ms"`
+	P99Ms             float64        `json:"p99_ms"`
+	MaxMs             float64        `json:"max_ms"`
+}
+
+// Bench invokes a verb N times with the given concurrency and reports latency percentiles
+func (h *Handler) Bench(c echo.Context) error {
+	var req BenchRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", err.Error())
+	}
+
+	if req.Count <= 0 || req.Count > maxBenchCount {
+		return response.BadRequest(c, "Invalid count", fmt.Sprintf("count must be between 1 and %d", maxBenchCount))
+	}
+	if req.Concurrency <= 0 {
+		req.Concurrency = 1
+	}
+	if req.Concurrency > maxBenchConcurrency {
+		return response.BadRequest(c, "Invalid concurrency", fmt.Sprintf("concurrency must be between 1 and %d", maxBenchConcurrency))
+	}
+
+	if err := h.validateRequest(req.Service, req.Resource, req.Verb); err != nil {
+		return response.APIError(c, err)
+	}
+
+	serviceCaller, err := h.grpcManager.GetServiceCaller(req.Service)
+	if err != nil {
+		apiErr := callerError(err)
+		return response.APIError(c, apiErr)
+	}
+
+	ctx := grpc.WithRequestID(c.Request().Context(), c.Response().Header().Get(echo.HeaderXRequestID))
+	return response.Success(c, runBench(ctx, serviceCaller, &req))
+}
+
+// runBench executes the benchmark and aggregates the results
+func runBench(ctx context.Context, serviceCaller *grpc.ServiceCaller, req *BenchRequest) *BenchResult {
+	latencies := make([]time.Duration, req.Count)
+	errs := make([]error, req.Count)
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	start := time.Now()
+
+	for w := 0; w < req.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				callStart := time.Now()
+				_, errs[i] = serviceCaller.CallMethod(ctx, req.Service, req.Resource, req.Verb, req.Parameters)
+				latencies[i] = time.Since(callStart)
+			}
+		}()
+	}
+
+	for i := 0; i < req.Count; i++ {
+		if ctx.Err() != nil {
+			errs[i] = ctx.Err()
+			continue
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	total := time.Since(start)
+	result := &BenchResult{
+		Count:             req.Count,
+		Concurrency:       req.Concurrency,
+		ErrorMessages:     make(map[string]int),
+		TotalDurationMs:   toMs(total),
+		RequestsPerSecond: float64(req.Count) / total.Seconds(),
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			result.Errors++
+			result.ErrorMessages[err.Error()]++
+		} else {
+			result.Successes++
+		}
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	result.MinMs = toMs(latencies[0])
+	result.P50Ms = toMs(percentile(latencies, 50))
+	result.P90Ms = toMs(percentile(latencies, 90))
+	result.P95Ms = toMs(percentile(latencies, 95))
+	result.P99Ms = toMs(percentile(latencies, 99))
+	result.MaxMs = toMs(latencies[len(latencies)-1])
+
+	return result
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// toMs converts a duration to fractional milliseconds
+func toMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"spacectl-web/server/internal/config"
+	"spacectl-web/server/internal/errors"
+	"spacectl-web/server/internal/middleware"
+
+	"github.com/labstack/echo/v4"
+)
+
+// detailsQueryParam makes the service list return presentation details instead of names
+const detailsQueryParam = "details"
+
+// showHiddenQueryParam makes discovery include hidden services and resources for admins
+const showHiddenQueryParam = "show_hidden"
+
+// ServiceSummary represents a service with its presentation from server.catalog
+type ServiceSummary struct {
+	Name        string   `json:"name"`
+	Alias       string   `json:"alias,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Hidden      bool     `json:"hidden,omitempty"` // Only listed with ?show_hidden=true
+}
+
+// serviceSummaries returns the services with their presentation
+func serviceSummaries(catalog config.CatalogConfig, services []string) []ServiceSummary {
+	summaries := make([]ServiceSummary, 0, len(services))
+	for _, service := range services {
+		display := catalog.Service(service)
+		summaries = append(summaries, ServiceSummary{
+			Name:        service,
+			Alias:       display.Alias,
+			Description: display.Description,
+			Tags:        display.Tags,
+			Hidden:      display.Hidden,
+		})
+	}
+	return summaries
+}
+
+// addDisplay adds the configured alias, description and tags to a resource entry
+func addDisplay(entry map[string]interface{}, display config.DisplayConfig) {
+	if display.Alias != "" {
+		entry["Alias"] = display.Alias
+	}
+	if display.Description != "" {
+		entry["Description"] = display.Description
+	}
+	if len(display.Tags) > 0 {
+		entry["Tags"] = display.Tags
+	}
+	if display.Hidden {
+		entry["Hidden"] = true
+	}
+}
+
+// showHidden reports whether discovery should include hidden services and resources.
+// Revealing them requires the admin token, so asking without it is an error.
+func (h *Handler) showHidden(c echo.Context) (bool, *errors.APIError) {
+	show, _ := strconv.ParseBool(c.QueryParam(showHiddenQueryParam))
+	if !show {
+		return false, nil
+	}
+	admin := h.config.Server.Admin
+	if !admin.Enabled || !middleware.HasAdminToken(c.Request(), admin.Token) {
+		c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="admin"`)
+		return false, &errors.APIError{
+			Code:    http.StatusUnauthorized,
+			Message: "Admin token required",
+			Details: "send the server.admin.token value as a bearer token to show hidden services",
+		}
+	}
+	return true, nil
+}
+
+// visibleServices returns the services that are not hidden, or every service when showHidden is set
+func visibleServices(catalog config.CatalogConfig, services []string, showHidden bool) []string {
+	if showHidden {
+		return services
+	}
+	visible := make([]string, 0, len(services))
+	for _, service := range services {
+		if !catalog.ServiceHidden(service) {
+			visible = append(visible, service)
+		}
+	}
+	return visible
+}
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"spacectl-web/server/internal/config"
+	"spacectl-web/server/internal/errors"
+	"spacectl-web/server/internal/grant"
+	"spacectl-web/server/internal/grpc"
+	"spacectl-web/server/internal/response"
+	"spacectl-web/server/internal/scheduler"
+	"spacectl-web/server/internal/storage"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CompareRequest selects the profiles a saved request is compared across
+type CompareRequest struct {
+	Profiles []string `json:"profiles"`            // Exactly two profile names, e.g. the source and target of a migration
+	KeyField string   `json:"key_field,omitempty"` // Matches results across profiles; defaults to e.g. server_id
+}
+
+// EnvironmentResult is the outcome of the request in one profile
+type EnvironmentResult struct {
+	Profile    string              `json:"profile"`
+	Count      int                 `json:"count"`                 // Results with a key field
+	TotalCount *int64              `json:"total_count,omitempty"` // Results matching the query, when the verb reports it
+	Error      *response.ErrorInfo `json:"error,omitempty"`
+}
+
+// KeyDifference is a result found in both profiles with different values
+type KeyDifference struct {
+	Key    string   `json:"key"`
+	Fields []string `json:"fields"` // Top-level fields whose values differ, including fields only one side has
+}
+
+// ComparisonReport compares the results of a saved request in two profiles by key
+type ComparisonReport struct {
+	Name      string            `json:"name"`
+	Request   storage.Request   `json:"request"`
+	KeyField  string            `json:"key_field,omitempty"`
+	Left      EnvironmentResult `json:"left"`
+	Right     EnvironmentResult `json:"right"`
+	Compared  bool              `json:"compared"` // False when either profile failed, leaving the lists below empty
+	OnlyLeft  []string          `json:"only_in_left"`
+	OnlyRight []string          `json:"only_in_right"`
+	Different []KeyDifference   `json:"different"`
+	Identical int               `json:"identical"`
+}
+
+// CompareFavorite runs a saved request against two profiles, such as the source and target
+// domains of a migration, and reports which results are missing from either and which differ.
+// Only verbs that read data are compared, and the request is validated like API calls. Each
+// profile is called with its own connections, kept between comparisons, so the active profile
+// is not switched.
+func (h *Handler) CompareFavorite(c echo.Context) error {
+	record, err := h.ownedRecord(c, storage.KindFavorite)
+	if err != nil {
+		return storageError(c, storage.KindFavorite, err)
+	}
+	var favorite storage.Favorite
+	if err := json.Unmarshal(record.Data, &favorite); err != nil {
+		return response.InternalServerError(c, "Invalid favorite", err.Error())
+	}
+	request := &favorite.Request
+	if !config.IsReadVerb(request.Verb) {
+		return response.APIError(c, errors.NewAPIError(errors.ErrVerbNotSupported,
+			fmt.Sprintf("comparisons only run verbs that read data; verb '%s' may change data", request.Verb)))
+	}
+	if apiErr := h.validateRequest(request.Service, request.Resource, request.Verb); apiErr != nil {
+		return response.APIError(c, apiErr)
+	}
+
+	var req CompareRequest
+	if err := c.Bind(&req); err != nil {
+		if apiErr := bodyTooLarge(err); apiErr != nil {
+			return response.APIError(c, apiErr)
+		}
+		return response.BadRequest(c, "Invalid request body", err.Error())
+	}
+	if fields := h.validateCompare(&req); len(fields) > 0 {
+		return response.APIError(c, errors.NewValidationError("comparison", fields))
+	}
+
+	report := &ComparisonReport{
+		Name:      favorite.Name,
+		Request:   favorite.Request,
+		KeyField:  req.KeyField,
+		OnlyLeft:  []string{},
+		OnlyRight: []string{},
+		Different: []KeyDifference{},
+	}
+	ctx := grpc.WithRequestID(c.Request().Context(), c.Response().Header().Get(echo.HeaderXRequestID))
+	language := response.Language(c)
+	results := make([]map[string]json.RawMessage, 2)
+	environments := []*EnvironmentResult{&report.Left, &report.Right}
+	var wg sync.WaitGroup
+	for i, profile := range req.Profiles {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			environments[i].Profile = profile
+			results[i] = h.runInProfile(ctx, profile, &favorite.Request, req.KeyField, environments[i], language)
+		}()
+	}
+	wg.Wait()
+	if results[0] == nil || results[1] == nil {
+		return response.Success(c, report)
+	}
+
+	report.Compared = true
+	diff := scheduler.Diff(results[0], results[1])
+	report.OnlyRight = diff.Added
+	report.OnlyLeft = diff.Removed
+	for _, key := range diff.Modified {
+		report.Different = append(report.Different, KeyDifference{Key: key, Fields: differentFields(results[0][key], results[1][key])})
+	}
+	report.Identical = len(results[0]) - len(report.OnlyLeft) - len(report.Different)
+	return response.Success(c, report)
+}
+
+// validateCompare reports the invalid fields of a comparison request
+func (h *Handler) validateCompare(req *CompareRequest) []errors.FieldError {
+	if len(req.Profiles) != 2 {
+		return []errors.FieldError{{Field: "profiles", Message: "must name exactly two profiles"}}
+	}
+	var fields []errors.FieldError
+	for i, profile := range req.Profiles {
+		if _, exists := h.config.Profiles[profile]; !exists {
+			fields = append(fields, errors.FieldError{Field: fmt.Sprintf("profiles[%d]", i), Message: fmt.Sprintf("profile '%s' not found", profile)})
+		}
+	}
+	if len(fields) == 0 && req.Profiles[0] == req.Profiles[1] {
+		fields = append(fields, errors.FieldError{Field: "profiles", Message: "must name two different profiles"})
+	}
+	return fields
+}
+
+// runInProfile makes the request with the profile's token and endpoints and returns its results
+// by key, or nil after setting the error of the environment
+func (h *Handler) runInProfile(ctx context.Context, profile string, request *storage.Request, keyField string,
+	environment *EnvironmentResult, language string) map[string]json.RawMessage {
+	fail := func(apiErr *errors.APIError) map[string]json.RawMessage {
+		environment.Error = response.NewErrorInfo(apiErr.Localize(language))
+		return nil
+	}
+
+	manager, apiErr := h.profileManager(ctx, profile)
+	if apiErr != nil {
+		return fail(apiErr)
+	}
+	caller, err := manager.GetServiceCaller(request.Service)
+	if err != nil {
+		return fail(callerError(err))
+	}
+	parameters := request.Parameters
+	if parameters == nil {
+		parameters = make(map[string]interface{})
+	}
+	jsonBytes, err := caller.CallMethod(ctx, request.Service, request.Resource, request.Verb, parameters)
+	if err != nil {
+		return fail(toAPIError(err))
+	}
+
+	items, err := scheduler.KeyedResults(jsonBytes, request.Resource, keyField)
+	if err != nil {
+		return fail(errors.NewAPIError(errors.ErrResponseConversionFailed, err.Error()))
+	}
+	environment.Count = len(items)
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(jsonBytes, &fields) == nil {
+		if total, found, err := totalCountField(fields); err == nil && found {
+			environment.TotalCount = &total
+		}
+	}
+	return items
+}
+
+// profileManager returns the client manager of a profile, creating it on first use. Its
+// connections are kept, and a token granted with the profile's app token is refreshed in the
+// background, so comparisons do not redial and re-grant every time.
+func (h *Handler) profileManager(ctx context.Context, profile string) (*grpc.ClientManager, *errors.APIError) {
+	h.profileMutex.Lock()
+	defer h.profileMutex.Unlock()
+	if manager, found := h.profileManagers[profile]; found {
+		return manager, nil
+	}
+
+	cfg, err := h.config.ForProfile(profile)
+	if err != nil {
+		return nil, errors.NewAPIError(errors.ErrInvalidParameters, err.Error())
+	}
+	pool := grpc.NewConnectionPool(cfg)
+	manager := grpc.NewClientManager(cfg, grpc.NewServiceDiscovery(cfg, pool), pool)
+	if cfg.GetGrant().Enabled() {
+		granter := grant.New(cfg, manager)
+		if err := granter.Grant(ctx); err != nil {
+			pool.Close()
+			return nil, errors.NewAPIError(errors.ErrGRPCClientFailed, err.Error())
+		}
+		go granter.Run(context.Background())
+	}
+	if h.profileManagers == nil {
+		h.profileManagers = make(map[string]*grpc.ClientManager)
+	}
+	h.profileManagers[profile] = manager
+	return manager, nil
+}
+
+// differentFields returns the top-level fields whose values differ between two results
+func differentFields(left, right json.RawMessage) []string {
+	var leftFields, rightFields map[string]json.RawMessage
+	_ = json.Unmarshal(left, &leftFields)
+	_ = json.Unmarshal(right, &rightFields)
+	fields := []string{}
+	for name, value := range leftFields {
+		if other, found := rightFields[name]; !found || !bytes.Equal(value, other) {
+			fields = append(fields, name)
+		}
+	}
+	for name := range rightFields {
+		if _, found := leftFields[name]; !found {
+			fields = append(fields, name)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"spacectl-web/server/internal/errors"
+	"spacectl-web/server/internal/grpc"
+	"spacectl-web/server/internal/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Connect protocol headers
+const (
+	connectTimeoutHeader  = "Connect-Timeout-Ms"
+	connectAcceptEncoding = "Connect-Accept-Encoding"
+)
+
+// connectHTTPStatus maps Connect error codes to the HTTP statuses the protocol sends them with
+var connectHTTPStatus = map[string]int{
+	"canceled":            499,
+	"unknown":             http.StatusInternalServerError,
+	"invalid_argument":    http.StatusBadRequest,
+	"deadline_exceeded":   http.StatusGatewayTimeout,
+	"not_found":           http.StatusNotFound,
+	"already_exists":      http.StatusConflict,
+	"permission_denied":   http.StatusForbidden,
+	"resource_exhausted":  http.StatusTooManyRequests,
+	"failed_precondition": http.StatusBadRequest,
+	"aborted":             http.StatusConflict,
+	"out_of_range":        http.StatusBadRequest,
+	"unimplemented":       http.StatusNotImplemented,
+	"internal":            http.StatusInternalServerError,
+	"unavailable":         http.StatusServiceUnavailable,
+	"data_loss":           http.StatusInternalServerError,
+	"unauthenticated":     http.StatusUnauthorized,
+}
+
+// connectCodesByStatus maps the HTTP status of errors raised before reaching upstream to Connect error codes
+var connectCodesByStatus = map[int]string{
+	http.StatusBadRequest:            "invalid_argument",
+	http.StatusUnauthorized:          "unauthenticated",
+	http.StatusForbidden:             "permission_denied",
+	http.StatusNotFound:              "not_found",
+	http.StatusRequestEntityTooLarge: "resource_exhausted",
+	http.StatusTooManyRequests:       "resource_exhausted",
+	http.StatusNotImplemented:        "unimplemented",
+	http.StatusServiceUnavailable:    "unavailable",
+	http.StatusGatewayTimeout:        "deadline_exceeded",
+}
+
+// connectError is the body of a failed Connect unary call
+type connectError struct {
+	Code    string `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// CallConnect serves a unary call of the Connect protocol with the JSON codec. Procedures are
+// addressed as /<service>/<package.Service>/<Method>, so a connect-es transport created with
+// the base URL /api/connect/<service> reaches the service's endpoint.
+func (h *Handler) CallConnect(c echo.Context) error {
+	serviceName := c.Param("service")
+	procedure := c.Param("procedure")
+	verb := c.Param("method")
+
+	// Only the unary JSON codec is supported; streaming and binary requests are refused
+	contentType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	if contentType != echo.MIMEApplicationJSON {
+		c.Response().Header().Set("Accept-Post", echo.MIMEApplicationJSON)
+		return c.NoContent(http.StatusUnsupportedMediaType)
+	}
+	if encoding := c.Request().Header.Get(echo.HeaderContentEncoding); encoding != "" && encoding != "identity" {
+		c.Response().Header().Set(connectAcceptEncoding, "identity")
+		return sendConnectError(c, "unimplemented", fmt.Sprintf("content encoding '%s' is not supported", encoding))
+	}
+
+	resourceName, apiErr := h.connectResource(serviceName, procedure)
+	if apiErr != nil {
+		return sendConnectAPIError(c, apiErr)
+	}
+
+	parameters := make(map[string]interface{})
+	if err := json.NewDecoder(c.Request().Body).Decode(&parameters); err != nil && !stderrors.Is(err, io.EOF) {
+		if apiErr := bodyTooLarge(err); apiErr != nil {
+			return sendConnectAPIError(c, apiErr)
+		}
+		return sendConnectError(c, "invalid_argument", fmt.Sprintf("invalid request body: %v", err))
+	}
+
+	ctx := grpc.WithRequestID(c.Request().Context(), c.Response().Header().Get(echo.HeaderXRequestID))
+	if timeout := c.Request().Header.Get(connectTimeoutHeader); timeout != "" {
+		milliseconds, err := strconv.ParseInt(timeout, 10, 64)
+		if err != nil || milliseconds <= 0 {
+			return sendConnectError(c, "invalid_argument", fmt.Sprintf("invalid %s header '%s'", connectTimeoutHeader, timeout))
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(milliseconds)*time.Millisecond)
+		defer cancel()
+	}
+
+	jsonBytes, apiErr := h.invoke(ctx, serviceName, resourceName, verb, parameters)
+	if apiErr != nil {
+		return sendConnectAPIError(c, apiErr)
+	}
+	return c.Blob(http.StatusOK, echo.MIMEApplicationJSON, jsonBytes)
+}
+
+// connectResource returns the resource served by a fully-qualified gRPC service of an endpoint
+func (h *Handler) connectResource(serviceName, procedure string) (string, *errors.APIError) {
+	if resourceName, wellKnown := grpc.WellKnownResourceName(procedure); wellKnown {
+		return resourceName, nil
+	}
+	serviceInfo, err := h.serviceDiscovery.GetServiceInfo(serviceName)
+	if err != nil {
+		return "", discoveryError(serviceName, err)
+	}
+	for _, resource := range serviceInfo.Resources {
+		if resource.ServiceName == procedure {
+			return resource.Name, nil
+		}
+	}
+	return "", errors.NewAPIError(errors.ErrResourceNotFound, fmt.Sprintf("service '%s' does not serve %s", serviceName, procedure))
+}
+
+// sendConnectAPIError sends an API error as a Connect error, using the upstream gRPC code when known
+func sendConnectAPIError(c echo.Context, apiErr *errors.APIError) error {
+	code := strings.ToLower(apiErr.GRPCCode)
+	if code == "cancelled" {
+		code = "canceled"
+	}
+	if _, known := connectHTTPStatus[code]; !known {
+		code = connectCodesByStatus[apiErr.Code]
+	}
+	if code == "" {
+		code = "internal"
+	}
+	return sendConnectError(c, code, apiErr.Localize(response.Language(c)).Error())
+}
+
+// sendConnectError sends a Connect error with the HTTP status of its code
+func sendConnectError(c echo.Context, code, message string) error {
+	return c.JSON(connectHTTPStatus[code], connectError{Code: code, Message: message})
+}
@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"spacectl-web/server/internal/grpc"
+	"spacectl-web/server/internal/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// costAnalysisService is the service whose analyze verbs the cost helpers wrap
+const costAnalysisService = "cost_analysis"
+
+// Granularities of cost analysis
+const (
+	granularityDaily   = "DAILY"
+	granularityMonthly = "MONTHLY"
+	granularityYearly  = "YEARLY"
+)
+
+// granularityLayouts are the date formats of each granularity
+var granularityLayouts = map[string]string{
+	granularityDaily:   "2006-01-02",
+	granularityMonthly: "2006-01",
+	granularityYearly:  "2006",
+}
+
+// granularityFormats describe the date formats of each granularity in errors
+var granularityFormats = map[string]string{
+	granularityDaily:   "YYYY-MM-DD",
+	granularityMonthly: "YYYY-MM",
+	granularityYearly:  "YYYY",
+}
+
+// maxSeriesPoints bounds the dates of a series, so a mistyped range cannot produce a huge response
+const maxSeriesPoints = 1000
+
+// CostSeriesRequest selects costs to return as chart series. Filters use the SpaceONE
+// filter format, e.g. {"k": "provider", "v": "aws", "o": "eq"}.
+type CostSeriesRequest struct {
+	Resource     string        `json:"resource"` // Resource with an analyze verb; defaults to Cost
+	DataSourceID string        `json:"data_source_id"`
+	Granularity  string        `json:"granularity"` // DAILY, MONTHLY (default), or YEARLY
+	Start        string        `json:"start"`       // In the granularity's format, e.g. 2024-01 for MONTHLY
+	End          string        `json:"end"`         // Inclusive; defaults to the current period
+	GroupBy      []string      `json:"group_by"`
+	Field        string        `json:"field"` // Summed field; defaults to cost
+	Filter       []interface{} `json:"filter"`
+}
+
+// CostSeries is one group's values, aligned with the dates of the result
+type CostSeries struct {
+	Name   string            `json:"name"`  // Group values joined with " / ", or Total without group_by
+	Group  map[string]string `json:"group"` // Value of each group_by field
+	Values []float64         `json:"values"`
+	Total  float64           `json:"total"`
+}
+
+// CostSeriesResult is the costs of a date range, ready for a chart
+type CostSeriesResult struct {
+	Granularity string       `json:"granularity"`
+	Start       string       `json:"start"`
+	End         string       `json:"end"`
+	Dates       []string     `json:"dates"`
+	Series      []CostSeries `json:"series"` // Largest total first
+	Total       float64      `json:"total"`
+}
+
+// AnalyzeCostSeries builds a cost_analysis analyze query from simplified parameters and
+// returns the results as series with a value for every date of the range
+func (h *Handler) AnalyzeCostSeries(c echo.Context) error {
+	var req CostSeriesRequest
+	if err := c.Bind(&req); err != nil {
+		if apiErr := bodyTooLarge(err); apiErr != nil {
+			return response.APIError(c, apiErr)
+		}
+		return response.BadRequest(c, "Invalid request body", err.Error())
+	}
+	if req.Resource == "" {
+		req.Resource = "Cost"
+	}
+	if req.Field == "" {
+		req.Field = "cost"
+	}
+	req.Granularity = strings.ToUpper(req.Granularity)
+	if req.Granularity == "" {
+		req.Granularity = granularityMonthly
+	}
+	dates, err := seriesDates(&req, time.Now())
+	if err != nil {
+		return response.BadRequest(c, "Invalid cost query", err.Error())
+	}
+
+	query := map[string]interface{}{
+		"granularity": req.Granularity,
+		"start":       req.Start,
+		"end":         req.End,
+		"group_by":    req.GroupBy,
+		"fields":      map[string]interface{}{"value": map[string]interface{}{"key": req.Field, "operator": "sum"}},
+	}
+	if len(req.Filter) > 0 {
+		query["filter"] = req.Filter
+	}
+	parameters := map[string]interface{}{"query": query}
+	if req.DataSourceID != "" {
+		parameters["data_source_id"] = req.DataSourceID
+	}
+
+	ctx := grpc.WithRequestID(c.Request().Context(), c.Response().Header().Get(echo.HeaderXRequestID))
+	jsonBytes, apiErr := h.invoke(ctx, costAnalysisService, req.Resource, "analyze", parameters)
+	if apiErr != nil {
+		return response.APIError(c, apiErr)
+	}
+	result, err := costSeries(jsonBytes, &req, dates)
+	if err != nil {
+		return response.InternalServerError(c, "Invalid response", err.Error())
+	}
+	return response.Success(c, result)
+}
+
+// seriesDates validates the range of the request, filling in defaults, and returns every
+// date in it. Without a start, the range covers the last 30 days, 6 months, or 3 years.
+func seriesDates(req *CostSeriesRequest, now time.Time) ([]string, error) {
+	layout, ok := granularityLayouts[req.Granularity]
+	if !ok {
+		return nil, fmt.Errorf("granularity must be %s, %s, or %s", granularityDaily, granularityMonthly, granularityYearly)
+	}
+	step := func(t time.Time, n int) time.Time {
+		switch req.Granularity {
+		case granularityDaily:
+			return t.AddDate(0, 0, n)
+		case granularityMonthly:
+			return t.AddDate(0, n, 0)
+		}
+		return t.AddDate(n, 0, 0)
+	}
+
+	end := now
+	if req.End != "" {
+		parsed, err := time.Parse(layout, req.End)
+		if err != nil {
+			return nil, fmt.Errorf("end must have the format %s for %s granularity", granularityFormats[req.Granularity], req.Granularity)
+		}
+		end = parsed
+	}
+	// Drop the parts finer than the granularity, so that stepping months back from the 31st works
+	end, _ = time.Parse(layout, end.Format(layout))
+	var start time.Time
+	if req.Start != "" {
+		parsed, err := time.Parse(layout, req.Start)
+		if err != nil {
+			return nil, fmt.Errorf("start must have the format %s for %s granularity", granularityFormats[req.Granularity], req.Granularity)
+		}
+		start = parsed
+	} else {
+		periods := map[string]int{granularityDaily: 30, granularityMonthly: 6, granularityYearly: 3}[req.Granularity]
+		start = step(end, 1-periods)
+	}
+	req.Start, req.End = start.Format(layout), end.Format(layout)
+	if end.Before(start) {
+		return nil, fmt.Errorf("end %s is before start %s", req.End, req.Start)
+	}
+	var dates []string
+	for t := start; !t.After(end); t = step(t, 1) {
+		if len(dates) == maxSeriesPoints {
+			return nil, fmt.Errorf("the range has more than %d dates; use a coarser granularity", maxSeriesPoints)
+		}
+		dates = append(dates, t.Format(layout))
+	}
+	return dates, nil
+}
+
+// costSeries pivots analyze results, one row per date and group, into series
+func costSeries(jsonBytes []byte, req *CostSeriesRequest, dates []string) (*CostSeriesResult, error) {
+	var analyzed struct {
+		Results []map[string]interface{} `json:"results"`
+	}
+	if err := json.Unmarshal(jsonBytes, &analyzed); err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]int, len(dates))
+	for i, date := range dates {
+		index[date] = i
+	}
+	result := &CostSeriesResult{Granularity: req.Granularity, Start: req.Start, End: req.End, Dates: dates, Series: []CostSeries{}}
+	byName := make(map[string]int)
+	for _, row := range analyzed.Results {
+		i, ok := index[fmt.Sprint(row["date"])]
+		if !ok {
+			continue
+		}
+		value, _ := row["value"].(float64)
+
+		group := make(map[string]string, len(req.GroupBy))
+		names := make([]string, 0, len(req.GroupBy))
+		for _, field := range req.GroupBy {
+			label := ""
+			if v := groupValue(row, field); v != nil {
+				label = fmt.Sprint(v)
+			}
+			group[field] = label
+			names = append(names, label)
+		}
+		name := strings.Join(names, " / ")
+		if len(req.GroupBy) == 0 {
+			name = "Total"
+		}
+
+		s, exists := byName[name]
+		if !exists {
+			s = len(result.Series)
+			byName[name] = s
+			result.Series = append(result.Series, CostSeries{Name: name, Group: group, Values: make([]float64, len(dates))})
+		}
+		result.Series[s].Values[i] += value
+		result.Series[s].Total += value
+		result.Total += value
+	}
+
+	slices.SortStableFunc(result.Series, func(a, b CostSeries) int {
+		switch {
+		case a.Total > b.Total:
+			return -1
+		case a.Total < b.Total:
+			return 1
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return result, nil
+}
+
+// groupValue returns the value of a group_by field in a result row. Nested fields such as
+// tags.Environment may come back under their full name, nested, or under their last part.
+func groupValue(row map[string]interface{}, field string) interface{} {
+	if v, ok := row[field]; ok {
+		return v
+	}
+	if v := fieldValue(row, field); v != nil {
+		return v
+	}
+	return row[field[strings.LastIndex(field, ".")+1:]]
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"spacectl-web/server/internal/errors"
+	"spacectl-web/server/internal/grpc"
+	"spacectl-web/server/internal/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CountResult is the number of results a call would return
+type CountResult struct {
+	TotalCount int64 `json:"total_count"`
+	DurationMS int64 `json:"duration_ms"`
+}
+
+// CountMatches runs a verb that takes a query with query.count_only set and returns the
+// total count, so the client can pick a pagination strategy before fetching the results.
+// The call is not added to the history.
+func (h *Handler) CountMatches(c echo.Context) error {
+	serviceName := c.Param("service")
+	resourceName := c.Param("resource")
+	verb := c.Param("verb")
+
+	var requestBody map[string]interface{}
+	if err := c.Bind(&requestBody); err != nil {
+		if apiErr := bodyTooLarge(err); apiErr != nil {
+			return response.APIError(c, apiErr)
+		}
+		requestBody = make(map[string]interface{})
+	}
+	parameters := filterParameters(requestBody)
+
+	resource, apiErr := h.findResource(serviceName, resourceName)
+	if apiErr != nil {
+		return response.APIError(c, apiErr)
+	}
+	if !verbTakesQuery(resource, verb) {
+		return response.APIError(c, errors.NewAPIError(errors.ErrVerbNotSupported,
+			fmt.Sprintf("verb '%s' of resource '%s' does not take a query", verb, resourceName)))
+	}
+
+	// Ask for the count only, keeping the caller's filters
+	if apiErr := h.applyURLQuery(c.QueryParams(), parameters, serviceName, resourceName, verb); apiErr != nil {
+		return response.APIError(c, apiErr)
+	}
+	query := make(map[string]interface{})
+	if existing, ok := parameters["query"].(map[string]interface{}); ok {
+		for key, value := range existing {
+			query[key] = value
+		}
+	}
+	delete(query, "page")
+	query["count_only"] = true
+	parameters["query"] = query
+
+	ctx := grpc.WithRequestID(c.Request().Context(), c.Response().Header().Get(echo.HeaderXRequestID))
+	started := time.Now()
+	jsonBytes, apiErr := h.invoke(ctx, serviceName, resourceName, verb, parameters)
+	if apiErr != nil {
+		return response.APIError(c, apiErr)
+	}
+
+	totalCount, err := totalCount(jsonBytes)
+	if err != nil {
+		return response.InternalServerError(c, "Invalid response", err.Error())
+	}
+	return response.Success(c, &CountResult{TotalCount: totalCount, DurationMS: time.Since(started).Milliseconds()})
+}
+
+// totalCount reads the total_count field of a list response, as a number or an int64 string
+func totalCount(jsonBytes []byte) (int64, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(jsonBytes, &fields); err != nil {
+		return 0, err
+	}
+	total, _, err := totalCountField(fields)
+	return total, err
+}
+
+// totalCountField reads the total_count field of decoded response fields and reports whether it was present
+func totalCountField(fields map[string]json.RawMessage) (int64, bool, error) {
+	raw, found := fields["total_count"]
+	if !found {
+		raw, found = fields["totalCount"]
+	}
+	if !found {
+		return 0, false, nil // Zero counts are left out of proto JSON
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return 0, true, err
+	}
+	switch v := value.(type) {
+	case float64:
+		return int64(v), true, nil
+	case string:
+		total, err := strconv.ParseInt(v, 10, 64)
+		return total, true, err
+	}
+	return 0, true, fmt.Errorf("total_count is not a number: %s", raw)
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"spacectl-web/server/internal/disconnects"
+	"spacectl-web/server/internal/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SetDisconnects sets the tracker of requests abandoned by their clients
+func (h *Handler) SetDisconnects(tracker *disconnects.Tracker) {
+	h.disconnects = tracker
+}
+
+// GetDisconnects returns how often clients abandoned calls before they completed, per route and
+// verb, most abandoned first. Together with the slow call log it shows which screens need
+// paging or background jobs.
+func (h *Handler) GetDisconnects(c echo.Context) error {
+	if h.disconnects == nil {
+		return response.Success(c, &disconnects.Report{Calls: []disconnects.CallStats{}})
+	}
+	return response.Success(c, h.disconnects.Report())
+}
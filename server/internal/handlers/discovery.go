@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"time"
+
+	"spacectl-web/server/internal/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// discoveryEventChanged is the SSE event sent when a service's resources or verbs change
+const discoveryEventChanged = "discovery-changed"
+
+// WatchDiscovery streams a server-sent event whenever rediscovering a service finds added or
+// removed resources or verbs, so open sessions can offer to reload their navigation
+func (h *Handler) WatchDiscovery(c echo.Context) error {
+	changes, unsubscribe := h.serviceDiscovery.SubscribeChanges()
+	defer unsubscribe()
+
+	sse := response.NewSSE(c)
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	ctx := c.Request().Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-heartbeat.C:
+			if err := sse.Comment("keep-alive"); err != nil {
+				return nil
+			}
+		case change := <-changes:
+			if err := sse.Event(discoveryEventChanged, &change); err != nil {
+				return nil
+			}
+		}
+	}
+}
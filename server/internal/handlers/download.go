@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// downloadQueryParam selects a bytes field of the response to return as a file
+const downloadQueryParam = "download"
+
+// filenameQueryParam overrides the file name offered to the browser
+const filenameQueryParam = "filename"
+
+// extractBytesField returns the decoded contents of a bytes field in a JSON response.
+// The field is addressed by a dotted path; each segment may use either the proto
+// field name or its lowerCamelCase JSON name.
+func extractBytesField(jsonBytes []byte, fieldPath string) ([]byte, error) {
+	var current interface{}
+	if err := json.Unmarshal(jsonBytes, &current); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	for _, segment := range strings.Split(fieldPath, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("field '%s' not found in response", fieldPath)
+		}
+		value, exists := object[segment]
+		if !exists {
+			value, exists = object[jsonFieldName(segment)]
+		}
+		if !exists {
+			return nil, fmt.Errorf("field '%s' not found in response", fieldPath)
+		}
+		current = value
+	}
+
+	encoded, ok := current.(string)
+	if !ok {
+		return nil, fmt.Errorf("field '%s' is not a bytes field", fieldPath)
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("field '%s' is not valid base64: %w", fieldPath, err)
+	}
+	return data, nil
+}
+
+// jsonFieldName converts a snake_case proto field name to its lowerCamelCase JSON name
+func jsonFieldName(name string) string {
+	parts := strings.Split(name, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// sendDownload streams decoded bytes as a file attachment
+func sendDownload(c echo.Context, data []byte, filename string) error {
+	contentType := http.DetectContentType(data)
+	if filename != "" {
+		if byExtension := mime.TypeByExtension(path.Ext(filename)); byExtension != "" {
+			contentType = byExtension
+		}
+	}
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	header.Set(echo.HeaderContentLength, strconv.Itoa(len(data)))
+	return c.Stream(http.StatusOK, contentType, bytes.NewReader(data))
+}
+
+// downloadFilename returns the requested file name or one derived from the call
+func downloadFilename(c echo.Context, resourceName, verb, field string) string {
+	if filename := c.QueryParam(filenameQueryParam); filename != "" {
+		return path.Base(filename)
+	}
+	return fmt.Sprintf("%s_%s_%s", resourceName, verb, strings.ReplaceAll(field, ".", "_"))
+}
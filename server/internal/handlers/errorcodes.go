@@ -0,0 +1,14 @@
+package handlers
+
+import (
+	"spacectl-web/server/internal/errors"
+	"spacectl-web/server/internal/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ListErrorCodes returns the error codes the proxy can return, in the client's language
+func (h *Handler) ListErrorCodes(c echo.Context) error {
+	c.Response().Header().Add(echo.HeaderVary, "Accept-Language")
+	return response.Success(c, errors.Catalog(response.Language(c)))
+}
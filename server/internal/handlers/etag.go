@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"spacectl-web/server/internal/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Headers of conditional requests, which echo does not define
+const (
+	headerETag            = "ETag"
+	headerIfNoneMatch     = "If-None-Match"
+	headerIfModifiedSince = "If-Modified-Since"
+)
+
+// cacheControlRevalidate lets clients keep responses but makes them revalidate before reuse
+const cacheControlRevalidate = "private, no-cache"
+
+// sendWithETag sends a buffered call response with an ETag of its content, or 304 Not Modified
+// when the client's If-None-Match already names it, so refetches of unchanged cached lists
+// cost no response body
+func sendWithETag(c echo.Context, stream *response.JSONStream, data []byte, sections map[string]interface{}) error {
+	encodedSections, err := json.Marshal(sections)
+	if err != nil {
+		return err
+	}
+	etag := contentETag(data, encodedSections)
+
+	header := c.Response().Header()
+	header.Set(headerETag, etag)
+	header.Set(echo.HeaderCacheControl, cacheControlRevalidate)
+	if etagMatches(c.Request().Header.Get(headerIfNoneMatch), etag) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	if _, err := stream.Write(data); err != nil {
+		return err
+	}
+	return stream.CloseWithSections(sections)
+}
+
+// sendSuccessWithValidators sends a successful response with an ETag of its content and a
+// Last-Modified time, or 304 Not Modified when the client's copy is current. If-None-Match
+// wins over If-Modified-Since. The ETag is content based, so it survives rediscovery that
+// refreshes the modification time without changing the response.
+func sendSuccessWithValidators(c echo.Context, data interface{}, lastModified time.Time) error {
+	body, err := json.Marshal(response.Response{Success: true, Data: data})
+	if err != nil {
+		return response.InternalServerError(c, "Failed to encode response", err.Error())
+	}
+	etag := contentETag(body)
+
+	header := c.Response().Header()
+	header.Set(headerETag, etag)
+	if !lastModified.IsZero() {
+		header.Set(echo.HeaderLastModified, lastModified.UTC().Format(http.TimeFormat))
+	}
+	header.Set(echo.HeaderCacheControl, cacheControlRevalidate)
+	if ifNoneMatch := c.Request().Header.Get(headerIfNoneMatch); ifNoneMatch != "" {
+		if etagMatches(ifNoneMatch, etag) {
+			return c.NoContent(http.StatusNotModified)
+		}
+	} else if since, err := http.ParseTime(c.Request().Header.Get(headerIfModifiedSince)); err == nil &&
+		!lastModified.IsZero() && !lastModified.Truncate(time.Second).After(since) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return c.Blob(http.StatusOK, echo.MIMEApplicationJSON, append(body, '\n'))
+}
+
+// contentETag returns a strong ETag of the content parts
+func contentETag(parts ...[]byte) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		hash.Write(part)
+	}
+	return `"` + hex.EncodeToString(hash.Sum(nil))[:16] + `"`
+}
+
+// etagMatches reports whether an If-None-Match header names the ETag, comparing weakly as
+// RFC 9110 requires
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
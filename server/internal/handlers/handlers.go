@@ -1,25 +1,71 @@
 package handlers
 
 import (
-	"encoding/base64"
-	"encoding/json"
+	"context"
+	stderrors "errors"
 	"fmt"
-	"strings"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
 
 	"spacectl-web/server/internal/config"
+	"spacectl-web/server/internal/disconnects"
 	"spacectl-web/server/internal/errors"
+	"spacectl-web/server/internal/grant"
 	"spacectl-web/server/internal/grpc"
+	"spacectl-web/server/internal/icons"
+	"spacectl-web/server/internal/jobs"
+	"spacectl-web/server/internal/jwt"
+	"spacectl-web/server/internal/prober"
+	"spacectl-web/server/internal/readiness"
+	"spacectl-web/server/internal/recording"
 	"spacectl-web/server/internal/response"
+	"spacectl-web/server/internal/session"
+	"spacectl-web/server/internal/siem"
+	"spacectl-web/server/internal/startup"
+	"spacectl-web/server/internal/storage"
+	"spacectl-web/server/internal/update"
+	"spacectl-web/server/internal/usage"
+	"spacectl-web/server/internal/validate"
+	"spacectl-web/server/internal/web"
+	"spacectl-web/server/internal/workflow"
 
 	"github.com/labstack/echo/v4"
 )
 
+// explainQueryParam adds a report of how each parameter was interpreted to the response
+const explainQueryParam = "explain"
+
 // Handler contains dependencies for HTTP handlers
 type Handler struct {
 	grpcManager      *grpc.ClientManager
 	serviceDiscovery *grpc.ServiceDiscovery
 	config           *config.Config
 	configFilePath   string
+	recordings       *recording.Store
+	usage            *usage.Tracker       // Per-user usage; nil when quotas are disabled
+	jobs             *jobs.Registry       // Running calls; nil when the admin API is disabled
+	updates          *update.Checker      // Release checks; nil when update checks are disabled
+	uiAssets         *web.Assets          // Served and embedded web client builds
+	readiness        *readiness.Gate      // Startup readiness; nil when the server is ready immediately
+	store            storage.Store        // History, favorites, collections and schedules
+	prober           *prober.Prober       // Background health checks; nil when the prober is disabled
+	startupReport    *startup.Report      // Configuration the server started with
+	granter          *grant.Granter       // App token grants; nil when no app token is configured
+	scopes           *session.Store       // Scoped tokens by session; nil in offline mode
+	scopeManager     *grpc.ClientManager  // Grants scoped tokens without recording them
+	audit            *log.Logger          // Impersonation audit log; nil when impersonation is disabled
+	workflows        *workflow.Engine     // Background workflow runs
+	siem             *siem.Exporter       // SIEM destinations; nil when no exporter is configured
+	slowCalls        *grpc.SlowCallLog    // Latest slow calls; nil when the slow call log is disabled
+	disconnects      *disconnects.Tracker // Requests abandoned by their clients
+	icons            *icons.Proxy         // Provider icons; nil when the icon proxy is disabled
+
+	profileManagers map[string]*grpc.ClientManager // Client managers of other profiles by name, kept for comparisons
+	profileMutex    sync.Mutex
 }
 
 // NewHandler creates a new Handler instance
@@ -32,33 +78,59 @@ func NewHandler(grpcManager *grpc.ClientManager, serviceDiscovery *grpc.ServiceD
 	}
 }
 
-// ListServices returns the list of available services
+// ListServices returns the list of available services. With ?details=true each service is
+// returned with its alias, description and tags from server.catalog. Hidden services are
+// left out unless an admin asks for them with ?show_hidden=true.
 func (h *Handler) ListServices(c echo.Context) error {
-	services := h.serviceDiscovery.GetAvailableServices()
+	showHidden, apiErr := h.showHidden(c)
+	if apiErr != nil {
+		return response.APIError(c, apiErr)
+	}
+	services := visibleServices(h.config.Server.Catalog, h.serviceDiscovery.GetAvailableServices(), showHidden)
+	if details, _ := strconv.ParseBool(c.QueryParam(detailsQueryParam)); details {
+		return response.Success(c, serviceSummaries(h.config.Server.Catalog, services))
+	}
 	return response.Success(c, services)
 }
 
-// ListResources returns the list of resources for a specific service
+// ListResources returns the list of resources for a specific service. Responses carry an
+// ETag and the discovery time as Last-Modified, and conditional requests for an unchanged
+// list get 304 Not Modified.
 func (h *Handler) ListResources(c echo.Context) error {
 	serviceName := c.Param("service")
+	showHidden, apiErr := h.showHidden(c)
+	if apiErr != nil {
+		return response.APIError(c, apiErr)
+	}
+	catalog := h.config.Server.Catalog
+	if catalog.ServiceHidden(serviceName) && !showHidden {
+		return response.APIError(c, errors.NewAPIError(errors.ErrServiceNotFound, fmt.Sprintf("service '%s' not found", serviceName)))
+	}
 
 	// Get service information from discovery
 	serviceInfo, err := h.serviceDiscovery.GetServiceInfo(serviceName)
 	if err != nil {
-		return response.NotFound(c, "Service not found", fmt.Sprintf("service '%s' not found: %v", serviceName, err))
+		apiErr := discoveryError(serviceName, err)
+		return response.APIError(c, apiErr)
 	}
 
 	// Convert to the expected format
 	resources := make([]map[string]interface{}, 0, len(serviceInfo.Resources))
 	for _, resource := range serviceInfo.Resources {
-		resources = append(resources, map[string]interface{}{
+		if catalog.ResourceHidden(serviceName, resource.Name) && !showHidden {
+			continue
+		}
+		entry := map[string]interface{}{
 			"Name":    resource.Name,
 			"Verbs":   resource.Verbs,
 			"Methods": resource.Methods,
-		})
+		}
+		addDisplay(entry, catalog.Resource(serviceName, resource.Name))
+		resources = append(resources, entry)
 	}
 
-	return response.Success(c, resources)
+	// Let the web client confirm its navigation tree is current without downloading it again
+	return sendSuccessWithValidators(c, resources, serviceInfo.LastUpdate)
 }
 
 // CallGRPCMethod calls a gRPC method for the specified service, resource, and verb
@@ -67,58 +139,206 @@ func (h *Handler) CallGRPCMethod(c echo.Context) error {
 	resourceName := c.Param("resource")
 	verb := c.Param("verb")
 
-	// Validate service, resource, and verb
-	if err := h.validateRequest(serviceName, resourceName, verb); err != nil {
-		return err
-	}
-
 	// Read request body for parameters
 	var requestBody map[string]interface{}
-	if err := c.Bind(&requestBody); err != nil {
+	multipart := isMultipart(c)
+	if multipart {
+		// Uploaded files are mapped to bytes fields
+		body, err := bindMultipart(c)
+		if err != nil {
+			if apiErr := bodyTooLarge(err); apiErr != nil {
+				return response.APIError(c, apiErr)
+			}
+			return response.BadRequest(c, "Invalid multipart request", err.Error())
+		}
+		requestBody = body
+	} else if err := c.Bind(&requestBody); err != nil {
+		if apiErr := bodyTooLarge(err); apiErr != nil {
+			return response.APIError(c, apiErr)
+		}
 		// If no body is provided, use empty map
 		requestBody = make(map[string]interface{})
 	}
 
-	// Filter out metadata fields that shouldn't be passed to gRPC
-	grpcParameters := make(map[string]interface{})
-	for key, value := range requestBody {
-		// Skip metadata fields that are not part of the actual gRPC request
-		if key != "service" && key != "resource" && key != "verb" {
-			grpcParameters[key] = value
+	ctx := grpc.WithRequestID(c.Request().Context(), c.Response().Header().Get(echo.HeaderXRequestID))
+	parameters := filterParameters(requestBody)
+
+	// Translate sort, keyword, filter, and paging query string parameters into the query
+	if apiErr := h.applyURLQuery(c.QueryParams(), parameters, serviceName, resourceName, verb); apiErr != nil {
+		return response.APIError(c, apiErr)
+	}
+
+	// Save the call to the user's history once it completes
+	var callErr *errors.APIError
+	started := time.Now()
+	defer func() {
+		entry := &storage.HistoryEntry{
+			Request:    storage.Request{Service: serviceName, Resource: resourceName, Verb: verb, Parameters: parameters},
+			Success:    callErr == nil,
+			DurationMS: time.Since(started).Milliseconds(),
+		}
+		if multipart {
+			entry.Parameters = nil // Uploaded file contents are not kept
 		}
+		if callErr != nil {
+			entry.ErrorCode = string(callErr.ErrorCode)
+		}
+		h.recordHistory(c, entry)
+	}()
+
+	// Report how each parameter was interpreted when requested
+	var report *grpc.ParameterReport
+	if explain, _ := strconv.ParseBool(c.QueryParam(explainQueryParam)); explain {
+		report = &grpc.ParameterReport{Parameters: []grpc.ParameterCoercion{}}
+		ctx = grpc.WithParameterReport(ctx, report)
+	}
+
+	// Return a bytes field as a file instead of base64 JSON
+	if field := c.QueryParam(downloadQueryParam); field != "" {
+		jsonBytes, apiErr := h.invoke(ctx, serviceName, resourceName, verb, parameters)
+		if apiErr != nil {
+			callErr = apiErr
+			return response.APIError(c, apiErr)
+		}
+		data, err := extractBytesField(jsonBytes, field)
+		if err != nil {
+			return response.BadRequest(c, "Invalid download field", err.Error())
+		}
+		return sendDownload(c, data, downloadFilename(c, resourceName, verb, field))
+	}
+
+	// Call method, streaming large responses directly to the client
+	stream := response.NewJSONStream(c)
+	capture := &paginationCapture{}
+	if report == nil && h.grpcManager.Cached(serviceName, resourceName, verb) {
+		jsonBytes, apiErr := h.invoke(ctx, serviceName, resourceName, verb, parameters)
+		if apiErr != nil {
+			callErr = apiErr
+			return response.APIError(c, apiErr)
+		}
+		_, _ = capture.Write(jsonBytes)
+		return sendWithETag(c, stream, jsonBytes, callSections(capture, parameters, nil))
+	}
+	if apiErr := h.invokeTo(ctx, serviceName, resourceName, verb, parameters, io.MultiWriter(stream, capture)); apiErr != nil {
+		callErr = apiErr
+		if stream.Started() {
+			// The status has already been sent, so the error can only be logged
+			log.Printf("ERROR: response for %s.%s.%s failed after streaming started: %v", serviceName, resourceName, verb, apiErr)
+			return nil
+		}
+		return response.APIError(c, apiErr)
+	}
+	return stream.CloseWithSections(callSections(capture, parameters, report))
+}
+
+// callSections returns the sections sent next to a call's data: paging in one shape whichever
+// service answered, and how each parameter was interpreted when requested
+func callSections(capture *paginationCapture, parameters map[string]interface{}, report *grpc.ParameterReport) map[string]interface{} {
+	sections := make(map[string]interface{})
+	if pagination := capture.Pagination(parameters); pagination != nil {
+		sections["pagination"] = pagination
+	}
+	if report != nil {
+		sections["parameters"] = report.Parameters
+	}
+	return sections
+}
+
+// invoke validates the request and calls the gRPC method, converting failures to API errors
+func (h *Handler) invoke(ctx context.Context, serviceName, resourceName, verb string, parameters map[string]interface{}) ([]byte, *errors.APIError) {
+	serviceCaller, apiErr := h.serviceCaller(serviceName, resourceName, verb)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	jsonBytes, err := serviceCaller.CallMethod(ctx, serviceName, resourceName, verb, parameters)
+	if err != nil {
+		return nil, toAPIError(err)
+	}
+
+	return jsonBytes, nil
+}
+
+// invokeTo validates the request and calls the gRPC method, writing the JSON response to w
+func (h *Handler) invokeTo(ctx context.Context, serviceName, resourceName, verb string, parameters map[string]interface{}, w io.Writer) *errors.APIError {
+	serviceCaller, apiErr := h.serviceCaller(serviceName, resourceName, verb)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	if err := serviceCaller.CallMethodTo(ctx, serviceName, resourceName, verb, parameters, w); err != nil {
+		return toAPIError(err)
+	}
+	return nil
+}
+
+// serviceCaller validates the request and returns a caller for the service
+func (h *Handler) serviceCaller(serviceName, resourceName, verb string) (*grpc.ServiceCaller, *errors.APIError) {
+	// Validate service, resource, and verb
+	if err := h.validateRequest(serviceName, resourceName, verb); err != nil {
+		return nil, err
 	}
 
 	// Get service caller
 	serviceCaller, err := h.grpcManager.GetServiceCaller(serviceName)
 	if err != nil {
-		return response.InternalServerError(c, "Failed to create service caller", err.Error())
+		return nil, callerError(err)
 	}
+	return serviceCaller, nil
+}
 
-	// Call method
-	jsonBytes, err := serviceCaller.CallMethod(serviceName, resourceName, verb, grpcParameters)
-	if err != nil {
-		apiErr, ok := err.(*errors.APIError)
-		if ok {
-			return response.Error(c, apiErr.Code, apiErr.Message, apiErr.Details)
-		}
-		return response.InternalServerError(c, "Unknown error occurred", err.Error())
+// discoveryError converts a discovery failure to an API error, keeping connectivity errors intact
+func discoveryError(serviceName string, err error) *errors.APIError {
+	var apiErr *errors.APIError
+	if stderrors.As(err, &apiErr) {
+		return apiErr
 	}
+	return errors.NewAPIError(errors.ErrServiceNotFound, fmt.Sprintf("service '%s' not found: %v", serviceName, err))
+}
 
-	return response.Success(c, json.RawMessage(jsonBytes))
+// callerError converts a failure to create a service caller to an API error
+func callerError(err error) *errors.APIError {
+	if apiErr, ok := err.(*errors.APIError); ok {
+		return apiErr
+	}
+	return errors.NewAPIError(errors.ErrGRPCClientFailed, err.Error())
 }
 
-// validateRequest validates the service, resource, and verb parameters
-func (h *Handler) validateRequest(serviceName, resourceName, verb string) error {
-	// Get service information from discovery
-	serviceInfo, err := h.serviceDiscovery.GetServiceInfo(serviceName)
-	if err != nil {
-		return response.NotFound(nil, "Service not found", fmt.Sprintf("service '%s' not found: %v", serviceName, err))
+// toAPIError converts a call failure to an API error
+func toAPIError(err error) *errors.APIError {
+	if apiErr, ok := err.(*errors.APIError); ok {
+		return apiErr
 	}
+	return errors.NewAPIError(errors.ErrRPCCallFailed, err.Error())
+}
 
-	// Validate resource exists
-	resource, exists := serviceInfo.Resources[resourceName]
-	if !exists {
-		return response.NotFound(nil, "Resource not found", fmt.Sprintf("resource '%s' not found", resourceName))
+// bodyTooLarge converts a body read that hit the request size limit to an API error
+func bodyTooLarge(err error) *errors.APIError {
+	var maxBytesErr *http.MaxBytesError
+	if !stderrors.As(err, &maxBytesErr) {
+		return nil
+	}
+	return errors.NewAPIError(errors.ErrRequestTooLarge,
+		fmt.Sprintf("request body exceeds the limit of %d bytes", maxBytesErr.Limit))
+}
+
+// filterParameters removes metadata fields that shouldn't be passed to gRPC
+func filterParameters(requestBody map[string]interface{}) map[string]interface{} {
+	grpcParameters := make(map[string]interface{})
+	for key, value := range requestBody {
+		// Skip metadata fields that are not part of the actual gRPC request
+		if key != "service" && key != "resource" && key != "verb" {
+			grpcParameters[key] = value
+		}
+	}
+	return grpcParameters
+}
+
+// validateRequest validates the service, resource, and verb parameters
+func (h *Handler) validateRequest(serviceName, resourceName, verb string) *errors.APIError {
+	resource, apiErr := h.findResource(serviceName, resourceName)
+	if apiErr != nil {
+		return apiErr
 	}
 
 	// Validate verb exists
@@ -130,70 +350,75 @@ func (h *Handler) validateRequest(serviceName, resourceName, verb string) error
 		}
 	}
 	if !verbExists {
-		return response.BadRequest(nil, "Verb not supported", fmt.Sprintf("verb '%s' is not supported for resource '%s'", verb, resourceName))
+		return errors.NewAPIError(errors.ErrVerbNotSupported, fmt.Sprintf("verb '%s' is not supported for resource '%s'", verb, resourceName))
 	}
 
-	return nil
-}
+	// Reject verbs that may change data in read-only mode
+	if h.config.Server.UI.ReadOnly && !config.IsReadVerb(verb) {
+		return errors.NewAPIError(errors.ErrReadOnly, fmt.Sprintf("verb '%s' may change data", verb))
+	}
 
-// JWTInfo represents parsed JWT token information
-type JWTInfo struct {
-	Header  map[string]interface{} `json:"header"`
-	Payload map[string]interface{} `json:"payload"`
+	return nil
 }
 
-// ConfigInfo represents configuration information
-type ConfigInfo struct {
-	ConfigFilePath string            `json:"config_file_path"`
-	Endpoints      map[string]string `json:"endpoints"`
-	JWTInfo        *JWTInfo          `json:"jwt_info,omitempty"`
+// CheckRequest validates a call made outside of an API request, such as a workflow step, the
+// same way calls made through the API are validated
+func (h *Handler) CheckRequest(serviceName, resourceName, verb string) error {
+	if apiErr := h.validateRequest(serviceName, resourceName, verb); apiErr != nil {
+		return apiErr
+	}
+	return nil
 }
 
-// parseJWT parses a JWT token and returns header and payload
-func parseJWT(token string) (*JWTInfo, error) {
-	parts := strings.Split(token, ".")
-	if len(parts) != 3 {
-		return nil, fmt.Errorf("invalid JWT format")
+// findResource returns a resource of a service. Well-known resources such as Health are
+// resolved directly so status checks do not wait for a full discovery of the service.
+func (h *Handler) findResource(serviceName, resourceName string) (*grpc.ResourceInfo, *errors.APIError) {
+	if grpc.IsWellKnownResource(resourceName) {
+		resource, err := h.serviceDiscovery.GetWellKnownResource(serviceName, resourceName)
+		if err != nil {
+			return nil, discoveryError(serviceName, err)
+		}
+		return resource, nil
 	}
 
-	// Parse header
-	headerData, err := base64.RawURLEncoding.DecodeString(parts[0])
+	// Get service information from discovery
+	serviceInfo, err := h.serviceDiscovery.GetServiceInfo(serviceName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode header: %w", err)
-	}
-
-	var header map[string]interface{}
-	if err := json.Unmarshal(headerData, &header); err != nil {
-		return nil, fmt.Errorf("failed to parse header: %w", err)
+		return nil, discoveryError(serviceName, err)
 	}
 
-	// Parse payload
-	payloadData, err := base64.RawURLEncoding.DecodeString(parts[1])
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode payload: %w", err)
+	// Validate resource exists
+	resource, exists := serviceInfo.Resources[resourceName]
+	if !exists {
+		return nil, errors.NewAPIError(errors.ErrResourceNotFound, fmt.Sprintf("resource '%s' not found", resourceName))
 	}
+	return resource, nil
+}
 
-	var payload map[string]interface{}
-	if err := json.Unmarshal(payloadData, &payload); err != nil {
-		return nil, fmt.Errorf("failed to parse payload: %w", err)
-	}
+// JWTInfo represents parsed JWT token information
+type JWTInfo = jwt.Info
 
-	return &JWTInfo{
-		Header:  header,
-		Payload: payload,
-	}, nil
+// ConfigInfo represents configuration information
+type ConfigInfo struct {
+	ConfigFilePath string            `json:"config_file_path"`
+	Endpoints      map[string]string `json:"endpoints"`
+	JWTInfo        *JWTInfo          `json:"jwt_info,omitempty"`
+	TokenSource    string            `json:"token_source,omitempty"`
+	Profile        string            `json:"profile"`
 }
 
 // GetConfigInfo returns configuration information including JWT token details
 func (h *Handler) GetConfigInfo(c echo.Context) error {
 	configInfo := &ConfigInfo{
 		ConfigFilePath: h.configFilePath,
-		Endpoints:      h.config.Endpoints,
+		Endpoints:      h.config.EndpointURLs(),
+		TokenSource:    h.config.TokenRef(),
+		Profile:        h.config.ActiveProfile(),
 	}
 
 	// Parse JWT token if available
-	if h.config.Token != "" {
-		jwtInfo, err := parseJWT(h.config.Token)
+	if token := h.config.GetToken(); token != "" {
+		jwtInfo, err := jwt.Parse(token)
 		if err != nil {
 			// If JWT parsing fails, still return config info but without JWT details
 			return response.Success(c, configInfo)
@@ -203,3 +428,74 @@ func (h *Handler) GetConfigInfo(c echo.Context) error {
 
 	return response.Success(c, configInfo)
 }
+
+// RefreshToken re-resolves the token from its secret reference, or grants a new access token
+// when the token is obtained with an app token
+func (h *Handler) RefreshToken(c echo.Context) error {
+	if h.granter != nil && h.config.GetGrant().Enabled() {
+		if err := h.granter.Grant(c.Request().Context()); err != nil {
+			return response.InternalServerError(c, "Failed to refresh token", err.Error())
+		}
+		return h.GetConfigInfo(c)
+	}
+	if h.config.TokenRef() == "" {
+		return response.BadRequest(c, "Token refresh not available", "token is not configured as a secret reference")
+	}
+
+	if err := h.config.RefreshToken(); err != nil {
+		return response.InternalServerError(c, "Failed to refresh token", err.Error())
+	}
+
+	return h.GetConfigInfo(c)
+}
+
+// SetGranter exchanges the app token for access tokens on token refreshes and profile switches
+func (h *Handler) SetGranter(granter *grant.Granter) {
+	h.granter = granter
+}
+
+// ProfileList represents the available profiles and the active one
+type ProfileList struct {
+	Active   string   `json:"active"`
+	Profiles []string `json:"profiles"`
+}
+
+// ListProfiles returns the profiles defined in the config file
+func (h *Handler) ListProfiles(c echo.Context) error {
+	return response.Success(c, &ProfileList{
+		Active:   h.config.ActiveProfile(),
+		Profiles: h.config.ProfileNames(),
+	})
+}
+
+// SwitchProfile activates a profile and drops connections made with the previous one
+func (h *Handler) SwitchProfile(c echo.Context) error {
+	profileName := c.Param("profile")
+
+	if err := h.config.SwitchProfile(profileName); err != nil {
+		return response.BadRequest(c, "Failed to switch profile", err.Error())
+	}
+
+	h.grpcManager.Reset()
+	h.serviceDiscovery.Reset()
+	if h.prober != nil {
+		h.prober.Reset()
+	}
+	if h.scopes != nil {
+		h.scopes.Reset()
+	}
+	if h.granter != nil {
+		if err := h.granter.Grant(c.Request().Context()); err != nil {
+			return response.InternalServerError(c, "Failed to grant access token", err.Error())
+		}
+	}
+
+	return h.GetConfigInfo(c)
+}
+
+// ValidateConfig checks the running configuration and returns a structured report
+func (h *Handler) ValidateConfig(c echo.Context) error {
+	report := validate.Config(h.config)
+	report.ConfigFile = h.configFilePath
+	return response.Success(c, report)
+}
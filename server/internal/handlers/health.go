@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"spacectl-web/server/internal/errors"
+	"spacectl-web/server/internal/grpc"
+	"spacectl-web/server/internal/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SSE event names for the health watch stream
+const (
+	healthEventStatus = "health"
+	healthEventError  = "error"
+	healthEventEnd    = "end"
+)
+
+// sseHeartbeatInterval is how often keep-alive comments are sent on idle event streams
+const sseHeartbeatInterval = 15 * time.Second
+
+// HealthStatus represents a health transition sent to the UI
+type HealthStatus struct {
+	Service   string          `json:"service"`
+	Status    json.RawMessage `json:"status"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+// HealthError represents a failed Health/Watch stream sent to the UI
+type HealthError struct {
+	Service string              `json:"service"`
+	Error   *response.ErrorInfo `json:"error"`
+}
+
+// healthMessage is a message received from the Health/Watch stream
+type healthMessage struct {
+	data []byte
+	err  error
+}
+
+// WatchHealth proxies grpc.health.v1.Health/Watch for a service and streams transitions as server-sent events.
+// The optional service_name query parameter selects the gRPC service to watch (empty watches the server).
+func (h *Handler) WatchHealth(c echo.Context) error {
+	serviceName := c.Param("service")
+
+	serviceCaller, err := h.grpcManager.GetServiceCaller(serviceName)
+	if err != nil {
+		apiErr := callerError(err)
+		return response.APIError(c, apiErr)
+	}
+
+	ctx := grpc.WithRequestID(c.Request().Context(), c.Response().Header().Get(echo.HeaderXRequestID))
+	stream, err := serviceCaller.OpenStream(ctx, serviceName, "Health", "Watch", map[string]interface{}{
+		"service": c.QueryParam("service_name"),
+	})
+	if err != nil {
+		if apiErr, ok := err.(*errors.APIError); ok {
+			return response.APIError(c, apiErr)
+		}
+		return response.InternalServerError(c, "Failed to watch health", err.Error())
+	}
+	defer stream.Close()
+
+	// Receive in the background so heartbeats can be sent while waiting for transitions
+	messages := make(chan healthMessage)
+	go func() {
+		defer close(messages)
+		for {
+			data, err := stream.Recv()
+			select {
+			case messages <- healthMessage{data: data, err: err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	sse := response.NewSSE(c)
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-heartbeat.C:
+			if err := sse.Comment("keep-alive"); err != nil {
+				return nil
+			}
+		case msg, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			if msg.err == io.EOF {
+				return sse.Event(healthEventEnd, map[string]string{"service": serviceName})
+			}
+			if msg.err != nil {
+				return sse.Event(healthEventError, &HealthError{
+					Service: serviceName,
+					Error:   response.NewErrorInfo(errors.NewAPIError(errors.ErrStreamFailed, msg.err.Error()).WithGRPCStatus(msg.err).Localize(response.Language(c))),
+				})
+			}
+			if err := sse.Event(healthEventStatus, &HealthStatus{
+				Service:   serviceName,
+				Status:    msg.data,
+				Timestamp: time.Now(),
+			}); err != nil {
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	stderrors "errors"
+	"net/http"
+
+	"spacectl-web/server/internal/icons"
+	"spacectl-web/server/internal/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Response headers of proxied icons. Icons are sandboxed so an SVG opened directly cannot run
+// scripts under the server's origin.
+const (
+	iconCacheControl = "public, max-age=86400"
+	iconCSP          = "default-src 'none'; style-src 'unsafe-inline'; sandbox"
+)
+
+// SetIcons sets the proxy serving provider and cloud service type icons
+func (h *Handler) SetIcons(proxy *icons.Proxy) {
+	h.icons = proxy
+}
+
+// GetIcon returns the icon at ?url= from an allowed host, so icon URLs returned by the
+// repository and inventory services render under the server's origin. Icons must be images
+// within the size limit; they are cached and answer If-None-Match with 304.
+func (h *Handler) GetIcon(c echo.Context) error {
+	if h.icons == nil {
+		return response.NotFound(c, "Icon proxy is disabled", "set server.icons.enabled to true in the config file")
+	}
+	rawURL := c.QueryParam("url")
+	if rawURL == "" {
+		return response.BadRequest(c, "Missing icon URL", "set the url query parameter")
+	}
+
+	icon, err := h.icons.Get(c.Request().Context(), rawURL)
+	if err != nil {
+		switch {
+		case stderrors.Is(err, icons.ErrInvalidURL):
+			return response.BadRequest(c, "Invalid icon URL", err.Error())
+		case stderrors.Is(err, icons.ErrHostNotAllowed):
+			return response.Forbidden(c, "Icon host not allowed", err.Error()+"; add it to server.icons.allowed_hosts")
+		}
+		return response.Error(c, http.StatusBadGateway, "Failed to fetch icon", err.Error())
+	}
+
+	header := c.Response().Header()
+	header.Set(headerETag, icon.ETag)
+	header.Set(echo.HeaderCacheControl, iconCacheControl)
+	header.Set(echo.HeaderContentSecurityPolicy, iconCSP)
+	header.Set(echo.HeaderXContentTypeOptions, "nosniff")
+	if etagMatches(c.Request().Header.Get(headerIfNoneMatch), icon.ETag) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return c.Blob(http.StatusOK, icon.ContentType, icon.Data)
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"spacectl-web/server/internal/errors"
+	"spacectl-web/server/internal/grpc"
+	"spacectl-web/server/internal/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// inventoryService is the service running collector jobs
+const inventoryService = "inventory"
+
+// defaultJobFollowInterval is how often a followed collector job is polled
+const defaultJobFollowInterval = 5 * time.Second
+
+// SSE event names for followed collector jobs
+const (
+	jobEventProgress = "progress"
+	jobEventError    = "error"
+	jobEventEnd      = "end"
+	jobEventTimeout  = "timeout"
+)
+
+// jobInProgress is the status of a collector job that has not finished
+const jobInProgress = "IN_PROGRESS"
+
+// JobProgress is the progress of a collector job and its tasks
+type JobProgress struct {
+	JobID         string            `json:"job_id"`
+	Status        string            `json:"status"` // IN_PROGRESS, SUCCESS, FAILURE, or CANCELED
+	TotalTasks    int64             `json:"total_tasks"`
+	RemainedTasks int64             `json:"remained_tasks"`
+	SuccessTasks  int64             `json:"success_tasks"`
+	FailureTasks  int64             `json:"failure_tasks"`
+	Percent       float64           `json:"percent"` // Finished tasks out of all tasks
+	Job           json.RawMessage   `json:"job"`
+	Tasks         []json.RawMessage `json:"tasks"`
+}
+
+// Finished reports whether the job has stopped running
+func (p *JobProgress) Finished() bool {
+	return p.Status != jobInProgress
+}
+
+// GetCollectorJob returns an inventory collector job with its tasks and progress. With
+// follow=true the job is polled every interval (default 5s) and its progress is sent as
+// server-sent events whenever it changes, ending with an end event once the job finishes.
+func (h *Handler) GetCollectorJob(c echo.Context) error {
+	follow, _ := strconv.ParseBool(c.QueryParam("follow"))
+	interval := defaultJobFollowInterval
+	if value := c.QueryParam("interval"); follow && value != "" {
+		var err error
+		interval, err = time.ParseDuration(value)
+		if err != nil || interval < minFollowInterval {
+			return response.BadRequest(c, "Invalid interval", fmt.Sprintf("interval must be a duration of at least %s", minFollowInterval))
+		}
+	}
+
+	jobID := c.Param("job_id")
+	ctx := grpc.WithRequestID(c.Request().Context(), c.Response().Header().Get(echo.HeaderXRequestID))
+	progress, apiErr := h.jobProgress(ctx, jobID)
+	if apiErr != nil {
+		return response.APIError(c, apiErr)
+	}
+	if !follow {
+		return response.Success(c, progress)
+	}
+
+	sse := response.NewSSE(c)
+	if err := sse.Event(jobEventProgress, progress); err != nil {
+		return nil
+	}
+	return h.followJob(c, ctx, sse, progress, interval, 0)
+}
+
+// followJob polls a collector job every interval and sends its progress whenever it changes,
+// then an end event once it finishes. With a timeout, following stops with a timeout event
+// when the job runs longer; the job itself keeps running.
+func (h *Handler) followJob(c echo.Context, ctx context.Context, sse *response.SSEWriter, progress *JobProgress,
+	interval, timeout time.Duration) error {
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+	for !progress.Finished() {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-deadline:
+			return sse.Event(jobEventTimeout, map[string]string{"job_id": progress.JobID, "status": progress.Status})
+		case <-heartbeat.C:
+			if err := sse.Comment("keep-alive"); err != nil {
+				return nil
+			}
+		case <-ticker.C:
+			next, apiErr := h.jobProgress(ctx, progress.JobID)
+			if apiErr != nil {
+				// Keep polling, since the job keeps running whatever happens to one poll
+				if err := sse.Event(jobEventError, response.NewErrorInfo(apiErr.Localize(response.Language(c)))); err != nil {
+					return nil
+				}
+				continue
+			}
+			if sameProgress(progress, next) {
+				continue
+			}
+			progress = next
+			if err := sse.Event(jobEventProgress, progress); err != nil {
+				return nil
+			}
+		}
+	}
+	return sse.Event(jobEventEnd, map[string]string{"job_id": progress.JobID, "status": progress.Status})
+}
+
+// jobProgress gets a collector job and lists its tasks
+func (h *Handler) jobProgress(ctx context.Context, jobID string) (*JobProgress, *errors.APIError) {
+	jobBytes, apiErr := h.invoke(ctx, inventoryService, "Job", "get", map[string]interface{}{"job_id": jobID})
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	taskBytes, apiErr := h.invoke(ctx, inventoryService, "JobTask", "list", map[string]interface{}{"job_id": jobID})
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	var job map[string]interface{}
+	if err := json.Unmarshal(jobBytes, &job); err != nil {
+		return nil, errors.NewAPIError(errors.ErrResponseConversionFailed, err.Error())
+	}
+	var tasks struct {
+		Results []json.RawMessage `json:"results"`
+	}
+	if err := json.Unmarshal(taskBytes, &tasks); err != nil {
+		return nil, errors.NewAPIError(errors.ErrResponseConversionFailed, err.Error())
+	}
+
+	progress := &JobProgress{
+		JobID:         jobID,
+		Status:        stringField(job, "status"),
+		TotalTasks:    pageNumber(job["total_tasks"], 0),
+		RemainedTasks: pageNumber(job["remained_tasks"], 0),
+		SuccessTasks:  pageNumber(job["success_tasks"], 0),
+		FailureTasks:  pageNumber(job["failure_tasks"], 0),
+		Job:           jobBytes,
+		Tasks:         tasks.Results,
+	}
+	if progress.Tasks == nil {
+		progress.Tasks = []json.RawMessage{}
+	}
+	if progress.TotalTasks > 0 {
+		progress.Percent = float64(progress.TotalTasks-progress.RemainedTasks) / float64(progress.TotalTasks) * 100
+	}
+	return progress, nil
+}
+
+// sameProgress reports whether a poll found the job and its tasks unchanged
+func sameProgress(a, b *JobProgress) bool {
+	if !bytes.Equal(a.Job, b.Job) || len(a.Tasks) != len(b.Tasks) {
+		return false
+	}
+	for i := range a.Tasks {
+		if !bytes.Equal(a.Tasks[i], b.Tasks[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"spacectl-web/server/internal/errors"
+	"spacectl-web/server/internal/response"
+	"spacectl-web/server/internal/storage"
+
+	"github.com/labstack/echo/v4"
+	"gopkg.in/yaml.v2"
+)
+
+// libraryFilename is the name of downloaded library exports
+const libraryFilename = "spacectl-library.yaml"
+
+// ExportLibrary downloads the user's favorites and collections as YAML
+func (h *Handler) ExportLibrary(c echo.Context) error {
+	library, err := storage.ExportLibrary(c.Request().Context(), h.store, h.user(c))
+	if err != nil {
+		return response.InternalServerError(c, "Export failed", err.Error())
+	}
+	output, err := yaml.Marshal(library)
+	if err != nil {
+		return response.InternalServerError(c, "Export failed", err.Error())
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, libraryFilename))
+	return c.Blob(http.StatusOK, "application/yaml", output)
+}
+
+// ImportLibrary saves favorites and collections from a YAML body as the user's.
+// The mode query parameter selects merge (default) or overwrite.
+func (h *Handler) ImportLibrary(c echo.Context) error {
+	mode := c.QueryParam("mode")
+	if mode == "" {
+		mode = storage.ImportMerge
+	}
+	if mode != storage.ImportMerge && mode != storage.ImportOverwrite {
+		return response.BadRequest(c, "Unsupported import mode", fmt.Sprintf("mode must be '%s' or '%s'", storage.ImportMerge, storage.ImportOverwrite))
+	}
+
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		if apiErr := bodyTooLarge(err); apiErr != nil {
+			return response.APIError(c, apiErr)
+		}
+		return response.BadRequest(c, "Invalid library", err.Error())
+	}
+	var library storage.Library
+	if err := yaml.UnmarshalStrict(body, &library); err != nil {
+		return response.BadRequest(c, "Invalid library", err.Error())
+	}
+	if fields := library.Validate(); len(fields) > 0 {
+		return response.APIError(c, errors.NewValidationError("library", fields))
+	}
+	library.Normalize()
+
+	counts, err := storage.ImportLibrary(c.Request().Context(), h.store, h.user(c), &library, mode)
+	if err != nil {
+		return response.InternalServerError(c, "Import failed", err.Error())
+	}
+	return response.Success(c, map[string]interface{}{"mode": mode, "imported": counts})
+}
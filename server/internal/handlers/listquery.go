@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"spacectl-web/server/internal/errors"
+	"spacectl-web/server/internal/grpc"
+)
+
+// Query string parameters translated into the SpaceONE query of list verbs
+const (
+	sortQueryParam    = "sort"    // Comma-separated keys, descending with a - prefix
+	keywordQueryParam = "keyword" // Keyword search
+	filterQueryParam  = "filter"  // Repeatable key=value conditions, see filterOperators
+	limitQueryParam   = "limit"   // Page size
+	startQueryParam   = "start"   // 1-based index of the first result
+)
+
+// filterOperators maps the operators of filter query parameters to SpaceONE filter operators.
+// Longer operators come first so that key>=value is not read as key> =value.
+var filterOperators = []struct {
+	symbol   string
+	operator string
+}{
+	{"!=", "not"},
+	{">=", "gte"},
+	{"<=", "lte"},
+	{"~=", "contain"},
+	{"=", "eq"},
+	{">", "gt"},
+	{"<", "lt"},
+}
+
+// verbTakesQuery reports whether a verb has a query parameter. Verbs without method
+// information are assumed to take one.
+func verbTakesQuery(resource *grpc.ResourceInfo, verb string) bool {
+	method := resource.Methods[verb]
+	return method == nil || slices.Contains(method.RequiredParams, "query") || slices.Contains(method.OptionalParams, "query")
+}
+
+// urlQuery translates the sort, keyword, filter, limit, and start query string parameters
+// into a SpaceONE query, or returns nil when none is set
+func urlQuery(values url.Values) (map[string]interface{}, error) {
+	query := make(map[string]interface{})
+
+	if sort := values.Get(sortQueryParam); sort != "" {
+		var keys []interface{}
+		for _, key := range strings.Split(sort, ",") {
+			key = strings.TrimSpace(key)
+			desc := strings.HasPrefix(key, "-")
+			key = strings.TrimPrefix(key, "-")
+			if key == "" {
+				return nil, queryParamError(sortQueryParam, "empty sort key")
+			}
+			keys = append(keys, map[string]interface{}{"key": key, "desc": desc})
+		}
+		query["sort"] = keys
+	}
+
+	if keyword := values.Get(keywordQueryParam); keyword != "" {
+		query["keyword"] = keyword
+	}
+
+	var filters []interface{}
+	for _, condition := range values[filterQueryParam] {
+		filter, err := parseFilter(condition)
+		if err != nil {
+			return nil, queryParamError(filterQueryParam, err.Error())
+		}
+		filters = append(filters, filter)
+	}
+	if len(filters) > 0 {
+		query["filter"] = filters
+	}
+
+	page := make(map[string]interface{})
+	for _, name := range []string{limitQueryParam, startQueryParam} {
+		value := values.Get(name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return nil, queryParamError(name, fmt.Sprintf("%q is not a positive integer", value))
+		}
+		page[name] = n
+	}
+	if len(page) > 0 {
+		query["page"] = page
+	}
+
+	if len(query) == 0 {
+		return nil, nil
+	}
+	return query, nil
+}
+
+// parseFilter parses a key<operator>value condition into a SpaceONE filter, splitting at the
+// first operator so that values may contain operator characters
+func parseFilter(condition string) (map[string]interface{}, error) {
+	for i := range condition {
+		for _, op := range filterOperators {
+			if !strings.HasPrefix(condition[i:], op.symbol) {
+				continue
+			}
+			key := strings.TrimSpace(condition[:i])
+			if key == "" {
+				return nil, fmt.Errorf("condition %q has no key", condition)
+			}
+			value := condition[i+len(op.symbol):]
+			filter := map[string]interface{}{"k": key, "v": value, "o": op.operator}
+			// Comparisons need numbers to compare numeric fields
+			if op.operator == "gt" || op.operator == "gte" || op.operator == "lt" || op.operator == "lte" {
+				if n, err := strconv.ParseFloat(value, 64); err == nil {
+					filter["v"] = n
+				}
+			}
+			return filter, nil
+		}
+	}
+	return nil, fmt.Errorf("condition %q has no operator, e.g. key=value", condition)
+}
+
+// applyURLQuery merges the query translated from the query string values into the parameters
+// of a call, rejecting it for verbs that do not take a query
+func (h *Handler) applyURLQuery(values url.Values, parameters map[string]interface{}, serviceName, resourceName, verb string) *errors.APIError {
+	query, err := urlQuery(values)
+	if err != nil {
+		return errors.NewAPIError(errors.ErrInvalidParameters, "invalid query parameter "+err.Error())
+	}
+	if query == nil {
+		return nil
+	}
+	resource, apiErr := h.findResource(serviceName, resourceName)
+	if apiErr != nil {
+		return apiErr
+	}
+	if !verbTakesQuery(resource, verb) {
+		return errors.NewAPIError(errors.ErrVerbNotSupported,
+			fmt.Sprintf("verb '%s' of resource '%s' does not take a query", verb, resourceName))
+	}
+	mergeURLQuery(parameters, query)
+	return nil
+}
+
+// mergeURLQuery adds the query translated from the query string to the parameters. Keys set
+// in the body's query take precedence, except that filters from both are combined.
+func mergeURLQuery(parameters map[string]interface{}, urlQuery map[string]interface{}) {
+	query := make(map[string]interface{})
+	for key, value := range urlQuery {
+		query[key] = value
+	}
+	if existing, ok := parameters["query"].(map[string]interface{}); ok {
+		for key, value := range existing {
+			switch key {
+			case "filter":
+				if filters, ok := value.([]interface{}); ok {
+					query[key] = append(slices.Clone(filters), toSlice(query[key])...)
+					continue
+				}
+			case "page":
+				if page, ok := value.(map[string]interface{}); ok {
+					merged := make(map[string]interface{})
+					for k, v := range toMap(query[key]) {
+						merged[k] = v
+					}
+					for k, v := range page {
+						merged[k] = v
+					}
+					query[key] = merged
+					continue
+				}
+			}
+			query[key] = value
+		}
+	}
+	parameters["query"] = query
+}
+
+// toSlice returns value as a slice, or nil when it is not one
+func toSlice(value interface{}) []interface{} {
+	slice, _ := value.([]interface{})
+	return slice
+}
+
+// toMap returns value as a map, or nil when it is not one
+func toMap(value interface{}) map[string]interface{} {
+	m, _ := value.(map[string]interface{})
+	return m
+}
+
+// queryParamError reports an invalid query string parameter
+func queryParamError(name, details string) error {
+	return fmt.Errorf("%s: %s", name, details)
+}
@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"spacectl-web/server/internal/errors"
+	"spacectl-web/server/internal/grpc"
+	"spacectl-web/server/internal/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// monitoringService is the service whose metric and log verbs the monitoring helpers wrap
+const monitoringService = "monitoring"
+
+// defaultMonitoringRange is the time range of monitoring queries without range or start
+const defaultMonitoringRange = time.Hour
+
+// Default and minimum polling intervals of followed monitoring queries
+const (
+	defaultMetricsFollowInterval = time.Minute
+	defaultLogsFollowInterval    = 10 * time.Second
+	minFollowInterval            = 5 * time.Second
+)
+
+// SSE event names for followed monitoring queries
+const (
+	monitoringEventMetrics = "metrics"
+	monitoringEventLogs    = "logs"
+	monitoringEventError   = "error"
+)
+
+// MetricSeries is one resource's values, aligned with the labels of the result
+type MetricSeries struct {
+	ResourceID string     `json:"resource_id"`
+	Values     []*float64 `json:"values"` // Null where the resource has no data point
+}
+
+// MetricResult is the data points of a metric, ready for a chart
+type MetricResult struct {
+	Start  time.Time      `json:"start"`
+	End    time.Time      `json:"end"`
+	Labels []string       `json:"labels"` // Timestamps of the data points
+	Series []MetricSeries `json:"series"` // Sorted by resource ID
+}
+
+// LogResult is the log entries of a time range
+type LogResult struct {
+	Start time.Time         `json:"start"`
+	End   time.Time         `json:"end"`
+	Logs  []json.RawMessage `json:"logs"`
+}
+
+// GetMetricData calls Metric.get_data of the monitoring service with a simplified time range
+// and returns a series per resource. Query parameters: data_source_id, resource_type, metric,
+// resource_id (repeatable), stat, period (seconds), and either range (e.g. 6h, 7d) or start
+// and end (RFC 3339). With follow=true the sliding range is re-queried every interval
+// (default 1m) and sent as server-sent events.
+func (h *Handler) GetMetricData(c echo.Context) error {
+	for _, name := range []string{"data_source_id", "resource_type", "metric", "resource_id"} {
+		if c.QueryParam(name) == "" {
+			return response.BadRequest(c, "Invalid metric query", name+" is required")
+		}
+	}
+	fetch := func(ctx context.Context, start, end time.Time) (interface{}, *errors.APIError) {
+		parameters := map[string]interface{}{
+			"data_source_id": c.QueryParam("data_source_id"),
+			"resource_type":  c.QueryParam("resource_type"),
+			"resources":      c.QueryParams()["resource_id"],
+			"metric":         c.QueryParam("metric"),
+			"start":          start.Format(time.RFC3339),
+			"end":            end.Format(time.RFC3339),
+		}
+		if stat := c.QueryParam("stat"); stat != "" {
+			parameters["stat"] = strings.ToUpper(stat)
+		}
+		if period := c.QueryParam("period"); period != "" {
+			parameters["period"] = period
+		}
+		jsonBytes, apiErr := h.invoke(ctx, monitoringService, "Metric", "get_data", parameters)
+		if apiErr != nil {
+			return nil, apiErr
+		}
+		result, err := metricResult(jsonBytes)
+		if err != nil {
+			return nil, errors.NewAPIError(errors.ErrResponseConversionFailed, err.Error())
+		}
+		result.Start, result.End = start, end
+		return result, nil
+	}
+	return h.monitoringQuery(c, monitoringEventMetrics, defaultMetricsFollowInterval, false, fetch)
+}
+
+// ListLogs calls Log.list of the monitoring service with a simplified time range. Query
+// parameters: data_source_id, resource_id, keyword, limit, and either range (e.g. 15m) or
+// start and end (RFC 3339). With follow=true the entries logged since the previous query
+// are sent every interval (default 10s) as server-sent events, like tail -f.
+func (h *Handler) ListLogs(c echo.Context) error {
+	for _, name := range []string{"data_source_id", "resource_id"} {
+		if c.QueryParam(name) == "" {
+			return response.BadRequest(c, "Invalid log query", name+" is required")
+		}
+	}
+	fetch := func(ctx context.Context, start, end time.Time) (interface{}, *errors.APIError) {
+		parameters := map[string]interface{}{
+			"data_source_id": c.QueryParam("data_source_id"),
+			"resource_id":    c.QueryParam("resource_id"),
+			"start":          start.Format(time.RFC3339),
+			"end":            end.Format(time.RFC3339),
+		}
+		if keyword := c.QueryParam("keyword"); keyword != "" {
+			parameters["keyword"] = keyword
+		}
+		if limit := c.QueryParam("limit"); limit != "" {
+			parameters["limit"] = limit
+		}
+		jsonBytes, apiErr := h.invoke(ctx, monitoringService, "Log", "list", parameters)
+		if apiErr != nil {
+			return nil, apiErr
+		}
+		logs, err := logEntries(jsonBytes)
+		if err != nil {
+			return nil, errors.NewAPIError(errors.ErrResponseConversionFailed, err.Error())
+		}
+		return &LogResult{Start: start, End: end, Logs: logs}, nil
+	}
+	return h.monitoringQuery(c, monitoringEventLogs, defaultLogsFollowInterval, true, fetch)
+}
+
+// monitoringQuery runs fetch over the requested time range and returns its result, or with
+// follow=true keeps running it and sends each result as an event. Incremental queries start
+// where the previous one ended; others keep the length of the range.
+func (h *Handler) monitoringQuery(c echo.Context, event string, defaultInterval time.Duration, incremental bool,
+	fetch func(ctx context.Context, start, end time.Time) (interface{}, *errors.APIError)) error {
+	now := time.Now().UTC().Truncate(time.Second)
+	start, end, err := timeRange(c.QueryParam("range"), c.QueryParam("start"), c.QueryParam("end"), now)
+	if err != nil {
+		return response.BadRequest(c, "Invalid time range", err.Error())
+	}
+
+	ctx := grpc.WithRequestID(c.Request().Context(), c.Response().Header().Get(echo.HeaderXRequestID))
+	if follow, _ := strconv.ParseBool(c.QueryParam("follow")); !follow {
+		result, apiErr := fetch(ctx, start, end)
+		if apiErr != nil {
+			return response.APIError(c, apiErr)
+		}
+		return response.Success(c, result)
+	}
+
+	interval := defaultInterval
+	if value := c.QueryParam("interval"); value != "" {
+		interval, err = time.ParseDuration(value)
+		if err != nil || interval < minFollowInterval {
+			return response.BadRequest(c, "Invalid interval", fmt.Sprintf("interval must be a duration of at least %s", minFollowInterval))
+		}
+	}
+
+	// Report failures of the first query as a normal error response
+	result, apiErr := fetch(ctx, start, end)
+	if apiErr != nil {
+		return response.APIError(c, apiErr)
+	}
+	sse := response.NewSSE(c)
+	if err := sse.Event(event, result); err != nil {
+		return nil
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-heartbeat.C:
+			if err := sse.Comment("keep-alive"); err != nil {
+				return nil
+			}
+		case now := <-ticker.C:
+			now = now.UTC().Truncate(time.Second)
+			nextStart := now.Add(start.Sub(end))
+			if incremental {
+				nextStart = end
+			}
+			result, apiErr := fetch(ctx, nextStart, now)
+			if apiErr != nil {
+				// Keep following, since monitoring queries often fail transiently. Incremental
+				// queries retry the missed time with the next one.
+				if err := sse.Event(monitoringEventError, response.NewErrorInfo(apiErr.Localize(response.Language(c)))); err != nil {
+					return nil
+				}
+				continue
+			}
+			start, end = nextStart, now
+			if err := sse.Event(event, result); err != nil {
+				return nil
+			}
+		}
+	}
+}
+
+// timeRange returns the time range of a monitoring query, from a range such as 6h or 7d
+// ending now, or from RFC 3339 start and end times where end defaults to now
+func timeRange(length, start, end string, now time.Time) (time.Time, time.Time, error) {
+	if length != "" && start != "" {
+		return time.Time{}, time.Time{}, fmt.Errorf("set either range or start, not both")
+	}
+	endTime := now
+	if end != "" {
+		parsed, err := time.Parse(time.RFC3339, end)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("end must be an RFC 3339 time, e.g. 2024-01-02T15:04:05Z")
+		}
+		endTime = parsed.UTC()
+	}
+	if start != "" {
+		parsed, err := time.Parse(time.RFC3339, start)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("start must be an RFC 3339 time, e.g. 2024-01-02T15:04:05Z")
+		}
+		if !parsed.Before(endTime) {
+			return time.Time{}, time.Time{}, fmt.Errorf("start must be before end")
+		}
+		return parsed.UTC(), endTime, nil
+	}
+
+	duration := defaultMonitoringRange
+	if length != "" {
+		parsed, err := parseRange(length)
+		if err != nil || parsed <= 0 {
+			return time.Time{}, time.Time{}, fmt.Errorf("range must be a positive duration such as 15m, 6h, or 7d")
+		}
+		duration = parsed
+	}
+	return endTime.Add(-duration), endTime, nil
+}
+
+// parseRange parses a duration, also accepting a number of days such as 7d
+func parseRange(value string) (time.Duration, error) {
+	if days, found := strings.CutSuffix(value, "d"); found {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// metricResult converts a Metric.get_data response, with labels and values by resource, into series
+func metricResult(jsonBytes []byte) (*MetricResult, error) {
+	var data struct {
+		Labels         []string              `json:"labels"`
+		ResourceValues map[string][]*float64 `json:"resource_values"`
+	}
+	if err := json.Unmarshal(jsonBytes, &data); err != nil {
+		return nil, err
+	}
+	result := &MetricResult{Labels: data.Labels, Series: []MetricSeries{}}
+	if result.Labels == nil {
+		result.Labels = []string{}
+	}
+	for resourceID, values := range data.ResourceValues {
+		result.Series = append(result.Series, MetricSeries{ResourceID: resourceID, Values: values})
+	}
+	sort.Slice(result.Series, func(i, j int) bool {
+		return result.Series[i].ResourceID < result.Series[j].ResourceID
+	})
+	return result, nil
+}
+
+// logEntries returns the entries of a Log.list response, found under logs or results
+func logEntries(jsonBytes []byte) ([]json.RawMessage, error) {
+	var data struct {
+		Logs    []json.RawMessage `json:"logs"`
+		Results []json.RawMessage `json:"results"`
+	}
+	if err := json.Unmarshal(jsonBytes, &data); err != nil {
+		return nil, err
+	}
+	if data.Logs != nil {
+		return data.Logs, nil
+	}
+	if data.Results != nil {
+		return data.Results, nil
+	}
+	return []json.RawMessage{}, nil
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+// maxPaginationCapture is the largest response whose pagination is reported.
+// Larger responses are streamed without keeping a copy to inspect.
+const maxPaginationCapture = 8 << 20
+
+// Pagination is the paging state of a list response, normalized across services
+type Pagination struct {
+	Total    *int64 `json:"total"` // Null when the verb does not report a total count
+	Page     int64  `json:"page"`
+	PageSize int64  `json:"page_size"`
+	HasMore  bool   `json:"has_more"`
+}
+
+// paginationCapture keeps a copy of a response written through it, up to maxPaginationCapture bytes
+type paginationCapture struct {
+	buffer   bytes.Buffer
+	overflow bool
+}
+
+// Write copies p unless the response has grown too large to keep
+func (p *paginationCapture) Write(data []byte) (int, error) {
+	if p.overflow {
+		return len(data), nil
+	}
+	if p.buffer.Len()+len(data) > maxPaginationCapture {
+		p.overflow = true
+		p.buffer = bytes.Buffer{}
+		return len(data), nil
+	}
+	return p.buffer.Write(data)
+}
+
+// Pagination returns the paging state of the captured response, or nil when it is not a list
+// response with results or was too large to keep
+func (p *paginationCapture) Pagination(parameters map[string]interface{}) *Pagination {
+	if p.overflow {
+		return nil
+	}
+	return responsePagination(p.buffer.Bytes(), parameters)
+}
+
+// responsePagination derives the paging state of a response following the results and
+// total_count conventions from the query.page of the request parameters
+func responsePagination(jsonBytes []byte, parameters map[string]interface{}) *Pagination {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(jsonBytes, &fields); err != nil {
+		return nil
+	}
+	raw, found := fields["results"]
+	if !found {
+		return nil
+	}
+	var results []json.RawMessage
+	if err := json.Unmarshal(raw, &results); err != nil {
+		return nil
+	}
+
+	// query.page.start is 1-based
+	start, limit := int64(1), int64(0)
+	if query, ok := parameters["query"].(map[string]interface{}); ok {
+		if page, ok := query["page"].(map[string]interface{}); ok {
+			start = max(pageNumber(page["start"], 1), 1)
+			limit = max(pageNumber(page["limit"], 0), 0)
+		}
+	}
+
+	pagination := &Pagination{Page: 1, PageSize: limit}
+	if limit > 0 {
+		pagination.Page = (start-1)/limit + 1
+	} else {
+		pagination.PageSize = int64(len(results))
+	}
+
+	returned := start - 1 + int64(len(results))
+	total, found, err := totalCountField(fields)
+	switch {
+	case err != nil:
+		return nil
+	case found || len(results) == 0:
+		pagination.Total = &total
+		pagination.HasMore = returned < total
+	default:
+		// Without a total, a full page suggests there are more results
+		pagination.HasMore = limit > 0 && int64(len(results)) >= limit
+	}
+	return pagination
+}
+
+// pageNumber reads a query.page value given as a number or a numeric string
+func pageNumber(value interface{}, fallback int64) int64 {
+	switch v := value.(type) {
+	case int:
+		return int64(v)
+	case float64:
+		return int64(v)
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+	case string:
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+	}
+	return fallback
+}
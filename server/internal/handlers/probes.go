@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"spacectl-web/server/internal/errors"
+	"spacectl-web/server/internal/readiness"
+	"spacectl-web/server/internal/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SetReadiness makes the readiness probe report the gate instead of always being ready
+func (h *Handler) SetReadiness(gate *readiness.Gate) {
+	h.readiness = gate
+}
+
+// Healthz is the liveness probe: the server is alive whenever it answers
+func (h *Handler) Healthz(c echo.Context) error {
+	return response.Success(c, map[string]string{"status": "ok"})
+}
+
+// Readyz is the readiness probe. It fails with 503 while the server waits for endpoints to
+// become reachable at startup.
+func (h *Handler) Readyz(c echo.Context) error {
+	if h.readiness == nil {
+		return response.Success(c, &readiness.Status{Ready: true})
+	}
+	status := h.readiness.Status()
+	if !status.Ready {
+		details := "waiting for endpoints to be checked"
+		if len(status.WaitingFor) > 0 {
+			details = fmt.Sprintf("waiting for endpoints of %s (attempt %d)", strings.Join(status.WaitingFor, ", "), status.Attempts)
+		}
+		return response.APIError(c, errors.NewAPIError(errors.ErrNotReady, details))
+	}
+	return response.Success(c, &status)
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"sort"
+
+	"spacectl-web/server/internal/grpc"
+	"spacectl-web/server/internal/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// secretService is the service holding the credentials of service and trusted accounts
+const secretService = "secret"
+
+// LinkedSecret is a secret or trusted secret with the name of its schema. Secret data is never
+// included.
+type LinkedSecret struct {
+	SecretID   string `json:"secret_id"`
+	Name       string `json:"name,omitempty"`
+	SchemaID   string `json:"schema_id,omitempty"`
+	SchemaName string `json:"schema_name,omitempty"`
+}
+
+// LinkedTrustedAccount is a trusted account with its trusted secrets
+type LinkedTrustedAccount struct {
+	TrustedAccountID    string         `json:"trusted_account_id"`
+	Name                string         `json:"name,omitempty"`
+	Provider            string         `json:"provider,omitempty"`
+	ResourceGroup       string         `json:"resource_group,omitempty"`
+	WorkspaceID         string         `json:"workspace_id,omitempty"`
+	ServiceAccountCount int            `json:"service_account_count"`
+	TrustedSecrets      []LinkedSecret `json:"trusted_secrets"`
+}
+
+// LinkedServiceAccount is a service account with its trusted account and secrets
+type LinkedServiceAccount struct {
+	ServiceAccountID   string         `json:"service_account_id"`
+	Name               string         `json:"name,omitempty"`
+	Provider           string         `json:"provider,omitempty"`
+	State              string         `json:"state,omitempty"`
+	WorkspaceID        string         `json:"workspace_id,omitempty"`
+	ProjectID          string         `json:"project_id,omitempty"`
+	TrustedAccountID   string         `json:"trusted_account_id,omitempty"`
+	TrustedAccountName string         `json:"trusted_account_name,omitempty"`
+	Secrets            []LinkedSecret `json:"secrets"`
+}
+
+// ProviderConnections is how the service and trusted accounts connect to providers
+type ProviderConnections struct {
+	ServiceAccounts []LinkedServiceAccount `json:"service_accounts"` // Sorted by provider and name
+	TrustedAccounts []LinkedTrustedAccount `json:"trusted_accounts"` // Sorted by provider and name
+	Warnings        []string               `json:"warnings,omitempty"`
+}
+
+// GetProviderConnections lists service accounts, trusted accounts, and schemas of the identity
+// service with the secrets and trusted secrets of the secret service, and links them into one
+// payload. Only IDs and names of secrets are returned, never their data. The optional provider
+// query parameter narrows the lists. Secrets and schemas are optional: when they cannot be
+// listed, the accounts are returned without them and a warning.
+func (h *Handler) GetProviderConnections(c echo.Context) error {
+	var filters []interface{}
+	if provider := c.QueryParam("provider"); provider != "" {
+		filters = []interface{}{map[string]interface{}{"k": "provider", "v": provider, "o": "eq"}}
+	}
+	queries := map[string]*resourceList{
+		"ServiceAccount": {Service: identityService, Resource: "ServiceAccount", Filters: filters},
+		"TrustedAccount": {Service: identityService, Resource: "TrustedAccount", Filters: filters},
+		"Schema":         {Service: identityService, Resource: "Schema", Filters: filters, Optional: true},
+		"Secret":         {Service: secretService, Resource: "Secret", Filters: filters, Optional: true},
+		"TrustedSecret":  {Service: secretService, Resource: "TrustedSecret", Filters: filters, Optional: true},
+	}
+
+	ctx := grpc.WithRequestID(c.Request().Context(), c.Response().Header().Get(echo.HeaderXRequestID))
+	lists, warnings, apiErr := h.listConcurrently(ctx, queries)
+	if apiErr != nil {
+		return response.APIError(c, apiErr)
+	}
+	connections := linkConnections(lists)
+	connections.Warnings = warnings
+	return response.Success(c, connections)
+}
+
+// linkConnections attaches secrets to their accounts and service accounts to their trusted accounts
+func linkConnections(lists map[string]*listResult) *ProviderConnections {
+	schemaNames := make(map[string]string)
+	for _, schema := range lists["Schema"].Results {
+		schemaNames[stringField(schema, "schema_id")] = stringField(schema, "name")
+	}
+	linkedSecret := func(result map[string]interface{}, idField string) LinkedSecret {
+		secret := LinkedSecret{SecretID: stringField(result, idField), Name: stringField(result, "name"), SchemaID: stringField(result, "schema_id")}
+		secret.SchemaName = schemaNames[secret.SchemaID]
+		return secret
+	}
+
+	secrets := make(map[string][]LinkedSecret) // By service account ID
+	for _, secret := range lists["Secret"].Results {
+		serviceAccountID := stringField(secret, "service_account_id")
+		secrets[serviceAccountID] = append(secrets[serviceAccountID], linkedSecret(secret, "secret_id"))
+	}
+	trustedSecrets := make(map[string][]LinkedSecret) // By trusted account ID
+	for _, secret := range lists["TrustedSecret"].Results {
+		trustedAccountID := stringField(secret, "trusted_account_id")
+		trustedSecrets[trustedAccountID] = append(trustedSecrets[trustedAccountID], linkedSecret(secret, "trusted_secret_id"))
+	}
+
+	connections := &ProviderConnections{ServiceAccounts: []LinkedServiceAccount{}, TrustedAccounts: []LinkedTrustedAccount{}}
+	trustedAccounts := make(map[string]int) // Index by trusted account ID
+	for _, account := range lists["TrustedAccount"].Results {
+		id := stringField(account, "trusted_account_id")
+		linked := LinkedTrustedAccount{
+			TrustedAccountID: id,
+			Name:             stringField(account, "name"),
+			Provider:         stringField(account, "provider"),
+			ResourceGroup:    stringField(account, "resource_group"),
+			WorkspaceID:      stringField(account, "workspace_id"),
+			TrustedSecrets:   trustedSecrets[id],
+		}
+		if linked.TrustedSecrets == nil {
+			linked.TrustedSecrets = []LinkedSecret{}
+		}
+		trustedAccounts[id] = len(connections.TrustedAccounts)
+		connections.TrustedAccounts = append(connections.TrustedAccounts, linked)
+	}
+
+	for _, account := range lists["ServiceAccount"].Results {
+		id := stringField(account, "service_account_id")
+		linked := LinkedServiceAccount{
+			ServiceAccountID: id,
+			Name:             stringField(account, "name"),
+			Provider:         stringField(account, "provider"),
+			State:            stringField(account, "state"),
+			WorkspaceID:      stringField(account, "workspace_id"),
+			ProjectID:        stringField(account, "project_id"),
+			TrustedAccountID: stringField(account, "trusted_account_id"),
+			Secrets:          secrets[id],
+		}
+		if linked.Secrets == nil {
+			linked.Secrets = []LinkedSecret{}
+		}
+		if i, found := trustedAccounts[linked.TrustedAccountID]; found {
+			linked.TrustedAccountName = connections.TrustedAccounts[i].Name
+			connections.TrustedAccounts[i].ServiceAccountCount++
+		}
+		connections.ServiceAccounts = append(connections.ServiceAccounts, linked)
+	}
+
+	sort.Slice(connections.ServiceAccounts, func(i, j int) bool {
+		a, b := connections.ServiceAccounts[i], connections.ServiceAccounts[j]
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		return a.Name < b.Name
+	})
+	sort.Slice(connections.TrustedAccounts, func(i, j int) bool {
+		a, b := connections.TrustedAccounts[i], connections.TrustedAccounts[j]
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		return a.Name < b.Name
+	})
+	return connections
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"net/http"
+
+	"spacectl-web/server/internal/recording"
+	"spacectl-web/server/internal/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// defaultFixturePackage is the package name used for exported Go fixtures
+const defaultFixturePackage = "fixtures"
+
+// SetRecordings sets the store used to list and export recorded responses
+func (h *Handler) SetRecordings(store *recording.Store) {
+	h.recordings = store
+}
+
+// recordingFilter builds a recording filter from the query parameters
+func recordingFilter(c echo.Context) recording.Filter {
+	return recording.Filter{
+		Service:  c.QueryParam("service"),
+		Resource: c.QueryParam("resource"),
+		Verb:     c.QueryParam("verb"),
+	}
+}
+
+// ListRecordings returns the recorded request/response pairs
+func (h *Handler) ListRecordings(c echo.Context) error {
+	recordings, err := h.recordings.List(recordingFilter(c))
+	if err != nil {
+		return response.InternalServerError(c, "Failed to list recordings", err.Error())
+	}
+	return response.Success(c, recordings)
+}
+
+// ExportRecordings exports recorded request/response pairs as golden files or Go test fixtures
+func (h *Handler) ExportRecordings(c echo.Context) error {
+	if h.config.Server.UI.DisableExports {
+		return response.Forbidden(c, "Exports are disabled", "unset server.ui.disable_exports to allow exports")
+	}
+	recordings, err := h.recordings.List(recordingFilter(c))
+	if err != nil {
+		return response.InternalServerError(c, "Failed to list recordings", err.Error())
+	}
+	if len(recordings) == 0 {
+		return response.NotFound(c, "No recordings found", fmt.Sprintf("no recordings in '%s' match the filter", h.recordings.Dir()))
+	}
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = recording.FormatGolden
+	}
+
+	// Render before sending, so a failure is reported instead of a truncated download
+	var body bytes.Buffer
+	switch format {
+	case recording.FormatGolden:
+		if err := recording.ExportGolden(&body, recordings); err != nil {
+			return response.InternalServerError(c, "Failed to export recordings", err.Error())
+		}
+		c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="fixtures.zip"`)
+		return c.Blob(http.StatusOK, "application/zip", body.Bytes())
+	case recording.FormatGo:
+		packageName := c.QueryParam("package")
+		if packageName == "" {
+			packageName = defaultFixturePackage
+		}
+		if !token.IsIdentifier(packageName) {
+			return response.BadRequest(c, "Invalid package name", fmt.Sprintf("'%s' is not a Go identifier", packageName))
+		}
+		if err := recording.ExportGo(&body, packageName, recordings); err != nil {
+			return response.InternalServerError(c, "Failed to export recordings", err.Error())
+		}
+		c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="fixtures_test.go"`)
+		return c.Blob(http.StatusOK, echo.MIMETextPlainCharsetUTF8, body.Bytes())
+	default:
+		return response.BadRequest(c, "Unsupported export format", fmt.Sprintf("format must be '%s' or '%s'", recording.FormatGolden, recording.FormatGo))
+	}
+}
@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"spacectl-web/server/internal/config"
+	"spacectl-web/server/internal/constants"
+	"spacectl-web/server/internal/errors"
+	"spacectl-web/server/internal/grpc"
+	"spacectl-web/server/internal/response"
+	"spacectl-web/server/internal/storage"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Report limits
+const (
+	reportMaxItems     = 20
+	reportMaxRows      = 500 // Rows rendered per section
+	reportMaxCellChars = 200
+	reportDefaultTitle = "spacectl-web report"
+)
+
+// Kinds of report items
+const (
+	ReportItemView     = "view"     // A saved view, rendered with its columns and filters
+	ReportItemFavorite = "favorite" // A saved request, rendered with the sorted fields of its results
+)
+
+// ReportRequest chooses the saved items a report renders, in order
+type ReportRequest struct {
+	Title string       `json:"title,omitempty"`
+	Items []ReportItem `json:"items"`
+}
+
+// ReportItem is a saved view or favorite to render
+type ReportItem struct {
+	Kind string `json:"kind"` // view or favorite
+	ID   string `json:"id"`
+}
+
+// reportSection is a rendered item
+type reportSection struct {
+	Name        string
+	Description string
+	Call        string // service.resource.verb
+	Columns     []string
+	Rows        [][]string
+	Total       int // Rows before the section was cut to reportMaxRows
+	Error       string
+}
+
+// reportPage is the data of the report template
+type reportPage struct {
+	Title     string
+	Generated string
+	Profile   string
+	Version   string
+	Sections  []reportSection
+}
+
+// filenameUnsafe matches characters left out of report file names
+var filenameUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// reportTemplate renders a standalone report: inline styles, no scripts, and a print
+// stylesheet so browsers can save it as a PDF
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Roboto, sans-serif; margin: 2rem; color: #161616; }
+header p, .meta { color: #525252; font-size: 0.875rem; }
+section { margin-top: 2.5rem; }
+h2 { margin-bottom: 0.25rem; }
+.error { color: #da1e28; }
+table { border-collapse: collapse; width: 100%; font-size: 0.8125rem; margin-top: 0.75rem; }
+th, td { border: 1px solid #e0e0e0; padding: 0.375rem 0.5rem; text-align: left; vertical-align: top; word-break: break-word; }
+th { background: #f4f4f4; }
+tr:nth-child(even) td { background: #fafafa; }
+@media print { body { margin: 0; } section { break-inside: avoid-page; } th { background: #eee; } }
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Title}}</h1>
+<p>Generated {{.Generated}} from profile {{.Profile}} by spacectl-web {{.Version}}</p>
+</header>
+{{range .Sections}}<section>
+<h2>{{.Name}}</h2>
+<p class="meta">{{.Call}}{{if .Description}} · {{.Description}}{{end}}</p>
+{{if .Error}}<p class="error">{{.Error}}</p>
+{{else if not .Rows}}<p>No results</p>
+{{else}}<table>
+<thead><tr>{{range .Columns}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{range .Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+{{if gt .Total (len .Rows)}}<p class="meta">Showing {{len .Rows}} of {{.Total}} rows</p>{{end}}
+{{end}}</section>
+{{end}}</body>
+</html>
+`))
+
+// CreateReport renders saved views and favorites into a standalone HTML report for people
+// without access to the tool. Each item's request is run now; an item that fails, or whose
+// verb may change data, is shown with its error instead of failing the report. Views are read
+// by ID like RunView, and favorites must belong to the caller.
+func (h *Handler) CreateReport(c echo.Context) error {
+	var req ReportRequest
+	if err := c.Bind(&req); err != nil {
+		if apiErr := bodyTooLarge(err); apiErr != nil {
+			return response.APIError(c, apiErr)
+		}
+		return response.BadRequest(c, "Invalid request body", err.Error())
+	}
+	if fields := validateReport(&req); len(fields) > 0 {
+		return response.APIError(c, errors.NewValidationError("report", fields))
+	}
+	if req.Title == "" {
+		req.Title = reportDefaultTitle
+	}
+
+	ctx := grpc.WithRequestID(c.Request().Context(), c.Response().Header().Get(echo.HeaderXRequestID))
+	language := response.Language(c)
+	page := &reportPage{
+		Title:     req.Title,
+		Generated: time.Now().UTC().Format("2006-01-02 15:04 UTC"),
+		Profile:   h.config.ActiveProfile(),
+		Version:   constants.Version,
+	}
+	for _, item := range req.Items {
+		page.Sections = append(page.Sections, h.reportSection(ctx, h.user(c), item, language))
+	}
+
+	var body bytes.Buffer
+	if err := reportTemplate.Execute(&body, page); err != nil {
+		return response.InternalServerError(c, "Failed to render report", err.Error())
+	}
+	filename := strings.Trim(filenameUnsafe.ReplaceAllString(req.Title, "-"), "-")
+	if filename == "" {
+		filename = "report"
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": filename + ".html"}))
+	return c.HTMLBlob(http.StatusOK, body.Bytes())
+}
+
+// validateReport reports the invalid fields of a report request
+func validateReport(req *ReportRequest) []errors.FieldError {
+	if len(req.Items) == 0 || len(req.Items) > reportMaxItems {
+		return []errors.FieldError{{Field: "items", Message: fmt.Sprintf("must have 1 to %d items", reportMaxItems)}}
+	}
+	var fields []errors.FieldError
+	for i, item := range req.Items {
+		if item.Kind != ReportItemView && item.Kind != ReportItemFavorite {
+			fields = append(fields, errors.FieldError{Field: fmt.Sprintf("items[%d].kind", i), Message: fmt.Sprintf("must be '%s' or '%s'", ReportItemView, ReportItemFavorite)})
+		}
+		if item.ID == "" {
+			fields = append(fields, errors.FieldError{Field: fmt.Sprintf("items[%d].id", i), Message: "required"})
+		}
+	}
+	return fields
+}
+
+// reportSection runs a report item and renders its rows
+func (h *Handler) reportSection(ctx context.Context, user string, item ReportItem, language string) reportSection {
+	section := reportSection{Name: item.Kind + " " + item.ID}
+	fail := func(apiErr *errors.APIError) reportSection {
+		section.Error = apiErr.Localize(language).Message
+		if apiErr.Details != "" {
+			section.Error += ": " + apiErr.Details
+		}
+		return section
+	}
+
+	kind := storage.KindView
+	if item.Kind == ReportItemFavorite {
+		kind = storage.KindFavorite
+	}
+	record, err := h.store.Get(ctx, kind, item.ID)
+	if err == nil && kind == storage.KindFavorite && record.Owner != user {
+		err = storage.ErrNotFound
+	}
+	if err != nil {
+		return fail(errors.NewAPIError(errors.ErrInvalidParameters, fmt.Sprintf("%s '%s' not found", item.Kind, item.ID)))
+	}
+
+	var view storage.View
+	if kind == storage.KindFavorite {
+		var favorite storage.Favorite
+		err = json.Unmarshal(record.Data, &favorite)
+		view = storage.View{Name: favorite.Name, Request: favorite.Request}
+	} else {
+		err = json.Unmarshal(record.Data, &view)
+	}
+	if err != nil {
+		return fail(errors.NewAPIError(errors.ErrInvalidParameters, err.Error()))
+	}
+	section.Name = view.Name
+	section.Description = view.Description
+	section.Call = view.Request.Service + "." + view.Request.Resource + "." + view.Request.Verb
+	if !config.IsReadVerb(view.Request.Verb) {
+		return fail(errors.NewAPIError(errors.ErrVerbNotSupported, fmt.Sprintf("reports only run verbs that read data; verb '%s' may change data", view.Request.Verb)))
+	}
+
+	rows, apiErr := h.runView(ctx, &view, nil)
+	if apiErr != nil {
+		return fail(apiErr)
+	}
+	section.Columns = view.Columns
+	if len(section.Columns) == 0 {
+		section.Columns = rowColumns(rows)
+	}
+	section.Total = len(rows)
+	for _, row := range rows[:min(len(rows), reportMaxRows)] {
+		cells := make([]string, len(section.Columns))
+		for i, column := range section.Columns {
+			cells[i] = reportCell(row[column])
+		}
+		section.Rows = append(section.Rows, cells)
+	}
+	return section
+}
+
+// reportCell formats a value for a report table cell, shortening long values
+func reportCell(value interface{}) string {
+	cell := csvValue(value)
+	if runes := []rune(cell); len(runes) > reportMaxCellChars {
+		cell = string(runes[:reportMaxCellChars]) + "…"
+	}
+	return cell
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"spacectl-web/server/internal/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetResponseSchema returns the fields of a verb's response message. For list verbs it also
+// returns the fields of each result and the columns a table of them shows by default.
+func (h *Handler) GetResponseSchema(c echo.Context) error {
+	serviceName := c.Param("service")
+	resourceName := c.Param("resource")
+	verb := c.Param("verb")
+
+	serviceCaller, apiErr := h.serviceCaller(serviceName, resourceName, verb)
+	if apiErr != nil {
+		return response.APIError(c, apiErr)
+	}
+	schema, err := serviceCaller.DescribeResponse(serviceName, resourceName, verb)
+	if err != nil {
+		return response.APIError(c, toAPIError(err))
+	}
+	return response.Success(c, schema)
+}
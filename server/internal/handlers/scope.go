@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	stderrors "errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"spacectl-web/server/internal/config"
+	"spacectl-web/server/internal/errors"
+	"spacectl-web/server/internal/grant"
+	"spacectl-web/server/internal/grpc"
+	"spacectl-web/server/internal/jwt"
+	"spacectl-web/server/internal/response"
+	"spacectl-web/server/internal/session"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ScopeRequest asks for a token narrowed to a domain or workspace
+type ScopeRequest struct {
+	Scope       string `json:"scope"` // DOMAIN or WORKSPACE; WORKSPACE when workspace_id is set
+	DomainID    string `json:"domain_id"`
+	WorkspaceID string `json:"workspace_id"`
+	ProjectID   string `json:"project_id"`
+}
+
+// SetScopes enables scoped tokens. Grants go through their own manager so the tokens they
+// carry are never recorded.
+func (h *Handler) SetScopes(store *session.Store, manager *grpc.ClientManager) {
+	h.scopes = store
+	h.scopeManager = manager
+}
+
+// GetScope returns the scoped token of the session, or null when calls use the configured token
+func (h *Handler) GetScope(c echo.Context) error {
+	if h.scopes == nil {
+		return response.Success(c, nil)
+	}
+	if cookie, err := c.Cookie(session.CookieName); err == nil {
+		if scoped, exists := h.scopes.Get(cookie.Value); exists {
+			return response.Success(c, scoped)
+		}
+	}
+	return response.Success(c, nil)
+}
+
+// CreateScope exchanges the configured token for a token scoped to a domain or workspace
+// through the identity grant API and uses it for the session's subsequent calls
+func (h *Handler) CreateScope(c echo.Context) error {
+	if h.scopes == nil {
+		return response.BadRequest(c, "Scoped tokens not available", "scoped tokens cannot be granted in offline mode")
+	}
+
+	var req ScopeRequest
+	if err := c.Bind(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", err.Error())
+	}
+	if req.ProjectID != "" {
+		return response.BadRequest(c, "Invalid scope", "identity grants are scoped to a workspace at most; scope the token to the project's workspace")
+	}
+	req.Scope = strings.ToUpper(req.Scope)
+	if req.Scope == "" {
+		req.Scope = config.GrantScopeDomain
+		if req.WorkspaceID != "" {
+			req.Scope = config.GrantScopeWorkspace
+		}
+	}
+	if req.Scope != config.GrantScopeDomain && req.Scope != config.GrantScopeWorkspace {
+		return response.BadRequest(c, "Invalid scope", "scope must be DOMAIN or WORKSPACE")
+	}
+	if req.Scope == config.GrantScopeWorkspace && req.WorkspaceID == "" {
+		return response.BadRequest(c, "Invalid scope", "workspace_id is required for the WORKSPACE scope")
+	}
+
+	// An app token is exchanged directly; otherwise the configured token is
+	token, settings := h.config.GetToken(), h.config.GetGrant().WithDefaults()
+	if settings.Enabled() {
+		appToken, err := settings.ResolveAppToken()
+		if err != nil {
+			return response.InternalServerError(c, "Failed to grant scoped token", err.Error())
+		}
+		token = appToken
+	}
+	if token == "" {
+		return response.BadRequest(c, "Token not configured", "a token is required to grant a scoped token")
+	}
+	if req.DomainID == "" {
+		req.DomainID = settings.DomainID
+	}
+	if req.DomainID == "" {
+		req.DomainID = tokenDomainID(h.config.GetToken())
+	}
+	if req.DomainID == "" {
+		return response.BadRequest(c, "Invalid scope", "domain_id is required")
+	}
+
+	scopedToken, expiresAt, err := grant.Exchange(c.Request().Context(), h.scopeManager, settings.Service, grant.Request{
+		Token:       token,
+		Scope:       req.Scope,
+		DomainID:    req.DomainID,
+		WorkspaceID: req.WorkspaceID,
+	})
+	if err != nil {
+		var apiErr *errors.APIError
+		if stderrors.As(err, &apiErr) {
+			return response.APIError(c, apiErr)
+		}
+		return response.Error(c, http.StatusBadGateway, "Failed to grant scoped token", err.Error())
+	}
+
+	scoped := &session.ScopedToken{
+		Token:       scopedToken,
+		Scope:       req.Scope,
+		DomainID:    req.DomainID,
+		WorkspaceID: req.WorkspaceID,
+		CreatedAt:   time.Now(),
+	}
+	if !expiresAt.IsZero() {
+		scoped.ExpiresAt = &expiresAt
+	}
+
+	h.scopes.Set(h.sessionID(c), scoped)
+	return response.Success(c, scoped)
+}
+
+// sessionID returns the ID of the request's session, starting a new one unless the client
+// sent the ID of a session this server knows
+func (h *Handler) sessionID(c echo.Context) string {
+	if cookie, err := c.Cookie(session.CookieName); err == nil && h.scopes.Exists(cookie.Value) {
+		return cookie.Value
+	}
+	sessionID := session.NewID()
+	c.SetCookie(&http.Cookie{
+		Name:     session.CookieName,
+		Value:    sessionID,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		SameSite: http.SameSiteStrictMode,
+	})
+	return sessionID
+}
+
+// DeleteScope drops the session's scoped token so its calls use the configured token again
+func (h *Handler) DeleteScope(c echo.Context) error {
+	if h.scopes != nil {
+		if cookie, err := c.Cookie(session.CookieName); err == nil {
+			h.scopes.Delete(cookie.Value)
+		}
+	}
+	return response.Success(c, nil)
+}
+
+// tokenDomainID returns the domain ID claim of a SpaceONE token, if any
+func tokenDomainID(token string) string {
+	info, err := jwt.Parse(token)
+	if err != nil {
+		return ""
+	}
+	domainID, _ := info.Payload["did"].(string)
+	return domainID
+}
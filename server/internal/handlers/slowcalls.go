@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"spacectl-web/server/internal/grpc"
+	"spacectl-web/server/internal/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SetSlowCalls sets the slow call log exposed by the API
+func (h *Handler) SetSlowCalls(slowCalls *grpc.SlowCallLog) {
+	h.slowCalls = slowCalls
+}
+
+// ListSlowCalls returns the latest calls slower than the threshold, newest first, with the
+// names of their parameters but not their values. ?service=, ?resource= and ?verb= narrow
+// the list to matching calls.
+func (h *Handler) ListSlowCalls(c echo.Context) error {
+	if h.slowCalls == nil {
+		return response.NotFound(c, "Slow call log is disabled", "set server.slow_calls.enabled to true in the config file")
+	}
+	report := h.slowCalls.Report()
+	calls := report.Calls[:0]
+	for _, call := range report.Calls {
+		if matchesParam(c, "service", call.Service) && matchesParam(c, "resource", call.Resource) && matchesParam(c, "verb", call.Verb) {
+			calls = append(calls, call)
+		}
+	}
+	report.Calls = calls
+	return response.Success(c, report)
+}
+
+// matchesParam reports whether a query parameter is unset or equal to value
+func matchesParam(c echo.Context, name, value string) bool {
+	param := c.QueryParam(name)
+	return param == "" || param == value
+}
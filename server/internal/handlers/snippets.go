@@ -0,0 +1,318 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"spacectl-web/server/internal/config"
+	"spacectl-web/server/internal/grpc"
+	"spacectl-web/server/internal/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// snippetTokenVariable is the environment variable snippets read the token from, so it is never rendered
+const snippetTokenVariable = "SPACEONE_TOKEN"
+
+// Snippets is a call rendered as code that makes it outside the web client
+type Snippets struct {
+	Python string `json:"python"` // spaceone-api stubs over gRPC, or requests for a console-api
+	Go     string `json:"go"`     // Reflection-based dynamic stub over gRPC, or net/http for a console-api
+	HTTP   string `json:"http"`   // curl against the console-api, or against this server's call route for gRPC
+}
+
+// snippetAuth is how a snippet sends the token: in header, after prefix
+type snippetAuth struct {
+	header string
+	prefix string
+}
+
+// GetCallSnippets renders a call as Python, Go and curl snippets. The request body holds the
+// parameters as for a call; presets are applied and, for gRPC services, field names and value
+// types come from the method's request message. The call is not made.
+func (h *Handler) GetCallSnippets(c echo.Context) error {
+	serviceName := c.Param("service")
+	resourceName := c.Param("resource")
+	verb := c.Param("verb")
+
+	var requestBody map[string]interface{}
+	if err := c.Bind(&requestBody); err != nil {
+		if apiErr := bodyTooLarge(err); apiErr != nil {
+			return response.APIError(c, apiErr)
+		}
+		requestBody = make(map[string]interface{})
+	}
+	parameters := filterParameters(requestBody)
+
+	serviceCaller, apiErr := h.serviceCaller(serviceName, resourceName, verb)
+	if apiErr != nil {
+		return response.APIError(c, apiErr)
+	}
+	call, err := serviceCaller.DescribeCall(serviceName, resourceName, verb, parameters)
+	if err != nil {
+		return response.APIError(c, toAPIError(err))
+	}
+
+	endpoint, _ := h.config.GetEndpoint(serviceName)
+	if endpoint == nil {
+		endpoint = &config.EndpointConfig{}
+	}
+	auth := snippetAuthFor(endpoint)
+	if call.REST {
+		return response.Success(c, &Snippets{
+			Python: restPythonSnippet(call, auth),
+			Go:     restGoSnippet(call, auth),
+			HTTP:   curlSnippet(call.URL, call.Parameters, auth),
+		})
+	}
+
+	callURL := c.Scheme() + "://" + c.Request().Host + strings.TrimSuffix(c.Request().URL.Path, "/snippets")
+	return response.Success(c, &Snippets{
+		Python: grpcPythonSnippet(call, endpoint, auth),
+		Go:     grpcGoSnippet(call, endpoint, auth),
+		HTTP:   curlSnippet(callURL, call.Parameters, snippetAuth{}), // The server adds the credentials
+	})
+}
+
+// snippetAuthFor returns how the endpoint's calls carry the token. Static credentials such as
+// API keys are read from the token variable too, so no configured secret is rendered.
+func snippetAuthFor(endpoint *config.EndpointConfig) snippetAuth {
+	switch endpoint.AuthType() {
+	case config.AuthBearer:
+		return snippetAuth{header: "authorization", prefix: "Bearer "}
+	case config.AuthBasic:
+		return snippetAuth{header: "authorization", prefix: "Basic "}
+	case config.AuthAPIKey:
+		if endpoint.Auth.Header == "" {
+			return snippetAuth{header: config.DefaultAPIKeyHeader}
+		}
+		return snippetAuth{header: strings.ToLower(endpoint.Auth.Header)}
+	case config.AuthHeader:
+		return snippetAuth{header: strings.ToLower(endpoint.Auth.Header)}
+	case config.AuthNone:
+		return snippetAuth{}
+	}
+	return snippetAuth{header: "token"}
+}
+
+// grpcPythonSnippet renders a gRPC call with the spaceone-api stubs
+func grpcPythonSnippet(call *grpc.CallDescription, endpoint *config.EndpointConfig, auth snippetAuth) string {
+	serviceModule := pythonModule(call.ServiceFile)
+	inputModule := pythonModule(call.InputFile)
+	serviceAlias := serviceModule[strings.LastIndex(serviceModule, ".")+1:]
+	inputAlias := inputModule[strings.LastIndex(inputModule, ".")+1:]
+	stubName := call.Service[strings.LastIndex(call.Service, ".")+1:] + "Stub"
+
+	var b strings.Builder
+	b.WriteString("import os\n\nimport grpc\nfrom google.protobuf.json_format import MessageToDict, ParseDict\n")
+	fmt.Fprintf(&b, "from %s import %s, %s_grpc\n", packageOf(serviceModule), serviceAlias, serviceAlias)
+	if inputModule != serviceModule {
+		fmt.Fprintf(&b, "from %s import %s\n", packageOf(inputModule), inputAlias)
+	}
+	b.WriteString("\n")
+	if endpoint.IsPlaintext() {
+		fmt.Fprintf(&b, "channel = grpc.insecure_channel(%s)\n", strconv.Quote(endpoint.Address()))
+	} else {
+		fmt.Fprintf(&b, "channel = grpc.secure_channel(%s, grpc.ssl_channel_credentials())\n", strconv.Quote(endpoint.Address()))
+	}
+	fmt.Fprintf(&b, "stub = %s_grpc.%s(channel)\n\n", serviceAlias, stubName)
+	fmt.Fprintf(&b, "request = ParseDict(%s, %s.%s())\n", pythonLiteral(call.Parameters, ""), inputAlias, call.InputType)
+	if auth.header == "" {
+		fmt.Fprintf(&b, "response = stub.%s(request)\n", call.Method)
+	} else {
+		fmt.Fprintf(&b, "metadata = [(%s, %s)]\n", strconv.Quote(auth.header), pythonToken(auth))
+		fmt.Fprintf(&b, "response = stub.%s(request, metadata=metadata)\n", call.Method)
+	}
+	b.WriteString("print(MessageToDict(response, preserving_proto_field_name=True))\n")
+	return b.String()
+}
+
+// restPythonSnippet renders a console-api call with requests
+func restPythonSnippet(call *grpc.CallDescription, auth snippetAuth) string {
+	var b strings.Builder
+	b.WriteString("import os\n\nimport requests\n\n")
+	fmt.Fprintf(&b, "response = requests.post(\n    %s,\n", strconv.Quote(call.URL))
+	if auth.header != "" {
+		fmt.Fprintf(&b, "    headers={%s: %s},\n", strconv.Quote(auth.header), pythonToken(auth))
+	}
+	fmt.Fprintf(&b, "    json=%s,\n)\n", pythonLiteral(call.Parameters, "    "))
+	b.WriteString("response.raise_for_status()\nprint(response.json())\n")
+	return b.String()
+}
+
+// grpcGoSnippet renders a gRPC call with a dynamic stub, resolving the method by server reflection
+// as this server does, since SpaceONE publishes no Go stubs
+func grpcGoSnippet(call *grpc.CallDescription, endpoint *config.EndpointConfig, auth snippetAuth) string {
+	credentialsImport, credentials := `"google.golang.org/grpc/credentials"`, "credentials.NewTLS(&tls.Config{})"
+	if endpoint.IsPlaintext() {
+		credentialsImport, credentials = `"google.golang.org/grpc/credentials/insecure"`, "insecure.NewCredentials()"
+	}
+
+	var b strings.Builder
+	b.WriteString("package main\n\nimport (\n\t\"context\"\n")
+	if !endpoint.IsPlaintext() {
+		b.WriteString("\t\"crypto/tls\"\n")
+	}
+	b.WriteString("\t\"fmt\"\n\t\"log\"\n")
+	if auth.header != "" {
+		b.WriteString("\t\"os\"\n")
+	}
+	b.WriteString("\n\t\"github.com/jhump/protoreflect/dynamic\"\n\t\"github.com/jhump/protoreflect/dynamic/grpcdynamic\"\n")
+	b.WriteString("\t\"github.com/jhump/protoreflect/grpcreflect\"\n\t\"google.golang.org/grpc\"\n")
+	fmt.Fprintf(&b, "\t%s\n", credentialsImport)
+	if auth.header != "" {
+		b.WriteString("\t\"google.golang.org/grpc/metadata\"\n")
+	}
+	b.WriteString(")\n\nfunc main() {\n")
+	fmt.Fprintf(&b, "\tconn, err := grpc.NewClient(%s, grpc.WithTransportCredentials(%s))\n", strconv.Quote(endpoint.Address()), credentials)
+	b.WriteString("\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n\tdefer conn.Close()\n\n")
+	if auth.header != "" {
+		fmt.Fprintf(&b, "\tctx := metadata.AppendToOutgoingContext(context.Background(), %s, %s)\n", strconv.Quote(auth.header), goToken(auth))
+	} else {
+		b.WriteString("\tctx := context.Background()\n")
+	}
+	b.WriteString("\treflection := grpcreflect.NewClientAuto(ctx, conn)\n\tdefer reflection.Reset()\n")
+	fmt.Fprintf(&b, "\tservice, err := reflection.ResolveService(%s)\n", strconv.Quote(call.Service))
+	b.WriteString("\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n")
+	fmt.Fprintf(&b, "\tmethod := service.FindMethodByName(%s)\n\n", strconv.Quote(call.Method))
+	b.WriteString("\trequest := dynamic.NewMessage(method.GetInputType())\n")
+	fmt.Fprintf(&b, "\tif err := request.UnmarshalJSON([]byte(%s)); err != nil {\n\t\tlog.Fatal(err)\n\t}\n", goString(indentedJSON(call.Parameters)))
+	b.WriteString("\tresponse, err := grpcdynamic.NewStub(conn).InvokeRpc(ctx, method, request)\n")
+	b.WriteString("\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n")
+	b.WriteString("\toutput, err := response.(*dynamic.Message).MarshalJSONIndent()\n")
+	b.WriteString("\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n\tfmt.Println(string(output))\n}\n")
+	return b.String()
+}
+
+// restGoSnippet renders a console-api call with net/http
+func restGoSnippet(call *grpc.CallDescription, auth snippetAuth) string {
+	var b strings.Builder
+	b.WriteString("package main\n\nimport (\n\t\"bytes\"\n\t\"fmt\"\n\t\"io\"\n\t\"log\"\n\t\"net/http\"\n")
+	if auth.header != "" {
+		b.WriteString("\t\"os\"\n")
+	}
+	b.WriteString(")\n\nfunc main() {\n")
+	fmt.Fprintf(&b, "\tbody := []byte(%s)\n", goString(indentedJSON(call.Parameters)))
+	fmt.Fprintf(&b, "\treq, err := http.NewRequest(http.MethodPost, %s, bytes.NewReader(body))\n", strconv.Quote(call.URL))
+	b.WriteString("\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n\treq.Header.Set(\"Content-Type\", \"application/json\")\n")
+	if auth.header != "" {
+		fmt.Fprintf(&b, "\treq.Header.Set(%s, %s)\n", strconv.Quote(auth.header), goToken(auth))
+	}
+	b.WriteString("\n\tresp, err := http.DefaultClient.Do(req)\n\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n\tdefer resp.Body.Close()\n")
+	b.WriteString("\toutput, err := io.ReadAll(resp.Body)\n\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n")
+	b.WriteString("\tfmt.Println(resp.Status)\n\tfmt.Println(string(output))\n}\n")
+	return b.String()
+}
+
+// curlSnippet renders a JSON POST as a curl command
+func curlSnippet(url string, parameters map[string]interface{}, auth snippetAuth) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "curl -X POST %s \\\n  -H 'Content-Type: application/json' \\\n", shellQuote(url))
+	if auth.header != "" {
+		fmt.Fprintf(&b, "  -H \"%s: %s$%s\" \\\n", auth.header, auth.prefix, snippetTokenVariable)
+	}
+	fmt.Fprintf(&b, "  -d %s\n", shellQuote(indentedJSON(parameters)))
+	return b.String()
+}
+
+// pythonModule converts a proto file name to the module of its generated messages,
+// e.g. spaceone/api/identity/v2/project.proto to spaceone.api.identity.v2.project_pb2
+func pythonModule(protoFile string) string {
+	return strings.ReplaceAll(strings.TrimSuffix(protoFile, ".proto"), "/", ".") + "_pb2"
+}
+
+// packageOf returns the package part of a dotted module name
+func packageOf(module string) string {
+	if i := strings.LastIndex(module, "."); i >= 0 {
+		return module[:i]
+	}
+	return module
+}
+
+// pythonToken renders the expression reading the token in Python
+func pythonToken(auth snippetAuth) string {
+	token := fmt.Sprintf("os.environ[%s]", strconv.Quote(snippetTokenVariable))
+	if auth.prefix == "" {
+		return token
+	}
+	return strconv.Quote(auth.prefix) + " + " + token
+}
+
+// goToken renders the expression reading the token in Go
+func goToken(auth snippetAuth) string {
+	token := fmt.Sprintf("os.Getenv(%s)", strconv.Quote(snippetTokenVariable))
+	if auth.prefix == "" {
+		return token
+	}
+	return strconv.Quote(auth.prefix) + "+" + token
+}
+
+// pythonLiteral renders decoded JSON as a Python literal, continuing lines at the given indent
+func pythonLiteral(value interface{}, indent string) string {
+	switch v := value.(type) {
+	case nil:
+		return "None"
+	case bool:
+		if v {
+			return "True"
+		}
+		return "False"
+	case string:
+		quoted, _ := json.Marshal(v) // JSON string escapes are valid in Python
+		return string(quoted)
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return "{}"
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var b strings.Builder
+		b.WriteString("{\n")
+		for _, key := range keys {
+			fmt.Fprintf(&b, "%s    %s: %s,\n", indent, pythonLiteral(key, ""), pythonLiteral(v[key], indent+"    "))
+		}
+		b.WriteString(indent + "}")
+		return b.String()
+	case []interface{}:
+		if len(v) == 0 {
+			return "[]"
+		}
+		var b strings.Builder
+		b.WriteString("[\n")
+		for _, item := range v {
+			fmt.Fprintf(&b, "%s    %s,\n", indent, pythonLiteral(item, indent+"    "))
+		}
+		b.WriteString(indent + "]")
+		return b.String()
+	}
+	encoded, _ := json.Marshal(value) // Numbers
+	return string(encoded)
+}
+
+// indentedJSON renders parameters as indented JSON
+func indentedJSON(parameters map[string]interface{}) string {
+	encoded, err := json.MarshalIndent(parameters, "", "  ")
+	if err != nil {
+		return "{}"
+	}
+	return string(encoded)
+}
+
+// goString renders s as a raw string literal, or an interpreted one when it contains a backtick
+func goString(s string) string {
+	if strings.Contains(s, "`") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
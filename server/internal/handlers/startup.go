@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"spacectl-web/server/internal/response"
+	"spacectl-web/server/internal/startup"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SetStartupReport sets the report describing the configuration the server started with
+func (h *Handler) SetStartupReport(report *startup.Report) {
+	h.startupReport = report
+}
+
+// GetStartupReport returns the endpoints, token and features the server started with
+func (h *Handler) GetStartupReport(c echo.Context) error {
+	if h.startupReport == nil {
+		return response.NotFound(c, "Startup report not available", "the server was started without a startup report")
+	}
+	return response.Success(c, h.startupReport)
+}
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"spacectl-web/server/internal/errors"
+	"spacectl-web/server/internal/grpc"
+	"spacectl-web/server/internal/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Field statistics limits
+const (
+	statsVerb      = "list"
+	statsPageSize  = 100 // Results analyzed unless the query sets a page
+	statsTopValues = 5   // Most frequent values reported per field
+	statsMaxDepth  = 4   // Nesting depth of the fields analyzed
+)
+
+// FieldStats summarizes the values of one field across a page of results
+type FieldStats struct {
+	Field    string       `json:"field"`         // Dot-separated path; array elements share their array's path
+	Count    int          `json:"count"`         // Results with the field
+	Distinct int          `json:"distinct"`      // Distinct values
+	Min      interface{}  `json:"min,omitempty"` // Smallest number, or first string in order
+	Max      interface{}  `json:"max,omitempty"`
+	Top      []ValueCount `json:"top"` // Most frequent values, most frequent first
+}
+
+// ValueCount is a value and the number of times it occurs
+type ValueCount struct {
+	Value interface{} `json:"value"`
+	Count int         `json:"count"`
+}
+
+// StatsResult is the field statistics of a page of results
+type StatsResult struct {
+	Results    int          `json:"results"`               // Results analyzed
+	TotalCount *int64       `json:"total_count,omitempty"` // Results matching the query, when the verb reports it
+	Fields     []FieldStats `json:"fields"`
+	DurationMS int64        `json:"duration_ms"`
+}
+
+// fieldValues collects the values of one field
+type fieldValues struct {
+	results  int
+	counts   map[string]int
+	values   map[string]interface{} // Value by key in counts
+	min, max interface{}
+}
+
+// GetFieldStats lists a page of a resource's results and returns, per field, how many results
+// have it, its number of distinct values, its range, and its most frequent values. The body
+// holds list parameters and the query string takes the sort, keyword, filter, and paging
+// parameters of calls; without a page, the first statsPageSize results are analyzed.
+// The call is not added to the history.
+func (h *Handler) GetFieldStats(c echo.Context) error {
+	serviceName := c.Param("service")
+	resourceName := c.Param("resource")
+
+	var requestBody map[string]interface{}
+	if err := c.Bind(&requestBody); err != nil {
+		if apiErr := bodyTooLarge(err); apiErr != nil {
+			return response.APIError(c, apiErr)
+		}
+		requestBody = make(map[string]interface{})
+	}
+	parameters := filterParameters(requestBody)
+
+	resource, apiErr := h.findResource(serviceName, resourceName)
+	if apiErr != nil {
+		return response.APIError(c, apiErr)
+	}
+	if !verbTakesQuery(resource, statsVerb) {
+		return response.APIError(c, errors.NewAPIError(errors.ErrVerbNotSupported,
+			fmt.Sprintf("verb '%s' of resource '%s' does not take a query", statsVerb, resourceName)))
+	}
+	if apiErr := h.applyURLQuery(c.QueryParams(), parameters, serviceName, resourceName, statsVerb); apiErr != nil {
+		return response.APIError(c, apiErr)
+	}
+	mergeURLQuery(parameters, map[string]interface{}{"page": map[string]interface{}{limitQueryParam: statsPageSize}})
+
+	ctx := grpc.WithRequestID(c.Request().Context(), c.Response().Header().Get(echo.HeaderXRequestID))
+	started := time.Now()
+	jsonBytes, apiErr := h.invoke(ctx, serviceName, resourceName, statsVerb, parameters)
+	if apiErr != nil {
+		return response.APIError(c, apiErr)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(jsonBytes, &fields); err != nil {
+		return response.InternalServerError(c, "Invalid response", err.Error())
+	}
+	result := &StatsResult{Fields: []FieldStats{}}
+	if total, found, err := totalCountField(fields); err == nil && found {
+		result.TotalCount = &total
+	}
+	results, _ := decodeNumbers(fields["results"]).([]interface{})
+	result.Results = len(results)
+
+	collected := make(map[string]*fieldValues)
+	for _, item := range results {
+		seen := make(map[string]bool)
+		collectFieldValues(collected, seen, "", item, 0)
+		for path := range seen {
+			collected[path].results++
+		}
+	}
+	for path, values := range collected {
+		result.Fields = append(result.Fields, values.stats(path))
+	}
+	sort.Slice(result.Fields, func(i, j int) bool { return result.Fields[i].Field < result.Fields[j].Field })
+	result.DurationMS = time.Since(started).Milliseconds()
+	return response.Success(c, result)
+}
+
+// collectFieldValues adds the scalar values under path to collected, marking the paths seen in the result
+func collectFieldValues(collected map[string]*fieldValues, seen map[string]bool, path string, value interface{}, depth int) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if depth >= statsMaxDepth {
+			return
+		}
+		for key, child := range v {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			collectFieldValues(collected, seen, childPath, child, depth+1)
+		}
+	case []interface{}:
+		for _, element := range v {
+			collectFieldValues(collected, seen, path, element, depth)
+		}
+	case nil:
+	default:
+		if path == "" {
+			return
+		}
+		values, exists := collected[path]
+		if !exists {
+			values = &fieldValues{counts: make(map[string]int), values: make(map[string]interface{})}
+			collected[path] = values
+		}
+		seen[path] = true
+		values.add(v)
+	}
+}
+
+// add counts a value and widens the field's range with it
+func (f *fieldValues) add(value interface{}) {
+	key := fmt.Sprintf("%T:%v", value, value)
+	f.counts[key]++
+	f.values[key] = value
+	if _, isBool := value.(bool); isBool {
+		return
+	}
+	if f.min == nil || compareValues(value, f.min) < 0 {
+		f.min = value
+	}
+	if f.max == nil || compareValues(value, f.max) > 0 {
+		f.max = value
+	}
+}
+
+// stats returns the statistics of the collected values
+func (f *fieldValues) stats(path string) FieldStats {
+	keys := make([]string, 0, len(f.counts))
+	for key := range f.counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if f.counts[keys[i]] != f.counts[keys[j]] {
+			return f.counts[keys[i]] > f.counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > statsTopValues {
+		keys = keys[:statsTopValues]
+	}
+	top := make([]ValueCount, 0, len(keys))
+	for _, key := range keys {
+		top = append(top, ValueCount{Value: f.values[key], Count: f.counts[key]})
+	}
+	return FieldStats{Field: path, Count: f.results, Distinct: len(f.counts), Min: f.min, Max: f.max, Top: top}
+}
+
+// compareValues orders numbers, and strings holding integers such as 64-bit counts, numerically,
+// other strings lexically, and numbers before strings
+func compareValues(a, b interface{}) int {
+	aNumber, aIsNumber := numericValue(a)
+	bNumber, bIsNumber := numericValue(b)
+	switch {
+	case aIsNumber && bIsNumber:
+		switch {
+		case aNumber < bNumber:
+			return -1
+		case aNumber > bNumber:
+			return 1
+		}
+		return 0
+	case aIsNumber:
+		return -1
+	case bIsNumber:
+		return 1
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// numericValue returns a number, or a string holding an integer, as a float
+func numericValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return float64(n), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"fmt"
+
+	"spacectl-web/server/internal/prober"
+	"spacectl-web/server/internal/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SetProber sets the background prober whose checks are reported by the status history
+func (h *Handler) SetProber(p *prober.Prober) {
+	h.prober = p
+}
+
+// GetStatusHistory returns the recent background health checks of a service's endpoint
+func (h *Handler) GetStatusHistory(c echo.Context) error {
+	if h.prober == nil {
+		return response.NotFound(c, "Endpoint probing is disabled", "set server.prober.enabled to true in the config file")
+	}
+	serviceName := c.Param("service")
+	history, found := h.prober.History(serviceName)
+	if !found {
+		return response.NotFound(c, "Endpoint not found", fmt.Sprintf("no endpoint is configured for service '%s'", serviceName))
+	}
+	return response.Success(c, history)
+}
@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"log"
+	"mime"
+	"strconv"
+	"time"
+
+	"spacectl-web/server/internal/errors"
+	"spacectl-web/server/internal/response"
+	"spacectl-web/server/internal/siem"
+	"spacectl-web/server/internal/storage"
+	"spacectl-web/server/internal/usage"
+
+	"github.com/labstack/echo/v4"
+	"gopkg.in/yaml.v2"
+)
+
+// model is a saved item that can check its own fields
+type model interface {
+	Validate() []errors.FieldError
+}
+
+// newModel returns an empty model for the kinds users can create
+func newModel(kind storage.Kind) model {
+	switch kind {
+	case storage.KindFavorite:
+		return &storage.Favorite{}
+	case storage.KindCollection:
+		return &storage.Collection{}
+	case storage.KindSchedule:
+		return &storage.Schedule{}
+	case storage.KindView:
+		return &storage.View{}
+	case storage.KindWorkflow:
+		return &storage.Workflow{}
+	}
+	return nil
+}
+
+// SetStore sets the store for history, favorites, collections, schedules, views and workflows
+func (h *Handler) SetStore(store storage.Store) {
+	h.store = store
+}
+
+// user returns the user that owns saved data, read from the same header as usage quotas
+func (h *Handler) user(c echo.Context) string {
+	if user := c.Request().Header.Get(h.config.Server.Quotas.WithDefaults().UserHeader); user != "" {
+		return user
+	}
+	return usage.AnonymousUser
+}
+
+// recordItem flattens a record into its model's fields plus id and timestamps
+func recordItem(record *storage.Record) map[string]interface{} {
+	item := make(map[string]interface{})
+	_ = json.Unmarshal(record.Data, &item)
+	item["id"] = record.ID
+	item["created_at"] = record.CreatedAt
+	item["updated_at"] = record.UpdatedAt
+	return item
+}
+
+// ownedRecord returns the user's record for the :id path parameter
+func (h *Handler) ownedRecord(c echo.Context, kind storage.Kind) (*storage.Record, error) {
+	record, err := h.store.Get(c.Request().Context(), kind, c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+	if record.Owner != h.user(c) {
+		return nil, storage.ErrNotFound
+	}
+	return record, nil
+}
+
+// storageError sends a response for a failed store operation
+func storageError(c echo.Context, kind storage.Kind, err error) error {
+	if stderrors.Is(err, storage.ErrNotFound) {
+		return response.NotFound(c, "Saved item not found", "no "+string(kind)+" with id '"+c.Param("id")+"'")
+	}
+	return response.InternalServerError(c, "Storage failed", err.Error())
+}
+
+// ListRecords returns a handler listing the user's records of a kind, newest first
+func (h *Handler) ListRecords(kind storage.Kind) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		records, err := h.store.List(c.Request().Context(), kind, h.user(c))
+		if err != nil {
+			return storageError(c, kind, err)
+		}
+		items := make([]map[string]interface{}, 0, len(records))
+		for _, record := range records {
+			items = append(items, recordItem(record))
+		}
+		return response.Success(c, items)
+	}
+}
+
+// GetRecord returns a handler returning one of the user's records
+func (h *Handler) GetRecord(kind storage.Kind) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		record, err := h.ownedRecord(c, kind)
+		if err != nil {
+			return storageError(c, kind, err)
+		}
+		return response.Success(c, recordItem(record))
+	}
+}
+
+// yamlModel is a model that can also be written as YAML, such as a workflow
+type yamlModel interface {
+	model
+	// Normalize converts the nested maps YAML decoding produces into maps that can be encoded as JSON
+	Normalize()
+}
+
+// bindModel decodes and validates the request body as a model of the kind. Models that
+// support it may be sent as YAML with a YAML content type.
+func bindModel(c echo.Context, kind storage.Kind) (json.RawMessage, *errors.APIError) {
+	item := newModel(kind)
+	if isYAML(c.Request().Header.Get(echo.HeaderContentType)) {
+		yamlItem, ok := item.(yamlModel)
+		if !ok {
+			return nil, errors.NewAPIError(errors.ErrInvalidParameters, string(kind)+" must be sent as JSON")
+		}
+		body, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			if apiErr := bodyTooLarge(err); apiErr != nil {
+				return nil, apiErr
+			}
+			return nil, errors.NewAPIError(errors.ErrInvalidParameters, err.Error())
+		}
+		if err := yaml.UnmarshalStrict(body, yamlItem); err != nil {
+			return nil, errors.NewAPIError(errors.ErrInvalidParameters, err.Error())
+		}
+		yamlItem.Normalize()
+	} else if err := json.NewDecoder(c.Request().Body).Decode(item); err != nil {
+		if apiErr := bodyTooLarge(err); apiErr != nil {
+			return nil, apiErr
+		}
+		return nil, errors.NewAPIError(errors.ErrInvalidParameters, err.Error())
+	}
+	if fields := item.Validate(); len(fields) > 0 {
+		return nil, errors.NewValidationError(string(kind), fields)
+	}
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, errors.NewAPIError(errors.ErrInvalidParameters, err.Error())
+	}
+	return data, nil
+}
+
+// checkSchedule validates the request of a schedule like calls made through the API, since the
+// scheduler runs it in the background. Other kinds are not checked.
+func (h *Handler) checkSchedule(kind storage.Kind, data json.RawMessage) *errors.APIError {
+	if kind != storage.KindSchedule {
+		return nil
+	}
+	var schedule storage.Schedule
+	if err := json.Unmarshal(data, &schedule); err != nil {
+		return errors.NewAPIError(errors.ErrInvalidParameters, err.Error())
+	}
+	return h.validateRequest(schedule.Request.Service, schedule.Request.Resource, schedule.Request.Verb)
+}
+
+// CreateRecord returns a handler saving a new record of a kind for the user
+func (h *Handler) CreateRecord(kind storage.Kind) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		data, apiErr := bindModel(c, kind)
+		if apiErr == nil {
+			apiErr = h.checkSchedule(kind, data)
+		}
+		if apiErr != nil {
+			return response.APIError(c, apiErr)
+		}
+		now := time.Now()
+		record := &storage.Record{Kind: kind, ID: storage.NewID(), Owner: h.user(c), Data: data, CreatedAt: now, UpdatedAt: now}
+		if err := h.store.Put(c.Request().Context(), record); err != nil {
+			return storageError(c, kind, err)
+		}
+		return response.Success(c, recordItem(record))
+	}
+}
+
+// UpdateRecord returns a handler replacing one of the user's records
+func (h *Handler) UpdateRecord(kind storage.Kind) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		record, err := h.ownedRecord(c, kind)
+		if err != nil {
+			return storageError(c, kind, err)
+		}
+		data, apiErr := bindModel(c, kind)
+		if apiErr == nil {
+			apiErr = h.checkSchedule(kind, data)
+		}
+		if apiErr != nil {
+			return response.APIError(c, apiErr)
+		}
+		record.Data = data
+		record.UpdatedAt = time.Now()
+		if err := h.store.Put(c.Request().Context(), record); err != nil {
+			return storageError(c, kind, err)
+		}
+		return response.Success(c, recordItem(record))
+	}
+}
+
+// DeleteRecord returns a handler deleting one of the user's records
+func (h *Handler) DeleteRecord(kind storage.Kind) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if _, err := h.ownedRecord(c, kind); err != nil {
+			return storageError(c, kind, err)
+		}
+		if err := h.store.Delete(c.Request().Context(), kind, c.Param("id")); err != nil {
+			return storageError(c, kind, err)
+		}
+		return response.Success(c, map[string]string{"id": c.Param("id")})
+	}
+}
+
+// ClearHistory deletes the user's call history
+func (h *Handler) ClearHistory(c echo.Context) error {
+	if err := h.store.Trim(c.Request().Context(), storage.KindHistory, h.user(c), 0); err != nil {
+		return storageError(c, storage.KindHistory, err)
+	}
+	return response.Success(c, map[string]string{"status": "cleared"})
+}
+
+// recordHistory saves a call to the user's history and deletes entries over the history limit.
+// Failures are logged, since history must never fail the call itself.
+func (h *Handler) recordHistory(c echo.Context, entry *storage.HistoryEntry) {
+	h.exportHistory(c, entry)
+	if h.store == nil || h.config.Server.UI.DisableHistory {
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		log.Printf("WARNING: failed to save history: %v", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request().Context()), 5*time.Second)
+	defer cancel()
+	now := time.Now()
+	owner := h.user(c)
+	record := &storage.Record{Kind: storage.KindHistory, ID: storage.NewID(), Owner: owner, Data: data, CreatedAt: now, UpdatedAt: now}
+	if err := h.store.Put(ctx, record); err != nil {
+		log.Printf("WARNING: failed to save history: %v", err)
+		return
+	}
+	if err := h.store.Trim(ctx, storage.KindHistory, owner, h.config.Server.Storage.WithDefaults().HistoryLimit); err != nil {
+		log.Printf("WARNING: failed to trim history: %v", err)
+	}
+}
+
+// SetSIEM exports calls added to the history when the exporter is configured to
+func (h *Handler) SetSIEM(exporter *siem.Exporter) {
+	h.siem = exporter
+}
+
+// exportHistory ships a call to the SIEM destinations. Calls are exported even when the
+// history is disabled or not persisted.
+func (h *Handler) exportHistory(c echo.Context, entry *storage.HistoryEntry) {
+	if h.siem == nil || !h.siem.ExportsHistory() {
+		return
+	}
+	h.siem.Export(siem.Event{
+		Kind:   siem.KindHistory,
+		Action: "call",
+		Fields: map[string]string{
+			"user":        h.user(c),
+			"operator":    c.RealIP(),
+			"service":     entry.Service,
+			"resource":    entry.Resource,
+			"verb":        entry.Verb,
+			"success":     strconv.FormatBool(entry.Success),
+			"error_code":  entry.ErrorCode,
+			"duration_ms": strconv.FormatInt(entry.DurationMS, 10),
+			"request_id":  c.Response().Header().Get(echo.HeaderXRequestID),
+		},
+	})
+}
+
+// isYAML reports whether a content type is one of the YAML media types
+func isYAML(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch mediaType {
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return true
+	}
+	return false
+}
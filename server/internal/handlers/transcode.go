@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"spacectl-web/server/internal/errors"
+	"spacectl-web/server/internal/grpc"
+	"spacectl-web/server/internal/response"
+	"spacectl-web/server/internal/storage"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HTTPRoute is a google.api.http binding of a discovered method
+type HTTPRoute struct {
+	Resource string `json:"resource"`
+	Verb     string `json:"verb"`
+	grpc.HTTPBinding
+}
+
+// httpRoutes returns the HTTP bindings of a service's visible methods, ordered by resource and verb
+func (h *Handler) httpRoutes(serviceName string, serviceInfo *grpc.ServiceInfo) []HTTPRoute {
+	routes := []HTTPRoute{}
+	for _, resource := range serviceInfo.Resources {
+		if h.config.Server.Catalog.ResourceHidden(serviceName, resource.Name) {
+			continue
+		}
+		for verb, method := range resource.Methods {
+			for _, binding := range method.HTTP {
+				routes = append(routes, HTTPRoute{Resource: resource.Name, Verb: verb, HTTPBinding: binding})
+			}
+		}
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Resource != routes[j].Resource {
+			return routes[i].Resource < routes[j].Resource
+		}
+		return routes[i].Verb < routes[j].Verb
+	})
+	return routes
+}
+
+// ListHTTPRoutes returns the google.api.http bindings declared by a service's methods
+func (h *Handler) ListHTTPRoutes(c echo.Context) error {
+	serviceName := c.Param("service")
+	if h.config.Server.Catalog.ServiceHidden(serviceName) {
+		return response.APIError(c, errors.NewAPIError(errors.ErrServiceNotFound, fmt.Sprintf("service '%s' not found", serviceName)))
+	}
+	serviceInfo, err := h.serviceDiscovery.GetServiceInfo(serviceName)
+	if err != nil {
+		return response.APIError(c, discoveryError(serviceName, err))
+	}
+	return response.Success(c, h.httpRoutes(serviceName, serviceInfo))
+}
+
+// TranscodeHTTP calls the method whose google.api.http binding matches the request method and
+// path, building its parameters from the path variables, the body and the query string
+func (h *Handler) TranscodeHTTP(c echo.Context) error {
+	serviceName := c.Param("service")
+	method, path := c.Request().Method, "/"+c.Param("*")
+	if h.config.Server.Catalog.ServiceHidden(serviceName) {
+		return response.APIError(c, errors.NewAPIError(errors.ErrServiceNotFound, fmt.Sprintf("service '%s' not found", serviceName)))
+	}
+	serviceInfo, err := h.serviceDiscovery.GetServiceInfo(serviceName)
+	if err != nil {
+		return response.APIError(c, discoveryError(serviceName, err))
+	}
+
+	// Prefer the template with the most literal segments when several match
+	var route *HTTPRoute
+	var vars map[string]string
+	bestLiterals := -1
+	for _, candidate := range h.httpRoutes(serviceName, serviceInfo) {
+		if matched, literals, ok := candidate.Match(method, path); ok && literals > bestLiterals {
+			route, vars, bestLiterals = &candidate, matched, literals
+		}
+	}
+	if route == nil {
+		return response.APIError(c, errors.NewAPIError(errors.ErrMethodNotFound,
+			fmt.Sprintf("no google.api.http binding of service '%s' matches %s %s", serviceName, method, path)))
+	}
+
+	var body interface{}
+	if route.Body != "" {
+		if err := json.NewDecoder(c.Request().Body).Decode(&body); err != nil && !stderrors.Is(err, io.EOF) {
+			if apiErr := bodyTooLarge(err); apiErr != nil {
+				return response.APIError(c, apiErr)
+			}
+			return response.BadRequest(c, "Invalid request body", err.Error())
+		}
+	}
+	parameters := route.Parameters(vars, body, c.QueryParams())
+	ctx := grpc.WithRequestID(c.Request().Context(), c.Response().Header().Get(echo.HeaderXRequestID))
+
+	// Save the call to the user's history once it completes
+	var callErr *errors.APIError
+	started := time.Now()
+	defer func() {
+		entry := &storage.HistoryEntry{
+			Request:    storage.Request{Service: serviceName, Resource: route.Resource, Verb: route.Verb, Parameters: parameters},
+			Success:    callErr == nil,
+			DurationMS: time.Since(started).Milliseconds(),
+		}
+		if callErr != nil {
+			entry.ErrorCode = string(callErr.ErrorCode)
+		}
+		h.recordHistory(c, entry)
+	}()
+
+	// Return only the bound field of the response
+	if route.ResponseBody != "" {
+		jsonBytes, apiErr := h.invoke(ctx, serviceName, route.Resource, route.Verb, parameters)
+		if apiErr != nil {
+			callErr = apiErr
+			return response.APIError(c, apiErr)
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(jsonBytes, &fields); err != nil {
+			return response.InternalServerError(c, "Invalid response", err.Error())
+		}
+		field, found := fields[route.ResponseBody]
+		if !found {
+			field = fields[lowerCamelCase(route.ResponseBody)]
+		}
+		return response.Success(c, field)
+	}
+
+	stream := response.NewJSONStream(c)
+	if apiErr := h.invokeTo(ctx, serviceName, route.Resource, route.Verb, parameters, stream); apiErr != nil {
+		callErr = apiErr
+		if stream.Started() {
+			log.Printf("ERROR: response for %s.%s.%s failed after streaming started: %v", serviceName, route.Resource, route.Verb, apiErr)
+			return nil
+		}
+		return response.APIError(c, apiErr)
+	}
+	return stream.Close()
+}
+
+// lowerCamelCase converts a proto field name to its protobuf JSON name, e.g. total_count to totalCount
+func lowerCamelCase(name string) string {
+	parts := strings.Split(name, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
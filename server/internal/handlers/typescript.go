@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"spacectl-web/server/internal/constants"
+	"spacectl-web/server/internal/grpc"
+
+	"github.com/labstack/echo/v4"
+)
+
+// typeScriptClientFilename is the name the generated client is downloaded as
+const typeScriptClientFilename = "spacectl-client.ts"
+
+// typeScriptPrelude declares the response envelope and the client's call method
+const typeScriptPrelude = `export interface FieldError {
+  field: string;
+  message: string;
+}
+
+export interface ErrorInfo {
+  code: number;
+  error_code?: string;
+  message: string;
+  details?: string;
+  grpc_code?: string;
+  hint?: string;
+  fields?: FieldError[];
+  request_id?: string;
+}
+
+export interface Pagination {
+  total: number | null;
+  page: number;
+  page_size: number;
+  has_more: boolean;
+}
+
+export interface Envelope<T> {
+  success: boolean;
+  data?: T;
+  error?: ErrorInfo;
+  pagination?: Pagination;
+}
+
+export class ApiError extends Error {
+  constructor(readonly status: number, readonly info: ErrorInfo) {
+    super(info.message);
+  }
+}
+
+export class SpacectlClient {
+  constructor(readonly baseURL: string = %s, readonly init: RequestInit = {}) {}
+
+  async call<Req, Res>(service: string, resource: string, verb: string, parameters: Req): Promise<Envelope<Res>> {
+    const path = ["services", service, "resources", resource, "verbs", verb].map(encodeURIComponent).join("/");
+    const response = await fetch(this.baseURL + "/" + path, {
+      ...this.init,
+      method: "POST",
+      headers: { "Content-Type": "application/json", ...this.init.headers },
+      body: JSON.stringify(parameters ?? {}),
+    });
+    const envelope = (await response.json()) as Envelope<Res>;
+    if (!envelope.success) {
+      throw new ApiError(response.status, envelope.error ?? { code: response.status, message: response.statusText });
+    }
+    return envelope;
+  }
+`
+
+// DownloadTypeScriptClient returns a TypeScript client for the call API with a typed method per
+// verb of the visible services. Request and response types come from the discovered message
+// descriptors. Services that cannot be discovered are left out and listed in the header, and
+// streaming verbs are left out as the call API does not stream messages.
+func (h *Handler) DownloadTypeScriptClient(c echo.Context) error {
+	catalog := h.config.Server.Catalog
+	types := grpc.NewTypeScriptTypes()
+	var methods strings.Builder
+	var skipped []string
+	for _, serviceName := range visibleServices(catalog, h.serviceDiscovery.GetAvailableServices(), false) {
+		serviceInfo, err := h.serviceDiscovery.GetServiceInfo(serviceName)
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s: %v", serviceName, err))
+			continue
+		}
+		serviceCaller, err := h.grpcManager.GetServiceCaller(serviceName)
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s: %v", serviceName, err))
+			continue
+		}
+
+		resourceNames := make([]string, 0, len(serviceInfo.Resources))
+		for resourceName := range serviceInfo.Resources {
+			if !catalog.ResourceHidden(serviceName, resourceName) {
+				resourceNames = append(resourceNames, resourceName)
+			}
+		}
+		sort.Strings(resourceNames)
+
+		fmt.Fprintf(&methods, "\n  readonly %s = {\n", grpc.TypeScriptProperty(serviceName))
+		for _, resourceName := range resourceNames {
+			resource := serviceInfo.Resources[resourceName]
+			fmt.Fprintf(&methods, "    %s: {\n", grpc.TypeScriptProperty(resourceName))
+			for _, verb := range resource.Verbs {
+				if method := resource.Methods[verb]; method != nil && (method.ClientStreaming || method.ServerStreaming) {
+					continue
+				}
+				requestType, responseType, err := serviceCaller.TypeScriptMethodTypes(types, serviceName, resourceName, verb)
+				if err != nil {
+					skipped = append(skipped, fmt.Sprintf("%s.%s.%s: %v", serviceName, resourceName, verb, err))
+					continue
+				}
+				fmt.Fprintf(&methods, "      %s: (parameters: %s = {}) =>\n        this.call<%s, %s>(%s, %s, %s, parameters),\n",
+					grpc.TypeScriptProperty(verb), requestType, requestType, responseType,
+					strconv.Quote(serviceName), strconv.Quote(resourceName), strconv.Quote(verb))
+			}
+			methods.WriteString("    },\n")
+		}
+		methods.WriteString("  };\n")
+	}
+
+	var b strings.Builder
+	b.WriteString("// Generated by spacectl-web from the discovered services. Do not edit.\n")
+	for _, reason := range skipped {
+		fmt.Fprintf(&b, "// Left out: %s\n", strings.ReplaceAll(reason, "\n", " "))
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, typeScriptPrelude, strconv.Quote(strings.TrimSuffix(c.Request().URL.Path, constants.TypeScriptClientPath)))
+	b.WriteString(methods.String())
+	b.WriteString("}\n\n")
+	_, _ = types.WriteTo(&b)
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, typeScriptClientFilename))
+	return c.Blob(http.StatusOK, "application/typescript", []byte(strings.TrimSuffix(b.String(), "\n")))
+}
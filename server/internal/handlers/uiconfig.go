@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"spacectl-web/server/internal/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// UIConfig represents server-driven settings the web client adapts to
+type UIConfig struct {
+	Title              string     `json:"title"`
+	LogoURL            string     `json:"logo_url,omitempty"`
+	Color              string     `json:"color,omitempty"`
+	ReadOnly           bool       `json:"read_only"`
+	DefaultEnvironment string     `json:"default_environment"` // Active profile
+	Environments       []string   `json:"environments"`
+	Features           UIFeatures `json:"features"`
+}
+
+// UIFeatures represents the optional features enabled on the server
+type UIFeatures struct {
+	History bool `json:"history"`
+	Exports bool `json:"exports"`
+	Usage   bool `json:"usage"`
+	Admin   bool `json:"admin"`
+	Icons   bool `json:"icons"` // Icon URLs can be loaded through GET /api/icons?url=
+}
+
+// GetUIConfig returns the settings the web client adapts to without being rebuilt
+func (h *Handler) GetUIConfig(c echo.Context) error {
+	ui := h.config.Server.UI.WithDefaults()
+	return response.Success(c, &UIConfig{
+		Title:              ui.Title,
+		LogoURL:            ui.LogoURL,
+		Color:              ui.Color,
+		ReadOnly:           ui.ReadOnly,
+		DefaultEnvironment: h.config.ActiveProfile(),
+		Environments:       h.config.ProfileNames(),
+		Features: UIFeatures{
+			History: !ui.DisableHistory,
+			Exports: !ui.DisableExports,
+			Usage:   h.usage != nil,
+			Admin:   h.jobs != nil,
+			Icons:   h.icons != nil,
+		},
+	})
+}
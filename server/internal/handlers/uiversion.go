@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"spacectl-web/server/internal/constants"
+	"spacectl-web/server/internal/response"
+	"spacectl-web/server/internal/web"
+
+	"github.com/labstack/echo/v4"
+)
+
+// UIVersion represents the web client build being served compared with the embedded one
+type UIVersion struct {
+	ServerVersion string `json:"server_version"`
+	web.Assets
+	Mismatch bool `json:"mismatch"` // The served client is not the build embedded in this server
+}
+
+// SetUIAssets records the served and embedded web client builds
+func (h *Handler) SetUIAssets(assets *web.Assets) {
+	h.uiAssets = assets
+}
+
+// GetUIVersion returns the hashes of the served and embedded web client builds
+func (h *Handler) GetUIVersion(c echo.Context) error {
+	if h.uiAssets == nil {
+		return response.NotFound(c, "Web client build information is not available")
+	}
+	return response.Success(c, &UIVersion{
+		ServerVersion: constants.Version,
+		Assets:        *h.uiAssets,
+		Mismatch:      h.uiAssets.Mismatch(),
+	})
+}
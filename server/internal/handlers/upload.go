@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxUploadMemory is the amount of multipart form data kept in memory before spilling to disk
+const maxUploadMemory = 32 << 20
+
+// multipartParametersField is the form field that may carry a JSON object of parameters
+const multipartParametersField = "parameters"
+
+// isMultipart reports whether the request body is multipart/form-data
+func isMultipart(c echo.Context) bool {
+	return strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm)
+}
+
+// bindMultipart reads parameters from a multipart/form-data request.
+// Text fields become string parameters, the "parameters" field is merged as a JSON object,
+// and uploaded files are mapped by their form field name to bytes fields.
+func bindMultipart(c echo.Context) (map[string]interface{}, error) {
+	if err := c.Request().ParseMultipartForm(maxUploadMemory); err != nil {
+		return nil, fmt.Errorf("failed to parse multipart form: %w", err)
+	}
+	form := c.Request().MultipartForm
+
+	parameters := make(map[string]interface{})
+	for key, values := range form.Value {
+		if len(values) == 0 {
+			continue
+		}
+		if key == multipartParametersField {
+			if err := json.Unmarshal([]byte(values[0]), &parameters); err != nil {
+				return nil, fmt.Errorf("field '%s' must be a JSON object: %w", key, err)
+			}
+			continue
+		}
+		parameters[key] = values[0]
+	}
+
+	for key, files := range form.File {
+		if len(files) == 0 {
+			continue
+		}
+		file, err := files[0].Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open uploaded file '%s': %w", key, err)
+		}
+		data, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read uploaded file '%s': %w", key, err)
+		}
+		parameters[key] = data
+	}
+
+	return parameters, nil
+}
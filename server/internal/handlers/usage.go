@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"spacectl-web/server/internal/response"
+	"spacectl-web/server/internal/usage"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SetUsage sets the tracker used to report per-user usage
+func (h *Handler) SetUsage(tracker *usage.Tracker) {
+	h.usage = tracker
+}
+
+// GetUsage returns per-user call counts and data volume
+func (h *Handler) GetUsage(c echo.Context) error {
+	if h.usage == nil {
+		return response.NotFound(c, "Usage tracking is disabled", "set server.quotas.enabled to true in the config file")
+	}
+	return response.Success(c, h.usage.Report())
+}
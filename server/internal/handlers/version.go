@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"spacectl-web/server/internal/constants"
+	"spacectl-web/server/internal/response"
+	"spacectl-web/server/internal/update"
+
+	"github.com/labstack/echo/v4"
+)
+
+// updateCheckTimeout keeps the version endpoint responsive when GitHub is slow or unreachable
+const updateCheckTimeout = 5 * time.Second
+
+// SetUpdateChecker enables update checks in the version response
+func (h *Handler) SetUpdateChecker(checker *update.Checker) {
+	h.updates = checker
+}
+
+// GetVersion returns the running version and, unless update checks are disabled, whether a
+// newer release is available
+func (h *Handler) GetVersion(c echo.Context) error {
+	if h.updates == nil {
+		return response.Success(c, &update.Status{Version: constants.Version})
+	}
+	ctx, cancel := context.WithTimeout(c.Request().Context(), updateCheckTimeout)
+	defer cancel()
+	return response.Success(c, h.updates.Status(ctx))
+}
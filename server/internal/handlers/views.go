@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"spacectl-web/server/internal/config"
+	"spacectl-web/server/internal/errors"
+	"spacectl-web/server/internal/grpc"
+	"spacectl-web/server/internal/response"
+	"spacectl-web/server/internal/storage"
+
+	"github.com/labstack/echo/v4"
+)
+
+// formatQueryParam overrides the output format of a view
+const formatQueryParam = "format"
+
+// ViewResult is the output of a view in JSON format
+type ViewResult struct {
+	Name    string                   `json:"name"`
+	Columns []string                 `json:"columns,omitempty"`
+	Rows    []map[string]interface{} `json:"rows"`
+}
+
+// RunView runs a saved view and returns its rows as JSON or CSV. Views are run by ID
+// without an ownership check, so that the URL can be shared. The sort, keyword, filter,
+// limit, and start query parameters refine the view's query like they do for calls.
+func (h *Handler) RunView(c echo.Context) error {
+	record, err := h.store.Get(c.Request().Context(), storage.KindView, c.Param("id"))
+	if err != nil {
+		return storageError(c, storage.KindView, err)
+	}
+	var view storage.View
+	if err := json.Unmarshal(record.Data, &view); err != nil {
+		return response.InternalServerError(c, "Invalid view", err.Error())
+	}
+
+	format := c.QueryParam(formatQueryParam)
+	if format == "" {
+		format = view.Format
+	}
+	if format == "" {
+		format = storage.ViewFormatJSON
+	}
+	if format != storage.ViewFormatJSON && format != storage.ViewFormatCSV {
+		return response.BadRequest(c, "Unsupported view format", fmt.Sprintf("format must be '%s' or '%s'", storage.ViewFormatJSON, storage.ViewFormatCSV))
+	}
+
+	ctx := grpc.WithRequestID(c.Request().Context(), c.Response().Header().Get(echo.HeaderXRequestID))
+	rows, apiErr := h.runView(ctx, &view, c.QueryParams())
+	if apiErr != nil {
+		return response.APIError(c, apiErr)
+	}
+
+	if format == storage.ViewFormatCSV {
+		return sendCSV(c, view.Name, view.Columns, rows)
+	}
+	return response.Success(c, &ViewResult{Name: view.Name, Columns: view.Columns, Rows: rows})
+}
+
+// runView calls a view's request with the view's filters and the query parameters applied and
+// returns its rows. Views are run by plain GETs, so only verbs that read data are run, including
+// for views saved before their verbs were checked.
+func (h *Handler) runView(ctx context.Context, view *storage.View, query url.Values) ([]map[string]interface{}, *errors.APIError) {
+	if !config.IsReadVerb(view.Request.Verb) {
+		return nil, errors.NewAPIError(errors.ErrVerbNotSupported, fmt.Sprintf("views only run verbs that read data; verb '%s' may change data", view.Request.Verb))
+	}
+
+	// Build the query from the saved parameters, the view's filters, and the URL
+	parameters := make(map[string]interface{})
+	for key, value := range view.Request.Parameters {
+		parameters[key] = value
+	}
+	values := make(url.Values)
+	for key, value := range query {
+		values[key] = value
+	}
+	values[filterQueryParam] = append(slices.Clone(view.Filters), values[filterQueryParam]...)
+	if apiErr := h.applyURLQuery(values, parameters, view.Request.Service, view.Request.Resource, view.Request.Verb); apiErr != nil {
+		return nil, apiErr
+	}
+
+	jsonBytes, apiErr := h.invoke(ctx, view.Request.Service, view.Request.Resource, view.Request.Verb, parameters)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	rows, err := viewRows(jsonBytes, view.Columns)
+	if err != nil {
+		return nil, errors.NewAPIError(errors.ErrResponseConversionFailed, err.Error())
+	}
+	return rows, nil
+}
+
+// viewRows returns the results of a list response, or the response itself for other verbs,
+// projected onto the columns
+func viewRows(jsonBytes []byte, columns []string) ([]map[string]interface{}, error) {
+	var value interface{}
+	if err := json.Unmarshal(jsonBytes, &value); err != nil {
+		return nil, err
+	}
+	items := []interface{}{value}
+	if fields, ok := value.(map[string]interface{}); ok {
+		if results, ok := fields["results"].([]interface{}); ok {
+			items = results
+		}
+	}
+
+	rows := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		fields, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if len(columns) == 0 {
+			rows = append(rows, fields)
+			continue
+		}
+		row := make(map[string]interface{}, len(columns))
+		for _, column := range columns {
+			row[column] = fieldValue(fields, column)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// fieldValue returns the value at a dot-separated path, or nil when it is missing
+func fieldValue(fields map[string]interface{}, fieldPath string) interface{} {
+	var value interface{} = fields
+	for _, name := range strings.Split(fieldPath, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = m[name]
+	}
+	return value
+}
+
+// sendCSV sends rows as a CSV file with a header line. Without columns, the columns are the
+// sorted top-level fields of the rows. Values other than strings are written as JSON.
+func sendCSV(c echo.Context, name string, columns []string, rows []map[string]interface{}) error {
+	if len(columns) == 0 {
+		columns = rowColumns(rows)
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	res.Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("inline", map[string]string{"filename": name + ".csv"}))
+	res.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(res)
+	_ = writer.Write(columns)
+	for _, row := range rows {
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			record[i] = csvValue(row[column])
+		}
+		_ = writer.Write(record)
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// rowColumns returns the sorted top-level fields found in any row
+func rowColumns(rows []map[string]interface{}) []string {
+	seen := make(map[string]bool)
+	var columns []string
+	for _, row := range rows {
+		for column := range row {
+			if !seen[column] {
+				seen[column] = true
+				columns = append(columns, column)
+			}
+		}
+	}
+	slices.Sort(columns)
+	return columns
+}
+
+// csvValue formats a value for a CSV cell
+func csvValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
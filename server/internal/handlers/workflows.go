@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"spacectl-web/server/internal/grpc"
+	"spacectl-web/server/internal/response"
+	"spacectl-web/server/internal/storage"
+	"spacectl-web/server/internal/workflow"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Default and maximum time a collect workflow follows its job
+const (
+	defaultCollectTimeout = 30 * time.Minute
+	maxCollectTimeout     = 6 * time.Hour
+)
+
+// collectEventStarted is the SSE event sent with the job a collect workflow started
+const collectEventStarted = "started"
+
+// CollectWorkflowRequest starts a collector and selects how its job is followed
+type CollectWorkflowRequest struct {
+	CollectorID string `json:"collector_id"`
+	SecretID    string `json:"secret_id"`    // Collects with one secret instead of all of them
+	WorkspaceID string `json:"workspace_id"` // Collects for one workspace
+	Timeout     string `json:"timeout"`      // Duration to follow the job; defaults to 30m
+	Interval    string `json:"interval"`     // Polling interval; defaults to 5s
+}
+
+// CollectWorkflow calls Collector.collect of the inventory service, then follows the job it
+// started like spacectl's collect-and-wait: a started event with the collect response, then
+// the job's progress as it changes, until an end event or a timeout event.
+func (h *Handler) CollectWorkflow(c echo.Context) error {
+	var req CollectWorkflowRequest
+	if err := c.Bind(&req); err != nil {
+		if apiErr := bodyTooLarge(err); apiErr != nil {
+			return response.APIError(c, apiErr)
+		}
+		return response.BadRequest(c, "Invalid request body", err.Error())
+	}
+	if req.CollectorID == "" {
+		return response.BadRequest(c, "Invalid collect request", "collector_id is required")
+	}
+	timeout := defaultCollectTimeout
+	if req.Timeout != "" {
+		var err error
+		timeout, err = time.ParseDuration(req.Timeout)
+		if err != nil || timeout <= 0 || timeout > maxCollectTimeout {
+			return response.BadRequest(c, "Invalid timeout", fmt.Sprintf("timeout must be a positive duration of at most %s", maxCollectTimeout))
+		}
+	}
+	interval := defaultJobFollowInterval
+	if req.Interval != "" {
+		var err error
+		interval, err = time.ParseDuration(req.Interval)
+		if err != nil || interval < minFollowInterval {
+			return response.BadRequest(c, "Invalid interval", fmt.Sprintf("interval must be a duration of at least %s", minFollowInterval))
+		}
+	}
+	if apiErr := h.validateRequest(inventoryService, "Collector", "collect"); apiErr != nil {
+		return response.APIError(c, apiErr)
+	}
+
+	parameters := map[string]interface{}{"collector_id": req.CollectorID}
+	if req.SecretID != "" {
+		parameters["secret_id"] = req.SecretID
+	}
+	if req.WorkspaceID != "" {
+		parameters["workspace_id"] = req.WorkspaceID
+	}
+	ctx := grpc.WithRequestID(c.Request().Context(), c.Response().Header().Get(echo.HeaderXRequestID))
+	jsonBytes, apiErr := h.invoke(ctx, inventoryService, "Collector", "collect", parameters)
+	if apiErr != nil {
+		return response.APIError(c, apiErr)
+	}
+	var started struct {
+		JobID string `json:"job_id"`
+	}
+	if err := json.Unmarshal(jsonBytes, &started); err != nil || started.JobID == "" {
+		return response.InternalServerError(c, "Invalid response", "Collector.collect did not return a job_id")
+	}
+
+	// The collect has started, so from here on failures are reported as events
+	sse := response.NewSSE(c)
+	if err := sse.Event(collectEventStarted, json.RawMessage(jsonBytes)); err != nil {
+		return nil
+	}
+	progress, apiErr := h.jobProgress(ctx, started.JobID)
+	if apiErr != nil {
+		if err := sse.Event(jobEventError, response.NewErrorInfo(apiErr.Localize(response.Language(c)))); err != nil {
+			return nil
+		}
+		progress = &JobProgress{JobID: started.JobID, Status: jobInProgress}
+	} else if err := sse.Event(jobEventProgress, progress); err != nil {
+		return nil
+	}
+	return h.followJob(c, ctx, sse, progress, interval, timeout)
+}
+
+// RunWorkflowRequest gives the inputs of a workflow run
+type RunWorkflowRequest struct {
+	Inputs map[string]interface{} `json:"inputs"`
+}
+
+// SetWorkflows sets the engine that runs saved workflows
+func (h *Handler) SetWorkflows(engine *workflow.Engine) {
+	h.workflows = engine
+}
+
+// RunWorkflow starts a run of one of the user's workflows in the background and returns it.
+// Its progress is followed with the workflow run endpoints.
+func (h *Handler) RunWorkflow(c echo.Context) error {
+	record, err := h.ownedRecord(c, storage.KindWorkflow)
+	if err != nil {
+		return storageError(c, storage.KindWorkflow, err)
+	}
+	var definition storage.Workflow
+	if err := json.Unmarshal(record.Data, &definition); err != nil {
+		return response.InternalServerError(c, "Invalid workflow", err.Error())
+	}
+	var req RunWorkflowRequest
+	if err := c.Bind(&req); err != nil {
+		if apiErr := bodyTooLarge(err); apiErr != nil {
+			return response.APIError(c, apiErr)
+		}
+		return response.BadRequest(c, "Invalid request body", err.Error())
+	}
+	if missing := workflow.MissingInputs(&definition, req.Inputs); len(missing) > 0 {
+		return response.BadRequest(c, "Missing workflow inputs", "inputs must include "+strings.Join(missing, ", "))
+	}
+
+	run, err := h.workflows.Start(c.Request().Context(), h.user(c), record.ID, &definition, req.Inputs)
+	if err != nil {
+		return storageError(c, storage.KindWorkflowRun, err)
+	}
+	return response.Success(c, recordItem(run))
+}
+
+// CancelWorkflowRun stops one of the user's workflow runs. The step in progress is
+// interrupted and the steps after it do not run.
+func (h *Handler) CancelWorkflowRun(c echo.Context) error {
+	record, err := h.ownedRecord(c, storage.KindWorkflowRun)
+	if err != nil {
+		return storageError(c, storage.KindWorkflowRun, err)
+	}
+	if !h.workflows.Cancel(record.ID) {
+		return response.BadRequest(c, "Workflow run not in progress", workflowRunStopped(record))
+	}
+	return response.Success(c, map[string]string{"id": record.ID, "status": storage.WorkflowCanceled})
+}
+
+// DeleteWorkflowRun deletes one of the user's workflow runs once it has stopped
+func (h *Handler) DeleteWorkflowRun(c echo.Context) error {
+	if _, err := h.ownedRecord(c, storage.KindWorkflowRun); err != nil {
+		return storageError(c, storage.KindWorkflowRun, err)
+	}
+	if h.workflows.Running(c.Param("id")) {
+		return response.BadRequest(c, "Workflow run in progress", "cancel the run before deleting it")
+	}
+	return h.DeleteRecord(storage.KindWorkflowRun)(c)
+}
+
+// workflowRunStopped explains why a run cannot be canceled
+func workflowRunStopped(record *storage.Record) string {
+	var run storage.WorkflowRun
+	if err := json.Unmarshal(record.Data, &run); err == nil && run.Finished() {
+		return "the run already " + run.Status
+	}
+	// Runs stop when the server that started them does
+	return "the run is not in progress on this server"
+}
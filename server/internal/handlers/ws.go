@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"spacectl-web/server/internal/errors"
+	"spacectl-web/server/internal/grpc"
+	"spacectl-web/server/internal/response"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Websocket message types
+const (
+	WSMessageCall      = "call"
+	WSMessageCancel    = "cancel"
+	WSMessageProgress  = "progress"
+	WSMessageResult    = "result"
+	WSMessageError     = "error"
+	WSMessageCancelled = "cancelled"
+
+	// Streaming RPC messages
+	WSMessageStreamOpen    = "stream_open"
+	WSMessageStreamSend    = "stream_send"
+	WSMessageStreamClose   = "stream_close"
+	WSMessageStreamMessage = "stream_message"
+	WSMessageStreamEnd     = "stream_end"
+)
+
+// wsProgressInterval is how often progress messages are sent for running calls
+const wsProgressInterval = time.Second
+
+// upgrader rejects cross-origin websocket connections by default
+var upgrader = websocket.Upgrader{}
+
+// WSRequest represents a message sent by the client over the websocket
+type WSRequest struct {
+	Type       string                 `json:"type"`
+	ID         string                 `json:"id"`
+	Service    string                 `json:"service,omitempty"`
+	Resource   string                 `json:"resource,omitempty"`
+	Verb       string                 `json:"verb,omitempty"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// WSResponse represents a message sent by the server over the websocket
+type WSResponse struct {
+	Type      string              `json:"type"`
+	ID        string              `json:"id"`
+	Data      json.RawMessage     `json:"data,omitempty"`
+	Error     *response.ErrorInfo `json:"error,omitempty"`
+	ElapsedMs int64               `json:"elapsed_ms,omitempty"`
+}
+
+// wsSession tracks the in-flight calls of a single websocket connection
+type wsSession struct {
+	conn       *websocket.Conn
+	writeMutex sync.Mutex
+	calls      map[string]context.CancelFunc
+	streams    map[string]*grpc.Stream
+	callsMutex sync.Mutex
+	wg         sync.WaitGroup
+	language   string // Error message language negotiated on upgrade
+}
+
+// send writes a message to the websocket; writes are serialized across goroutines
+func (s *wsSession) send(msg *WSResponse) {
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+	_ = s.conn.WriteJSON(msg)
+}
+
+// WebSocket handles interactive sessions: the client sends call and cancel messages
+// and receives progress, result, error and cancelled messages correlated by ID
+func (h *Handler) WebSocket(c echo.Context) error {
+	conn, err := upgrader.Upgrade(c.Response(), c.Request(), nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	session := &wsSession{
+		conn:     conn,
+		calls:    make(map[string]context.CancelFunc),
+		streams:  make(map[string]*grpc.Stream),
+		language: response.Language(c),
+	}
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+
+	ctx, cancel := context.WithCancel(c.Request().Context())
+	defer func() {
+		cancel()
+		session.wg.Wait()
+	}()
+
+	for {
+		var req WSRequest
+		if err := conn.ReadJSON(&req); err != nil {
+			return nil // Connection closed by the client
+		}
+
+		switch req.Type {
+		case WSMessageCall:
+			h.startWSCall(grpc.WithRequestID(ctx, requestID), session, &req)
+		case WSMessageCancel:
+			session.callsMutex.Lock()
+			if cancelCall, exists := session.calls[req.ID]; exists {
+				cancelCall()
+			}
+			if stream, exists := session.streams[req.ID]; exists {
+				stream.Close()
+			}
+			session.callsMutex.Unlock()
+		case WSMessageStreamOpen:
+			h.openWSStream(grpc.WithRequestID(ctx, requestID), session, &req)
+		case WSMessageStreamSend, WSMessageStreamClose:
+			h.writeWSStream(session, &req)
+		default:
+			session.send(&WSResponse{
+				Type:  WSMessageError,
+				ID:    req.ID,
+				Error: &response.ErrorInfo{Code: http.StatusBadRequest, Message: "Unknown message type", Details: req.Type, RequestID: requestID},
+			})
+		}
+	}
+}
+
+// startWSCall runs a call in the background, sending progress until it completes or is cancelled
+func (h *Handler) startWSCall(ctx context.Context, session *wsSession, req *WSRequest) {
+	callCtx, cancel := context.WithCancel(ctx)
+
+	session.callsMutex.Lock()
+	session.calls[req.ID] = cancel
+	session.callsMutex.Unlock()
+
+	session.wg.Add(1)
+	go func() {
+		defer session.wg.Done()
+		defer func() {
+			cancel()
+			session.callsMutex.Lock()
+			delete(session.calls, req.ID)
+			session.callsMutex.Unlock()
+		}()
+
+		start := time.Now()
+		done := make(chan struct{})
+		go func() {
+			ticker := time.NewTicker(wsProgressInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-done:
+					return
+				case <-ticker.C:
+					session.send(&WSResponse{Type: WSMessageProgress, ID: req.ID, ElapsedMs: time.Since(start).Milliseconds()})
+				}
+			}
+		}()
+
+		session.send(&WSResponse{Type: WSMessageProgress, ID: req.ID})
+		jsonBytes, apiErr := h.invoke(callCtx, req.Service, req.Resource, req.Verb, filterParameters(req.Parameters))
+		close(done)
+
+		elapsed := time.Since(start).Milliseconds()
+		switch {
+		case callCtx.Err() == context.Canceled:
+			session.send(&WSResponse{Type: WSMessageCancelled, ID: req.ID, ElapsedMs: elapsed})
+		case apiErr != nil:
+			session.send(&WSResponse{
+				Type:      WSMessageError,
+				ID:        req.ID,
+				Error:     response.NewErrorInfo(apiErr),
+				ElapsedMs: elapsed,
+			})
+		default:
+			session.send(&WSResponse{Type: WSMessageResult, ID: req.ID, Data: jsonBytes, ElapsedMs: elapsed})
+		}
+	}()
+}
+
+// sendError sends an error message for the request ID
+func (s *wsSession) sendError(id string, code int, message, details string) {
+	s.sendAPIError(id, &errors.APIError{Code: code, Message: message, Details: details})
+}
+
+// sendAPIError sends a structured API error for the request ID
+func (s *wsSession) sendAPIError(id string, apiErr *errors.APIError) {
+	s.send(&WSResponse{
+		Type:  WSMessageError,
+		ID:    id,
+		Error: response.NewErrorInfo(apiErr.Localize(s.language)),
+	})
+}
+
+// openWSStream opens a streaming RPC and forwards every response until the stream ends
+func (h *Handler) openWSStream(ctx context.Context, session *wsSession, req *WSRequest) {
+	if err := h.validateRequest(req.Service, req.Resource, req.Verb); err != nil {
+		session.sendAPIError(req.ID, err)
+		return
+	}
+
+	serviceCaller, err := h.grpcManager.GetServiceCaller(req.Service)
+	if err != nil {
+		apiErr := callerError(err)
+		session.sendAPIError(req.ID, apiErr)
+		return
+	}
+
+	stream, err := serviceCaller.OpenStream(ctx, req.Service, req.Resource, req.Verb, filterParameters(req.Parameters))
+	if err != nil {
+		if apiErr, ok := err.(*errors.APIError); ok {
+			session.sendAPIError(req.ID, apiErr)
+		} else {
+			session.sendError(req.ID, http.StatusInternalServerError, "Failed to open stream", err.Error())
+		}
+		return
+	}
+
+	session.callsMutex.Lock()
+	session.streams[req.ID] = stream
+	session.callsMutex.Unlock()
+
+	session.wg.Add(1)
+	go func() {
+		defer session.wg.Done()
+		defer func() {
+			stream.Close()
+			session.callsMutex.Lock()
+			delete(session.streams, req.ID)
+			session.callsMutex.Unlock()
+		}()
+
+		for {
+			jsonBytes, err := stream.Recv()
+			if err == io.EOF {
+				session.send(&WSResponse{Type: WSMessageStreamEnd, ID: req.ID})
+				return
+			}
+			if err != nil {
+				if status.Code(err) == codes.Canceled {
+					session.send(&WSResponse{Type: WSMessageCancelled, ID: req.ID})
+				} else {
+					session.sendAPIError(req.ID, errors.NewAPIError(errors.ErrStreamFailed, err.Error()).WithGRPCStatus(err))
+				}
+				return
+			}
+			session.send(&WSResponse{Type: WSMessageStreamMessage, ID: req.ID, Data: jsonBytes})
+		}
+	}()
+}
+
+// writeWSStream sends a message on, or half-closes, an open stream
+func (h *Handler) writeWSStream(session *wsSession, req *WSRequest) {
+	session.callsMutex.Lock()
+	stream, exists := session.streams[req.ID]
+	session.callsMutex.Unlock()
+	if !exists {
+		session.sendError(req.ID, http.StatusNotFound, "Stream not found", req.ID)
+		return
+	}
+
+	var err error
+	if req.Type == WSMessageStreamClose {
+		err = stream.CloseSend()
+	} else {
+		err = stream.Send(filterParameters(req.Parameters))
+	}
+	if apiErr, ok := err.(*errors.APIError); ok {
+		session.sendAPIError(req.ID, apiErr)
+	} else if err != nil {
+		session.sendError(req.ID, http.StatusBadRequest, "Failed to write to stream", err.Error())
+	}
+}
@@ -0,0 +1,212 @@
+package icons
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"spacectl-web/server/internal/config"
+)
+
+const (
+	fetchTimeout = 10 * time.Second
+	maxRedirects = 3
+)
+
+// imageTypes are the content types served as icons
+var imageTypes = map[string]bool{
+	"image/png":                true,
+	"image/jpeg":               true,
+	"image/gif":                true,
+	"image/webp":               true,
+	"image/svg+xml":            true,
+	"image/x-icon":             true,
+	"image/vnd.microsoft.icon": true,
+}
+
+// Icon fetch failures
+var (
+	ErrInvalidURL      = errors.New("icon URL must be an absolute http or https URL")
+	ErrHostNotAllowed  = errors.New("icon host is not allowed")
+	ErrBlockedAddress  = errors.New("icon host resolves to a private or loopback address")
+	ErrNotImage        = errors.New("icon is not an image")
+	ErrTooLarge        = errors.New("icon exceeds the size limit")
+	errTooManyRedirect = errors.New("too many redirects")
+)
+
+// Icon is a fetched icon
+type Icon struct {
+	Data        []byte
+	ContentType string
+	ETag        string
+	fetched     time.Time
+}
+
+// Proxy fetches icons from the allowed hosts and caches them. Hosts that resolve to private,
+// loopback or link-local addresses are refused, so the proxy cannot reach internal services.
+type Proxy struct {
+	hosts      []string
+	maxBytes   int64
+	ttl        time.Duration
+	maxEntries int
+	client     *http.Client
+	icons      map[string]*Icon // By URL
+	mutex      sync.Mutex
+}
+
+// New creates an icon proxy from the configuration
+func New(cfg config.IconsConfig) *Proxy {
+	cfg = cfg.WithDefaults()
+	p := &Proxy{
+		hosts:      cfg.AllowedHosts,
+		maxBytes:   int64(cfg.MaxBytes),
+		ttl:        time.Duration(cfg.CacheMinutes) * time.Minute,
+		maxEntries: cfg.CacheEntries,
+		icons:      make(map[string]*Icon),
+	}
+	dialer := &net.Dialer{Timeout: fetchTimeout, Control: refusePrivateAddresses}
+	p.client = &http.Client{
+		Timeout:   fetchTimeout,
+		Transport: &http.Transport{DialContext: dialer.DialContext}, // Direct, so the address check applies
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return errTooManyRedirect
+			}
+			return p.checkURL(req.URL)
+		},
+	}
+	return p
+}
+
+// Get returns the icon at the URL, from the cache when it was fetched recently
+func (p *Proxy) Get(ctx context.Context, rawURL string) (*Icon, error) {
+	iconURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, ErrInvalidURL
+	}
+	if err := p.checkURL(iconURL); err != nil {
+		return nil, err
+	}
+
+	p.mutex.Lock()
+	icon, cached := p.icons[rawURL]
+	p.mutex.Unlock()
+	if cached && time.Since(icon.fetched) < p.ttl {
+		return icon, nil
+	}
+
+	icon, err = p.fetch(ctx, iconURL)
+	if err != nil {
+		return nil, err
+	}
+	p.store(rawURL, icon)
+	return icon, nil
+}
+
+// checkURL checks that the URL is absolute and its host allowed
+func (p *Proxy) checkURL(iconURL *url.URL) error {
+	if (iconURL.Scheme != "http" && iconURL.Scheme != "https") || iconURL.Hostname() == "" {
+		return ErrInvalidURL
+	}
+	host := strings.ToLower(iconURL.Hostname())
+	for _, allowed := range p.hosts {
+		allowed = strings.ToLower(allowed)
+		if host == allowed || (strings.HasPrefix(allowed, "*.") && strings.HasSuffix(host, allowed[1:])) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrHostNotAllowed, host)
+}
+
+// fetch downloads an icon, checking its size and content type
+func (p *Proxy) fetch(ctx context.Context, iconURL *url.URL) (*Icon, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, iconURL.String(), nil)
+	if err != nil {
+		return nil, ErrInvalidURL
+	}
+	req.Header.Set("Accept", "image/*")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("icon request returned %s", resp.Status)
+	}
+	if resp.ContentLength > p.maxBytes {
+		return nil, ErrTooLarge
+	}
+
+	contentType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || !imageTypes[contentType] {
+		return nil, fmt.Errorf("%w: content type '%s'", ErrNotImage, resp.Header.Get("Content-Type"))
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, p.maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > p.maxBytes {
+		return nil, ErrTooLarge
+	}
+	if !matchesContent(contentType, data) {
+		return nil, fmt.Errorf("%w: content does not match '%s'", ErrNotImage, contentType)
+	}
+
+	sum := sha256.Sum256(data)
+	return &Icon{Data: data, ContentType: contentType, ETag: `"` + hex.EncodeToString(sum[:])[:16] + `"`, fetched: time.Now()}, nil
+}
+
+// store caches an icon, dropping expired icons and then arbitrary ones when the cache is full
+func (p *Proxy) store(rawURL string, icon *Icon) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if len(p.icons) >= p.maxEntries {
+		for key, cached := range p.icons {
+			if time.Since(cached.fetched) >= p.ttl {
+				delete(p.icons, key)
+			}
+		}
+	}
+	for key := range p.icons {
+		if len(p.icons) < p.maxEntries {
+			break
+		}
+		delete(p.icons, key)
+	}
+	p.icons[rawURL] = icon
+}
+
+// matchesContent reports whether the data looks like the declared image type. SVG is text, so
+// it only needs an svg element.
+func matchesContent(contentType string, data []byte) bool {
+	if contentType == "image/svg+xml" {
+		return bytes.Contains(bytes.ToLower(data[:min(len(data), 4096)]), []byte("<svg"))
+	}
+	detected := http.DetectContentType(data)
+	return strings.HasPrefix(detected, "image/")
+}
+
+// refusePrivateAddresses fails connections to addresses that are not public unicast
+func refusePrivateAddresses(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !ip.IsGlobalUnicast() || ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() {
+		return ErrBlockedAddress
+	}
+	return nil
+}
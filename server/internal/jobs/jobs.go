@@ -0,0 +1,80 @@
+package jobs
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Job represents a running request that can be cancelled, such as a gRPC call or websocket session
+type Job struct {
+	ID        string    `json:"id"`
+	Route     string    `json:"route"` // Route pattern, e.g. /api/bench
+	Path      string    `json:"path"`
+	RemoteIP  string    `json:"remote_ip"`
+	RequestID string    `json:"request_id,omitempty"`
+	Started   time.Time `json:"started"`
+}
+
+// Registry tracks running jobs so they can be listed and cancelled
+type Registry struct {
+	jobs   map[string]*entry
+	nextID int64
+	mutex  sync.Mutex
+}
+
+// entry is a registered job with the function that cancels it
+type entry struct {
+	job    Job
+	cancel context.CancelFunc
+}
+
+// NewRegistry creates an empty job registry
+func NewRegistry() *Registry {
+	return &Registry{jobs: make(map[string]*entry)}
+}
+
+// Start registers a job and returns the function to call when it finishes
+func (r *Registry) Start(job Job, cancel context.CancelFunc) (string, func()) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.nextID++
+	job.ID = strconv.FormatInt(r.nextID, 10)
+	job.Started = time.Now()
+	r.jobs[job.ID] = &entry{job: job, cancel: cancel}
+
+	return job.ID, func() {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		delete(r.jobs, job.ID)
+	}
+}
+
+// List returns the running jobs, oldest first
+func (r *Registry) List() []Job {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	list := make([]Job, 0, len(r.jobs))
+	for _, e := range r.jobs {
+		list = append(list, e.job)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Started.Before(list[j].Started) })
+	return list
+}
+
+// Cancel cancels a running job and reports whether it was found
+func (r *Registry) Cancel(id string) bool {
+	r.mutex.Lock()
+	e, exists := r.jobs[id]
+	r.mutex.Unlock()
+
+	if !exists {
+		return false
+	}
+	e.cancel()
+	return true
+}
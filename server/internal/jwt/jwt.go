@@ -0,0 +1,59 @@
+package jwt
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Info represents parsed JWT token information
+type Info struct {
+	Header  map[string]interface{} `json:"header"`
+	Payload map[string]interface{} `json:"payload"`
+}
+
+// Parse parses a JWT token and returns header and payload without verifying the signature
+func Parse(token string) (*Info, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid JWT format")
+	}
+
+	// Parse header
+	headerData, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode header: %w", err)
+	}
+
+	var header map[string]interface{}
+	if err := json.Unmarshal(headerData, &header); err != nil {
+		return nil, fmt.Errorf("failed to parse header: %w", err)
+	}
+
+	// Parse payload
+	payloadData, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode payload: %w", err)
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(payloadData, &payload); err != nil {
+		return nil, fmt.Errorf("failed to parse payload: %w", err)
+	}
+
+	return &Info{
+		Header:  header,
+		Payload: payload,
+	}, nil
+}
+
+// ExpiresAt returns the token expiry from the "exp" claim, if present
+func (i *Info) ExpiresAt() (time.Time, bool) {
+	exp, ok := i.Payload["exp"].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(exp), 0), true
+}
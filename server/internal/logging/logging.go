@@ -0,0 +1,31 @@
+package logging
+
+import (
+	"io"
+	"os"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// RotationOptions controls size and age based log file rotation
+type RotationOptions struct {
+	MaxSizeMB  int  // Maximum size in megabytes before the file is rotated
+	MaxAgeDays int  // Maximum number of days to retain rotated files (0 keeps all)
+	MaxBackups int  // Maximum number of rotated files to retain (0 keeps all)
+	Compress   bool // Gzip rotated files
+}
+
+// NewWriter returns a rotating file writer for the path, or stdout when the path is empty
+func NewWriter(path string, opts RotationOptions) io.Writer {
+	if path == "" {
+		return os.Stdout
+	}
+
+	return &lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    opts.MaxSizeMB,
+		MaxAge:     opts.MaxAgeDays,
+		MaxBackups: opts.MaxBackups,
+		Compress:   opts.Compress,
+	}
+}
@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc/codes"
+)
+
+// MetricsPath is the path of the Prometheus metrics endpoint
+const MetricsPath = "/metrics"
+
+// callLabels are the labels recorded for every upstream gRPC call
+var callLabels = []string{"service", "resource", "verb", "grpc_code"}
+
+var (
+	callDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "spacectl_web",
+		Name:      "upstream_call_duration_seconds",
+		Help:      "Duration of upstream gRPC calls by service, resource, verb and status code.",
+		Buckets:   []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, callLabels)
+
+	responseSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "spacectl_web",
+		Name:      "upstream_response_size_bytes",
+		Help:      "Size of upstream gRPC responses converted to JSON by service, resource, verb and status code.",
+		Buckets:   prometheus.ExponentialBuckets(256, 4, 10), // 256B .. 64MB
+	}, callLabels)
+
+	clientDisconnects = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "spacectl_web",
+		Name:      "client_disconnects_total",
+		Help:      "Requests whose client went away before the response was complete, by route, service, resource and verb.",
+	}, []string{"route", "service", "resource", "verb"})
+)
+
+// ObserveCall records the duration and response size of an upstream gRPC call
+func ObserveCall(service, resource, verb string, code codes.Code, duration time.Duration, size int) {
+	labels := prometheus.Labels{
+		"service":   service,
+		"resource":  resource,
+		"verb":      verb,
+		"grpc_code": code.String(),
+	}
+	callDuration.With(labels).Observe(duration.Seconds())
+	responseSize.With(labels).Observe(float64(size))
+}
+
+// ObserveDisconnect counts a request abandoned by its client
+func ObserveDisconnect(route, service, resource, verb string) {
+	clientDisconnects.With(prometheus.Labels{"route": route, "service": service, "resource": resource, "verb": verb}).Inc()
+}
+
+// Setup exposes the Prometheus metrics endpoint
+func Setup(e *echo.Echo, basePath string) {
+	e.GET(basePath+MetricsPath, echo.WrapHandler(promhttp.Handler()))
+}
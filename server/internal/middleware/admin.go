@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"spacectl-web/server/internal/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HasAdminToken reports whether the request sends the admin token as a bearer token
+func HasAdminToken(r *http.Request, token string) bool {
+	presented, found := strings.CutPrefix(r.Header.Get(echo.HeaderAuthorization), "Bearer ")
+	return found && token != "" && subtle.ConstantTimeCompare([]byte(presented), []byte(token)) == 1
+}
+
+// AdminAuth only lets through requests sending the admin token as a bearer token
+func AdminAuth(token string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !HasAdminToken(c.Request(), token) {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="admin"`)
+				return response.Error(c, http.StatusUnauthorized, "Admin token required", "send the server.admin.token value as a bearer token")
+			}
+			return next(c)
+		}
+	}
+}
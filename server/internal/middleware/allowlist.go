@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"spacectl-web/server/internal/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// IPAllowlist rejects requests to paths under pathPrefix whose source address is outside the allowed networks.
+// The source is the direct peer address; forwarding headers are not trusted.
+func IPAllowlist(networks []*net.IPNet, pathPrefix string) echo.MiddlewareFunc {
+	extractIP := echo.ExtractIPDirect()
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if len(networks) == 0 {
+			return next
+		}
+
+		return func(c echo.Context) error {
+			if !strings.HasPrefix(c.Request().URL.Path, pathPrefix) {
+				return next(c)
+			}
+
+			ip := net.ParseIP(extractIP(c.Request()))
+			for _, network := range networks {
+				if ip != nil && network.Contains(ip) {
+					return next(c)
+				}
+			}
+
+			return response.Forbidden(c, "Access denied", fmt.Sprintf("source address '%s' is not allowed", ip))
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"spacectl-web/server/internal/errors"
+	"spacectl-web/server/internal/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// BodyLimit rejects request bodies larger than maxBytes with a 413 response.
+// Bodies without a declared length are cut off once they exceed the limit.
+func BodyLimit(maxBytes int64) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if req.ContentLength > maxBytes {
+				apiErr := errors.NewAPIError(errors.ErrRequestTooLarge,
+					fmt.Sprintf("request body is %d bytes, limit is %d bytes", req.ContentLength, maxBytes))
+				return response.APIError(c, apiErr)
+			}
+
+			req.Body = http.MaxBytesReader(c.Response(), req.Body, maxBytes)
+			return next(c)
+		}
+	}
+}
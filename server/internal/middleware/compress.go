@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"spacectl-web/server/internal/config"
+
+	"github.com/labstack/echo/v4"
+)
+
+// compressibleTypes lists the media types worth compressing
+var compressibleTypes = map[string]bool{
+	echo.MIMEApplicationJSON:       true,
+	echo.MIMEApplicationJavaScript: true,
+	"application/manifest+json":    true,
+	"image/svg+xml":                true,
+}
+
+// Compress gzip-compresses JSON, text and script responses when the client accepts it.
+// Websocket upgrades, range requests and responses that already have a Content-Encoding,
+// such as pre-compressed static assets, are passed through unchanged.
+func Compress(cfg config.CompressionConfig) echo.MiddlewareFunc {
+	if cfg.Disabled {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return next
+		}
+	}
+
+	cfg = cfg.WithDefaults()
+	pool := sync.Pool{New: func() interface{} {
+		w, _ := gzip.NewWriterLevel(io.Discard, cfg.Level)
+		return w
+	}}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if req.Header.Get(echo.HeaderUpgrade) != "" || req.Header.Get("Range") != "" {
+				return next(c)
+			}
+
+			res := c.Response()
+			res.Header().Add(echo.HeaderVary, echo.HeaderAcceptEncoding)
+			if !acceptsGzip(req.Header.Get(echo.HeaderAcceptEncoding)) {
+				return next(c)
+			}
+
+			cw := &compressWriter{ResponseWriter: res.Writer, pool: &pool, minLength: cfg.MinLength}
+			res.Writer = cw
+			defer func() {
+				res.Writer = cw.ResponseWriter
+				cw.close()
+			}()
+			return next(c)
+		}
+	}
+}
+
+// compressWriter decides on the first write whether to compress the response
+type compressWriter struct {
+	http.ResponseWriter
+	pool      *sync.Pool
+	minLength int
+	gz        *gzip.Writer
+	decided   bool
+}
+
+// WriteHeader decides whether to compress and sends the status code
+func (w *compressWriter) WriteHeader(code int) {
+	if !w.decided {
+		w.decided = true
+		if w.shouldCompress(code) {
+			header := w.Header()
+			header.Set(echo.HeaderContentEncoding, "gzip")
+			header.Del(echo.HeaderContentLength)
+			w.gz = w.pool.Get().(*gzip.Writer)
+			w.gz.Reset(w.ResponseWriter)
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write writes the body, compressing it when enabled
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if !w.decided {
+		if w.Header().Get(echo.HeaderContentType) == "" {
+			w.Header().Set(echo.HeaderContentType, http.DetectContentType(p))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.gz != nil {
+		return w.gz.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush flushes compressed data so streamed responses reach the client promptly
+func (w *compressWriter) Flush() {
+	if w.gz != nil {
+		_ = w.gz.Flush()
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap returns the underlying writer for http.ResponseController
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// close finishes the gzip stream and returns the writer to the pool
+func (w *compressWriter) close() {
+	if w.gz == nil {
+		return
+	}
+	_ = w.gz.Close()
+	w.gz.Reset(io.Discard)
+	w.pool.Put(w.gz)
+	w.gz = nil
+}
+
+// shouldCompress reports whether a response with the current headers is worth compressing
+func (w *compressWriter) shouldCompress(code int) bool {
+	if code < http.StatusOK || code == http.StatusNoContent || code == http.StatusNotModified {
+		return false
+	}
+	header := w.Header()
+	if header.Get(echo.HeaderContentEncoding) != "" {
+		return false
+	}
+	if length, err := strconv.Atoi(header.Get(echo.HeaderContentLength)); err == nil && length < w.minLength {
+		return false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(header.Get(echo.HeaderContentType))
+	if err != nil {
+		return false
+	}
+	if mediaType == "text/event-stream" {
+		return false
+	}
+	return strings.HasPrefix(mediaType, "text/") || compressibleTypes[mediaType]
+}
+
+// acceptsGzip reports whether the Accept-Encoding header allows gzip
+func acceptsGzip(acceptEncoding string) bool {
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		value, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.EqualFold(strings.TrimSpace(value), "gzip") {
+			return strings.TrimSpace(strings.ReplaceAll(params, " ", "")) != "q=0"
+		}
+	}
+	return false
+}
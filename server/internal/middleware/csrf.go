@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+
+	"spacectl-web/server/internal/config"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// CSRF issues a CSRF token cookie on safe requests and verifies the matching header on POST/PUT/PATCH/DELETE
+func CSRF(cfg config.CSRFConfig) echo.MiddlewareFunc {
+	if !cfg.Enabled {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return next
+		}
+	}
+
+	cfg = cfg.WithDefaults()
+	return middleware.CSRFWithConfig(middleware.CSRFConfig{
+		TokenLookup:    "header:" + cfg.HeaderName,
+		CookieName:     cfg.CookieName,
+		CookiePath:     "/",
+		CookieSameSite: http.SameSiteStrictMode,
+		CookieHTTPOnly: false, // The web client reads the cookie to echo it back in the header
+	})
+}
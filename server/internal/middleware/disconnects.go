@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+	"time"
+
+	"spacectl-web/server/internal/disconnects"
+	"spacectl-web/server/internal/metrics"
+
+	"github.com/labstack/echo/v4"
+)
+
+// TrackDisconnects records whether the client of each request went away before the response was
+// complete. The request's own context is checked, so calls cancelled through the admin API are
+// not counted. Websocket sessions always end with a disconnect and are left out.
+func TrackDisconnects(tracker *disconnects.Tracker) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if strings.EqualFold(req.Header.Get(echo.HeaderUpgrade), "websocket") {
+				return next(c)
+			}
+			ctx := req.Context()
+			started := time.Now()
+
+			err := next(c)
+			abandoned := stderrors.Is(ctx.Err(), context.Canceled)
+			serviceName, resourceName, verb := c.Param("service"), c.Param("resource"), c.Param("verb")
+			tracker.Observe(c.Path(), serviceName, resourceName, verb, abandoned, time.Since(started))
+			if abandoned {
+				metrics.ObserveDisconnect(c.Path(), serviceName, resourceName, verb)
+			}
+			return err
+		}
+	}
+}
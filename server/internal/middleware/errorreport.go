@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"spacectl-web/server/internal/errorreport"
+	"spacectl-web/server/internal/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ReportErrors reports panics and 5xx responses with the request's route, user and ID.
+// Panics are re-raised after reporting, so the recover middleware still answers them.
+func ReportErrors(reporter *errorreport.Reporter, userHeader string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					if recovered != http.ErrAbortHandler {
+						reporter.Report(&errorreport.Report{
+							Kind:    errorreport.KindPanic,
+							Status:  http.StatusInternalServerError,
+							Message: fmt.Sprint(recovered),
+							Stack:   string(debug.Stack()),
+							Request: requestContext(c, userHeader),
+						})
+					}
+					panic(recovered)
+				}
+			}()
+
+			err = next(c)
+			status := c.Response().Status
+			message := http.StatusText(status)
+			if err != nil {
+				// The error handler responds after the middleware returns
+				status = http.StatusInternalServerError
+				if httpErr, ok := err.(*echo.HTTPError); ok {
+					status = httpErr.Code
+				}
+				message = err.Error()
+			}
+			if status < http.StatusInternalServerError {
+				return err
+			}
+
+			report := &errorreport.Report{Kind: errorreport.KindError, Status: status, Message: message, Request: requestContext(c, userHeader)}
+			if sent := response.SentError(c); sent != nil && err == nil {
+				report.Message = sent.Message
+				report.ErrorCode = sent.ErrorCode
+				report.Details = sent.Details
+			}
+			reporter.Report(report)
+			return err
+		}
+	}
+}
+
+// requestContext identifies a request without its headers, query string or body
+func requestContext(c echo.Context, userHeader string) errorreport.RequestContext {
+	req := c.Request()
+	return errorreport.RequestContext{
+		Method:    req.Method,
+		Route:     c.Path(),
+		Path:      req.URL.Path,
+		RequestID: c.Response().Header().Get(echo.HeaderXRequestID),
+		User:      req.Header.Get(userHeader),
+		RemoteIP:  c.RealIP(),
+		UserAgent: req.UserAgent(),
+	}
+}
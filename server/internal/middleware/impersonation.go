@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"log"
+	"strings"
+
+	"spacectl-web/server/internal/config"
+	"spacectl-web/server/internal/grpc"
+	"spacectl-web/server/internal/session"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Impersonate sends the user and workspace a session impersonates as metadata with its calls,
+// and writes every request under pathPrefix made during the impersonation to the audit log
+func Impersonate(store *session.Store, cfg config.ImpersonationConfig, audit *log.Logger, pathPrefix string) echo.MiddlewareFunc {
+	cfg = cfg.WithDefaults()
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			cookie, err := c.Cookie(session.CookieName)
+			if err != nil || cookie.Value == "" || !strings.HasPrefix(c.Request().URL.Path, pathPrefix) {
+				return next(c)
+			}
+			impersonation, exists := store.Impersonation(cookie.Value)
+			if !exists {
+				return next(c)
+			}
+
+			req := c.Request()
+			c.SetRequest(req.WithContext(grpc.WithMetadata(req.Context(), map[string]string{
+				cfg.UserKey:      impersonation.UserID,
+				cfg.WorkspaceKey: impersonation.WorkspaceID,
+			})))
+			err = next(c)
+			audit.Printf("impersonated request: user=%q workspace=%q operator=%s operator_user=%q method=%s path=%s status=%d request_id=%s",
+				impersonation.UserID, impersonation.WorkspaceID, impersonation.Operator, impersonation.OperatorUser, req.Method, req.URL.Path,
+				c.Response().Status, c.Response().Header().Get(echo.HeaderXRequestID))
+			return err
+		}
+	}
+}
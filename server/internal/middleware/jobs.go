@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"context"
+
+	"spacectl-web/server/internal/jobs"
+
+	"github.com/labstack/echo/v4"
+)
+
+// TrackJobs registers each request as a job whose context is cancelled when the job is cancelled
+func TrackJobs(registry *jobs.Registry) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			ctx, cancel := context.WithCancel(req.Context())
+			defer cancel()
+
+			_, done := registry.Start(jobs.Job{
+				Route:     c.Path(),
+				Path:      req.URL.Path,
+				RemoteIP:  c.RealIP(),
+				RequestID: c.Response().Header().Get(echo.HeaderXRequestID),
+			}, cancel)
+			defer done()
+
+			c.SetRequest(req.WithContext(ctx))
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"spacectl-web/server/internal/errors"
+	"spacectl-web/server/internal/response"
+	"spacectl-web/server/internal/usage"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Quota records per-user usage of the routes it wraps and rejects calls over quota with a 429 response.
+// The user is read from userHeader, which must be set by an authenticating reverse proxy.
+func Quota(tracker *usage.Tracker, userHeader string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user := c.Request().Header.Get(userHeader)
+			if user == "" {
+				user = usage.AnonymousUser
+			}
+
+			if allowed, retryAfter := tracker.Allow(user); !allowed {
+				c.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				apiErr := errors.NewAPIError(errors.ErrQuotaExceeded,
+					fmt.Sprintf("quota for user '%s' resets in %s", user, retryAfter.Round(time.Second)))
+				return response.APIError(c, apiErr)
+			}
+
+			err := next(c)
+			tracker.Record(user, c.Param("service"), max(c.Request().ContentLength, 0)+c.Response().Size)
+			return err
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"spacectl-web/server/internal/grpc"
+	"spacectl-web/server/internal/session"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ScopedToken makes the calls of a session use the scoped token stored for it, if any
+func ScopedToken(store *session.Store) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			cookie, err := c.Cookie(session.CookieName)
+			if err != nil || cookie.Value == "" {
+				return next(c)
+			}
+			if scoped, exists := store.Get(cookie.Value); exists {
+				req := c.Request()
+				c.SetRequest(req.WithContext(grpc.WithToken(req.Context(), scoped.Token)))
+			}
+			return next(c)
+		}
+	}
+}
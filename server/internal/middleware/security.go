@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"spacectl-web/server/internal/config"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// SecurityHeaders sets Content-Security-Policy, X-Frame-Options, X-Content-Type-Options and Referrer-Policy headers
+func SecurityHeaders(cfg config.SecurityHeadersConfig) echo.MiddlewareFunc {
+	if cfg.Disabled {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return next
+		}
+	}
+
+	cfg = cfg.WithDefaults()
+	return middleware.SecureWithConfig(middleware.SecureConfig{
+		ContentTypeNosniff:    "nosniff",
+		XFrameOptions:         cfg.XFrameOptions,
+		ContentSecurityPolicy: cfg.ContentSecurityPolicy,
+		ReferrerPolicy:        cfg.ReferrerPolicy,
+	})
+}
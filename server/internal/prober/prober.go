@@ -0,0 +1,126 @@
+package prober
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"spacectl-web/server/internal/config"
+	"spacectl-web/server/internal/grpc"
+)
+
+// Sample is the result of one health check
+type Sample struct {
+	Time      time.Time `json:"time"`
+	Up        bool      `json:"up"`
+	LatencyMS float64   `json:"latency_ms"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// History is the rolling window of health checks of an endpoint, oldest first
+type History struct {
+	Service         string   `json:"service"`
+	IntervalSeconds int      `json:"interval_seconds"`
+	Uptime          float64  `json:"uptime"` // Fraction of checks in the window that were up
+	Samples         []Sample `json:"samples"`
+}
+
+// Prober checks the health of every endpoint at a fixed interval and keeps the recent results
+type Prober struct {
+	config   *config.Config
+	pool     *grpc.ConnectionPool
+	settings config.ProberConfig
+	samples  map[string][]Sample // By service name
+	mutex    sync.RWMutex
+}
+
+// New creates a prober for the endpoints of the config
+func New(cfg *config.Config, pool *grpc.ConnectionPool, settings config.ProberConfig) *Prober {
+	return &Prober{
+		config:   cfg,
+		pool:     pool,
+		settings: settings.WithDefaults(),
+		samples:  make(map[string][]Sample),
+	}
+}
+
+// Run checks every endpoint immediately and then every interval until ctx is canceled
+func (p *Prober) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.settings.Interval())
+	defer ticker.Stop()
+	for {
+		p.probeAll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// probeAll checks every endpoint concurrently
+func (p *Prober) probeAll(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, service := range p.config.ServiceNames() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.record(service, p.probe(ctx, service))
+		}()
+	}
+	wg.Wait()
+}
+
+// probe checks one endpoint
+func (p *Prober) probe(ctx context.Context, service string) Sample {
+	ctx, cancel := context.WithTimeout(ctx, p.settings.Timeout())
+	defer cancel()
+
+	started := time.Now()
+	err := p.pool.CheckHealth(ctx, service)
+	sample := Sample{Time: started, Up: err == nil, LatencyMS: float64(time.Since(started).Microseconds()) / 1000}
+	if err != nil {
+		sample.Error = err.Error()
+	}
+	return sample
+}
+
+// record adds a sample, dropping the oldest beyond the window
+func (p *Prober) record(service string, sample Sample) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	samples := append(p.samples[service], sample)
+	if excess := len(samples) - p.settings.Window; excess > 0 {
+		samples = append([]Sample(nil), samples[excess:]...)
+	}
+	p.samples[service] = samples
+}
+
+// Reset forgets every check, e.g. after switching to a profile with other endpoints
+func (p *Prober) Reset() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.samples = make(map[string][]Sample)
+}
+
+// History returns the recent checks of a service's endpoint, or false for an unknown service
+func (p *Prober) History(service string) (*History, bool) {
+	if _, exists := p.config.GetEndpoint(service); !exists {
+		return nil, false
+	}
+	p.mutex.RLock()
+	samples := append([]Sample{}, p.samples[service]...)
+	p.mutex.RUnlock()
+
+	history := &History{Service: service, IntervalSeconds: p.settings.IntervalSeconds, Samples: samples}
+	if len(samples) > 0 {
+		up := 0
+		for _, sample := range samples {
+			if sample.Up {
+				up++
+			}
+		}
+		history.Uptime = float64(up) / float64(len(samples))
+	}
+	return history, true
+}
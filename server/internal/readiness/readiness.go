@@ -0,0 +1,89 @@
+package readiness
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"spacectl-web/server/internal/config"
+	"spacectl-web/server/internal/grpc"
+)
+
+// probeTimeout bounds a single endpoint reachability check
+const probeTimeout = 3 * time.Second
+
+// Gate tracks whether the server is ready to serve calls
+type Gate struct {
+	ready    bool
+	waiting  []string // Services whose endpoints were unreachable at the last attempt
+	attempts int
+	mutex    sync.RWMutex
+}
+
+// Status represents the readiness of the server
+type Status struct {
+	Ready      bool     `json:"ready"`
+	WaitingFor []string `json:"waiting_for,omitempty"`
+	Attempts   int      `json:"attempts,omitempty"`
+}
+
+// NewGate creates a gate that is not ready yet
+func NewGate() *Gate {
+	return &Gate{}
+}
+
+// MarkReady marks the server ready
+func (g *Gate) MarkReady() {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.ready = true
+	g.waiting = nil
+}
+
+// Status returns the current readiness
+func (g *Gate) Status() Status {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+	return Status{Ready: g.ready, WaitingFor: append([]string(nil), g.waiting...), Attempts: g.attempts}
+}
+
+// WaitForEndpoints probes every configured endpoint until all accept connections, retrying the
+// unreachable ones every interval, then marks the gate ready. It gives up when ctx is canceled.
+func (g *Gate) WaitForEndpoints(ctx context.Context, cfg *config.Config, interval time.Duration) {
+	pending := cfg.ServiceNames()
+	for {
+		var unreachable []string
+		for _, service := range pending {
+			endpoint, exists := cfg.GetEndpoint(service)
+			if !exists {
+				continue
+			}
+			if err := grpc.ProbeEndpoint(service, endpoint, probeTimeout); err != nil {
+				unreachable = append(unreachable, service)
+			}
+		}
+		sort.Strings(unreachable)
+
+		g.mutex.Lock()
+		g.attempts++
+		g.waiting = unreachable
+		attempts := g.attempts
+		g.mutex.Unlock()
+
+		if len(unreachable) == 0 {
+			g.MarkReady()
+			log.Printf("All endpoints reachable after %d attempt(s): ready", attempts)
+			return
+		}
+		log.Printf("Waiting for endpoints of %v (attempt %d), retrying in %s", unreachable, attempts, interval)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+		pending = unreachable
+	}
+}
@@ -0,0 +1,161 @@
+package recording
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// discoveryFile is the file name used for recorded service discovery information
+const discoveryFile = "_discovery.json"
+
+// Recording represents a recorded request/response pair
+type Recording struct {
+	Service    string                 `json:"service"`
+	Resource   string                 `json:"resource"`
+	Verb       string                 `json:"verb"`
+	Parameters map[string]interface{} `json:"parameters"`
+	Response   json.RawMessage        `json:"response"`
+	RecordedAt time.Time              `json:"recorded_at"`
+}
+
+// Store persists recorded responses and discovery information in a directory tree:
+// <dir>/<service>/_discovery.json and <dir>/<service>/<resource>/<verb>/<hash>.json
+type Store struct {
+	dir string
+}
+
+// NewStore creates a Store rooted at the given directory
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Dir returns the root directory of the store
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Key returns a stable key for the call parameters
+func Key(parameters map[string]interface{}) string {
+	// encoding/json sorts map keys, so equal parameters produce equal keys
+	data, err := json.Marshal(parameters)
+	if err != nil {
+		data = []byte(fmt.Sprintf("%v", parameters))
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// SaveResponse records a response for the given call
+func (s *Store) SaveResponse(service, resource, verb string, parameters map[string]interface{}, resp []byte) error {
+	recording := &Recording{
+		Service:    service,
+		Resource:   resource,
+		Verb:       verb,
+		Parameters: parameters,
+		Response:   json.RawMessage(resp),
+		RecordedAt: time.Now(),
+	}
+	return writeJSON(filepath.Join(s.verbDir(service, resource, verb), Key(parameters)+".json"), recording)
+}
+
+// LoadResponse returns the recorded response for the given call.
+// If no recording matches the parameters exactly, the most recent recording for the verb is returned.
+func (s *Store) LoadResponse(service, resource, verb string, parameters map[string]interface{}) (*Recording, error) {
+	verbDir := s.verbDir(service, resource, verb)
+
+	var recording Recording
+	if err := readJSON(filepath.Join(verbDir, Key(parameters)+".json"), &recording); err == nil {
+		return &recording, nil
+	}
+
+	recordings, err := s.ListResponses(service, resource, verb)
+	if err != nil || len(recordings) == 0 {
+		return nil, fmt.Errorf("no recording found for %s.%s.%s", service, resource, verb)
+	}
+	return recordings[0], nil
+}
+
+// ListResponses returns the recordings for a verb, most recent first
+func (s *Store) ListResponses(service, resource, verb string) ([]*Recording, error) {
+	matches, err := filepath.Glob(filepath.Join(s.verbDir(service, resource, verb), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	recordings := make([]*Recording, 0, len(matches))
+	for _, path := range matches {
+		var recording Recording
+		if err := readJSON(path, &recording); err != nil {
+			continue
+		}
+		recordings = append(recordings, &recording)
+	}
+
+	sort.Slice(recordings, func(i, j int) bool {
+		return recordings[i].RecordedAt.After(recordings[j].RecordedAt)
+	})
+	return recordings, nil
+}
+
+// SaveDiscovery records service discovery information
+func (s *Store) SaveDiscovery(service string, info interface{}) error {
+	return writeJSON(filepath.Join(s.dir, safeName(service), discoveryFile), info)
+}
+
+// LoadDiscovery loads recorded service discovery information into info
+func (s *Store) LoadDiscovery(service string, info interface{}) error {
+	if err := readJSON(filepath.Join(s.dir, safeName(service), discoveryFile), info); err != nil {
+		return fmt.Errorf("no recorded discovery for service '%s': %w", service, err)
+	}
+	return nil
+}
+
+// Services returns the names of services with recorded discovery information
+func (s *Store) Services() []string {
+	matches, _ := filepath.Glob(filepath.Join(s.dir, "*", discoveryFile))
+	services := make([]string, 0, len(matches))
+	for _, path := range matches {
+		services = append(services, filepath.Base(filepath.Dir(path)))
+	}
+	sort.Strings(services)
+	return services
+}
+
+func (s *Store) verbDir(service, resource, verb string) string {
+	return filepath.Join(s.dir, safeName(service), safeName(resource), safeName(verb))
+}
+
+// safeName prevents path traversal through service, resource or verb names
+func safeName(name string) string {
+	name = strings.ReplaceAll(name, string(filepath.Separator), "_")
+	if name == "" || name == "." || name == ".." {
+		return "_"
+	}
+	return name
+}
+
+func writeJSON(path string, v interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create recording directory: %w", err)
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode recording: %w", err)
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+func readJSON(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
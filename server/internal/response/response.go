@@ -2,6 +2,9 @@ package response
 
 import (
 	"net/http"
+	"strings"
+
+	"spacectl-web/server/internal/errors"
 
 	"github.com/labstack/echo/v4"
 )
@@ -15,9 +18,36 @@ type Response struct {
 
 // ErrorInfo represents error information in the response
 type ErrorInfo struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-	Details string `json:"details,omitempty"`
+	Code      int                 `json:"code"`
+	ErrorCode string              `json:"error_code,omitempty"` // Machine-readable error code
+	Message   string              `json:"message"`
+	Details   string              `json:"details,omitempty"`
+	GRPCCode  string              `json:"grpc_code,omitempty"` // Upstream gRPC status code name
+	Hint      string              `json:"hint,omitempty"`      // Suggested remediation
+	Fields    []errors.FieldError `json:"fields,omitempty"`    // Per-field validation failures
+	RequestID string              `json:"request_id,omitempty"`
+}
+
+// NewErrorInfo converts an API error to error information for a response
+func NewErrorInfo(apiErr *errors.APIError) *ErrorInfo {
+	errorCode := string(apiErr.ErrorCode)
+	if errorCode == "" {
+		errorCode = statusErrorCode(apiErr.Code)
+	}
+	return &ErrorInfo{
+		Code:      apiErr.Code,
+		ErrorCode: errorCode,
+		Message:   apiErr.Message,
+		Details:   apiErr.Details,
+		GRPCCode:  apiErr.GRPCCode,
+		Hint:      apiErr.Hint,
+		Fields:    apiErr.Fields,
+	}
+}
+
+// statusErrorCode derives an error code from an HTTP status, e.g. 404 becomes NOT_FOUND
+func statusErrorCode(code int) string {
+	return strings.ToUpper(strings.ReplaceAll(http.StatusText(code), " ", "_"))
 }
 
 // Success sends a successful response
@@ -30,21 +60,50 @@ func Success(c echo.Context, data interface{}) error {
 
 // Error sends an error response
 func Error(c echo.Context, code int, message string, details ...string) error {
-	errorInfo := &ErrorInfo{
-		Code:    code,
-		Message: message,
-	}
-
+	apiErr := &errors.APIError{Code: code, Message: message}
 	if len(details) > 0 {
-		errorInfo.Details = details[0]
+		apiErr.Details = details[0]
 	}
+	return APIError(c, apiErr)
+}
 
-	return c.JSON(code, Response{
+// Language negotiation headers, which echo does not define
+const (
+	headerAcceptLanguage  = "Accept-Language"
+	headerContentLanguage = "Content-Language"
+)
+
+// Language returns the error message language requested by the client's Accept-Language header
+func Language(c echo.Context) string {
+	return errors.PreferredLanguage(c.Request().Header.Get(headerAcceptLanguage))
+}
+
+// sentErrorKey is the context key of the error information sent in a response
+const sentErrorKey = "sentError"
+
+// APIError sends an error response for a structured API error in the client's language
+func APIError(c echo.Context, apiErr *errors.APIError) error {
+	language := Language(c)
+	header := c.Response().Header()
+	header.Set(headerContentLanguage, language)
+	header.Add(echo.HeaderVary, headerAcceptLanguage)
+
+	errorInfo := NewErrorInfo(apiErr.Localize(language))
+	errorInfo.RequestID = c.Response().Header().Get(echo.HeaderXRequestID)
+	c.Set(sentErrorKey, errorInfo)
+
+	return c.JSON(apiErr.Code, Response{
 		Success: false,
 		Error:   errorInfo,
 	})
 }
 
+// SentError returns the error information sent in the response, or nil when none was sent
+func SentError(c echo.Context) *ErrorInfo {
+	errorInfo, _ := c.Get(sentErrorKey).(*ErrorInfo)
+	return errorInfo
+}
+
 // NotFound sends a 404 response
 func NotFound(c echo.Context, message string, details ...string) error {
 	return Error(c, http.StatusNotFound, message, details...)
@@ -55,6 +114,11 @@ func BadRequest(c echo.Context, message string, details ...string) error {
 	return Error(c, http.StatusBadRequest, message, details...)
 }
 
+// Forbidden sends a 403 response
+func Forbidden(c echo.Context, message string, details ...string) error {
+	return Error(c, http.StatusForbidden, message, details...)
+}
+
 // InternalServerError sends a 500 response
 func InternalServerError(c echo.Context, message string, details ...string) error {
 	return Error(c, http.StatusInternalServerError, message, details...)
@@ -0,0 +1,48 @@
+package response
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SSEWriter writes server-sent events to the response
+type SSEWriter struct {
+	c echo.Context
+}
+
+// NewSSE writes the event stream headers and returns a writer for sending events
+func NewSSE(c echo.Context) *SSEWriter {
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentType, "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no") // Disable proxy buffering (nginx)
+	c.Response().WriteHeader(http.StatusOK)
+	c.Response().Flush()
+	return &SSEWriter{c: c}
+}
+
+// Event sends a named event with JSON-encoded data
+func (w *SSEWriter) Event(name string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w.c.Response(), "event: %s\ndata: %s\n\n", name, payload); err != nil {
+		return err
+	}
+	w.c.Response().Flush()
+	return nil
+}
+
+// Comment sends a comment line, used as a keep-alive heartbeat
+func (w *SSEWriter) Comment(text string) error {
+	if _, err := fmt.Fprintf(w.c.Response(), ": %s\n\n", text); err != nil {
+		return err
+	}
+	w.c.Response().Flush()
+	return nil
+}
@@ -0,0 +1,69 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// successPrefix opens a successful response envelope whose data follows
+const successPrefix = `{"success":true,"data":`
+
+// JSONStream writes a successful response whose data is written incrementally.
+// The status and envelope are only sent on the first write, so failures before
+// any data is written can still be reported with Error.
+type JSONStream struct {
+	c       echo.Context
+	started bool
+}
+
+// NewJSONStream creates a JSONStream for the request
+func NewJSONStream(c echo.Context) *JSONStream {
+	return &JSONStream{c: c}
+}
+
+// Write writes part of the response data, sending the envelope first if needed
+func (s *JSONStream) Write(p []byte) (int, error) {
+	if !s.started {
+		s.started = true
+		res := s.c.Response()
+		res.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		res.WriteHeader(http.StatusOK)
+		if _, err := res.Write([]byte(successPrefix)); err != nil {
+			return 0, err
+		}
+	}
+	return s.c.Response().Write(p)
+}
+
+// Started reports whether any part of the response has been sent
+func (s *JSONStream) Started() bool {
+	return s.started
+}
+
+// Close closes the response envelope
+func (s *JSONStream) Close() error {
+	_, err := s.c.Response().Write([]byte("}\n"))
+	return err
+}
+
+// CloseWithSection closes the response envelope after adding a top-level section next to the data
+func (s *JSONStream) CloseWithSection(name string, value interface{}) error {
+	return s.CloseWithSections(map[string]interface{}{name: value})
+}
+
+// CloseWithSections closes the response envelope after adding top-level sections next to the data
+func (s *JSONStream) CloseWithSections(sections map[string]interface{}) error {
+	if len(sections) == 0 {
+		return s.Close()
+	}
+	section, err := json.Marshal(sections)
+	if err != nil {
+		return err
+	}
+	// Replace the section object's opening brace with a comma to continue the envelope
+	section[0] = ','
+	_, err = s.c.Response().Write(append(section, '\n'))
+	return err
+}
@@ -3,16 +3,109 @@ package routes
 import (
 	"spacectl-web/server/internal/constants"
 	"spacectl-web/server/internal/handlers"
+	"spacectl-web/server/internal/storage"
 
 	"github.com/labstack/echo/v4"
 )
 
-// SetupRoutes configures all API routes
-func SetupRoutes(e *echo.Echo, handler *handlers.Handler) {
+// SetupRoutes configures all API routes under the given base path.
+// callMiddleware is applied to the routes that call gRPC methods.
+func SetupRoutes(e *echo.Echo, basePath string, handler *handlers.Handler, callMiddleware ...echo.MiddlewareFunc) {
 	// API routes
-	api := e.Group(constants.APIPrefix)
+	api := e.Group(basePath + constants.APIPrefix)
 	api.GET(constants.ServicesPath, handler.ListServices)
 	api.GET(constants.ResourcesPath, handler.ListResources)
-	api.POST(constants.GRPCMethodPath, handler.CallGRPCMethod)
+	api.POST(constants.GRPCMethodPath, handler.CallGRPCMethod, callMiddleware...)
+	api.POST(constants.GRPCCountPath, handler.CountMatches, callMiddleware...)
+	api.POST(constants.GRPCSnippetsPath, handler.GetCallSnippets)
+	api.GET(constants.GRPCSchemaPath, handler.GetResponseSchema)
+	api.POST(constants.BatchPath, handler.CallBatch, callMiddleware...)
+	api.POST(constants.FieldStatsPath, handler.GetFieldStats, callMiddleware...)
+	api.POST(constants.CostSeriesPath, handler.AnalyzeCostSeries, callMiddleware...)
+	api.GET(constants.MetricDataPath, handler.GetMetricData, callMiddleware...)
+	api.GET(constants.LogsPath, handler.ListLogs, callMiddleware...)
+	api.POST(constants.AlertsBulkPath, handler.UpdateAlerts, callMiddleware...)
+	api.GET(constants.AccessOverviewPath, handler.GetAccessOverview, callMiddleware...)
+	api.GET(constants.ProvidersPath, handler.GetProviderConnections, callMiddleware...)
+	api.GET(constants.CollectorJobPath, handler.GetCollectorJob, callMiddleware...)
+	api.POST(constants.CollectWorkflowPath, handler.CollectWorkflow, callMiddleware...)
+	api.GET(constants.TypeScriptClientPath, handler.DownloadTypeScriptClient)
+	api.GET(constants.HealthWatchPath, handler.WatchHealth)
+	api.GET(constants.HTTPRoutesPath, handler.ListHTTPRoutes)
+	api.Any(constants.HTTPTranscodePath, handler.TranscodeHTTP, callMiddleware...)
+	api.GET(constants.StatusHistoryPath, handler.GetStatusHistory)
+	api.GET(constants.StartupPath, handler.GetStartupReport)
+	api.GET(constants.DiscoveryEventsPath, handler.WatchDiscovery)
+	api.POST(constants.BenchPath, handler.Bench, callMiddleware...)
+	api.GET(constants.WebSocketPath, handler.WebSocket, callMiddleware...)
+	api.GET(constants.RecordingsPath, handler.ListRecordings)
+	api.GET(constants.RecordingsExportPath, handler.ExportRecordings)
 	api.GET(constants.ConfigInfoPath, handler.GetConfigInfo)
+	api.POST(constants.TokenRefreshPath, handler.RefreshToken)
+	api.GET(constants.ScopePath, handler.GetScope)
+	api.POST(constants.ScopePath, handler.CreateScope)
+	api.DELETE(constants.ScopePath, handler.DeleteScope)
+	api.GET(constants.ValidatePath, handler.ValidateConfig)
+	api.GET(constants.ProfilesPath, handler.ListProfiles)
+	api.POST(constants.ProfileSwitchPath, handler.SwitchProfile)
+	api.GET(constants.ErrorCodesPath, handler.ListErrorCodes)
+	api.GET(constants.UsagePath, handler.GetUsage)
+	api.GET(constants.SlowCallsPath, handler.ListSlowCalls)
+	api.GET(constants.DisconnectsPath, handler.GetDisconnects)
+	api.GET(constants.IconPath, handler.GetIcon)
+	api.GET(constants.UIConfigPath, handler.GetUIConfig)
+	api.GET(constants.VersionPath, handler.GetVersion)
+	api.GET(constants.UIVersionPath, handler.GetUIVersion)
+	api.GET(constants.HealthzPath, handler.Healthz)
+	api.GET(constants.ReadyzPath, handler.Readyz)
+	api.GET(constants.HistoryPath, handler.ListRecords(storage.KindHistory))
+	api.DELETE(constants.HistoryPath, handler.ClearHistory)
+	for kind, paths := range map[storage.Kind][2]string{
+		storage.KindFavorite:   {constants.FavoritesPath, constants.FavoritePath},
+		storage.KindCollection: {constants.CollectionsPath, constants.CollectionPath},
+		storage.KindSchedule:   {constants.SchedulesPath, constants.SchedulePath},
+		storage.KindView:       {constants.ViewsPath, constants.ViewPath},
+		storage.KindWorkflow:   {constants.WorkflowsPath, constants.WorkflowPath},
+	} {
+		api.GET(paths[0], handler.ListRecords(kind))
+		api.POST(paths[0], handler.CreateRecord(kind))
+		api.GET(paths[1], handler.GetRecord(kind))
+		api.PUT(paths[1], handler.UpdateRecord(kind))
+		api.DELETE(paths[1], handler.DeleteRecord(kind))
+	}
+	api.GET(constants.ViewRunPath, handler.RunView, callMiddleware...)
+	api.POST(constants.ReportsPath, handler.CreateReport, callMiddleware...)
+	api.POST(constants.FavoriteComparePath, handler.CompareFavorite, callMiddleware...)
+	api.GET(constants.ChangeReportsPath, handler.ListRecords(storage.KindChangeReport))
+	api.GET(constants.ChangeReportPath, handler.GetRecord(storage.KindChangeReport))
+	api.DELETE(constants.ChangeReportPath, handler.DeleteRecord(storage.KindChangeReport))
+	api.POST(constants.WorkflowRunPath, handler.RunWorkflow, callMiddleware...)
+	api.GET(constants.WorkflowRunsPath, handler.ListRecords(storage.KindWorkflowRun))
+	api.GET(constants.WorkflowRunIDPath, handler.GetRecord(storage.KindWorkflowRun))
+	api.DELETE(constants.WorkflowRunIDPath, handler.DeleteWorkflowRun)
+	api.POST(constants.WorkflowCancelPath, handler.CancelWorkflowRun)
+	api.GET(constants.BackupPath, handler.Backup)
+	api.POST(constants.RestorePath, handler.Restore)
+	api.GET(constants.LibraryPath, handler.ExportLibrary)
+	api.POST(constants.LibraryPath, handler.ImportLibrary)
+}
+
+// SetupAdminRoutes configures the runtime inspection API under /api/admin behind the given middleware
+func SetupAdminRoutes(e *echo.Echo, basePath string, handler *handlers.Handler, adminMiddleware ...echo.MiddlewareFunc) {
+	admin := e.Group(basePath+constants.APIPrefix+constants.AdminPath, adminMiddleware...)
+	admin.GET(constants.AdminConnectionsPath, handler.ListConnections)
+	admin.DELETE(constants.AdminConnectionPath, handler.DropConnection)
+	admin.GET(constants.AdminCachePath, handler.ListCache)
+	admin.GET(constants.AdminJobsPath, handler.ListJobs)
+	admin.DELETE(constants.AdminJobPath, handler.CancelJob)
+	admin.GET(constants.AdminConfigPath, handler.GetEffectiveConfig)
+	admin.GET(constants.AdminImpersonatePath, handler.GetImpersonation)
+	admin.POST(constants.AdminImpersonatePath, handler.StartImpersonation)
+	admin.DELETE(constants.AdminImpersonatePath, handler.StopImpersonation)
+}
+
+// SetupConnectRoutes configures the Connect protocol endpoint under /api/connect.
+// callMiddleware is applied as to the other routes that call gRPC methods.
+func SetupConnectRoutes(e *echo.Echo, basePath string, handler *handlers.Handler, callMiddleware ...echo.MiddlewareFunc) {
+	e.POST(basePath+constants.APIPrefix+constants.ConnectPath, handler.CallConnect, callMiddleware...)
 }
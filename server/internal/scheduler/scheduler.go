@@ -0,0 +1,308 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"spacectl-web/server/internal/config"
+	"spacectl-web/server/internal/grpc"
+	"spacectl-web/server/internal/storage"
+)
+
+// checkInterval is how often the scheduler looks for schedules that are due
+const checkInterval = time.Minute
+
+// runTimeout bounds a single run of a schedule
+const runTimeout = 5 * time.Minute
+
+// webhookTimeout bounds the delivery of a change report
+const webhookTimeout = 10 * time.Second
+
+// Scheduler runs the enabled schedules at their intervals and reports how list results
+// changed since the previous run
+type Scheduler struct {
+	store    storage.Store
+	manager  *grpc.ClientManager
+	validate func(serviceName, resourceName, verb string) error
+	settings config.SchedulerConfig
+	client   *http.Client
+}
+
+// New creates a scheduler for the schedules in the store. Each run's call is checked with
+// validate first, so schedules saved before read-only mode or restored from a backup cannot
+// make calls the API would reject.
+func New(store storage.Store, manager *grpc.ClientManager, validate func(serviceName, resourceName, verb string) error,
+	settings config.SchedulerConfig) *Scheduler {
+	return &Scheduler{
+		store:    store,
+		manager:  manager,
+		validate: validate,
+		settings: settings.WithDefaults(),
+		client:   &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+// Run runs the due schedules immediately and then every minute until ctx is canceled
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		s.runDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runDue runs every enabled schedule whose interval has passed since its snapshot was taken,
+// and deletes the snapshots of deleted schedules
+func (s *Scheduler) runDue(ctx context.Context) {
+	schedules, err := s.store.List(ctx, storage.KindSchedule, "")
+	if err != nil {
+		log.Printf("WARNING: failed to list schedules: %v", err)
+		return
+	}
+	snapshots, err := s.store.List(ctx, storage.KindSnapshot, "")
+	if err != nil {
+		log.Printf("WARNING: failed to list schedule snapshots: %v", err)
+		return
+	}
+	previous := make(map[string]*storage.Record, len(snapshots))
+	for _, snapshot := range snapshots {
+		previous[snapshot.ID] = snapshot
+	}
+
+	now := time.Now()
+	for _, record := range schedules {
+		snapshot := previous[record.ID]
+		delete(previous, record.ID)
+
+		var schedule storage.Schedule
+		if err := json.Unmarshal(record.Data, &schedule); err != nil || !schedule.Enabled || schedule.IntervalMinutes <= 0 {
+			continue
+		}
+		// Allow for the time runs take, so that a schedule is not pushed back by a whole check
+		due := snapshot == nil || !now.Before(snapshot.UpdatedAt.Add(time.Duration(schedule.IntervalMinutes)*time.Minute-checkInterval/2))
+		if !due {
+			continue
+		}
+		s.run(ctx, record, &schedule, snapshot)
+	}
+
+	for id := range previous {
+		if err := s.store.Delete(ctx, storage.KindSnapshot, id); err != nil {
+			log.Printf("WARNING: failed to delete snapshot of deleted schedule %s: %v", id, err)
+		}
+	}
+}
+
+// run calls a schedule's request, saves its results as the new snapshot, and reports the
+// changes since the previous snapshot
+func (s *Scheduler) run(ctx context.Context, record *storage.Record, schedule *storage.Schedule, previous *storage.Record) {
+	current := &storage.Snapshot{}
+	items, err := s.call(ctx, schedule)
+	if err != nil {
+		log.Printf("WARNING: schedule '%s' failed: %v", schedule.Name, err)
+		current.Error = err.Error()
+	} else {
+		current.Items = items
+	}
+
+	var last storage.Snapshot
+	if previous != nil {
+		_ = json.Unmarshal(previous.Data, &last)
+	}
+
+	data, err := json.Marshal(current)
+	if err != nil {
+		log.Printf("WARNING: failed to save snapshot of schedule '%s': %v", schedule.Name, err)
+		return
+	}
+	now := time.Now()
+	snapshot := &storage.Record{Kind: storage.KindSnapshot, ID: record.ID, Owner: record.Owner, Data: data, CreatedAt: now, UpdatedAt: now}
+	if previous != nil {
+		snapshot.CreatedAt = previous.CreatedAt
+	}
+	if err := s.store.Put(ctx, snapshot); err != nil {
+		log.Printf("WARNING: failed to save snapshot of schedule '%s': %v", schedule.Name, err)
+		return
+	}
+
+	// The first successful run is the baseline, and a failed run has nothing to compare
+	if previous == nil || last.Items == nil || current.Items == nil {
+		return
+	}
+	report := Diff(last.Items, current.Items)
+	if len(report.Added) == 0 && len(report.Removed) == 0 && len(report.Modified) == 0 {
+		return
+	}
+	report.ScheduleID = record.ID
+	report.ScheduleName = schedule.Name
+	report.Request = schedule.Request
+	report.PreviousRun = previous.UpdatedAt
+	s.save(ctx, record.Owner, report)
+	s.notify(ctx, report)
+}
+
+// call runs the schedule's request and returns its results by key, or an empty set when the
+// response has no results
+func (s *Scheduler) call(ctx context.Context, schedule *storage.Schedule) (map[string]json.RawMessage, error) {
+	ctx, cancel := context.WithTimeout(ctx, runTimeout)
+	defer cancel()
+
+	request := schedule.Request
+	if err := s.validate(request.Service, request.Resource, request.Verb); err != nil {
+		return nil, err
+	}
+	caller, err := s.manager.GetServiceCaller(request.Service)
+	if err != nil {
+		return nil, err
+	}
+	parameters := request.Parameters
+	if parameters == nil {
+		parameters = make(map[string]interface{})
+	}
+	jsonBytes, err := caller.CallMethod(ctx, request.Service, request.Resource, request.Verb, parameters)
+	if err != nil {
+		return nil, err
+	}
+	return KeyedResults(jsonBytes, request.Resource, schedule.KeyField)
+}
+
+// KeyedResults returns the results of a list response by the value of their key field, or an
+// empty set when the response has no results. The key field defaults to the resource's ID
+// field, e.g. server_id, and results without it are left out.
+func KeyedResults(jsonBytes []byte, resource, keyField string) (map[string]json.RawMessage, error) {
+	var response struct {
+		Results []map[string]json.RawMessage `json:"results"`
+	}
+	if err := json.Unmarshal(jsonBytes, &response); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	if keyField == "" {
+		keyField = defaultKeyField(resource)
+	}
+	items := make(map[string]json.RawMessage, len(response.Results))
+	for _, result := range response.Results {
+		raw, found := result[keyField]
+		if !found {
+			raw, found = result[camelCase(keyField)]
+		}
+		if !found {
+			continue // Results without a key cannot be compared
+		}
+		var key interface{}
+		if err := json.Unmarshal(raw, &key); err != nil {
+			continue
+		}
+		item, err := json.Marshal(result) // Sorted keys, so equal results compare equal
+		if err != nil {
+			continue
+		}
+		items[fmt.Sprint(key)] = item
+	}
+	return items, nil
+}
+
+// Diff compares the results of two runs by key
+func Diff(previous, current map[string]json.RawMessage) *storage.ChangeReport {
+	report := &storage.ChangeReport{Added: []string{}, Removed: []string{}, Modified: []string{}}
+	for key, item := range current {
+		before, found := previous[key]
+		switch {
+		case !found:
+			report.Added = append(report.Added, key)
+		case !bytes.Equal(before, item):
+			report.Modified = append(report.Modified, key)
+		}
+	}
+	for key := range previous {
+		if _, found := current[key]; !found {
+			report.Removed = append(report.Removed, key)
+		}
+	}
+	slices.Sort(report.Added)
+	slices.Sort(report.Removed)
+	slices.Sort(report.Modified)
+	return report
+}
+
+// save stores a change report for the schedule's owner and deletes reports over the limit
+func (s *Scheduler) save(ctx context.Context, owner string, report *storage.ChangeReport) {
+	data, err := json.Marshal(report)
+	if err != nil {
+		log.Printf("WARNING: failed to save change report: %v", err)
+		return
+	}
+	now := time.Now()
+	record := &storage.Record{Kind: storage.KindChangeReport, ID: storage.NewID(), Owner: owner, Data: data, CreatedAt: now, UpdatedAt: now}
+	if err := s.store.Put(ctx, record); err != nil {
+		log.Printf("WARNING: failed to save change report: %v", err)
+		return
+	}
+	if err := s.store.Trim(ctx, storage.KindChangeReport, owner, s.settings.ReportLimit); err != nil {
+		log.Printf("WARNING: failed to trim change reports: %v", err)
+	}
+}
+
+// notify posts a change report to the webhook, if one is configured
+func (s *Scheduler) notify(ctx context.Context, report *storage.ChangeReport) {
+	if s.settings.WebhookURL == "" {
+		return
+	}
+	body, err := json.Marshal(report)
+	if err != nil {
+		return
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.settings.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		log.Printf("WARNING: failed to send change report of schedule '%s': %v", report.ScheduleName, err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		log.Printf("WARNING: failed to send change report of schedule '%s': %v", report.ScheduleName, err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		log.Printf("WARNING: change report webhook for schedule '%s' returned %s", report.ScheduleName, resp.Status)
+	}
+}
+
+// defaultKeyField returns the ID field of a resource, e.g. service_account_id for ServiceAccount
+func defaultKeyField(resource string) string {
+	var name strings.Builder
+	for i, r := range resource {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				name.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		name.WriteRune(r)
+	}
+	return name.String() + "_id"
+}
+
+// camelCase converts a snake_case field name to its JSON name, e.g. server_id to serverId
+func camelCase(name string) string {
+	parts := strings.Split(name, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,98 @@
+//go:build darwin
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"text/template"
+)
+
+// plistTemplate is the launchd agent definition; values are XML-escaped by the escape function
+var plistTemplate = template.Must(template.New("plist").Funcs(template.FuncMap{"escape": escapeXML}).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>{{escape .Name}}</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>{{escape .Executable}}</string>
+{{- range .Args}}
+		<string>{{escape .}}</string>
+{{- end}}
+	</array>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<true/>
+	<key>StandardOutPath</key>
+	<string>{{escape .LogDir}}/{{escape .Name}}.out</string>
+	<key>StandardErrorPath</key>
+	<string>{{escape .LogDir}}/{{escape .Name}}.out</string>
+</dict>
+</plist>
+`))
+
+// Install writes a launchd agent for the current user and loads it
+func Install(opts Options) (string, error) {
+	path, err := plistPath(opts.Name)
+	if err != nil {
+		return "", err
+	}
+	var plist bytes.Buffer
+	if err := plistTemplate.Execute(&plist, opts); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, plist.Bytes(), 0o644); err != nil {
+		return "", err
+	}
+
+	_ = exec.Command("launchctl", "unload", path).Run() // Replace an agent that is already loaded
+	if output, err := exec.Command("launchctl", "load", "-w", path).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("launchctl load failed: %v: %s", err, bytes.TrimSpace(output))
+	}
+	return path, nil
+}
+
+// Uninstall unloads the launchd agent and removes its definition
+func Uninstall(name string) error {
+	path, err := plistPath(name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("launchd agent '%s' is not installed", name)
+	}
+	_ = exec.Command("launchctl", "unload", "-w", path).Run()
+	return os.Remove(path)
+}
+
+// NotifyContext returns a context canceled when the server is asked to stop
+func NotifyContext(parent context.Context) (context.Context, context.CancelFunc) {
+	return notifySignals(parent)
+}
+
+// plistPath returns the path of the user's launchd agent definition
+func plistPath(name string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", name+".plist"), nil
+}
+
+// escapeXML escapes a value for use in the plist
+func escapeXML(value string) string {
+	var escaped bytes.Buffer
+	_ = xml.EscapeText(&escaped, []byte(value))
+	return escaped.String()
+}
@@ -0,0 +1,22 @@
+package service
+
+import (
+	"errors"
+)
+
+// DefaultName is the name the server is registered under unless another one is chosen
+const DefaultName = "spacectl-web"
+
+// description is shown by the operating system's service manager
+const description = "SpaceONE gRPC API web console"
+
+// ErrUnsupported is returned on platforms without service integration
+var ErrUnsupported = errors.New("service installation is supported on Windows and macOS only")
+
+// Options describes how the server is registered with the operating system
+type Options struct {
+	Name       string   // Service name, or launchd label on macOS
+	Executable string   // Absolute path of the spacectl-web binary
+	Args       []string // Arguments of the serve command
+	LogDir     string   // Directory for output the service manager captures
+}
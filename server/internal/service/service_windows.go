@@ -0,0 +1,122 @@
+//go:build windows
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// stopWait is how long Uninstall waits for the running service to stop
+const stopWait = 10 * time.Second
+
+// Install registers an automatically started Windows service and starts it
+func Install(opts Options) (string, error) {
+	m, err := mgr.Connect()
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to the service manager (run as administrator): %w", err)
+	}
+	defer m.Disconnect()
+
+	if s, err := m.OpenService(opts.Name); err == nil {
+		s.Close()
+		return "", fmt.Errorf("service '%s' is already installed: uninstall it first", opts.Name)
+	}
+
+	s, err := m.CreateService(opts.Name, opts.Executable, mgr.Config{
+		DisplayName: opts.Name,
+		Description: description,
+		StartType:   mgr.StartAutomatic,
+	}, opts.Args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to create service '%s': %w", opts.Name, err)
+	}
+	defer s.Close()
+
+	if err := s.Start(); err != nil {
+		return "", fmt.Errorf("service '%s' was installed but failed to start: %w", opts.Name, err)
+	}
+	return opts.Name, nil
+}
+
+// Uninstall stops and removes the Windows service
+func Uninstall(name string) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to the service manager (run as administrator): %w", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(name)
+	if err != nil {
+		return fmt.Errorf("service '%s' is not installed", name)
+	}
+	defer s.Close()
+
+	if status, err := s.Control(svc.Stop); err == nil {
+		deadline := time.Now().Add(stopWait)
+		for status.State != svc.Stopped && time.Now().Before(deadline) {
+			time.Sleep(300 * time.Millisecond)
+			if status, err = s.Query(); err != nil {
+				break
+			}
+		}
+	}
+	return s.Delete()
+}
+
+// NotifyContext returns a context canceled when the server is asked to stop, either by the
+// service manager when running as a Windows service or by Ctrl+C otherwise. The returned
+// function must be called once the server has shut down so the service reports it stopped.
+func NotifyContext(parent context.Context) (context.Context, context.CancelFunc) {
+	isService, err := svc.IsWindowsService()
+	if err != nil || !isService {
+		return signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+	}
+
+	ctx, cancel := context.WithCancel(parent)
+	h := &handler{cancel: cancel, done: make(chan struct{})}
+	go func() {
+		_ = svc.Run(DefaultName, h) // The name is ignored for services running in their own process
+		cancel()
+	}()
+
+	var once sync.Once
+	return ctx, func() {
+		cancel()
+		once.Do(func() { close(h.done) })
+	}
+}
+
+// handler reports the server's state to the service manager
+type handler struct {
+	cancel context.CancelFunc
+	done   chan struct{} // Closed once the server has shut down
+}
+
+// Execute runs until the service manager asks the service to stop and the server has shut down
+func (h *handler) Execute(args []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+	for {
+		select {
+		case request := <-requests:
+			switch request.Cmd {
+			case svc.Interrogate:
+				status <- request.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending}
+				h.cancel()
+			}
+		case <-h.done:
+			return false, 0
+		}
+	}
+}
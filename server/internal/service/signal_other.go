@@ -0,0 +1,15 @@
+//go:build !windows
+
+package service
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifySignals returns a context canceled on SIGINT or SIGTERM
+func notifySignals(parent context.Context) (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+}
@@ -0,0 +1,22 @@
+//go:build !darwin && !windows
+
+package service
+
+import (
+	"context"
+)
+
+// Install is not supported on this platform; use the system's init system instead
+func Install(opts Options) (string, error) {
+	return "", ErrUnsupported
+}
+
+// Uninstall is not supported on this platform
+func Uninstall(name string) error {
+	return ErrUnsupported
+}
+
+// NotifyContext returns a context canceled when the server is asked to stop
+func NotifyContext(parent context.Context) (context.Context, context.CancelFunc) {
+	return notifySignals(parent)
+}
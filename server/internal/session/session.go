@@ -0,0 +1,129 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// CookieName is the cookie that identifies a browser session
+const CookieName = "spacectl_web_session"
+
+// ScopedToken is a token narrowed to a domain or workspace, used instead of the configured
+// token for the calls of one session
+type ScopedToken struct {
+	Token       string     `json:"-"`
+	Scope       string     `json:"scope"`
+	DomainID    string     `json:"domain_id"`
+	WorkspaceID string     `json:"workspace_id,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// Expired reports whether the token has expired
+func (t *ScopedToken) Expired() bool {
+	return t.ExpiresAt != nil && !time.Now().Before(*t.ExpiresAt)
+}
+
+// Impersonation makes a session's calls on behalf of a user or workspace
+type Impersonation struct {
+	UserID       string    `json:"user_id,omitempty"`
+	WorkspaceID  string    `json:"workspace_id,omitempty"`
+	Reason       string    `json:"reason"`
+	Operator     string    `json:"operator"`      // Direct peer address the impersonation was started from
+	OperatorUser string    `json:"operator_user"` // User that started it, as authenticated by the reverse proxy
+	StartedAt    time.Time `json:"started_at"`
+}
+
+// Store keeps the scoped token and impersonation of each session in memory
+type Store struct {
+	tokens         map[string]*ScopedToken   // By session ID
+	impersonations map[string]*Impersonation // By session ID
+	mutex          sync.RWMutex
+}
+
+// NewStore creates an empty store
+func NewStore() *Store {
+	return &Store{
+		tokens:         make(map[string]*ScopedToken),
+		impersonations: make(map[string]*Impersonation),
+	}
+}
+
+// Exists reports whether the session has a scoped token or an impersonation
+func (s *Store) Exists(id string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.tokens[id] != nil || s.impersonations[id] != nil
+}
+
+// NewID returns a random session ID
+func NewID() string {
+	id := make([]byte, 24)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// Get returns the scoped token of a session, dropping it once expired
+func (s *Store) Get(id string) (*ScopedToken, bool) {
+	s.mutex.RLock()
+	token, exists := s.tokens[id]
+	s.mutex.RUnlock()
+	if !exists {
+		return nil, false
+	}
+	if token.Expired() {
+		s.Delete(id)
+		return nil, false
+	}
+	return token, true
+}
+
+// Set stores the scoped token of a session
+func (s *Store) Set(id string, token *ScopedToken) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.tokens[id] = token
+}
+
+// Delete drops the scoped token of a session and reports whether it had one
+func (s *Store) Delete(id string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, exists := s.tokens[id]
+	delete(s.tokens, id)
+	return exists
+}
+
+// Impersonation returns the impersonation of a session
+func (s *Store) Impersonation(id string) (*Impersonation, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	impersonation, exists := s.impersonations[id]
+	return impersonation, exists
+}
+
+// SetImpersonation stores the impersonation of a session
+func (s *Store) SetImpersonation(id string, impersonation *Impersonation) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.impersonations[id] = impersonation
+}
+
+// DeleteImpersonation ends the impersonation of a session and returns it
+func (s *Store) DeleteImpersonation(id string) (*Impersonation, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	impersonation, exists := s.impersonations[id]
+	delete(s.impersonations, id)
+	return impersonation, exists
+}
+
+// Reset drops every scoped token and impersonation, e.g. after a profile switch made them meaningless
+func (s *Store) Reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.tokens = make(map[string]*ScopedToken)
+	s.impersonations = make(map[string]*Impersonation)
+}
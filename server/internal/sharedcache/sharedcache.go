@@ -0,0 +1,134 @@
+package sharedcache
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"spacectl-web/server/internal/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// connectTimeout bounds the connectivity check at startup
+const connectTimeout = 5 * time.Second
+
+// Cache is a Redis-backed key-value store shared by all replicas. Keys are namespaced with
+// the configured prefix.
+type Cache struct {
+	client *redis.Client
+	prefix string
+}
+
+// New connects to the shared cache and checks that it is reachable
+func New(cfg config.SharedCacheConfig) (*Cache, error) {
+	cfg = cfg.WithDefaults()
+	options, err := redis.ParseURL(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid shared cache URL: %w", err)
+	}
+	client := redis.NewClient(options)
+
+	ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to connect to shared cache at %s: %w", options.Addr, err)
+	}
+	return &Cache{client: client, prefix: cfg.KeyPrefix}, nil
+}
+
+// Get returns the value of a key; a missing key is not an error
+func (c *Cache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if stderrors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Set stores a value that expires after ttl
+func (c *Cache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.client.Set(ctx, c.prefix+key, value, ttl).Err()
+}
+
+// Delete removes keys
+func (c *Cache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.prefix + key
+	}
+	return c.client.Del(ctx, prefixed...).Err()
+}
+
+// Add increments counters that expire together after window, starting the window when the
+// first counter is created. It returns the new values and the time left in the window.
+func (c *Cache) Add(ctx context.Context, window time.Duration, deltas map[string]int64) (map[string]int64, time.Duration, error) {
+	keys := make([]string, 0, len(deltas))
+	for key := range deltas {
+		keys = append(keys, key)
+	}
+
+	pipe := c.client.TxPipeline()
+	increments := make(map[string]*redis.IntCmd, len(keys))
+	for _, key := range keys {
+		increments[key] = pipe.IncrBy(ctx, c.prefix+key, deltas[key])
+		pipe.ExpireNX(ctx, c.prefix+key, window)
+	}
+	var ttl *redis.DurationCmd
+	if len(keys) > 0 {
+		ttl = pipe.PTTL(ctx, c.prefix+keys[0])
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, 0, err
+	}
+
+	values := make(map[string]int64, len(keys))
+	for key, cmd := range increments {
+		values[key] = cmd.Val()
+	}
+	var remaining time.Duration
+	if ttl != nil {
+		remaining = ttl.Val()
+	}
+	return values, remaining, nil
+}
+
+// Counters returns the current values of counters and the time left in the window of the
+// first one; missing counters are zero
+func (c *Cache) Counters(ctx context.Context, keys ...string) ([]int64, time.Duration, error) {
+	if len(keys) == 0 {
+		return nil, 0, nil
+	}
+	pipe := c.client.Pipeline()
+	gets := make([]*redis.StringCmd, len(keys))
+	for i, key := range keys {
+		gets[i] = pipe.Get(ctx, c.prefix+key)
+	}
+	ttl := pipe.PTTL(ctx, c.prefix+keys[0])
+	if _, err := pipe.Exec(ctx); err != nil && !stderrors.Is(err, redis.Nil) {
+		return nil, 0, err
+	}
+
+	values := make([]int64, len(keys))
+	for i, get := range gets {
+		values[i], _ = get.Int64() // Missing counters are zero
+	}
+	remaining := ttl.Val()
+	if remaining < 0 {
+		remaining = 0 // The window has not started or has no expiry
+	}
+	return values, remaining, nil
+}
+
+// Close closes the connection to the shared cache
+func (c *Cache) Close() error {
+	return c.client.Close()
+}
@@ -0,0 +1,82 @@
+package siem
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"spacectl-web/server/internal/config"
+	"spacectl-web/server/internal/constants"
+)
+
+// product names the server in CEF headers and JSON events
+const product = "spacectl-web"
+
+// cefKeys maps event fields to CEF extension keys; other fields keep their names
+var cefKeys = map[string]string{
+	"user":       "suser",
+	"operator":   "src",
+	"method":     "requestMethod",
+	"path":       "request",
+	"request_id": "externalId",
+}
+
+// formatEvent renders an event as a JSON object or a CEF line
+func formatEvent(event Event, format string) string {
+	if format == config.SIEMFormatCEF {
+		return cefEvent(event)
+	}
+	return string(jsonEvent(event))
+}
+
+// jsonEvent renders an event as a JSON object with its fields next to time, source, kind and action
+func jsonEvent(event Event) json.RawMessage {
+	object := make(map[string]string, len(event.Fields)+4)
+	for key, value := range event.Fields {
+		object[key] = value
+	}
+	object["time"] = event.Time.UTC().Format(time.RFC3339Nano)
+	object["source"] = product
+	object["kind"] = event.Kind
+	object["action"] = event.Action
+	data, _ := json.Marshal(object) // Sorted keys
+	return data
+}
+
+// cefEvent renders an event as a CEF line. History calls that failed and audit lines have a
+// higher severity than successful calls.
+func cefEvent(event Event) string {
+	severity := 3
+	if event.Kind == KindAudit {
+		severity = 5
+	} else if event.Fields["success"] == "false" {
+		severity = 6
+	}
+	signature := event.Kind + ":" + strings.ReplaceAll(event.Action, " ", "_")
+
+	extensions := []string{"rt=" + strconv.FormatInt(event.Time.UnixMilli(), 10)}
+	keys := make([]string, 0, len(event.Fields))
+	for key := range event.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		name := key
+		if cefKey, ok := cefKeys[key]; ok {
+			name = cefKey
+		}
+		extensions = append(extensions, name+"="+cefExtensionEscaper.Replace(event.Fields[key]))
+	}
+	return fmt.Sprintf("CEF:0|%s|%s|%s|%s|%s|%d|%s", product, product,
+		cefHeaderEscaper.Replace(constants.Version), cefHeaderEscaper.Replace(signature),
+		cefHeaderEscaper.Replace(event.Action), severity, strings.Join(extensions, " "))
+}
+
+// CEF escaping of header fields and extension values
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", " ", "\r", " ")
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`)
+)
@@ -0,0 +1,161 @@
+package siem
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"spacectl-web/server/internal/config"
+)
+
+// syslogFacility is local0, whose messages security tooling commonly collects
+const syslogFacility = 16
+
+// Syslog severities
+const (
+	syslogNotice = 5
+	syslogInfo   = 6
+)
+
+// syslogSender writes RFC 5424 messages to a syslog server, reconnecting after failures
+type syslogSender struct {
+	network  string // udp or tcp
+	address  string
+	format   string
+	hostname string
+	conn     net.Conn
+	mutex    sync.Mutex
+}
+
+// newSyslogSender creates a sender for a udp:// or tcp:// syslog address
+func newSyslogSender(cfg config.SIEMExporterConfig) (*syslogSender, error) {
+	address, err := url.Parse(cfg.Address)
+	if err != nil {
+		return nil, err
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	return &syslogSender{network: address.Scheme, address: address.Host, format: cfg.Format, hostname: hostname}, nil
+}
+
+// send writes one message per event; TCP messages are newline-terminated
+func (s *syslogSender) send(ctx context.Context, events []Event) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.conn == nil {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, s.network, s.address)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = s.conn.SetWriteDeadline(deadline)
+	}
+	for _, event := range events {
+		severity := syslogInfo
+		if event.Kind == KindAudit {
+			severity = syslogNotice
+		}
+		message := fmt.Sprintf("<%d>1 %s %s %s %d %s - %s", syslogFacility*8+severity,
+			event.Time.UTC().Format("2006-01-02T15:04:05.000Z"), s.hostname, product, os.Getpid(), event.Kind,
+			formatEvent(event, s.format))
+		if s.network == "tcp" {
+			message += "\n"
+		}
+		if _, err := s.conn.Write([]byte(message)); err != nil {
+			s.conn.Close()
+			s.conn = nil
+			return err
+		}
+	}
+	return nil
+}
+
+// close closes the connection
+func (s *syslogSender) close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+}
+
+// httpSender POSTs batches of events: a JSON array, or one CEF line per event
+type httpSender struct {
+	url     string
+	format  string
+	headers map[string]string
+	client  *http.Client
+}
+
+// newHTTPSender creates a sender for a collector URL, resolving secret references in its headers
+func newHTTPSender(cfg config.SIEMExporterConfig) (*httpSender, error) {
+	headers := make(map[string]string, len(cfg.Headers))
+	for key, value := range cfg.Headers {
+		if config.IsSecretRef(value) {
+			secret, err := config.ResolveSecret(value)
+			if err != nil {
+				return nil, fmt.Errorf("headers.%s: %w", key, err)
+			}
+			value = secret
+		}
+		headers[key] = value
+	}
+	return &httpSender{url: cfg.URL, format: cfg.Format, headers: headers, client: &http.Client{}}, nil
+}
+
+// send POSTs the events in one request
+func (s *httpSender) send(ctx context.Context, events []Event) error {
+	var body []byte
+	contentType := "application/json"
+	if s.format == config.SIEMFormatCEF {
+		lines := make([]string, 0, len(events))
+		for _, event := range events {
+			lines = append(lines, cefEvent(event))
+		}
+		body = []byte(strings.Join(lines, "\n") + "\n")
+		contentType = "text/plain"
+	} else {
+		objects := make([]json.RawMessage, 0, len(events))
+		for _, event := range events {
+			objects = append(objects, jsonEvent(event))
+		}
+		body, _ = json.Marshal(objects)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	for key, value := range s.headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %s", s.url, resp.Status)
+	}
+	return nil
+}
+
+// close releases idle connections
+func (s *httpSender) close() {
+	s.client.CloseIdleConnections()
+}
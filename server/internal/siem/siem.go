@@ -0,0 +1,212 @@
+package siem
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"spacectl-web/server/internal/config"
+)
+
+// Event kinds
+const (
+	KindAudit   = "audit"   // Lines of the impersonation audit log
+	KindHistory = "history" // Calls added to the history
+)
+
+const (
+	queueSize     = 1000        // Events waiting to be sent; further events are dropped
+	batchSize     = 100         // Events sent together at most
+	flushInterval = time.Second // Wait for more events before sending a partial batch
+	sendTimeout   = 10 * time.Second
+)
+
+// auditTimeLayout is the date and time the audit logger writes after its prefix
+const auditTimeLayout = "2006/01/02 15:04:05"
+
+// Event is an activity of the server that security tooling may ingest
+type Event struct {
+	Time   time.Time
+	Kind   string
+	Action string            // What happened, e.g. impersonation started or call
+	Fields map[string]string // Details such as user, service or status
+}
+
+// sender delivers batches of events to one destination
+type sender interface {
+	send(ctx context.Context, events []Event) error
+	close()
+}
+
+// Exporter ships events to the configured destinations in the background. Events are queued
+// and dropped when the queue is full, so a slow destination never delays requests.
+type Exporter struct {
+	senders []sender
+	history bool
+	events  chan Event
+	dropped atomic.Int64
+	done    chan struct{}
+	once    sync.Once
+}
+
+// New creates an exporter for the configured destinations and starts sending. Secret
+// references in HTTP headers are resolved once here.
+func New(cfg config.SIEMConfig) (*Exporter, error) {
+	e := &Exporter{history: cfg.History, events: make(chan Event, queueSize), done: make(chan struct{})}
+	for i, exporter := range cfg.Exporters {
+		exporter = exporter.WithDefaults()
+		var s sender
+		var err error
+		switch exporter.Type {
+		case config.SIEMSyslog:
+			s, err = newSyslogSender(exporter)
+		default:
+			s, err = newHTTPSender(exporter)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("server.siem.exporters[%d]: %w", i, err)
+		}
+		e.senders = append(e.senders, s)
+	}
+	go e.run()
+	return e, nil
+}
+
+// ExportsHistory reports whether calls added to the history are exported
+func (e *Exporter) ExportsHistory() bool {
+	return e.history
+}
+
+// Export queues an event, dropping it when the queue is full
+func (e *Exporter) Export(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	select {
+	case e.events <- event:
+	default:
+		e.dropped.Add(1)
+	}
+}
+
+// AuditWriter returns a writer for the audit logger that writes to next and exports every line.
+// Lines are read as an action followed by key=value pairs, e.g.
+// AUDIT 2006/01/02 15:04:05 impersonation started: user="u-1" operator=10.0.0.1
+func (e *Exporter) AuditWriter(next io.Writer) io.Writer {
+	return &auditWriter{exporter: e, next: next}
+}
+
+// Close sends the queued events and releases the destinations
+func (e *Exporter) Close() {
+	e.once.Do(func() {
+		close(e.events)
+		<-e.done
+		for _, s := range e.senders {
+			s.close()
+		}
+	})
+}
+
+// run sends batches of queued events until the exporter is closed
+func (e *Exporter) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	var batch []Event
+	for {
+		select {
+		case event, ok := <-e.events:
+			if !ok {
+				e.send(batch)
+				return
+			}
+			batch = append(batch, event)
+			if len(batch) < batchSize {
+				continue
+			}
+		case <-ticker.C:
+		}
+		e.send(batch)
+		batch = nil
+	}
+}
+
+// send delivers a batch to every destination, logging failures
+func (e *Exporter) send(batch []Event) {
+	if dropped := e.dropped.Swap(0); dropped > 0 {
+		log.Printf("WARNING: dropped %d SIEM events because the export queue was full", dropped)
+	}
+	if len(batch) == 0 {
+		return
+	}
+	for _, s := range e.senders {
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		if err := s.send(ctx, batch); err != nil {
+			log.Printf("WARNING: failed to export %d SIEM events: %v", len(batch), err)
+		}
+		cancel()
+	}
+}
+
+// auditWriter exports the lines written by the audit logger
+type auditWriter struct {
+	exporter *Exporter
+	next     io.Writer
+}
+
+// Write writes p to the next writer and exports each of its lines
+func (w *auditWriter) Write(p []byte) (int, error) {
+	for _, line := range bytes.Split(bytes.TrimSpace(p), []byte("\n")) {
+		if len(line) > 0 {
+			w.exporter.Export(parseAuditLine(string(line)))
+		}
+	}
+	return w.next.Write(p)
+}
+
+// parseAuditLine reads an audit log line into an event
+func parseAuditLine(line string) Event {
+	event := Event{Time: time.Now(), Kind: KindAudit, Fields: make(map[string]string)}
+	line = strings.TrimPrefix(line, "AUDIT ")
+	if len(line) >= len(auditTimeLayout) {
+		if logged, err := time.ParseInLocation(auditTimeLayout, line[:len(auditTimeLayout)], time.Local); err == nil {
+			event.Time = logged
+			line = strings.TrimSpace(line[len(auditTimeLayout):])
+		}
+	}
+	action, pairs, found := strings.Cut(line, ": ")
+	if !found {
+		event.Action = line
+		return event
+	}
+	event.Action = action
+	for pairs != "" {
+		key, rest, found := strings.Cut(pairs, "=")
+		if !found {
+			break
+		}
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				value, rest = strings.TrimPrefix(rest, `"`), "" // Unterminated quote
+			} else {
+				value, _ = strconv.Unquote(quoted)
+				rest = rest[len(quoted):]
+			}
+		} else {
+			value, rest, _ = strings.Cut(rest, " ")
+		}
+		event.Fields[strings.TrimSpace(key)] = value
+		pairs = strings.TrimSpace(rest)
+	}
+	return event
+}
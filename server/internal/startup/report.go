@@ -0,0 +1,209 @@
+package startup
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"spacectl-web/server/internal/config"
+	"spacectl-web/server/internal/constants"
+	"spacectl-web/server/internal/jwt"
+)
+
+// tokenExpiryWarning is how close to expiry a token must be to produce a warning
+const tokenExpiryWarning = 24 * time.Hour
+
+// TLS modes of an endpoint
+const (
+	TLSPlaintext  = "plaintext"
+	TLSVerified   = "tls"
+	TLSCustomCA   = "tls (custom CA)"
+	TLSSkipVerify = "tls (skip verify)"
+)
+
+// Endpoint describes a configured endpoint
+type Endpoint struct {
+	Service        string   `json:"service"`
+	URL            string   `json:"url"`
+	Protocol       string   `json:"protocol"` // grpc, or rest for console-api endpoints
+	TLS            string   `json:"tls"`
+	Auth           string   `json:"auth"` // Credential type, e.g. token or api_key
+	TimeoutSeconds int      `json:"timeout_seconds,omitempty"`
+	MetadataKeys   []string `json:"metadata_keys,omitempty"` // Extra metadata sent with every call; values are not reported
+}
+
+// Token describes the token calls are made with
+type Token struct {
+	Source    string     `json:"source"` // config, the secret reference it was resolved from, or grant
+	Subject   string     `json:"subject,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Report summarizes the configuration the server started with, so misconfigurations show up
+// at startup instead of on the first call
+type Report struct {
+	Version       string          `json:"version"`
+	StartedAt     time.Time       `json:"started_at"`
+	ConfigFile    string          `json:"config_file"`
+	Profile       string          `json:"profile"`
+	ListenAddress string          `json:"listen_address"`
+	Endpoints     []Endpoint      `json:"endpoints"`
+	Token         *Token          `json:"token,omitempty"`
+	Features      map[string]bool `json:"features"`
+	Warnings      []string        `json:"warnings,omitempty"`
+}
+
+// NewReport describes the endpoints and token of the config. Features and warnings that
+// depend on how the server is run are added by the caller.
+func NewReport(cfg *config.Config, configFile, listenAddress string) *Report {
+	report := &Report{
+		Version:       constants.Version,
+		StartedAt:     time.Now(),
+		ConfigFile:    configFile,
+		Profile:       cfg.ActiveProfile(),
+		ListenAddress: listenAddress,
+		Endpoints:     []Endpoint{},
+		Features:      make(map[string]bool),
+	}
+
+	for _, service := range cfg.ServiceNames() {
+		endpoint, exists := cfg.GetEndpoint(service)
+		if !exists {
+			continue
+		}
+		protocol := "grpc"
+		if endpoint.IsREST() {
+			protocol = "rest"
+		}
+		report.Endpoints = append(report.Endpoints, Endpoint{
+			Service:        service,
+			URL:            endpoint.URL,
+			Protocol:       protocol,
+			TLS:            tlsMode(endpoint),
+			Auth:           endpoint.AuthType(),
+			TimeoutSeconds: endpoint.Timeout,
+			MetadataKeys:   metadataKeys(endpoint),
+		})
+		if endpoint.TLS.InsecureSkipVerify && !endpoint.IsPlaintext() {
+			report.Warn("endpoint of %s does not verify the server certificate", service)
+		}
+	}
+	if len(report.Endpoints) == 0 {
+		report.Warn("no endpoints configured")
+	}
+
+	report.Token = describeToken(report, cfg)
+	return report
+}
+
+// metadataKeys returns the sorted metadata keys of an endpoint
+func metadataKeys(endpoint *config.EndpointConfig) []string {
+	keys := make([]string, 0, len(endpoint.Metadata))
+	for key := range endpoint.Metadata {
+		keys = append(keys, strings.ToLower(key))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// tlsMode describes how the connection to an endpoint is secured
+func tlsMode(endpoint *config.EndpointConfig) string {
+	switch {
+	case endpoint.IsPlaintext():
+		return TLSPlaintext
+	case endpoint.TLS.InsecureSkipVerify:
+		return TLSSkipVerify
+	case endpoint.TLS.CAFile != "":
+		return TLSCustomCA
+	}
+	return TLSVerified
+}
+
+// describeToken describes the token and warns when it is missing, unreadable or expiring
+func describeToken(report *Report, cfg *config.Config) *Token {
+	token := cfg.GetToken()
+	if token == "" {
+		report.Warn("no token configured: calls will be unauthenticated")
+		return nil
+	}
+
+	described := &Token{Source: "config"}
+	if ref := cfg.TokenRef(); ref != "" {
+		described.Source = ref
+	}
+	if cfg.TokenGranted() {
+		described.Source = "grant (" + cfg.GetGrant().WithDefaults().Scope + " scope)"
+	}
+	info, err := jwt.Parse(token)
+	if err != nil {
+		report.Warn("token is not a JWT: %v", err)
+		return described
+	}
+	if subject, ok := info.Payload["sub"].(string); ok {
+		described.Subject = subject
+	}
+	if expiresAt, ok := info.ExpiresAt(); ok {
+		described.ExpiresAt = &expiresAt
+		switch remaining := time.Until(expiresAt); {
+		case remaining <= 0:
+			report.Warn("token expired at %s", expiresAt.Format(time.RFC3339))
+		case remaining < tokenExpiryWarning && !cfg.TokenGranted(): // Granted tokens are refreshed
+			report.Warn("token expires in %s", remaining.Round(time.Minute))
+		}
+	}
+	return described
+}
+
+// Warn adds a warning to the report
+func (r *Report) Warn(format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// Write prints the report as aligned text
+func (r *Report) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Startup summary\n")
+	fmt.Fprintf(tw, "  Version\t%s\n", r.Version)
+	fmt.Fprintf(tw, "  Config file\t%s\n", r.ConfigFile)
+	fmt.Fprintf(tw, "  Profile\t%s\n", r.Profile)
+	fmt.Fprintf(tw, "  Listen address\t%s\n", r.ListenAddress)
+
+	fmt.Fprintf(tw, "  Endpoints\t%d\n", len(r.Endpoints))
+	for _, endpoint := range r.Endpoints {
+		fmt.Fprintf(tw, "    %s\t%s\t%s, %s, %s auth\n", endpoint.Service, endpoint.URL, endpoint.Protocol, endpoint.TLS, endpoint.Auth)
+	}
+
+	switch {
+	case r.Token == nil:
+		fmt.Fprintf(tw, "  Token\tnone\n")
+	default:
+		token := "from " + r.Token.Source
+		if r.Token.Subject != "" {
+			token += ", subject " + r.Token.Subject
+		}
+		if r.Token.ExpiresAt != nil {
+			token += ", expires " + r.Token.ExpiresAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "  Token\t%s\n", token)
+	}
+
+	var enabled []string
+	for feature, on := range r.Features {
+		if on {
+			enabled = append(enabled, feature)
+		}
+	}
+	sort.Strings(enabled)
+	if len(enabled) == 0 {
+		enabled = []string{"none"}
+	}
+	fmt.Fprintf(tw, "  Features\t%s\n", strings.Join(enabled, ", "))
+
+	for _, warning := range r.Warnings {
+		fmt.Fprintf(tw, "  WARNING\t%s\n", warning)
+	}
+	return tw.Flush()
+}
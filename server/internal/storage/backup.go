@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// BackupVersion is the format version written to backup manifests
+const BackupVersion = 1
+
+// manifestFile is the name of the manifest inside a backup archive
+const manifestFile = "manifest.json"
+
+// Kinds lists every kind of saved data, in the order backups are written. Snapshots are left
+// out, since the next scheduled run takes a new one, and so are workflow runs, which cannot
+// resume after a restore.
+var Kinds = []Kind{KindHistory, KindFavorite, KindCollection, KindSchedule, KindView, KindChangeReport, KindWorkflow}
+
+// Manifest describes a backup archive
+type Manifest struct {
+	Version   int          `json:"version"`
+	CreatedAt time.Time    `json:"created_at"`
+	Owner     string       `json:"owner,omitempty"` // Empty when the backup holds every user's data
+	Counts    map[Kind]int `json:"counts"`
+}
+
+// Backup writes the records owned by owner, or every record when owner is empty, to w as a
+// tar.gz archive holding a manifest and one JSON file per kind
+func Backup(ctx context.Context, store Store, w io.Writer, owner string) (*Manifest, error) {
+	manifest := &Manifest{Version: BackupVersion, CreatedAt: time.Now().UTC(), Owner: owner, Counts: make(map[Kind]int)}
+	files := make(map[Kind][]byte, len(Kinds))
+	for _, kind := range Kinds {
+		records, err := store.List(ctx, kind, owner)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", kind, err)
+		}
+		if records == nil {
+			records = []*Record{}
+		}
+		data, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		files[kind] = data
+		manifest.Counts[kind] = len(records)
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	gz := gzip.NewWriter(w)
+	archive := tar.NewWriter(gz)
+	if err := writeTarFile(archive, manifestFile, manifestData, manifest.CreatedAt); err != nil {
+		return nil, err
+	}
+	for _, kind := range Kinds {
+		if err := writeTarFile(archive, string(kind)+".json", files[kind], manifest.CreatedAt); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, gz.Close()
+}
+
+// writeTarFile adds a regular file to the archive
+func writeTarFile(archive *tar.Writer, name string, data []byte, modTime time.Time) error {
+	header := &tar.Header{Name: name, Mode: 0o600, Size: int64(len(data)), ModTime: modTime, Typeflag: tar.TypeReg}
+	if err := archive.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := archive.Write(data)
+	return err
+}
+
+// Restore reads a backup archive from r and saves its records, replacing records with the
+// same ID. When owner is not empty every record is restored as owned by owner, and records
+// whose ID belongs to another user get a new ID. Files of the archive larger than maxFileBytes
+// once decompressed are rejected, so a small archive cannot exhaust memory. It returns the
+// number of records restored by kind.
+func Restore(ctx context.Context, store Store, r io.Reader, owner string, maxFileBytes int64) (map[Kind]int, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a tar.gz backup: %w", err)
+	}
+	defer gz.Close()
+
+	known := make(map[Kind]bool, len(Kinds))
+	for _, kind := range Kinds {
+		known[kind] = true
+	}
+
+	var manifest *Manifest
+	records := make(map[Kind][]*Record)
+	archive := tar.NewReader(gz)
+	for {
+		header, err := archive.Next()
+		if stderrors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid backup archive: %w", err)
+		}
+		name := path.Clean(header.Name)
+		if header.Size > maxFileBytes {
+			return nil, fmt.Errorf("invalid backup archive: %s is larger than %d bytes", name, maxFileBytes)
+		}
+		entry := io.LimitReader(archive, maxFileBytes)
+		switch {
+		case name == manifestFile:
+			manifest = &Manifest{}
+			if err := json.NewDecoder(entry).Decode(manifest); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", manifestFile, err)
+			}
+		case known[Kind(strings.TrimSuffix(name, ".json"))]:
+			kind := Kind(strings.TrimSuffix(name, ".json"))
+			var kindRecords []*Record
+			if err := json.NewDecoder(entry).Decode(&kindRecords); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", name, err)
+			}
+			records[kind] = kindRecords
+		}
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("invalid backup archive: %s is missing", manifestFile)
+	}
+	if manifest.Version > BackupVersion {
+		return nil, fmt.Errorf("backup format version %d is newer than this server supports (%d)", manifest.Version, BackupVersion)
+	}
+
+	counts := make(map[Kind]int, len(Kinds))
+	for _, kind := range Kinds {
+		for _, record := range records[kind] {
+			if record == nil || record.ID == "" {
+				continue
+			}
+			record.Kind = kind
+			if owner != "" {
+				existing, err := store.Get(ctx, kind, record.ID)
+				if err == nil && existing.Owner != owner {
+					record.ID = NewID()
+				}
+				record.Owner = owner
+			}
+			if err := store.Put(ctx, record); err != nil {
+				return counts, fmt.Errorf("failed to restore %s '%s': %w", kind, record.ID, err)
+			}
+			counts[kind]++
+		}
+	}
+	return counts, nil
+}
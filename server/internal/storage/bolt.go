@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Bolt keeps records in a BoltDB file, one bucket per kind
+type Bolt struct {
+	db *bolt.DB
+}
+
+// NewBolt opens or creates the BoltDB file at path
+func NewBolt(path string) (*Bolt, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	return &Bolt{db: db}, nil
+}
+
+// Put inserts or replaces a record
+func (b *Bolt) Put(ctx context.Context, record *Record) error {
+	value, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(record.Kind))
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(record.ID), value)
+	})
+}
+
+// Get returns a record
+func (b *Bolt) Get(ctx context.Context, kind Kind, id string) (*Record, error) {
+	var record *Record
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(kind))
+		if bucket == nil {
+			return ErrNotFound
+		}
+		value := bucket.Get([]byte(id))
+		if value == nil {
+			return ErrNotFound
+		}
+		record = &Record{}
+		return json.Unmarshal(value, record)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// List returns the records of a kind owned by owner, newest first
+func (b *Bolt) List(ctx context.Context, kind Kind, owner string) ([]*Record, error) {
+	var records []*Record
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(kind))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(_, value []byte) error {
+			record := &Record{}
+			if err := json.Unmarshal(value, record); err != nil {
+				return err
+			}
+			if owner == "" || record.Owner == owner {
+				records = append(records, record)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortNewestFirst(records)
+	return records, nil
+}
+
+// Delete removes a record
+func (b *Bolt) Delete(ctx context.Context, kind Kind, id string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(kind))
+		if bucket == nil || bucket.Get([]byte(id)) == nil {
+			return ErrNotFound
+		}
+		return bucket.Delete([]byte(id))
+	})
+}
+
+// Trim deletes all but the newest keep records of a kind owned by owner
+func (b *Bolt) Trim(ctx context.Context, kind Kind, owner string, keep int) error {
+	records, err := b.List(ctx, kind, owner)
+	if err != nil || len(records) <= keep {
+		return err
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(kind))
+		for _, record := range records[keep:] {
+			if err := bucket.Delete([]byte(record.ID)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Close closes the database file
+func (b *Bolt) Close() error {
+	return b.db.Close()
+}
@@ -0,0 +1,180 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"spacectl-web/server/internal/errors"
+)
+
+// LibraryVersion is the format version written to exported libraries
+const LibraryVersion = 1
+
+// Library is a user's favorites and collections in a human-editable form for sharing through
+// git. Items are matched by name instead of ID, so exported files stay stable and diffable.
+type Library struct {
+	Version     int          `json:"version" yaml:"version"`
+	Favorites   []Favorite   `json:"favorites,omitempty" yaml:"favorites,omitempty"`
+	Collections []Collection `json:"collections,omitempty" yaml:"collections,omitempty"`
+}
+
+// Import modes for libraries
+const (
+	ImportMerge     = "merge"     // Replace items with the same name and keep the others
+	ImportOverwrite = "overwrite" // Delete the user's items before importing
+)
+
+// ExportLibrary returns the favorites and collections owned by owner, oldest first
+func ExportLibrary(ctx context.Context, store Store, owner string) (*Library, error) {
+	library := &Library{Version: LibraryVersion}
+	favorites, err := store.List(ctx, KindFavorite, owner)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(favorites) - 1; i >= 0; i-- {
+		var favorite Favorite
+		if err := json.Unmarshal(favorites[i].Data, &favorite); err != nil {
+			return nil, fmt.Errorf("invalid favorite '%s': %w", favorites[i].ID, err)
+		}
+		library.Favorites = append(library.Favorites, favorite)
+	}
+	collections, err := store.List(ctx, KindCollection, owner)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(collections) - 1; i >= 0; i-- {
+		var collection Collection
+		if err := json.Unmarshal(collections[i].Data, &collection); err != nil {
+			return nil, fmt.Errorf("invalid collection '%s': %w", collections[i].ID, err)
+		}
+		library.Collections = append(library.Collections, collection)
+	}
+	return library, nil
+}
+
+// Validate reports the invalid items of the library, prefixed with their position
+func (l *Library) Validate() []errors.FieldError {
+	var fields []errors.FieldError
+	if l.Version > LibraryVersion {
+		fields = append(fields, errors.FieldError{Field: "version", Message: fmt.Sprintf("must be at most %d", LibraryVersion)})
+	}
+	for i := range l.Favorites {
+		for _, field := range l.Favorites[i].Validate() {
+			field.Field = fmt.Sprintf("favorites[%d].%s", i, field.Field)
+			fields = append(fields, field)
+		}
+	}
+	for i := range l.Collections {
+		for _, field := range l.Collections[i].Validate() {
+			field.Field = fmt.Sprintf("collections[%d].%s", i, field.Field)
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// Normalize converts the nested maps YAML decoding produces in parameters into maps that
+// can be encoded as JSON
+func (l *Library) Normalize() {
+	for i := range l.Favorites {
+		l.Favorites[i].Parameters = normalizeMap(l.Favorites[i].Parameters)
+	}
+	for i := range l.Collections {
+		for j := range l.Collections[i].Requests {
+			request := &l.Collections[i].Requests[j]
+			request.Parameters = normalizeMap(request.Parameters)
+		}
+	}
+}
+
+// normalizeMap converts the values of a parameters map with normalizeValue
+func normalizeMap(parameters map[string]interface{}) map[string]interface{} {
+	for key, value := range parameters {
+		parameters[key] = normalizeValue(value)
+	}
+	return parameters
+}
+
+// normalizeValue replaces map[interface{}]interface{} values with map[string]interface{}
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			converted[fmt.Sprint(key)] = normalizeValue(item)
+		}
+		return converted
+	case map[string]interface{}:
+		return normalizeMap(v)
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeValue(item)
+		}
+	}
+	return value
+}
+
+// ImportLibrary saves the library's favorites and collections as owned by owner using the
+// given mode, and returns the number of items saved by kind
+func ImportLibrary(ctx context.Context, store Store, owner string, library *Library, mode string) (map[Kind]int, error) {
+	items := map[Kind][]namedItem{}
+	for i := range library.Favorites {
+		items[KindFavorite] = append(items[KindFavorite], namedItem{library.Favorites[i].Name, &library.Favorites[i]})
+	}
+	for i := range library.Collections {
+		items[KindCollection] = append(items[KindCollection], namedItem{library.Collections[i].Name, &library.Collections[i]})
+	}
+
+	counts := make(map[Kind]int, len(items))
+	for _, kind := range []Kind{KindFavorite, KindCollection} {
+		existing, err := store.List(ctx, kind, owner)
+		if err != nil {
+			return counts, err
+		}
+		byName := make(map[string]*Record, len(existing))
+		for _, record := range existing {
+			if mode == ImportOverwrite {
+				if err := store.Delete(ctx, kind, record.ID); err != nil {
+					return counts, err
+				}
+				continue
+			}
+			var named struct {
+				Name string `json:"name"`
+			}
+			if json.Unmarshal(record.Data, &named) == nil {
+				byName[named.Name] = record
+			}
+		}
+
+		// Space creation times so imported items keep their order when listed
+		now := time.Now()
+		for i, item := range items[kind] {
+			data, err := json.Marshal(item.value)
+			if err != nil {
+				return counts, fmt.Errorf("invalid %s '%s': %w", kind, item.name, err)
+			}
+			record, exists := byName[item.name]
+			if !exists {
+				created := now.Add(time.Duration(i) * time.Microsecond)
+				record = &Record{Kind: kind, ID: NewID(), Owner: owner, CreatedAt: created}
+				byName[item.name] = record
+			}
+			record.Data = data
+			record.UpdatedAt = now
+			if err := store.Put(ctx, record); err != nil {
+				return counts, err
+			}
+			counts[kind]++
+		}
+	}
+	return counts, nil
+}
+
+// namedItem is a library item with the name it is matched by
+type namedItem struct {
+	name  string
+	value interface{}
+}
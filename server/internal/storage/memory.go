@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// memoryKey identifies a record in the memory store
+type memoryKey struct {
+	kind Kind
+	id   string
+}
+
+// Memory keeps records in memory; they are lost when the server stops
+type Memory struct {
+	records map[memoryKey]*Record
+	mutex   sync.RWMutex
+}
+
+// NewMemory creates an empty memory store
+func NewMemory() *Memory {
+	return &Memory{records: make(map[memoryKey]*Record)}
+}
+
+// Put inserts or replaces a record
+func (m *Memory) Put(ctx context.Context, record *Record) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	stored := *record
+	m.records[memoryKey{record.Kind, record.ID}] = &stored
+	return nil
+}
+
+// Get returns a record
+func (m *Memory) Get(ctx context.Context, kind Kind, id string) (*Record, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	record, exists := m.records[memoryKey{kind, id}]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	found := *record
+	return &found, nil
+}
+
+// List returns the records of a kind owned by owner, newest first
+func (m *Memory) List(ctx context.Context, kind Kind, owner string) ([]*Record, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	var records []*Record
+	for key, record := range m.records {
+		if key.kind == kind && (owner == "" || record.Owner == owner) {
+			found := *record
+			records = append(records, &found)
+		}
+	}
+	sortNewestFirst(records)
+	return records, nil
+}
+
+// Delete removes a record
+func (m *Memory) Delete(ctx context.Context, kind Kind, id string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	key := memoryKey{kind, id}
+	if _, exists := m.records[key]; !exists {
+		return ErrNotFound
+	}
+	delete(m.records, key)
+	return nil
+}
+
+// Trim deletes all but the newest keep records of a kind owned by owner
+func (m *Memory) Trim(ctx context.Context, kind Kind, owner string, keep int) error {
+	records, _ := m.List(ctx, kind, owner)
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for _, record := range records[min(keep, len(records)):] {
+		delete(m.records, memoryKey{kind, record.ID})
+	}
+	return nil
+}
+
+// Close does nothing for the memory store
+func (m *Memory) Close() error {
+	return nil
+}
+
+// sortNewestFirst orders records by creation time, newest first, then by ID for a stable order
+func sortNewestFirst(records []*Record) {
+	sort.Slice(records, func(i, j int) bool {
+		if !records[i].CreatedAt.Equal(records[j].CreatedAt) {
+			return records[i].CreatedAt.After(records[j].CreatedAt)
+		}
+		return records[i].ID < records[j].ID
+	})
+}
@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"spacectl-web/server/internal/config"
+	"spacectl-web/server/internal/errors"
+)
+
+// Request is a gRPC call that can be replayed
+type Request struct {
+	Service    string                 `json:"service" yaml:"service"`
+	Resource   string                 `json:"resource" yaml:"resource"`
+	Verb       string                 `json:"verb" yaml:"verb"`
+	Parameters map[string]interface{} `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+}
+
+// HistoryEntry is a call made through the web console
+type HistoryEntry struct {
+	Request
+	Success    bool   `json:"success"`
+	ErrorCode  string `json:"error_code,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// Favorite is a named request kept for quick access
+type Favorite struct {
+	Name    string `json:"name" yaml:"name"`
+	Request `yaml:",inline"`
+}
+
+// Collection is a named, ordered group of requests
+type Collection struct {
+	Name        string     `json:"name" yaml:"name"`
+	Description string     `json:"description,omitempty" yaml:"description,omitempty"`
+	Requests    []Favorite `json:"requests" yaml:"requests"`
+}
+
+// Schedule is a request to run at a fixed interval
+type Schedule struct {
+	Name            string  `json:"name" yaml:"name"`
+	Request         Request `json:"request" yaml:"request"`
+	IntervalMinutes int     `json:"interval_minutes" yaml:"interval_minutes"`
+	Enabled         bool    `json:"enabled" yaml:"enabled"`
+	KeyField        string  `json:"key_field,omitempty" yaml:"key_field,omitempty"` // Identifies results in change reports; defaults to e.g. server_id
+}
+
+// Snapshot is the results of a schedule's last run by key, kept to diff the next run.
+// Its record has the schedule's ID.
+type Snapshot struct {
+	Items map[string]json.RawMessage `json:"items"`
+	Error string                     `json:"error,omitempty"` // Why the last run failed
+}
+
+// ChangeReport lists the results that changed between two runs of a schedule, by key
+type ChangeReport struct {
+	ScheduleID   string    `json:"schedule_id"`
+	ScheduleName string    `json:"schedule_name"`
+	Request      Request   `json:"request"`
+	Added        []string  `json:"added"`
+	Removed      []string  `json:"removed"`
+	Modified     []string  `json:"modified"`
+	PreviousRun  time.Time `json:"previous_run"`
+}
+
+// View formats
+const (
+	ViewFormatJSON = "json"
+	ViewFormatCSV  = "csv"
+)
+
+// View is a named request whose results are filtered and projected onto columns when run
+type View struct {
+	Name        string   `json:"name" yaml:"name"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Request     Request  `json:"request" yaml:"request"`
+	Columns     []string `json:"columns,omitempty" yaml:"columns,omitempty"` // Dot-separated result fields; empty keeps whole results
+	Filters     []string `json:"filters,omitempty" yaml:"filters,omitempty"` // key=value conditions added to the query
+	Format      string   `json:"format,omitempty" yaml:"format,omitempty"`   // json (default) or csv
+}
+
+// Validate reports the missing fields of a request, with field names under prefix
+func (r *Request) Validate(prefix string) []errors.FieldError {
+	var fields []errors.FieldError
+	for field, value := range map[string]string{"service": r.Service, "resource": r.Resource, "verb": r.Verb} {
+		if value == "" {
+			fields = append(fields, errors.FieldError{Field: prefix + field, Message: "required"})
+		}
+	}
+	return fields
+}
+
+// Validate reports the invalid fields of the favorite
+func (f *Favorite) Validate() []errors.FieldError {
+	fields := f.Request.Validate("")
+	if f.Name == "" {
+		fields = append(fields, errors.FieldError{Field: "name", Message: "required"})
+	}
+	return fields
+}
+
+// Validate reports the invalid fields of the collection
+func (c *Collection) Validate() []errors.FieldError {
+	var fields []errors.FieldError
+	if c.Name == "" {
+		fields = append(fields, errors.FieldError{Field: "name", Message: "required"})
+	}
+	for i := range c.Requests {
+		for _, field := range c.Requests[i].Validate() {
+			field.Field = fmt.Sprintf("requests[%d].%s", i, field.Field)
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// Validate reports the invalid fields of the schedule
+func (s *Schedule) Validate() []errors.FieldError {
+	fields := s.Request.Validate("request.")
+	if s.Name == "" {
+		fields = append(fields, errors.FieldError{Field: "name", Message: "required"})
+	}
+	if s.IntervalMinutes <= 0 {
+		fields = append(fields, errors.FieldError{Field: "interval_minutes", Message: "must be positive"})
+	}
+	return fields
+}
+
+// Validate reports the invalid fields of the view
+func (v *View) Validate() []errors.FieldError {
+	fields := v.Request.Validate("request.")
+	if v.Request.Verb != "" && !config.IsReadVerb(v.Request.Verb) {
+		fields = append(fields, errors.FieldError{Field: "request.verb", Message: "must only read data, such as list or get"})
+	}
+	if v.Name == "" {
+		fields = append(fields, errors.FieldError{Field: "name", Message: "required"})
+	}
+	for i, column := range v.Columns {
+		if column == "" {
+			fields = append(fields, errors.FieldError{Field: fmt.Sprintf("columns[%d]", i), Message: "must not be empty"})
+		}
+	}
+	if v.Format != "" && v.Format != ViewFormatJSON && v.Format != ViewFormatCSV {
+		fields = append(fields, errors.FieldError{Field: "format", Message: fmt.Sprintf("must be '%s' or '%s'", ViewFormatJSON, ViewFormatCSV)})
+	}
+	return fields
+}
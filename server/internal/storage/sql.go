@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib" // Registers the pgx driver
+	_ "modernc.org/sqlite"             // Registers the sqlite driver
+)
+
+// schema creates the records table shared by the SQL backends
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS spacectl_records (
+		kind       VARCHAR(32)  NOT NULL,
+		id         VARCHAR(64)  NOT NULL,
+		owner      VARCHAR(255) NOT NULL,
+		data       TEXT         NOT NULL,
+		created_at BIGINT       NOT NULL,
+		updated_at BIGINT       NOT NULL,
+		PRIMARY KEY (kind, id)
+	)`,
+	`CREATE INDEX IF NOT EXISTS spacectl_records_owner ON spacectl_records (kind, owner, created_at)`,
+}
+
+// SQL keeps records in a SQL database. Timestamps are stored as Unix nanoseconds so SQLite
+// and PostgreSQL compare them the same way.
+type SQL struct {
+	db       *sql.DB
+	numbered bool // Placeholders are $1, $2, ... instead of ?
+}
+
+// NewSQLite opens or creates the SQLite database file at path
+func NewSQLite(path string) (*SQL, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1) // SQLite allows a single writer
+	return newSQL(db, false)
+}
+
+// NewPostgres connects to the PostgreSQL database at dsn
+func NewPostgres(dsn string) (*SQL, error) {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, err
+	}
+	return newSQL(db, true)
+}
+
+// newSQL creates the schema and wraps the database
+func newSQL(db *sql.DB, numbered bool) (*SQL, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for _, statement := range schema {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to create the storage schema: %w", err)
+		}
+	}
+	return &SQL{db: db, numbered: numbered}, nil
+}
+
+// query rewrites ? placeholders for databases that number them
+func (s *SQL) query(query string) string {
+	if !s.numbered {
+		return query
+	}
+	var rewritten strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			fmt.Fprintf(&rewritten, "$%d", n)
+			continue
+		}
+		rewritten.WriteRune(r)
+	}
+	return rewritten.String()
+}
+
+// Put inserts or replaces a record
+func (s *SQL) Put(ctx context.Context, record *Record) error {
+	_, err := s.db.ExecContext(ctx, s.query(`INSERT INTO spacectl_records (kind, id, owner, data, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (kind, id) DO UPDATE SET owner = excluded.owner, data = excluded.data,
+			created_at = excluded.created_at, updated_at = excluded.updated_at`),
+		string(record.Kind), record.ID, record.Owner, string(record.Data),
+		record.CreatedAt.UnixNano(), record.UpdatedAt.UnixNano())
+	return err
+}
+
+// Get returns a record
+func (s *SQL) Get(ctx context.Context, kind Kind, id string) (*Record, error) {
+	row := s.db.QueryRowContext(ctx, s.query(`SELECT kind, id, owner, data, created_at, updated_at
+		FROM spacectl_records WHERE kind = ? AND id = ?`), string(kind), id)
+	record, err := scanRecord(row)
+	if stderrors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return record, err
+}
+
+// List returns the records of a kind owned by owner, newest first
+func (s *SQL) List(ctx context.Context, kind Kind, owner string) ([]*Record, error) {
+	query := `SELECT kind, id, owner, data, created_at, updated_at FROM spacectl_records WHERE kind = ?`
+	args := []interface{}{string(kind)}
+	if owner != "" {
+		query += ` AND owner = ?`
+		args = append(args, owner)
+	}
+	rows, err := s.db.QueryContext(ctx, s.query(query+` ORDER BY created_at DESC, id`), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []*Record
+	for rows.Next() {
+		record, err := scanRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// Delete removes a record
+func (s *SQL) Delete(ctx context.Context, kind Kind, id string) error {
+	result, err := s.db.ExecContext(ctx, s.query(`DELETE FROM spacectl_records WHERE kind = ? AND id = ?`), string(kind), id)
+	if err != nil {
+		return err
+	}
+	if deleted, err := result.RowsAffected(); err == nil && deleted == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Trim deletes all but the newest keep records of a kind owned by owner
+func (s *SQL) Trim(ctx context.Context, kind Kind, owner string, keep int) error {
+	_, err := s.db.ExecContext(ctx, s.query(`DELETE FROM spacectl_records WHERE kind = ? AND owner = ? AND id NOT IN (
+		SELECT id FROM spacectl_records WHERE kind = ? AND owner = ? ORDER BY created_at DESC, id LIMIT ?)`),
+		string(kind), owner, string(kind), owner, keep)
+	return err
+}
+
+// Close closes the database connections
+func (s *SQL) Close() error {
+	return s.db.Close()
+}
+
+// scanRecord reads a record from a row of the records table
+func scanRecord(row interface{ Scan(...interface{}) error }) (*Record, error) {
+	var (
+		record               Record
+		kind, data           string
+		createdAt, updatedAt int64
+	)
+	if err := row.Scan(&kind, &record.ID, &record.Owner, &data, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	record.Kind = Kind(kind)
+	record.Data = []byte(data)
+	record.CreatedAt = time.Unix(0, createdAt)
+	record.UpdatedAt = time.Unix(0, updatedAt)
+	return &record, nil
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"spacectl-web/server/internal/config"
+)
+
+// Kind identifies the type of data a record holds
+type Kind string
+
+// Kinds of saved data
+const (
+	KindHistory      Kind = "history"
+	KindFavorite     Kind = "favorite"
+	KindCollection   Kind = "collection"
+	KindSchedule     Kind = "schedule"
+	KindView         Kind = "view"
+	KindSnapshot     Kind = "snapshot"
+	KindChangeReport Kind = "change_report"
+	KindWorkflow     Kind = "workflow"
+	KindWorkflowRun  Kind = "workflow_run"
+)
+
+// ErrNotFound is returned when a record does not exist
+var ErrNotFound = errors.New("record not found")
+
+// Record is a saved item owned by a user. Data holds the kind's model as JSON, so backends
+// store every kind the same way.
+type Record struct {
+	Kind      Kind            `json:"kind"`
+	ID        string          `json:"id"`
+	Owner     string          `json:"owner"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// Store persists saved data. Implementations must be safe for concurrent use.
+type Store interface {
+	// Put inserts the record, or replaces the record with the same kind and ID
+	Put(ctx context.Context, record *Record) error
+	// Get returns a record, or ErrNotFound
+	Get(ctx context.Context, kind Kind, id string) (*Record, error)
+	// List returns the records of a kind owned by owner, newest first; an empty owner lists every record
+	List(ctx context.Context, kind Kind, owner string) ([]*Record, error)
+	// Delete removes a record, or returns ErrNotFound
+	Delete(ctx context.Context, kind Kind, id string) error
+	// Trim deletes all but the newest keep records of a kind owned by owner
+	Trim(ctx context.Context, kind Kind, owner string, keep int) error
+	// Close releases the backend's resources
+	Close() error
+}
+
+// New opens the store selected by the config
+func New(cfg config.StorageConfig) (Store, error) {
+	cfg = cfg.WithDefaults()
+	switch cfg.Backend {
+	case config.StorageMemory:
+		return NewMemory(), nil
+	case config.StorageBolt:
+		return NewBolt(cfg.Path)
+	case config.StorageSQLite:
+		return NewSQLite(cfg.Path)
+	case config.StoragePostgres:
+		return NewPostgres(cfg.DSN)
+	}
+	return nil, fmt.Errorf("unsupported storage backend '%s'", cfg.Backend)
+}
+
+// NewID returns a random record ID
+func NewID() string {
+	id := make([]byte, 12)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"spacectl-web/server/internal/errors"
+)
+
+// MaxWorkflowRetries bounds the retries of a workflow step
+const MaxWorkflowRetries = 10
+
+// WorkflowReference matches the references in workflow parameters and conditions, such as
+// ${inputs.name} or ${steps.project.project_id}
+var WorkflowReference = regexp.MustCompile(`\$\{\s*([^}]*?)\s*\}`)
+
+// workflowName matches the names of workflow inputs, steps, and outputs, which references use
+var workflowName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// Workflow is a named sequence of calls, where the outputs of a step can feed the parameters
+// of the steps after it
+type Workflow struct {
+	Name        string         `json:"name" yaml:"name"`
+	Description string         `json:"description,omitempty" yaml:"description,omitempty"`
+	Inputs      []string       `json:"inputs,omitempty" yaml:"inputs,omitempty"` // Values each run must be given
+	Steps       []WorkflowStep `json:"steps" yaml:"steps"`
+}
+
+// WorkflowStep is a call in a workflow. String parameters may hold references to inputs and
+// to the outputs of earlier steps; a parameter that is a single reference takes the
+// referenced value as is, e.g. a list.
+type WorkflowStep struct {
+	Name              string `json:"name" yaml:"name"`
+	Request           `yaml:",inline"`
+	Outputs           map[string]string `json:"outputs,omitempty" yaml:"outputs,omitempty"`                         // JSONPath expressions on the response by output name, e.g. $.project_id
+	When              string            `json:"when,omitempty" yaml:"when,omitempty"`                               // Condition such as "${steps.find.total_count} == 0"; the step is skipped when false
+	Retries           int               `json:"retries,omitempty" yaml:"retries,omitempty"`                         // Extra attempts after a failed call
+	RetryDelaySeconds int               `json:"retry_delay_seconds,omitempty" yaml:"retry_delay_seconds,omitempty"` // Defaults to 5
+}
+
+// Statuses of workflow runs and their steps
+const (
+	WorkflowPending   = "pending"
+	WorkflowRunning   = "running"
+	WorkflowSucceeded = "succeeded"
+	WorkflowFailed    = "failed"
+	WorkflowSkipped   = "skipped"
+	WorkflowCanceled  = "canceled"
+)
+
+// WorkflowRun is the progress of one run of a workflow
+type WorkflowRun struct {
+	WorkflowID   string                 `json:"workflow_id"`
+	WorkflowName string                 `json:"workflow_name"`
+	Status       string                 `json:"status"` // running, succeeded, failed, or canceled
+	Inputs       map[string]interface{} `json:"inputs"`
+	Steps        []WorkflowStepRun      `json:"steps"`
+	Error        string                 `json:"error,omitempty"`
+	FinishedAt   *time.Time             `json:"finished_at,omitempty"`
+}
+
+// WorkflowStepRun is the progress of a step in a workflow run
+type WorkflowStepRun struct {
+	Name       string                 `json:"name"`
+	Status     string                 `json:"status"`
+	Attempts   int                    `json:"attempts"`
+	Outputs    map[string]interface{} `json:"outputs,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+	StartedAt  *time.Time             `json:"started_at,omitempty"`
+	FinishedAt *time.Time             `json:"finished_at,omitempty"`
+}
+
+// Finished reports whether the run has stopped
+func (r *WorkflowRun) Finished() bool {
+	return r.Status != WorkflowRunning
+}
+
+// Validate reports the invalid fields of the workflow, including references to inputs that
+// are not declared and to outputs of steps that do not come earlier
+func (w *Workflow) Validate() []errors.FieldError {
+	var fields []errors.FieldError
+	if w.Name == "" {
+		fields = append(fields, errors.FieldError{Field: "name", Message: "required"})
+	}
+	known := make(map[string]bool)
+	for i, input := range w.Inputs {
+		if !workflowName.MatchString(input) {
+			fields = append(fields, errors.FieldError{Field: fmt.Sprintf("inputs[%d]", i), Message: "must be a name of letters, digits, _ and -"})
+		}
+		known["inputs."+input] = true
+	}
+	if len(w.Steps) == 0 {
+		fields = append(fields, errors.FieldError{Field: "steps", Message: "must have at least one step"})
+	}
+
+	steps := make(map[string]bool, len(w.Steps))
+	for i := range w.Steps {
+		step := &w.Steps[i]
+		prefix := fmt.Sprintf("steps[%d].", i)
+		fields = append(fields, step.Request.Validate(prefix)...)
+		switch {
+		case !workflowName.MatchString(step.Name):
+			fields = append(fields, errors.FieldError{Field: prefix + "name", Message: "must be a name of letters, digits, _ and -"})
+		case steps[step.Name]:
+			fields = append(fields, errors.FieldError{Field: prefix + "name", Message: fmt.Sprintf("duplicates step '%s'", step.Name)})
+		}
+		if step.Retries < 0 || step.Retries > MaxWorkflowRetries {
+			fields = append(fields, errors.FieldError{Field: prefix + "retries", Message: fmt.Sprintf("must be between 0 and %d", MaxWorkflowRetries)})
+		}
+		if step.RetryDelaySeconds < 0 {
+			fields = append(fields, errors.FieldError{Field: prefix + "retry_delay_seconds", Message: "must not be negative"})
+		}
+
+		// References may only use what is known before the step runs
+		for _, reference := range references(step.Parameters) {
+			if !known[reference] {
+				fields = append(fields, errors.FieldError{Field: prefix + "parameters", Message: fmt.Sprintf("references unknown ${%s}", reference)})
+			}
+		}
+		for _, match := range WorkflowReference.FindAllStringSubmatch(step.When, -1) {
+			if !known[match[1]] {
+				fields = append(fields, errors.FieldError{Field: prefix + "when", Message: fmt.Sprintf("references unknown ${%s}", match[1])})
+			}
+		}
+
+		steps[step.Name] = true
+		for output, path := range step.Outputs {
+			if !workflowName.MatchString(output) {
+				fields = append(fields, errors.FieldError{Field: prefix + "outputs", Message: fmt.Sprintf("output '%s' must be a name of letters, digits, _ and -", output)})
+			}
+			if !strings.HasPrefix(path, "$") {
+				fields = append(fields, errors.FieldError{Field: prefix + "outputs." + output, Message: "must be a JSONPath expression starting with $"})
+			}
+			known["steps."+step.Name+"."+output] = true
+		}
+	}
+	return fields
+}
+
+// references returns the references in the strings of a parameter value
+func references(value interface{}) []string {
+	var found []string
+	switch v := value.(type) {
+	case string:
+		for _, match := range WorkflowReference.FindAllStringSubmatch(v, -1) {
+			found = append(found, match[1])
+		}
+	case map[string]interface{}:
+		for _, item := range v {
+			found = append(found, references(item)...)
+		}
+	case []interface{}:
+		for _, item := range v {
+			found = append(found, references(item)...)
+		}
+	}
+	return found
+}
+
+// Normalize converts the nested maps YAML decoding produces in step parameters into maps that
+// can be encoded as JSON
+func (w *Workflow) Normalize() {
+	for i := range w.Steps {
+		w.Steps[i].Parameters = normalizeMap(w.Steps[i].Parameters)
+	}
+}
@@ -0,0 +1,108 @@
+package update
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Apply downloads the binary for the running platform from the release, verifies it against
+// the release checksums and replaces the executable at path with it
+func Apply(ctx context.Context, client *http.Client, release *Release, path string) error {
+	name := BinaryAsset()
+	binary, exists := release.Asset(name)
+	if !exists {
+		return fmt.Errorf("release %s has no binary for this platform (%s)", release.TagName, name)
+	}
+	checksums, exists := release.Asset(ChecksumsAsset)
+	if !exists {
+		return fmt.Errorf("release %s has no %s to verify the download", release.TagName, ChecksumsAsset)
+	}
+
+	expected, err := fetchChecksum(ctx, client, checksums.DownloadURL, name)
+	if err != nil {
+		return err
+	}
+
+	// Download next to the executable so the final rename stays on one file system
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".spacectl-web-update-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	if err := download(ctx, client, binary.DownloadURL, io.MultiWriter(tmp, hash)); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != expected {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", name, expected, actual)
+	}
+	if err := os.Chmod(tmp.Name(), 0o755); err != nil {
+		return err
+	}
+	return replace(path, tmp.Name())
+}
+
+// replace swaps the executable for the new binary. The old executable is moved aside first,
+// since Windows cannot overwrite a running executable, and restored if the swap fails.
+func replace(path, newPath string) error {
+	oldPath := path + ".old"
+	_ = os.Remove(oldPath)
+	if err := os.Rename(path, oldPath); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	if err := os.Rename(newPath, path); err != nil {
+		_ = os.Rename(oldPath, path)
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	_ = os.Remove(oldPath) // Fails while the old executable is running on Windows; it is removed by the next update
+	return nil
+}
+
+// fetchChecksum returns the checksum of a file from a sha256sum-format checksums file
+func fetchChecksum(ctx context.Context, client *http.Client, url, name string) (string, error) {
+	var checksums strings.Builder
+	if err := download(ctx, client, url, &checksums); err != nil {
+		return "", err
+	}
+	scanner := bufio.NewScanner(strings.NewReader(checksums.String()))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("%s does not list %s", ChecksumsAsset, name)
+}
+
+// download writes the body of a URL to w
+func download(ctx context.Context, client *http.Client, url string, w io.Writer) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download %s: %s", url, resp.Status)
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	return nil
+}
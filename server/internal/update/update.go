@@ -0,0 +1,172 @@
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Release source
+const (
+	Repository  = "raccoon-mh/spacectl-web"
+	releasesURL = "https://api.github.com/repos/" + Repository + "/releases/latest"
+)
+
+// ChecksumsAsset is the release asset listing the SHA-256 checksums of the binaries in sha256sum format
+const ChecksumsAsset = "checksums.txt"
+
+// Timeouts and caching of release checks
+const (
+	requestTimeout = 10 * time.Second
+	checkInterval  = 6 * time.Hour // How long a check result, successful or not, is reused
+)
+
+// Release represents a GitHub release
+type Release struct {
+	TagName string  `json:"tag_name"`
+	HTMLURL string  `json:"html_url"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Asset represents a file attached to a release
+type Asset struct {
+	Name        string `json:"name"`
+	DownloadURL string `json:"browser_download_url"`
+}
+
+// Version returns the release version without the leading v
+func (r *Release) Version() string {
+	return strings.TrimPrefix(r.TagName, "v")
+}
+
+// Asset returns the asset with the given name
+func (r *Release) Asset(name string) (*Asset, bool) {
+	for i := range r.Assets {
+		if r.Assets[i].Name == name {
+			return &r.Assets[i], true
+		}
+	}
+	return nil, false
+}
+
+// Status represents the result of an update check
+type Status struct {
+	Version         string `json:"version"`
+	LatestVersion   string `json:"latest_version,omitempty"`
+	UpdateAvailable bool   `json:"update_available"`
+	ReleaseURL      string `json:"release_url,omitempty"`
+}
+
+// Checker looks up the latest release and caches the result
+type Checker struct {
+	current   string
+	client    *http.Client
+	status    *Status
+	checkedAt time.Time
+	mutex     sync.Mutex
+}
+
+// NewChecker creates a checker for the running version
+func NewChecker(current string) *Checker {
+	return &Checker{current: current, client: &http.Client{Timeout: requestTimeout}}
+}
+
+// Status compares the running version with the latest release. Lookup failures are not
+// errors: the status then only reports the running version.
+func (c *Checker) Status(ctx context.Context) *Status {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.status != nil && time.Since(c.checkedAt) < checkInterval {
+		return c.status
+	}
+	status := &Status{Version: c.current}
+	if release, err := Latest(ctx, c.client); err == nil {
+		status.LatestVersion = release.Version()
+		status.UpdateAvailable = IsNewer(release.Version(), c.current)
+		status.ReleaseURL = release.HTMLURL
+	}
+	c.status = status
+	c.checkedAt = time.Now()
+	return status
+}
+
+// Latest returns the latest published release
+func Latest(ctx context.Context, client *http.Client) (*Release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, releasesURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for releases: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to check for releases: %s", resp.Status)
+	}
+
+	var release Release
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, fmt.Errorf("invalid release information: %w", err)
+	}
+	return &release, nil
+}
+
+// IsNewer reports whether version is newer than current. Versions that are not numeric,
+// such as "dev" builds, are never considered older.
+func IsNewer(version, current string) bool {
+	v, ok := parseVersion(version)
+	if !ok {
+		return false
+	}
+	cur, ok := parseVersion(current)
+	if !ok {
+		return false
+	}
+	for i := range v {
+		if v[i] != cur[i] {
+			return v[i] > cur[i]
+		}
+	}
+	return false
+}
+
+// parseVersion parses major.minor.patch, ignoring a leading v and any pre-release or build suffix
+func parseVersion(version string) ([3]int, bool) {
+	var parsed [3]int
+	version = strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	parts := strings.Split(version, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return parsed, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return parsed, false
+		}
+		parsed[i] = n
+	}
+	return parsed, true
+}
+
+// BinaryAsset returns the release asset name of the binary for the running platform,
+// e.g. spacectl-web_linux_amd64 or spacectl-web_windows_amd64.exe
+func BinaryAsset() string {
+	name := fmt.Sprintf("spacectl-web_%s_%s", runtime.GOOS, runtime.GOARCH)
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
@@ -0,0 +1,215 @@
+package usage
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"spacectl-web/server/internal/config"
+	"spacectl-web/server/internal/sharedcache"
+)
+
+// AnonymousUser is the user name recorded for requests without a user header
+const AnonymousUser = "anonymous"
+
+// sharedTimeout bounds a shared counter update so a slow Redis does not delay calls
+const sharedTimeout = 2 * time.Second
+
+// Tracker counts calls and data volume per user and enforces the configured quotas.
+// Quotas are counted over fixed windows that start with a user's first call. With a shared
+// cache the window counts are kept in it, so quotas hold across replicas; totals and the
+// per-service breakdown stay per replica.
+type Tracker struct {
+	window   time.Duration
+	maxCalls int
+	maxBytes int64
+	users    map[string]*userUsage
+	shared   *sharedcache.Cache
+	mutex    sync.Mutex
+}
+
+// userUsage is the usage of a single user
+type userUsage struct {
+	windowStart time.Time
+	calls       int
+	bytes       int64
+	totalCalls  int64
+	totalBytes  int64
+	services    map[string]int64
+	lastCall    time.Time
+}
+
+// UserUsage represents a user's usage in the current window and since the server started
+type UserUsage struct {
+	User        string           `json:"user"`
+	WindowStart time.Time        `json:"window_start"`
+	Calls       int              `json:"calls"`
+	Bytes       int64            `json:"bytes"`
+	TotalCalls  int64            `json:"total_calls"`
+	TotalBytes  int64            `json:"total_bytes"`
+	Services    map[string]int64 `json:"services"` // Total calls by service; bench and websocket sessions are not broken down
+	LastCall    time.Time        `json:"last_call"`
+}
+
+// Report represents the usage of every user along with the quotas
+type Report struct {
+	WindowMinutes int         `json:"window_minutes"`
+	MaxCalls      int         `json:"max_calls,omitempty"`
+	MaxBytes      int64       `json:"max_bytes,omitempty"`
+	Users         []UserUsage `json:"users"`
+}
+
+// NewTracker creates a usage tracker with the quotas from the config
+func NewTracker(cfg config.QuotasConfig) *Tracker {
+	return &Tracker{
+		window:   cfg.Window(),
+		maxCalls: cfg.MaxCalls,
+		maxBytes: cfg.MaxBytes,
+		users:    make(map[string]*userUsage),
+	}
+}
+
+// SetSharedCache keeps the window counts in the shared cache
+func (t *Tracker) SetSharedCache(cache *sharedcache.Cache) {
+	t.shared = cache
+}
+
+// Allow reports whether the user may make another call, and otherwise how long until the window resets
+func (t *Tracker) Allow(user string) (bool, time.Duration) {
+	if calls, bytes, remaining, ok := t.sharedCounts(user); ok {
+		if (t.maxCalls > 0 && calls >= int64(t.maxCalls)) || (t.maxBytes > 0 && bytes >= t.maxBytes) {
+			return false, remaining
+		}
+		return true, 0
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := time.Now()
+	u := t.get(user, now)
+	if (t.maxCalls > 0 && u.calls >= t.maxCalls) || (t.maxBytes > 0 && u.bytes >= t.maxBytes) {
+		return false, u.windowStart.Add(t.window).Sub(now)
+	}
+	return true, 0
+}
+
+// Record adds a call and the bytes it transferred to the user's usage
+func (t *Tracker) Record(user, service string, bytes int64) {
+	if t.shared != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), sharedTimeout)
+		_, _, err := t.shared.Add(ctx, t.window, map[string]int64{callsKey(user): 1, bytesKey(user): bytes})
+		cancel()
+		if err != nil {
+			log.Printf("WARNING: failed to update shared usage of %s: %v", user, err)
+		}
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := time.Now()
+	u := t.get(user, now)
+	u.calls++
+	u.bytes += bytes
+	u.totalCalls++
+	u.totalBytes += bytes
+	if service != "" {
+		u.services[service]++
+	}
+	u.lastCall = now
+}
+
+// Report returns the usage of every user, heaviest users first
+func (t *Tracker) Report() *Report {
+	now := time.Now()
+	report := t.localReport(now)
+
+	// Report the window counts of all replicas
+	for i := range report.Users {
+		u := &report.Users[i]
+		if calls, bytes, remaining, ok := t.sharedCounts(u.User); ok {
+			u.Calls = int(calls)
+			u.Bytes = bytes
+			u.WindowStart = now.Add(remaining - t.window)
+		}
+	}
+
+	sort.Slice(report.Users, func(i, j int) bool {
+		a, b := report.Users[i], report.Users[j]
+		if a.TotalCalls != b.TotalCalls {
+			return a.TotalCalls > b.TotalCalls
+		}
+		return a.User < b.User
+	})
+	return report
+}
+
+// localReport returns the usage of every user counted by this replica
+func (t *Tracker) localReport(now time.Time) *Report {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	report := &Report{
+		WindowMinutes: int(t.window / time.Minute),
+		MaxCalls:      t.maxCalls,
+		MaxBytes:      t.maxBytes,
+		Users:         make([]UserUsage, 0, len(t.users)),
+	}
+	for user := range t.users {
+		u := t.get(user, now)
+		services := make(map[string]int64, len(u.services))
+		for service, calls := range u.services {
+			services[service] = calls
+		}
+		report.Users = append(report.Users, UserUsage{
+			User:        user,
+			WindowStart: u.windowStart,
+			Calls:       u.calls,
+			Bytes:       u.bytes,
+			TotalCalls:  u.totalCalls,
+			TotalBytes:  u.totalBytes,
+			Services:    services,
+			LastCall:    u.lastCall,
+		})
+	}
+	return report
+}
+
+// sharedCounts returns the user's window counts from the shared cache and the time left in the
+// window; ok is false without a shared cache or when it cannot be read
+func (t *Tracker) sharedCounts(user string) (calls, bytes int64, remaining time.Duration, ok bool) {
+	if t.shared == nil {
+		return 0, 0, 0, false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), sharedTimeout)
+	defer cancel()
+	values, remaining, err := t.shared.Counters(ctx, callsKey(user), bytesKey(user))
+	if err != nil {
+		log.Printf("WARNING: failed to read shared usage of %s, using this replica's counts: %v", user, err)
+		return 0, 0, 0, false
+	}
+	return values[0], values[1], remaining, true
+}
+
+// callsKey and bytesKey are the shared cache keys of a user's window counts
+func callsKey(user string) string { return "usage:" + user + ":calls" }
+func bytesKey(user string) string { return "usage:" + user + ":bytes" }
+
+// get returns the usage of a user, starting a new window when the current one has ended.
+// The caller must hold the mutex.
+func (t *Tracker) get(user string, now time.Time) *userUsage {
+	u, exists := t.users[user]
+	if !exists {
+		u = &userUsage{windowStart: now, services: make(map[string]int64)}
+		t.users[user] = u
+	}
+	if now.Sub(u.windowStart) >= t.window {
+		u.windowStart = now
+		u.calls = 0
+		u.bytes = 0
+	}
+	return u
+}
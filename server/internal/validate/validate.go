@@ -0,0 +1,178 @@
+package validate
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"spacectl-web/server/internal/config"
+	"spacectl-web/server/internal/jwt"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Check statuses
+const (
+	StatusOK      = "ok"
+	StatusWarning = "warning"
+	StatusError   = "error"
+)
+
+// reachabilityTimeout bounds the TCP dial used to probe each endpoint
+const reachabilityTimeout = 5 * time.Second
+
+// tokenExpiryWarning is how close to expiry a token must be to produce a warning
+const tokenExpiryWarning = 24 * time.Hour
+
+// Check represents the result of a single validation check
+type Check struct {
+	Name    string `json:"name"`
+	Target  string `json:"target,omitempty"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// Report represents the structured result of validating a configuration
+type Report struct {
+	Valid      bool    `json:"valid"`
+	ConfigFile string  `json:"config_file,omitempty"`
+	Profile    string  `json:"profile,omitempty"`
+	Checks     []Check `json:"checks"`
+}
+
+func (r *Report) add(check Check) {
+	if check.Status == StatusError {
+		r.Valid = false
+	}
+	r.Checks = append(r.Checks, check)
+}
+
+// Files validates config files on disk, including YAML syntax and schema errors. Several files
+// are checked one by one for syntax, then layered as the server loads them for the schema.
+func Files(filenames []string, profile string) *Report {
+	report := &Report{Valid: true, ConfigFile: strings.Join(filenames, ", ")}
+
+	for _, filename := range filenames {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			report.add(Check{Name: "file", Target: filename, Status: StatusError, Message: err.Error()})
+			return report
+		}
+
+		var raw map[string]interface{}
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			report.add(Check{Name: "yaml", Target: filename, Status: StatusError, Message: err.Error()})
+			return report
+		}
+		report.add(Check{Name: "yaml", Target: filename, Status: StatusOK})
+	}
+
+	cfg, err := config.LoadFiles(filenames, profile)
+	if err != nil {
+		report.add(Check{Name: "schema", Target: report.ConfigFile, Status: StatusError, Message: err.Error()})
+		return report
+	}
+	report.add(Check{Name: "schema", Target: report.ConfigFile, Status: StatusOK})
+
+	checkConfig(report, cfg)
+	return report
+}
+
+// Config validates an already loaded configuration
+func Config(cfg *config.Config) *Report {
+	report := &Report{Valid: true}
+	if err := cfg.Validate(); err != nil {
+		report.add(Check{Name: "schema", Status: StatusError, Message: err.Error()})
+	} else {
+		report.add(Check{Name: "schema", Status: StatusOK})
+	}
+
+	checkConfig(report, cfg)
+	return report
+}
+
+// checkConfig runs the token, URL format and reachability checks
+func checkConfig(report *Report, cfg *config.Config) {
+	report.Profile = cfg.ActiveProfile()
+
+	report.add(checkToken(cfg.GetToken()))
+
+	names := cfg.ServiceNames()
+	reachability := make([]Check, len(names))
+	var wg sync.WaitGroup
+
+	for i, name := range names {
+		endpoint, _ := cfg.GetEndpoint(name)
+		target := "endpoints." + name
+
+		if err := checkURL(endpoint.URL); err != nil {
+			report.add(Check{Name: "url", Target: target, Status: StatusError, Message: err.Error()})
+			reachability[i] = Check{Name: "reachability", Target: target, Status: StatusError, Message: "skipped: invalid URL"}
+			continue
+		}
+		report.add(Check{Name: "url", Target: target, Status: StatusOK})
+
+		wg.Add(1)
+		go func(i int, address string) {
+			defer wg.Done()
+			reachability[i] = checkReachable(target, address)
+		}(i, endpoint.Address())
+	}
+
+	wg.Wait()
+	for _, check := range reachability {
+		report.add(check)
+	}
+}
+
+// checkToken verifies the token is present, decodable and not expired
+func checkToken(token string) Check {
+	if token == "" {
+		return Check{Name: "token", Status: StatusError, Message: "token is empty"}
+	}
+
+	info, err := jwt.Parse(token)
+	if err != nil {
+		return Check{Name: "token", Status: StatusError, Message: err.Error()}
+	}
+
+	expiresAt, ok := info.ExpiresAt()
+	if !ok {
+		return Check{Name: "token", Status: StatusWarning, Message: "token has no exp claim"}
+	}
+
+	remaining := time.Until(expiresAt)
+	switch {
+	case remaining <= 0:
+		return Check{Name: "token", Status: StatusError, Message: fmt.Sprintf("token expired at %s", expiresAt.Format(time.RFC3339))}
+	case remaining < tokenExpiryWarning:
+		return Check{Name: "token", Status: StatusWarning, Message: fmt.Sprintf("token expires at %s", expiresAt.Format(time.RFC3339))}
+	default:
+		return Check{Name: "token", Status: StatusOK, Message: fmt.Sprintf("token expires at %s", expiresAt.Format(time.RFC3339))}
+	}
+}
+
+// checkURL verifies the endpoint URL can be parsed
+func checkURL(rawURL string) error {
+	_, err := config.ParseEndpointURL(rawURL)
+	return err
+}
+
+// checkReachable verifies a TCP connection can be opened to the endpoint address
+func checkReachable(target, address string) Check {
+	ctx, cancel := context.WithTimeout(context.Background(), reachabilityTimeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return Check{Name: "reachability", Target: target, Status: StatusError, Message: err.Error()}
+	}
+	conn.Close()
+
+	return Check{Name: "reachability", Target: target, Status: StatusOK, Message: address}
+}
@@ -0,0 +1,62 @@
+package web
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+)
+
+// Sources the web client can be served from
+const (
+	SourceEmbedded  = "embedded"
+	SourceDirectory = "directory"
+)
+
+// Build identifies a web client build by the hash of its files
+type Build struct {
+	Hash  string `json:"hash"` // SHA-256 over the sorted file paths and contents
+	Files int    `json:"files"`
+}
+
+// Assets describes the web client being served and the build embedded in the binary
+type Assets struct {
+	Source   string `json:"source"`
+	Served   Build  `json:"served"`
+	Embedded Build  `json:"embedded"`
+}
+
+// Mismatch reports whether the served web client differs from the embedded build
+func (a *Assets) Mismatch() bool {
+	return a.Served.Hash != a.Embedded.Hash
+}
+
+// HashBuild hashes every file of a web client build. Paths are included so renamed files
+// change the hash, and fs.WalkDir visits them in lexical order so the hash is stable.
+func HashBuild(webFS fs.FS) (Build, error) {
+	var build Build
+	hash := sha256.New()
+	err := fs.WalkDir(webFS, ".", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		file, err := webFS.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, _ = io.WriteString(hash, path+"\x00")
+		if _, err := io.Copy(hash, file); err != nil {
+			return err
+		}
+		_, _ = hash.Write([]byte{0})
+		build.Files++
+		return nil
+	})
+	if err != nil {
+		return Build{}, err
+	}
+	build.Hash = hex.EncodeToString(hash.Sum(nil))
+	return build, nil
+}
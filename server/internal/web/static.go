@@ -0,0 +1,165 @@
+package web
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Cache-Control values for static assets
+const (
+	cacheControlImmutable = "public, max-age=31536000, immutable"
+	cacheControlNoCache   = "no-cache"
+)
+
+// hashedAssetPattern matches content-hashed build output such as main.1a2b3c4d.js
+var hashedAssetPattern = regexp.MustCompile(`\.[0-9a-f]{8,}\.`)
+
+// precompressedEncodings lists pre-compressed variants in order of preference
+var precompressedEncodings = []struct {
+	encoding  string
+	extension string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// staticServer serves files from a filesystem with caching headers
+type staticServer struct {
+	webFS fs.FS
+	etags sync.Map // cache key -> ETag
+}
+
+func newStaticServer(webFS fs.FS) *staticServer {
+	return &staticServer{webFS: webFS}
+}
+
+// serve writes the named file with content type, ETag, Last-Modified and Cache-Control headers,
+// preferring a pre-compressed variant when the client accepts it
+func (s *staticServer) serve(c echo.Context, name string) error {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	header := c.Response().Header()
+
+	servedName, encoding := s.negotiate(c.Request().Header.Get(echo.HeaderAcceptEncoding), name)
+
+	file, err := s.webFS.Open(servedName)
+	if err != nil {
+		return c.String(http.StatusNotFound, "File not found")
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		return c.String(http.StatusNotFound, "File not found")
+	}
+
+	content, ok := file.(io.ReadSeeker)
+	if !ok {
+		return c.String(http.StatusInternalServerError, "File is not seekable")
+	}
+
+	// Set content type from the original (uncompressed) file extension
+	if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+		header.Set(echo.HeaderContentType, contentType)
+	}
+	if encoding != "" {
+		header.Set(echo.HeaderContentEncoding, encoding)
+	}
+	if !varies(header, echo.HeaderAcceptEncoding) {
+		header.Add(echo.HeaderVary, echo.HeaderAcceptEncoding)
+	}
+
+	if hashedAssetPattern.MatchString(path.Base(name)) {
+		header.Set("Cache-Control", cacheControlImmutable)
+	} else {
+		header.Set("Cache-Control", cacheControlNoCache)
+	}
+
+	etag, err := s.etag(servedName, info, content)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "Failed to read file")
+	}
+	header.Set("ETag", etag)
+
+	// ServeContent handles If-None-Match, If-Modified-Since and Range requests
+	http.ServeContent(c.Response(), c.Request(), name, info.ModTime(), content)
+	return nil
+}
+
+// negotiate returns the file to serve and its content encoding based on Accept-Encoding
+func (s *staticServer) negotiate(acceptEncoding, name string) (servedName, encoding string) {
+	for _, variant := range precompressedEncodings {
+		if !acceptsEncoding(acceptEncoding, variant.encoding) {
+			continue
+		}
+		if info, err := fs.Stat(s.webFS, name+variant.extension); err == nil && !info.IsDir() {
+			return name + variant.extension, variant.encoding
+		}
+	}
+	return name, ""
+}
+
+// etag returns a strong ETag for the file content, cached by name, size and modification time
+func (s *staticServer) etag(name string, info fs.FileInfo, content io.ReadSeeker) (string, error) {
+	key := fmt.Sprintf("%s|%d|%d", name, info.Size(), info.ModTime().UnixNano())
+	if cached, ok := s.etags.Load(key); ok {
+		return cached.(string), nil
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	etag := formatETag(hash.Sum(nil))
+	s.etags.Store(key, etag)
+	return etag, nil
+}
+
+// contentETag returns a strong ETag for in-memory content
+func contentETag(content []byte) string {
+	sum := sha256.Sum256(content)
+	return formatETag(sum[:])
+}
+
+// formatETag formats a content hash as a quoted ETag value
+func formatETag(sum []byte) string {
+	return `"` + hex.EncodeToString(sum)[:16] + `"`
+}
+
+// varies reports whether the Vary header already lists the given request header
+func varies(header http.Header, name string) bool {
+	for _, value := range header.Values(echo.HeaderVary) {
+		for _, field := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(field), name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// acceptsEncoding reports whether the Accept-Encoding header allows the given encoding
+func acceptsEncoding(acceptEncoding, encoding string) bool {
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		value, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(value), encoding) {
+			continue
+		}
+		return strings.TrimSpace(strings.ReplaceAll(params, " ", "")) != "q=0"
+	}
+	return false
+}
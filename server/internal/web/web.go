@@ -0,0 +1,143 @@
+package web
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io/fs"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"spacectl-web/server/internal/constants"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Meta tags used to expose server settings to the SPA
+const (
+	BasePathMetaName = "spacectl-base-path"
+	LogoURLMetaName  = "spacectl-logo-url"
+	ColorMetaName    = "spacectl-brand-color"
+)
+
+// Branding represents the title, logo and color injected into index.html; empty values keep the built-in ones
+type Branding struct {
+	Title   string
+	LogoURL string
+	Color   string // CSS color, also exposed as the --brand-color custom property
+}
+
+// titlePattern matches the title element of index.html
+var titlePattern = regexp.MustCompile(`(?s)<title>.*?</title>`)
+
+// rootFiles are served from the root of the web filesystem
+var rootFiles = []string{
+	"/favicon.ico",
+	"/logo192.png",
+	"/logo512.png",
+	"/manifest.json",
+	"/robots.txt",
+}
+
+// NormalizeBasePath returns the base path with a leading slash and without a trailing slash.
+// The root path is returned as an empty string.
+func NormalizeBasePath(basePath string) string {
+	basePath = strings.Trim(basePath, "/")
+	if basePath == "" {
+		return ""
+	}
+	return "/" + basePath
+}
+
+// Setup configures web file serving for the web client under the given base path
+func Setup(e *echo.Echo, webFS fs.FS, basePath string, branding Branding) error {
+	// Fail fast if index.html is missing
+	if _, err := renderIndex(webFS, basePath, branding); err != nil {
+		return err
+	}
+
+	static := newStaticServer(webFS)
+
+	// Serve static files (CSS, JS, etc.) from the static/ subdirectory
+	e.GET(basePath+"/static/*", func(c echo.Context) error {
+		return static.serve(c, "static/"+c.Param("*"))
+	})
+
+	// Serve favicon and other root files
+	for _, name := range rootFiles {
+		e.GET(basePath+name, func(c echo.Context) error {
+			return static.serve(c, name)
+		})
+	}
+
+	// Serve index.html for all non-API routes (SPA routing)
+	spaHandler := func(c echo.Context) error {
+		path := strings.TrimPrefix(c.Request().URL.Path, basePath)
+
+		// If the request is for an API route, return 404
+		if strings.HasPrefix(path, constants.APIPrefix) {
+			return c.String(http.StatusNotFound, "API endpoint not found")
+		}
+
+		// Render index.html per request so changes in an external web directory are picked up
+		index, err := renderIndex(webFS, basePath, branding)
+		if err != nil {
+			return c.String(http.StatusInternalServerError, "Failed to load index.html")
+		}
+
+		c.Response().Header().Set("Cache-Control", cacheControlNoCache)
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMETextHTMLCharsetUTF8)
+		c.Response().Header().Set("ETag", contentETag(index))
+		http.ServeContent(c.Response(), c.Request(), "index.html", time.Time{}, bytes.NewReader(index))
+		return nil
+	}
+	e.GET(basePath+"/*", spaHandler)
+	if basePath != "" {
+		e.GET(basePath, spaHandler)
+	}
+
+	return nil
+}
+
+// renderIndex loads index.html, rewrites root-relative asset URLs to include the base path and applies the branding.
+// The base path and branding are exposed to the SPA through meta tags so no inline script is needed under the CSP.
+func renderIndex(webFS fs.FS, basePath string, branding Branding) ([]byte, error) {
+	index, err := fs.ReadFile(webFS, "index.html")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load index.html: %w", err)
+	}
+
+	var head strings.Builder
+	if basePath != "" {
+		for _, attr := range []string{`href="/`, `src="/`} {
+			index = bytes.ReplaceAll(index, []byte(attr), []byte(attr[:len(attr)-1]+basePath+"/"))
+		}
+		head.WriteString(metaTag(BasePathMetaName, basePath))
+	}
+
+	if branding.Title != "" {
+		title := "<title>" + html.EscapeString(branding.Title) + "</title>"
+		index = titlePattern.ReplaceAllLiteral(index, []byte(title))
+	}
+	if branding.LogoURL != "" {
+		head.WriteString(metaTag(LogoURLMetaName, branding.LogoURL))
+	}
+	if branding.Color != "" {
+		head.WriteString(metaTag(ColorMetaName, branding.Color))
+		head.WriteString(metaTag("theme-color", branding.Color))
+		// The color is validated when the config is loaded, so it cannot break out of the style element
+		head.WriteString("<style>:root{--brand-color:" + branding.Color + "}</style>")
+	}
+
+	if head.Len() > 0 {
+		index = bytes.Replace(index, []byte("<head>"), []byte("<head>"+head.String()), 1)
+	}
+	return index, nil
+}
+
+// metaTag formats a meta tag with an escaped value
+func metaTag(name, content string) string {
+	return fmt.Sprintf(`<meta name="%s" content="%s" />`, name, html.EscapeString(content))
+}
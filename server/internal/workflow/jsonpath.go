@@ -0,0 +1,127 @@
+package workflow
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// segment is one step of a JSONPath expression
+type segment struct {
+	field    string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// Extract evaluates a JSONPath expression on decoded JSON. It supports the subset workflow
+// outputs need: $ for the root, .field and ['field'] for object fields, [n] for list elements
+// (negative indexes count from the end), and [*] or .* for every element, which makes the
+// result a list.
+func Extract(data interface{}, path string) (interface{}, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := []interface{}{data}
+	list := false
+	for _, seg := range segments {
+		var next []interface{}
+		for _, node := range nodes {
+			switch {
+			case seg.wildcard:
+				list = true
+				switch v := node.(type) {
+				case []interface{}:
+					next = append(next, v...)
+				case map[string]interface{}:
+					for _, item := range v {
+						next = append(next, item)
+					}
+				}
+			case seg.isIndex:
+				items, ok := node.([]interface{})
+				if !ok {
+					continue
+				}
+				i := seg.index
+				if i < 0 {
+					i += len(items)
+				}
+				if i >= 0 && i < len(items) {
+					next = append(next, items[i])
+				}
+			default:
+				if fields, ok := node.(map[string]interface{}); ok {
+					if value, found := fields[seg.field]; found {
+						next = append(next, value)
+					}
+				}
+			}
+		}
+		nodes = next
+	}
+
+	if list {
+		if nodes == nil {
+			nodes = []interface{}{}
+		}
+		return nodes, nil
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("%s matched nothing", path)
+	}
+	return nodes[0], nil
+}
+
+// parsePath splits a JSONPath expression into segments
+func parsePath(path string) ([]segment, error) {
+	rest, found := strings.CutPrefix(strings.TrimSpace(path), "$")
+	if !found {
+		return nil, fmt.Errorf("JSONPath %q must start with $", path)
+	}
+	var segments []segment
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			if name == "" {
+				return nil, fmt.Errorf("JSONPath %q has an empty field name", path)
+			}
+			if name == "*" {
+				segments = append(segments, segment{wildcard: true})
+			} else {
+				segments = append(segments, segment{field: name})
+			}
+			rest = rest[end:]
+		case '[':
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("JSONPath %q has an unclosed [", path)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			switch {
+			case inner == "*":
+				segments = append(segments, segment{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				segments = append(segments, segment{field: inner[1 : len(inner)-1]})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("JSONPath %q has an invalid index [%s]", path, inner)
+				}
+				segments = append(segments, segment{index: index, isIndex: true})
+			}
+		default:
+			return nil, fmt.Errorf("JSONPath %q has an unexpected %q", path, rest[0])
+		}
+	}
+	return segments, nil
+}
@@ -0,0 +1,138 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"spacectl-web/server/internal/storage"
+)
+
+// scope holds the values references resolve to: inputs.<name> and steps.<step>.<output>
+type scope map[string]interface{}
+
+// lookup returns the value of a reference, or false when it has none
+func (s scope) lookup(reference string) (interface{}, bool) {
+	var value interface{} = map[string]interface{}(s)
+	for _, name := range strings.Split(reference, ".") {
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		value, ok = fields[name]
+		if !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// resolve replaces the references in a parameter value. A string that is a single reference
+// becomes the referenced value; references inside longer strings are replaced by their text.
+func (s scope) resolve(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		if match := storage.WorkflowReference.FindStringSubmatchIndex(v); match != nil && match[0] == 0 && match[1] == len(v) {
+			reference := v[match[2]:match[3]]
+			resolved, ok := s.lookup(reference)
+			if !ok {
+				return nil, fmt.Errorf("${%s} has no value", reference)
+			}
+			return resolved, nil
+		}
+		return s.interpolate(v)
+	case map[string]interface{}:
+		resolved := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			r, err := s.resolve(item)
+			if err != nil {
+				return nil, err
+			}
+			resolved[key] = r
+		}
+		return resolved, nil
+	case []interface{}:
+		resolved := make([]interface{}, len(v))
+		for i, item := range v {
+			r, err := s.resolve(item)
+			if err != nil {
+				return nil, err
+			}
+			resolved[i] = r
+		}
+		return resolved, nil
+	}
+	return value, nil
+}
+
+// interpolate replaces each reference in a string by the text of its value
+func (s scope) interpolate(text string) (string, error) {
+	var missing error
+	result := storage.WorkflowReference.ReplaceAllStringFunc(text, func(match string) string {
+		reference := storage.WorkflowReference.FindStringSubmatch(match)[1]
+		value, ok := s.lookup(reference)
+		if !ok {
+			if missing == nil {
+				missing = fmt.Errorf("${%s} has no value", reference)
+			}
+			return ""
+		}
+		return valueText(value)
+	})
+	return result, missing
+}
+
+// condition evaluates a step's when expression: "a == b" and "a != b" compare the text of
+// both sides, and anything else is true unless it is empty, false, 0, null, or a reference
+// without a value, such as an output of a skipped step
+func (s scope) condition(when string) bool {
+	for _, operator := range []string{"==", "!="} {
+		left, right, found := strings.Cut(when, operator)
+		if !found {
+			continue
+		}
+		equal := s.operand(left) == s.operand(right)
+		return equal == (operator == "==")
+	}
+
+	when = strings.TrimSpace(when)
+	if match := storage.WorkflowReference.FindStringSubmatchIndex(when); match != nil && match[0] == 0 && match[1] == len(when) {
+		value, ok := s.lookup(when[match[2]:match[3]])
+		if !ok {
+			return false
+		}
+		switch v := value.(type) {
+		case []interface{}:
+			return len(v) > 0
+		case map[string]interface{}:
+			return len(v) > 0
+		}
+		when = valueText(value)
+	}
+	switch strings.ToLower(when) {
+	case "", "false", "0", "null":
+		return false
+	}
+	return true
+}
+
+// operand returns the text of one side of a comparison, without surrounding quotes
+func (s scope) operand(text string) string {
+	text, _ = s.interpolate(strings.TrimSpace(text))
+	if len(text) >= 2 && (text[0] == '\'' || text[0] == '"') && text[len(text)-1] == text[0] {
+		return text[1 : len(text)-1]
+	}
+	return text
+}
+
+// valueText returns a value as text: strings as they are, and anything else as JSON
+func valueText(value interface{}) string {
+	if text, ok := value.(string); ok {
+		return text
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
@@ -0,0 +1,269 @@
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"spacectl-web/server/internal/grpc"
+	"spacectl-web/server/internal/storage"
+)
+
+// stepTimeout bounds a single call of a workflow step
+const stepTimeout = 5 * time.Minute
+
+// defaultRetryDelay is the wait between attempts of a step without retry_delay_seconds
+const defaultRetryDelay = 5 * time.Second
+
+// runLimit is the number of runs kept per user; older runs are deleted
+const runLimit = 100
+
+// saveTimeout bounds saving the progress of a run
+const saveTimeout = 5 * time.Second
+
+// Validator checks that a call may be made, e.g. that its verb exists and is allowed in
+// read-only mode
+type Validator func(serviceName, resourceName, verb string) error
+
+// Engine runs workflows in the background, saving the progress of each run so it can be
+// followed and canceled
+type Engine struct {
+	store    storage.Store
+	manager  *grpc.ClientManager
+	validate Validator
+	running  map[string]context.CancelFunc
+	mutex    sync.Mutex
+}
+
+// New creates an engine that calls services through the manager, after checking each step's
+// call with validate, and saves runs in the store
+func New(store storage.Store, manager *grpc.ClientManager, validate Validator) *Engine {
+	return &Engine{store: store, manager: manager, validate: validate, running: make(map[string]context.CancelFunc)}
+}
+
+// MissingInputs returns the inputs a workflow declares that have no value
+func MissingInputs(workflow *storage.Workflow, inputs map[string]interface{}) []string {
+	var missing []string
+	for _, input := range workflow.Inputs {
+		if _, found := inputs[input]; !found {
+			missing = append(missing, input)
+		}
+	}
+	return missing
+}
+
+// Start saves a new run of a workflow for owner and runs its steps in the background. The
+// run keeps the values of ctx, such as a session's scoped token or impersonation, but not its
+// cancellation. The returned record is the run as started.
+func (e *Engine) Start(ctx context.Context, owner, workflowID string, workflow *storage.Workflow, inputs map[string]interface{}) (*storage.Record, error) {
+	if inputs == nil {
+		inputs = make(map[string]interface{})
+	}
+	run := &storage.WorkflowRun{
+		WorkflowID:   workflowID,
+		WorkflowName: workflow.Name,
+		Status:       storage.WorkflowRunning,
+		Inputs:       inputs,
+		Steps:        make([]storage.WorkflowStepRun, len(workflow.Steps)),
+	}
+	for i, step := range workflow.Steps {
+		run.Steps[i] = storage.WorkflowStepRun{Name: step.Name, Status: storage.WorkflowPending}
+	}
+	data, err := json.Marshal(run)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	record := &storage.Record{Kind: storage.KindWorkflowRun, ID: storage.NewID(), Owner: owner, Data: data, CreatedAt: now, UpdatedAt: now}
+	if err := e.store.Put(ctx, record); err != nil {
+		return nil, err
+	}
+	if err := e.store.Trim(ctx, storage.KindWorkflowRun, owner, runLimit); err != nil {
+		log.Printf("WARNING: failed to trim workflow runs: %v", err)
+	}
+
+	runCtx, cancel := context.WithCancel(grpc.WithRequestID(context.WithoutCancel(ctx), record.ID))
+	e.mutex.Lock()
+	e.running[record.ID] = cancel
+	e.mutex.Unlock()
+
+	progress := *record
+	go func() {
+		defer func() {
+			e.mutex.Lock()
+			delete(e.running, record.ID)
+			e.mutex.Unlock()
+			cancel()
+		}()
+		e.execute(runCtx, &progress, workflow, run)
+	}()
+	return record, nil
+}
+
+// Cancel stops a run and reports whether it was running
+func (e *Engine) Cancel(runID string) bool {
+	e.mutex.Lock()
+	cancel, found := e.running[runID]
+	e.mutex.Unlock()
+	if found {
+		cancel()
+	}
+	return found
+}
+
+// Running reports whether a run is in progress on this server
+func (e *Engine) Running(runID string) bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	_, found := e.running[runID]
+	return found
+}
+
+// execute runs the steps in order, skipping those whose condition is false, until one fails
+// or the run is canceled
+func (e *Engine) execute(ctx context.Context, record *storage.Record, workflow *storage.Workflow, run *storage.WorkflowRun) {
+	values := scope{"inputs": run.Inputs, "steps": map[string]interface{}{}}
+	outcome := storage.WorkflowSucceeded
+	for i := range workflow.Steps {
+		step := &workflow.Steps[i]
+		progress := &run.Steps[i]
+		if ctx.Err() != nil {
+			outcome = storage.WorkflowCanceled
+			break
+		}
+
+		started := time.Now()
+		progress.StartedAt = &started
+		if step.When != "" && !values.condition(step.When) {
+			progress.Status = storage.WorkflowSkipped
+			progress.FinishedAt = &started
+			e.save(record, run)
+			continue
+		}
+		progress.Status = storage.WorkflowRunning
+		outputs, err := e.runStep(ctx, step, progress, values, record, run)
+		finished := time.Now()
+		progress.FinishedAt = &finished
+		if err != nil {
+			progress.Status = storage.WorkflowFailed
+			outcome = storage.WorkflowFailed
+			if ctx.Err() != nil {
+				progress.Status = storage.WorkflowCanceled
+				outcome = storage.WorkflowCanceled
+			}
+			progress.Error = err.Error()
+			run.Error = fmt.Sprintf("step '%s' failed: %v", step.Name, err)
+			break
+		}
+		progress.Status = storage.WorkflowSucceeded
+		progress.Error = ""
+		progress.Outputs = outputs
+		values["steps"].(map[string]interface{})[step.Name] = outputs
+		e.save(record, run)
+	}
+
+	if outcome == storage.WorkflowCanceled {
+		run.Error = "the run was canceled"
+	}
+	finished := time.Now()
+	run.Status = outcome
+	run.FinishedAt = &finished
+	e.save(record, run)
+}
+
+// runStep calls a step's request with its references resolved, retrying failed calls, and
+// returns the outputs extracted from the response
+func (e *Engine) runStep(ctx context.Context, step *storage.WorkflowStep, progress *storage.WorkflowStepRun,
+	values scope, record *storage.Record, run *storage.WorkflowRun) (map[string]interface{}, error) {
+	resolved, err := values.resolve(step.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	parameters, _ := resolved.(map[string]interface{})
+	if parameters == nil {
+		parameters = make(map[string]interface{})
+	}
+	delay := defaultRetryDelay
+	if step.RetryDelaySeconds > 0 {
+		delay = time.Duration(step.RetryDelaySeconds) * time.Second
+	}
+
+	for attempt := 0; ; attempt++ {
+		progress.Attempts = attempt + 1
+		e.save(record, run)
+		jsonBytes, err := e.call(ctx, &step.Request, parameters)
+		if err == nil {
+			return outputs(jsonBytes, step.Outputs)
+		}
+		if attempt >= step.Retries || ctx.Err() != nil {
+			return nil, err
+		}
+
+		// Show why the step is waiting to retry
+		progress.Error = err.Error()
+		e.save(record, run)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// call validates a step's call and calls its service with the resolved parameters
+func (e *Engine) call(ctx context.Context, request *storage.Request, parameters map[string]interface{}) ([]byte, error) {
+	if err := e.validate(request.Service, request.Resource, request.Verb); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, stepTimeout)
+	defer cancel()
+	caller, err := e.manager.GetServiceCaller(request.Service)
+	if err != nil {
+		return nil, err
+	}
+	return caller.CallMethod(ctx, request.Service, request.Resource, request.Verb, parameters)
+}
+
+// outputs evaluates a step's output expressions on its response
+func outputs(jsonBytes []byte, expressions map[string]string) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(expressions))
+	if len(expressions) == 0 {
+		return values, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(jsonBytes))
+	decoder.UseNumber() // Keep large IDs and counts exact
+	var response interface{}
+	if err := decoder.Decode(&response); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	for name, expression := range expressions {
+		value, err := Extract(response, expression)
+		if err != nil {
+			return nil, fmt.Errorf("output '%s': %w", name, err)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// save stores the progress of a run. Failures are logged, since the run goes on regardless.
+func (e *Engine) save(record *storage.Record, run *storage.WorkflowRun) {
+	data, err := json.Marshal(run)
+	if err != nil {
+		log.Printf("WARNING: failed to save workflow run %s: %v", record.ID, err)
+		return
+	}
+	record.Data = data
+	record.UpdatedAt = time.Now()
+
+	// Save even when the run was canceled, so the cancellation is recorded
+	ctx, cancel := context.WithTimeout(context.Background(), saveTimeout)
+	defer cancel()
+	if err := e.store.Put(ctx, record); err != nil {
+		log.Printf("WARNING: failed to save workflow run %s: %v", record.ID, err)
+	}
+}